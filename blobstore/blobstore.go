@@ -0,0 +1,167 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+var (
+	// ErrNotExist is returned when object doesn't exist.
+	ErrNotExist = errors.New("blobstore: object doesn't exist")
+
+	// ErrNotSupported is returned by Watch if bucket doesn't support
+	// watching. Caller would poll the bucket instead.
+	ErrNotSupported = errors.New("blobstore: not supported")
+)
+
+// Attrs is attributes of an object.
+type Attrs struct {
+	Name    string
+	Size    int64
+	Updated time.Time
+
+	// Expire is expiration time of the object. Zero means no expiration.
+	// Expired object may still exist, so caller needs to check it.
+	Expire time.Time
+
+	// CRC32C is CRC-32C checksum of the content.
+	CRC32C uint32
+	// MD5 is MD5 hash of the content. nil if checksums are not
+	// available, e.g. for files not written by Put in Local.
+	MD5 []byte
+}
+
+// PutOpts is options of Put.
+type PutOpts struct {
+	// Expire is expiration time of the object. Zero means no expiration.
+	Expire time.Time
+}
+
+// Watcher watches updates of objects.
+type Watcher interface {
+	// Next waits for updates, and returns names of updated or deleted
+	// objects. It returns all pending updates at once, so names may
+	// have duplicates.
+	Next(ctx context.Context) ([]string, error)
+
+	// Close stops watching.
+	Close() error
+}
+
+// Bucket is a bucket of blob store.
+// Object name is slash separated path.
+type Bucket interface {
+	// Attrs returns attributes of the object.
+	// It returns ErrNotExist if the object doesn't exist.
+	Attrs(ctx context.Context, name string) (*Attrs, error)
+
+	// Get returns content of the object.
+	// It returns ErrNotExist if the object doesn't exist.
+	Get(ctx context.Context, name string) ([]byte, error)
+
+	// Put stores data as the object, replacing existing object.
+	Put(ctx context.Context, name string, data []byte, opts PutOpts) (*Attrs, error)
+
+	// Delete deletes the object.
+	// It returns ErrNotExist if the object doesn't exist.
+	Delete(ctx context.Context, name string) error
+
+	// List returns attributes of objects whose name has prefix,
+	// sorted by name.
+	List(ctx context.Context, prefix string) ([]*Attrs, error)
+
+	// Watch watches updates of objects whose name has prefix.
+	// It returns ErrNotSupported if the bucket can't watch updates.
+	Watch(ctx context.Context, prefix string) (Watcher, error)
+}
+
+// ExpireSetter is implemented by Bucket that can update expiration time
+// of the object without rewriting its content.
+type ExpireSetter interface {
+	// SetExpire sets expiration time of the object to expire.
+	// It returns ErrNotExist if the object doesn't exist.
+	SetExpire(ctx context.Context, name string, expire time.Time) (*Attrs, error)
+}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksums returns CRC-32C and MD5 of data.
+func checksums(data []byte) (uint32, []byte) {
+	md5sum := md5.Sum(data)
+	return crc32.Checksum(data, crc32cTable), md5sum[:]
+}
+
+// Options is options to open bucket.
+type Options struct {
+	// GCSClientOptions are options of cloud storage client.
+	GCSClientOptions []option.ClientOption
+
+	// PubsubClient and SubscriberID are used to watch cloud storage
+	// bucket. If PubsubClient is nil, Watch returns ErrNotSupported.
+	PubsubClient *pubsub.Client
+	SubscriberID string
+
+	// S3Endpoint is endpoint URL of S3 compatible storage,
+	// e.g. "http://minio:9000". Empty uses AWS endpoint.
+	S3Endpoint string
+	// S3Region is region of S3 bucket.
+	S3Region string
+}
+
+// Open opens bucket specified by uri.
+//
+//	gs://<bucket>  google cloud storage bucket.
+//	s3://<bucket>  S3 compatible storage bucket.
+//	file://<dir>   local directory. e.g. file:///var/cache/goma
+//	<bucket>       google cloud storage bucket.
+//
+// Credentials of S3 are read from environment variables or shared
+// credentials file, as AWS SDK does.
+// Client created for the bucket is used until the end of the process.
+func Open(ctx context.Context, uri string, opts Options) (Bucket, error) {
+	if !strings.Contains(uri, "://") {
+		uri = "gs://" + uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "gs":
+		client, err := storage.NewClient(ctx, opts.GCSClientOptions...)
+		if err != nil {
+			return nil, err
+		}
+		b := NewGCS(client, u.Host)
+		b.PubsubClient = opts.PubsubClient
+		b.SubscriberID = opts.SubscriberID
+		return b, nil
+	case "s3":
+		return NewS3(u.Host, S3Opts{
+			Endpoint: opts.S3Endpoint,
+			Region:   opts.S3Region,
+		})
+	case "file":
+		dir := u.Path
+		if u.Host != "" {
+			// relative path. e.g. file://cache/dir
+			dir = u.Host + u.Path
+		}
+		return NewLocal(dir)
+	}
+	return nil, fmt.Errorf("blobstore: unknown scheme %q", u.Scheme)
+}
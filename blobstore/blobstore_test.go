@@ -0,0 +1,135 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// testBucket checks bkt behaves as Bucket.
+func testBucket(t *testing.T, bkt Bucket) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := bkt.Attrs(ctx, "dir/obj")
+	if err != ErrNotExist {
+		t.Errorf("Attrs(dir/obj)=_, %v; want ErrNotExist", err)
+	}
+	_, err = bkt.Get(ctx, "dir/obj")
+	if err != ErrNotExist {
+		t.Errorf("Get(dir/obj)=_, %v; want ErrNotExist", err)
+	}
+	err = bkt.Delete(ctx, "dir/obj")
+	if err != ErrNotExist {
+		t.Errorf("Delete(dir/obj)=%v; want ErrNotExist", err)
+	}
+
+	data := []byte("hello, world\n")
+	crc32c, md5sum := checksums(data)
+	expire := time.Now().Add(time.Hour).Round(time.Second)
+	for _, name := range []string{"dir/obj", "dir/sub/obj", "other"} {
+		_, err = bkt.Put(ctx, name, data, PutOpts{Expire: expire})
+		if err != nil {
+			t.Fatalf("Put(%s)=_, %v; want nil error", name, err)
+		}
+	}
+	attrs, err := bkt.Attrs(ctx, "dir/obj")
+	if err != nil {
+		t.Fatalf("Attrs(dir/obj)=_, %v; want nil error", err)
+	}
+	if attrs.Name != "dir/obj" || attrs.Size != int64(len(data)) || !attrs.Expire.Equal(expire) || attrs.CRC32C != crc32c || !bytes.Equal(attrs.MD5, md5sum) {
+		t.Errorf("Attrs(dir/obj)=%#v; want name:dir/obj size:%d expire:%s crc32c:%x md5:%x", attrs, len(data), expire, crc32c, md5sum)
+	}
+	got, err := bkt.Get(ctx, "dir/obj")
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get(dir/obj)=%q, %v; want %q, nil", got, err, data)
+	}
+
+	if s, ok := bkt.(ExpireSetter); ok {
+		later := expire.Add(time.Hour)
+		_, err = s.SetExpire(ctx, "dir/obj", later)
+		if err != nil {
+			t.Fatalf("SetExpire(dir/obj)=_, %v; want nil error", err)
+		}
+		attrs, err = bkt.Attrs(ctx, "dir/obj")
+		if err != nil || !attrs.Expire.Equal(later) || attrs.CRC32C != crc32c || !bytes.Equal(attrs.MD5, md5sum) {
+			t.Errorf("Attrs(dir/obj) after SetExpire=%#v, %v; want expire:%s crc32c:%x md5:%x", attrs, err, later, crc32c, md5sum)
+		}
+		got, err = bkt.Get(ctx, "dir/obj")
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("Get(dir/obj) after SetExpire=%q, %v; want %q, nil", got, err, data)
+		}
+		_, err = s.SetExpire(ctx, "dir/none", later)
+		if err != ErrNotExist {
+			t.Errorf("SetExpire(dir/none)=_, %v; want ErrNotExist", err)
+		}
+	}
+
+	// overwrite without expiration.
+	_, err = bkt.Put(ctx, "dir/obj", []byte("updated"), PutOpts{})
+	if err != nil {
+		t.Fatalf("Put(dir/obj)=_, %v; want nil error", err)
+	}
+	attrs, err = bkt.Attrs(ctx, "dir/obj")
+	if err != nil || attrs.Size != int64(len("updated")) || !attrs.Expire.IsZero() {
+		t.Errorf("Attrs(dir/obj)=%#v, %v; want size:%d no expire", attrs, err, len("updated"))
+	}
+
+	list, err := bkt.List(ctx, "dir/")
+	if err != nil {
+		t.Fatalf("List(dir/)=_, %v; want nil error", err)
+	}
+	var names []string
+	for _, a := range list {
+		names = append(names, a.Name)
+	}
+	if len(names) != 2 || names[0] != "dir/obj" || names[1] != "dir/sub/obj" {
+		t.Errorf("List(dir/)=%q; want [dir/obj dir/sub/obj]", names)
+	}
+
+	err = bkt.Delete(ctx, "dir/obj")
+	if err != nil {
+		t.Errorf("Delete(dir/obj)=%v; want nil error", err)
+	}
+	_, err = bkt.Get(ctx, "dir/obj")
+	if err != ErrNotExist {
+		t.Errorf("Get(dir/obj) after delete=_, %v; want ErrNotExist", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "blobstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bkt, err := Open(ctx, "file://"+dir, Options{})
+	if err != nil {
+		t.Fatalf("Open(file://%s)=_, %v; want nil error", dir, err)
+	}
+	if b, ok := bkt.(*Local); !ok || b.Dir() != dir {
+		t.Errorf("Open(file://%s)=%#v; want Local on %s", dir, bkt, dir)
+	}
+
+	bkt, err = Open(ctx, "s3://goma-cache", Options{S3Endpoint: "http://localhost:9000"})
+	if err != nil {
+		t.Fatalf("Open(s3://goma-cache)=_, %v; want nil error", err)
+	}
+	if b, ok := bkt.(*S3); !ok || b.Name() != "goma-cache" {
+		t.Errorf("Open(s3://goma-cache)=%#v; want S3 goma-cache", bkt)
+	}
+
+	_, err = Open(ctx, "ftp://goma-cache", Options{})
+	if err == nil {
+		t.Errorf("Open(ftp://goma-cache)=_, nil; want error")
+	}
+}
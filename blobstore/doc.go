@@ -0,0 +1,10 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Package blobstore provides blob store bucket interface, with
+implementations by google cloud storage, local filesystem and
+S3 compatible storage.
+*/
+package blobstore
@@ -0,0 +1,330 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// GCS is a bucket on google cloud storage.
+//
+// Object's CustomTime is used as expiration time of the object.
+// Bucket's lifecycle rule (daysSinceCustomTime) may delete
+// expired objects.
+type GCS struct {
+	name string
+	bkt  *storage.BucketHandle
+
+	// PubsubClient is used to watch the bucket via default notification
+	// topic on the bucket, created by
+	//  $ gsutil notification create -f json <bucket>
+	PubsubClient *pubsub.Client
+
+	// SubscriberID should be unique per each server instance
+	// to get notification in every server instance.
+	SubscriberID string
+}
+
+// NewGCS creates bucket of name on google cloud storage.
+func NewGCS(client *storage.Client, name string) *GCS {
+	return &GCS{
+		name: name,
+		bkt:  client.Bucket(name),
+	}
+}
+
+// Name returns name of the bucket.
+func (b *GCS) Name() string {
+	return b.name
+}
+
+func gcsAttrs(attr *storage.ObjectAttrs) *Attrs {
+	return &Attrs{
+		Name:    attr.Name,
+		Size:    attr.Size,
+		Updated: attr.Updated,
+		Expire:  attr.CustomTime,
+		CRC32C:  attr.CRC32C,
+		MD5:     attr.MD5,
+	}
+}
+
+func gcsError(err error) error {
+	if err == storage.ErrObjectNotExist {
+		return ErrNotExist
+	}
+	return err
+}
+
+// Attrs returns attributes of the object.
+func (b *GCS) Attrs(ctx context.Context, name string) (*Attrs, error) {
+	attr, err := b.bkt.Object(name).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return gcsAttrs(attr), nil
+}
+
+// Get returns content of the object.
+func (b *GCS) Get(ctx context.Context, name string) ([]byte, error) {
+	r, err := b.bkt.Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	defer r.Close()
+	buf := make([]byte, r.Attrs.Size)
+	_, err = io.ReadFull(r, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Put stores data as the object.
+// It sends CRC-32C of data, so cloud storage rejects corrupted upload.
+func (b *GCS) Put(ctx context.Context, name string, data []byte, opts PutOpts) (*Attrs, error) {
+	crc32c, _ := checksums(data)
+	w := b.bkt.Object(name).NewWriter(ctx)
+	w.CustomTime = opts.Expire
+	w.CRC32C = crc32c
+	w.SendCRC32C = true
+	w.ChunkSize = len(data)
+	if w.ChunkSize > googleapi.DefaultUploadChunkSize {
+		w.ChunkSize = googleapi.DefaultUploadChunkSize
+	}
+	if _, err := w.Write(data); err != nil {
+		w.CloseWithError(err)
+		return nil, fmt.Errorf("write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("close: %w", err)
+	}
+	return gcsAttrs(w.Attrs()), nil
+}
+
+// SetExpire sets expiration time of the object by updating its CustomTime.
+// CustomTime can't be removed or set to earlier time once set.
+func (b *GCS) SetExpire(ctx context.Context, name string, expire time.Time) (*Attrs, error) {
+	if expire.IsZero() {
+		return nil, errors.New("blobstore: can't remove expiration")
+	}
+	attr, err := b.bkt.Object(name).Update(ctx, storage.ObjectAttrsToUpdate{
+		CustomTime: expire,
+	})
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return gcsAttrs(attr), nil
+}
+
+// Delete deletes the object.
+func (b *GCS) Delete(ctx context.Context, name string) error {
+	return gcsError(b.bkt.Object(name).Delete(ctx))
+}
+
+// List returns attributes of objects whose name has prefix.
+func (b *GCS) List(ctx context.Context, prefix string) ([]*Attrs, error) {
+	iter := b.bkt.Objects(ctx, &storage.Query{
+		Prefix: prefix,
+	})
+	var attrs []*Attrs
+	for {
+		attr, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, gcsAttrs(attr))
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name < attrs[j].Name
+	})
+	return attrs, nil
+}
+
+// Watch watches the bucket via pubsub notification.
+// It creates subscription of SubscriberID if not exists.
+func (b *GCS) Watch(ctx context.Context, prefix string) (Watcher, error) {
+	if b.PubsubClient == nil {
+		return nil, ErrNotSupported
+	}
+	logger := log.FromContext(ctx)
+	notification, err := b.notification(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("topic: %s in %s", notification.TopicID, notification.TopicProjectID)
+	topic := b.PubsubClient.TopicInProject(notification.TopicID, notification.TopicProjectID)
+	ok, err := topic.Exists(ctx)
+	if !ok || err != nil {
+		return nil, fmt.Errorf("notification topic:%s (notification:%#v): not exist: %v", topic, notification, err)
+	}
+	if b.SubscriberID == "" {
+		return nil, errors.New("SubscriberID is not specified")
+	}
+	subscription := b.PubsubClient.Subscription(b.SubscriberID)
+	ok, err = subscription.Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("subscription:%s err:%v", b.SubscriberID, err)
+	}
+	if ok {
+		sc, err := subscription.Config(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("subscription config:%s err:%v", b.SubscriberID, err)
+		}
+		if sc.Topic.String() != topic.String() {
+			return nil, fmt.Errorf("topic mismatch? %s != %s. delete subscription:%s", sc.Topic, topic, b.SubscriberID)
+		}
+	} else {
+		logger.Infof("subscriber:%s not found. creating", b.SubscriberID)
+		subscription, err = b.PubsubClient.CreateSubscription(ctx, b.SubscriberID, pubsub.SubscriptionConfig{
+			Topic: topic,
+			// experimental config.
+			// minimum is 1 day
+			// +12 hours margin, to cover summar time switch (+1 hour)
+			// b/112820308
+			ExpirationPolicy: 36 * time.Hour,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create subscription:%s err:%v", b.SubscriberID, err)
+		}
+	}
+	wctx, cancel := context.WithCancel(context.Background())
+	w := gcsWatcher{
+		prefix: prefix,
+		s:      subscription,
+		cancel: cancel,
+		ch:     make(chan *pubsub.Message),
+	}
+	go w.run(wctx)
+	return w, nil
+}
+
+func (b *GCS) notification(ctx context.Context) (*storage.Notification, error) {
+	nm, err := b.bkt.Notifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range nm {
+		// use default topic, created by
+		//  $ gsutil notification create -f json <bucket>
+		// json payload will be:
+		// https://cloud.google.com/storage/docs/json_api/v1/objects#resource-representations
+		// we don't use json payload, so '-f none' is ok too.
+		if n.TopicID == b.name {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("notification:%s not found in %v", b.name, nm)
+}
+
+type gcsWatcher struct {
+	prefix string
+	s      *pubsub.Subscription
+	cancel func()
+	ch     chan *pubsub.Message
+}
+
+func (w gcsWatcher) run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	logger.Infof("watch start")
+	err := w.s.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		logger.Debugf("receive message: %s", msg.ID)
+		w.ch <- msg
+	})
+	if err != nil {
+		logger.Errorf("gcsWatcher.run: %v", err)
+	}
+	close(w.ch)
+	logger.Infof("watch finished")
+}
+
+// handle acks msg and returns updated object name in msg, or "" if msg
+// is not an update of objects under the prefix.
+func (w gcsWatcher) handle(ctx context.Context, msg *pubsub.Message) string {
+	logger := log.FromContext(ctx)
+	// https://cloud.google.com/storage/docs/pubsub-notifications#attributes
+	eventType := msg.Attributes["eventType"]
+	objectID := msg.Attributes["objectId"]
+	objectGeneration := msg.Attributes["objectGeneration"]
+	eventTime := msg.Attributes["eventTime"]
+	logger.Debugf("handle message: %s eventType:%s objectId:%s", msg.ID, eventType, objectID)
+
+	// ok to ack because we use notification as trigger only.
+	msg.Ack()
+
+	switch eventType {
+	case storage.ObjectFinalizeEvent, storage.ObjectDeleteEvent:
+	default:
+		return ""
+	}
+	if !strings.HasPrefix(objectID, w.prefix) {
+		return ""
+	}
+	logger.Infof("%s %s gen:%s at %s (published:%s)", objectID, eventType, objectGeneration, eventTime, msg.PublishTime)
+	return objectID
+}
+
+func (w gcsWatcher) Next(ctx context.Context) ([]string, error) {
+	var names []string
+	for len(names) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case msg, ok := <-w.ch:
+			if !ok {
+				return nil, errors.New("watcher closed")
+			}
+			if name := w.handle(ctx, msg); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	// drain pending messages.
+	for {
+		select {
+		case msg, ok := <-w.ch:
+			if !ok {
+				return names, nil
+			}
+			if name := w.handle(ctx, msg); name != "" {
+				names = append(names, name)
+			}
+		default:
+			return names, nil
+		}
+	}
+}
+
+func (w gcsWatcher) Close() error {
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	logger.Infof("watcher close")
+	w.cancel() // finish w.s.Receive in run.
+	// drain ch
+	go func() {
+		for msg := range w.ch {
+			logger.Debugf("drain message: %s", msg.ID)
+			msg.Ack()
+		}
+	}()
+	logger.Infof("delete subscription: %s", w.s)
+	return w.s.Delete(ctx)
+}
@@ -0,0 +1,510 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go.chromium.org/goma/server/fswatch"
+	"go.chromium.org/goma/server/log"
+)
+
+// Local is a bucket on local directory.
+// Object is stored as a file in the directory, and its attributes
+// (expiration time and checksums) are stored in ".<name>.meta" file
+// in the same directory.
+// Files and directories starting with "." are not objects.
+// Symlinks are followed.
+type Local struct {
+	dir string
+}
+
+// NewLocal creates bucket on dir.
+func NewLocal(dir string) (*Local, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("blobstore: %s is not a directory", dir)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Dir returns directory of the bucket.
+func (b *Local) Dir() string {
+	return b.dir
+}
+
+// localMeta is content of meta file.
+type localMeta struct {
+	// Size and ModTime are those of the object when the meta is
+	// written, to detect the object updated by others.
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	Expire time.Time `json:"expire,omitempty"`
+	CRC32C uint32    `json:"crc32c"`
+	MD5    []byte    `json:"md5"`
+}
+
+func (b *Local) filename(name string) (string, error) {
+	if name == "" || path.Clean(name) != name || path.IsAbs(name) {
+		return "", fmt.Errorf("blobstore: invalid name %q", name)
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if strings.HasPrefix(elem, ".") {
+			return "", fmt.Errorf("blobstore: invalid name %q", name)
+		}
+	}
+	return filepath.Join(b.dir, filepath.FromSlash(name)), nil
+}
+
+func metaFilename(fname string) string {
+	return filepath.Join(filepath.Dir(fname), "."+filepath.Base(fname)+".meta")
+}
+
+func (b *Local) attrs(name, fname string, fi os.FileInfo) *Attrs {
+	attrs := &Attrs{
+		Name:    name,
+		Size:    fi.Size(),
+		Updated: fi.ModTime(),
+	}
+	buf, err := ioutil.ReadFile(metaFilename(fname))
+	if err != nil {
+		return attrs
+	}
+	var meta localMeta
+	err = json.Unmarshal(buf, &meta)
+	if err != nil || meta.Size != fi.Size() || !meta.ModTime.Equal(fi.ModTime()) {
+		// stale meta.
+		return attrs
+	}
+	attrs.Expire = meta.Expire
+	attrs.CRC32C = meta.CRC32C
+	attrs.MD5 = meta.MD5
+	return attrs
+}
+
+// Attrs returns attributes of the object.
+// Checksums are not available for files not written by Put.
+func (b *Local) Attrs(ctx context.Context, name string) (*Attrs, error) {
+	fname, err := b.filename(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(fname)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, ErrNotExist
+	}
+	return b.attrs(name, fname, fi), nil
+}
+
+// Get returns content of the object.
+func (b *Local) Get(ctx context.Context, name string) ([]byte, error) {
+	fname, err := b.filename(name)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := ioutil.ReadFile(fname)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	return buf, err
+}
+
+// writeFile writes data to fname atomically.
+func writeFile(fname string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(fname), "."+filepath.Base(fname)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fname)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Put stores data as the object.
+func (b *Local) Put(ctx context.Context, name string, data []byte, opts PutOpts) (*Attrs, error) {
+	fname, err := b.filename(name)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Dir(fname), 0755)
+	if err != nil {
+		return nil, err
+	}
+	err = writeFile(fname, data)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(fname)
+	if err != nil {
+		return nil, err
+	}
+	crc32c, md5sum := checksums(data)
+	return writeMeta(name, fname, fi, opts.Expire, crc32c, md5sum)
+}
+
+// writeMeta writes meta file of the object, and returns its attributes.
+func writeMeta(name, fname string, fi os.FileInfo, expire time.Time, crc32c uint32, md5sum []byte) (*Attrs, error) {
+	meta := localMeta{
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+		Expire:  expire,
+		CRC32C:  crc32c,
+		MD5:     md5sum,
+	}
+	buf, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	err = writeFile(metaFilename(fname), buf)
+	if err != nil {
+		return nil, err
+	}
+	return &Attrs{
+		Name:    name,
+		Size:    fi.Size(),
+		Updated: fi.ModTime(),
+		Expire:  expire,
+		CRC32C:  crc32c,
+		MD5:     md5sum,
+	}, nil
+}
+
+// SetExpire sets expiration time of the object by rewriting its meta file.
+// For files not written by Put, it computes checksums from the content.
+func (b *Local) SetExpire(ctx context.Context, name string, expire time.Time) (*Attrs, error) {
+	fname, err := b.filename(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(fname)
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, ErrNotExist
+	}
+	attrs := b.attrs(name, fname, fi)
+	if attrs.MD5 == nil {
+		data, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		attrs.CRC32C, attrs.MD5 = checksums(data)
+	}
+	return writeMeta(name, fname, fi, expire, attrs.CRC32C, attrs.MD5)
+}
+
+// Delete deletes the object.
+func (b *Local) Delete(ctx context.Context, name string) error {
+	fname, err := b.filename(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fname)
+	if os.IsNotExist(err) {
+		return ErrNotExist
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Remove(metaFilename(fname))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isDir reports whether fi in dir is a directory or a symlink to directory.
+func isDir(dir string, fi os.FileInfo) bool {
+	if fi.IsDir() {
+		return true
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(dir, fi.Name()))
+	return err == nil && fi.IsDir()
+}
+
+// inPrefix reports whether directory of name may have objects
+// whose name has prefix.
+func inPrefix(name, prefix string) bool {
+	if name == "" {
+		return true
+	}
+	return strings.HasPrefix(name+"/", prefix) || strings.HasPrefix(prefix, name+"/")
+}
+
+// walk calls fn for each object or directory under directory of name,
+// whose name has prefix.
+func (b *Local) walk(name, prefix string, fn func(name string, fi os.FileInfo, isDir bool) error) error {
+	dir := filepath.Join(b.dir, filepath.FromSlash(name))
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		oname := path.Join(name, fi.Name())
+		if isDir(dir, fi) {
+			if !inPrefix(oname, prefix) {
+				continue
+			}
+			err = fn(oname, fi, true)
+			if err != nil {
+				return err
+			}
+			err = b.walk(oname, prefix, fn)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasPrefix(oname, prefix) {
+			continue
+		}
+		err = fn(oname, fi, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns attributes of objects whose name has prefix.
+func (b *Local) List(ctx context.Context, prefix string) ([]*Attrs, error) {
+	var attrs []*Attrs
+	err := b.walk("", prefix, func(name string, fi os.FileInfo, isDir bool) error {
+		if isDir {
+			return nil
+		}
+		fname := filepath.Join(b.dir, filepath.FromSlash(name))
+		fi, err := os.Stat(fname)
+		if err != nil {
+			return err
+		}
+		attrs = append(attrs, b.attrs(name, fname, fi))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name < attrs[j].Name
+	})
+	return attrs, nil
+}
+
+// Watch watches the directory and its subdirectories with fsnotify.
+// For new directory, it reports objects in the directory too.
+func (b *Local) Watch(ctx context.Context, prefix string) (Watcher, error) {
+	wctx, cancel := context.WithCancel(context.Background())
+	w := &localWatcher{
+		b:        b,
+		prefix:   prefix,
+		ctx:      wctx,
+		cancel:   cancel,
+		ch:       make(chan localEvent),
+		watchers: make(map[string]*fswatch.Watcher),
+	}
+	err := w.watch("")
+	if err == nil {
+		err = b.walk("", prefix, func(name string, fi os.FileInfo, isDir bool) error {
+			if !isDir {
+				return nil
+			}
+			return w.watch(name)
+		})
+	}
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+type localEvent struct {
+	name string
+	err  error
+}
+
+// localWatcher watches directories in Local bucket.
+type localWatcher struct {
+	b      *Local
+	prefix string
+	ctx    context.Context
+	cancel func()
+	ch     chan localEvent
+
+	mu       sync.Mutex
+	watchers map[string]*fswatch.Watcher
+	wg       sync.WaitGroup
+}
+
+// watch starts watching directory of name, and sends its events to w.ch.
+func (w *localWatcher) watch(name string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watchers == nil {
+		return errors.New("watcher closed")
+	}
+	if _, ok := w.watchers[name]; ok {
+		return nil
+	}
+	fw, err := fswatch.New(w.ctx, filepath.Join(w.b.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	w.watchers[name] = fw
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			ev, err := fw.Next(w.ctx)
+			if w.ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				w.send(localEvent{name: name, err: err})
+				continue
+			}
+			w.handle(name, ev)
+		}
+	}()
+	return nil
+}
+
+func (w *localWatcher) send(ev localEvent) {
+	select {
+	case w.ch <- ev:
+	case <-w.ctx.Done():
+	}
+}
+
+// handle handles fsnotify event in directory of dir.
+func (w *localWatcher) handle(dir string, ev fsnotify.Event) {
+	base := filepath.Base(ev.Name)
+	if strings.HasPrefix(base, ".") {
+		return
+	}
+	name := path.Join(dir, base)
+	if ev.Op&fsnotify.Create != 0 {
+		fi, err := os.Stat(ev.Name)
+		if err == nil && fi.IsDir() {
+			if !inPrefix(name, w.prefix) {
+				return
+			}
+			err = w.watch(name)
+			if err != nil {
+				w.send(localEvent{name: name, err: err})
+				return
+			}
+			// objects may be created before watch starts.
+			err = w.b.walk(name, w.prefix, func(name string, fi os.FileInfo, isDir bool) error {
+				if isDir {
+					return w.watch(name)
+				}
+				w.send(localEvent{name: name})
+				return nil
+			})
+			if err != nil {
+				w.send(localEvent{name: name, err: err})
+			}
+			return
+		}
+	}
+	if !strings.HasPrefix(name, w.prefix) {
+		return
+	}
+	w.send(localEvent{name: name})
+}
+
+func (w *localWatcher) Next(ctx context.Context) ([]string, error) {
+	logger := log.FromContext(ctx)
+	var names []string
+	handle := func(ev localEvent) {
+		if ev.err != nil {
+			logger.Errorf("watch %s: %v", ev.name, ev.err)
+			return
+		}
+		logger.Debugf("handle event: %s", ev.name)
+		names = append(names, ev.name)
+	}
+	for len(names) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-w.ctx.Done():
+			return nil, errors.New("watcher closed")
+		case ev := <-w.ch:
+			handle(ev)
+		}
+	}
+	// drain pending events.
+	for {
+		select {
+		case ev := <-w.ch:
+			handle(ev)
+		default:
+			return names, nil
+		}
+	}
+}
+
+func (w *localWatcher) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []string
+	for name, fw := range w.watchers {
+		err := fw.Close()
+		if err != nil {
+			errs = append(errs, name+": "+err.Error())
+		}
+	}
+	w.watchers = nil
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLocal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBucket(t, bkt)
+}
+
+func TestLocalNotPut(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "blobstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fname := filepath.Join(dir, "obj")
+	err = ioutil.WriteFile(fname, []byte("data"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := bkt.Attrs(ctx, "obj")
+	if err != nil || attrs.Size != 4 || attrs.MD5 != nil {
+		t.Errorf("Attrs(obj)=%#v, %v; want size:4 without checksums", attrs, err)
+	}
+
+	_, err = bkt.Put(ctx, "obj", []byte("data"), PutOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// updated by others, so meta is stale.
+	err = ioutil.WriteFile(fname, []byte("other data"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err = bkt.Attrs(ctx, "obj")
+	if err != nil || attrs.Size != 10 || attrs.MD5 != nil {
+		t.Errorf("Attrs(obj)=%#v, %v; want size:10 without checksums", attrs, err)
+	}
+	// SetExpire computes checksums of the content.
+	expire := time.Now().Add(time.Hour).Round(time.Second)
+	_, err = bkt.SetExpire(ctx, "obj", expire)
+	if err != nil {
+		t.Fatalf("SetExpire(obj)=_, %v; want nil error", err)
+	}
+	crc32c, md5sum := checksums([]byte("other data"))
+	attrs, err = bkt.Attrs(ctx, "obj")
+	if err != nil || !attrs.Expire.Equal(expire) || attrs.CRC32C != crc32c || !bytes.Equal(attrs.MD5, md5sum) {
+		t.Errorf("Attrs(obj)=%#v, %v; want expire:%s crc32c:%x md5:%x", attrs, err, expire, crc32c, md5sum)
+	}
+
+	list, err := bkt.List(ctx, "")
+	if err != nil || len(list) != 1 || list[0].Name != "obj" {
+		t.Errorf("List()=%v, %v; want [obj] without meta files", list, err)
+	}
+
+	for _, name := range []string{"../obj", "/obj", "dir/../obj", ".obj.meta", "dir/"} {
+		_, err := bkt.Get(ctx, name)
+		if err == nil || err == ErrNotExist {
+			t.Errorf("Get(%q)=_, %v; want invalid name error", name, err)
+		}
+	}
+}
+
+func TestLocalWatch(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "blobstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bkt.Put(ctx, "linux/seq", []byte("1"), PutOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := bkt.Watch(ctx, "linux/")
+	if err != nil {
+		t.Fatalf("Watch(linux/)=_, %v; want nil error", err)
+	}
+	defer w.Close()
+
+	next := func(want string) {
+		t.Helper()
+		wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		for {
+			names, err := w.Next(wctx)
+			if err != nil {
+				t.Fatalf("Next()=_, %v; want %s", err, want)
+			}
+			for _, name := range names {
+				if name == want {
+					return
+				}
+			}
+		}
+	}
+
+	// not under prefix.
+	_, err = bkt.Put(ctx, "windows/seq", []byte("1"), PutOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bkt.Put(ctx, "linux/seq", []byte("2"), PutOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next("linux/seq")
+
+	// new directory. objects created before watching the directory
+	// are reported too.
+	err = os.MkdirAll(filepath.Join(dir, "linux", "chrome"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bkt.Put(ctx, "linux/chrome/desc", []byte("desc"), PutOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next("linux/chrome/desc")
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Opts is options of S3 bucket.
+type S3Opts struct {
+	// Endpoint is endpoint URL of S3 compatible storage,
+	// e.g. "http://minio:9000". Empty uses AWS endpoint.
+	// If Endpoint is set, path-style addressing is used.
+	Endpoint string
+
+	// Region is region of the bucket. Default is "us-east-1".
+	Region string
+
+	// Credentials is credentials to access the bucket.
+	// If nil, default credential chain of AWS SDK is used.
+	Credentials *credentials.Credentials
+}
+
+// S3 is a bucket on S3 compatible storage.
+//
+// Expiration time and checksums of object are stored in user-defined
+// metadata of the object. S3 doesn't delete expired objects unless
+// bucket's lifecycle rule is configured.
+// S3 doesn't support Watch.
+type S3 struct {
+	name   string
+	client *s3.S3
+}
+
+// user-defined metadata keys, canonicalized as AWS SDK does.
+const (
+	s3MetaExpire = "Expire"
+	s3MetaCRC32C = "Crc32c"
+	s3MetaMD5    = "Md5"
+)
+
+// NewS3 creates bucket of name on S3 compatible storage.
+func NewS3(name string, opts S3Opts) (*S3, error) {
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	config := &aws.Config{
+		Region:      aws.String(region),
+		Credentials: opts.Credentials,
+	}
+	if opts.Endpoint != "" {
+		config.Endpoint = aws.String(opts.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	return &S3{
+		name:   name,
+		client: s3.New(sess),
+	}, nil
+}
+
+// Name returns name of the bucket.
+func (b *S3) Name() string {
+	return b.name
+}
+
+func s3Error(err error) error {
+	if rerr, ok := err.(awserr.RequestFailure); ok && rerr.StatusCode() == http.StatusNotFound {
+		return ErrNotExist
+	}
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return ErrNotExist
+	}
+	return err
+}
+
+func s3Metadata(expire time.Time, crc32c uint32, md5sum []byte) map[string]*string {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, crc32c)
+	m := map[string]*string{
+		s3MetaCRC32C: aws.String(base64.StdEncoding.EncodeToString(buf)),
+		s3MetaMD5:    aws.String(base64.StdEncoding.EncodeToString(md5sum)),
+	}
+	if !expire.IsZero() {
+		m[s3MetaExpire] = aws.String(expire.UTC().Format(time.RFC3339Nano))
+	}
+	return m
+}
+
+// s3Attrs returns attributes from user-defined metadata.
+// Checksums are not set if metadata is missing or broken.
+func s3Attrs(name string, size int64, updated time.Time, meta map[string]*string) *Attrs {
+	attrs := &Attrs{
+		Name:    name,
+		Size:    size,
+		Updated: updated,
+	}
+	get := func(key string) string {
+		for k, v := range meta {
+			if strings.EqualFold(k, key) {
+				return aws.StringValue(v)
+			}
+		}
+		return ""
+	}
+	if v := get(s3MetaExpire); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err == nil {
+			attrs.Expire = t
+		}
+	}
+	crc32c, err := base64.StdEncoding.DecodeString(get(s3MetaCRC32C))
+	if err != nil || len(crc32c) != 4 {
+		return attrs
+	}
+	md5sum, err := base64.StdEncoding.DecodeString(get(s3MetaMD5))
+	if err != nil || len(md5sum) == 0 {
+		return attrs
+	}
+	attrs.CRC32C = binary.BigEndian.Uint32(crc32c)
+	attrs.MD5 = md5sum
+	return attrs
+}
+
+// Attrs returns attributes of the object.
+func (b *S3) Attrs(ctx context.Context, name string) (*Attrs, error) {
+	resp, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return s3Attrs(name, aws.Int64Value(resp.ContentLength), aws.TimeValue(resp.LastModified), resp.Metadata), nil
+}
+
+// Get returns content of the object.
+func (b *S3) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Put stores data as the object.
+// It sends MD5 of data, so storage rejects corrupted upload.
+func (b *S3) Put(ctx context.Context, name string, data []byte, opts PutOpts) (*Attrs, error) {
+	crc32c, md5sum := checksums(data)
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:     aws.String(b.name),
+		Key:        aws.String(name),
+		Body:       bytes.NewReader(data),
+		ContentMD5: aws.String(base64.StdEncoding.EncodeToString(md5sum)),
+		Metadata:   s3Metadata(opts.Expire, crc32c, md5sum),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Attrs{
+		Name:    name,
+		Size:    int64(len(data)),
+		Updated: time.Now(),
+		Expire:  opts.Expire,
+		CRC32C:  crc32c,
+		MD5:     md5sum,
+	}, nil
+}
+
+// SetExpire sets expiration time of the object by copying the object
+// onto itself with replaced metadata. Storage copies the content
+// server-side, so it doesn't upload the content again.
+func (b *S3) SetExpire(ctx context.Context, name string, expire time.Time) (*Attrs, error) {
+	resp, err := b.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(name),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	attrs := s3Attrs(name, aws.Int64Value(resp.ContentLength), aws.TimeValue(resp.LastModified), resp.Metadata)
+	meta := s3Metadata(expire, attrs.CRC32C, attrs.MD5)
+	if attrs.MD5 == nil {
+		// object written by others has no checksums.
+		delete(meta, s3MetaCRC32C)
+		delete(meta, s3MetaMD5)
+	}
+	_, err = b.client.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(b.name),
+		Key:        aws.String(name),
+		CopySource: aws.String(url.PathEscape(b.name) + "/" + s3EscapeKey(name)),
+		// fails if the object was replaced after HeadObject.
+		CopySourceIfMatch: resp.ETag,
+		Metadata:          meta,
+		MetadataDirective: aws.String(s3.MetadataDirectiveReplace),
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	attrs.Expire = expire
+	attrs.Updated = time.Now()
+	return attrs, nil
+}
+
+// s3EscapeKey escapes object key for copy source.
+func s3EscapeKey(name string) string {
+	elems := strings.Split(name, "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return strings.Join(elems, "/")
+}
+
+// Delete deletes the object.
+// S3 doesn't report missing object on delete, so it checks existence
+// before deleting.
+func (b *S3) Delete(ctx context.Context, name string) error {
+	_, err := b.Attrs(ctx, name)
+	if err != nil {
+		return err
+	}
+	_, err = b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(name),
+	})
+	return s3Error(err)
+}
+
+// List returns attributes of objects whose name has prefix.
+// Listing doesn't return user-defined metadata, so expiration time and
+// checksums are not set.
+func (b *S3) List(ctx context.Context, prefix string) ([]*Attrs, error) {
+	var attrs []*Attrs
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.name),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			attrs = append(attrs, &Attrs{
+				Name:    aws.StringValue(obj.Key),
+				Size:    aws.Int64Value(obj.Size),
+				Updated: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// Watch is not supported.
+func (b *S3) Watch(ctx context.Context, prefix string) (Watcher, error) {
+	return nil, ErrNotSupported
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package blobstore
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+type fakeS3Object struct {
+	data    []byte
+	meta    http.Header
+	updated time.Time
+}
+
+// fakeS3 is a minimal S3 compatible server like MinIO, serving one
+// bucket with path-style addressing.
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]fakeS3Object
+}
+
+type fakeS3Contents struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+}
+
+type fakeS3ListResult struct {
+	XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name        string
+	Prefix      string
+	KeyCount    int
+	MaxKeys     int
+	IsTruncated bool
+	Contents    []fakeS3Contents
+}
+
+func (s *fakeS3) error(w http.ResponseWriter, code int, errCode string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", errCode, errCode)
+}
+
+func etag(data []byte) string {
+	md5sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(md5sum[:]) + `"`
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		s.error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+	p := strings.TrimPrefix(r.URL.Path, "/")
+	i := strings.Index(p, "/")
+	bucket, key := p, ""
+	if i >= 0 {
+		bucket, key = p[:i], p[i+1:]
+	}
+	if bucket != s.bucket {
+		s.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key == "" {
+		if r.Method != http.MethodGet || r.URL.Query().Get("list-type") != "2" {
+			s.error(w, http.StatusNotImplemented, "NotImplemented")
+			return
+		}
+		s.list(w, r.URL.Query().Get("prefix"))
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		if src := r.Header.Get("X-Amz-Copy-Source"); src != "" {
+			s.copy(w, r, key, src)
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		md5sum := md5.Sum(data)
+		if cmd5 := r.Header.Get("Content-MD5"); cmd5 != "" && cmd5 != base64.StdEncoding.EncodeToString(md5sum[:]) {
+			s.error(w, http.StatusBadRequest, "BadDigest")
+			return
+		}
+		meta := make(http.Header)
+		for k, v := range r.Header {
+			if strings.HasPrefix(k, "X-Amz-Meta-") {
+				meta[k] = v
+			}
+		}
+		s.objects[key] = fakeS3Object{
+			data:    data,
+			meta:    meta,
+			updated: time.Now(),
+		}
+		w.Header().Set("ETag", etag(data))
+
+	case http.MethodGet, http.MethodHead:
+		obj, ok := s.objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		for k, v := range obj.meta {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(obj.data)))
+		w.Header().Set("Last-Modified", obj.updated.UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", etag(obj.data))
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// copy copies object of src to key. it supports copy onto itself
+// with replaced metadata only.
+func (s *fakeS3) copy(w http.ResponseWriter, r *http.Request, key, src string) {
+	src, err := url.PathUnescape(strings.TrimPrefix(src, "/"))
+	if err != nil || src != s.bucket+"/"+key || r.Header.Get("X-Amz-Metadata-Directive") != "REPLACE" {
+		s.error(w, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+	obj, ok := s.objects[key]
+	if !ok {
+		s.error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	if m := r.Header.Get("X-Amz-Copy-Source-If-Match"); m != "" && m != etag(obj.data) {
+		s.error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+		return
+	}
+	meta := make(http.Header)
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			meta[k] = v
+		}
+	}
+	obj.meta = meta
+	obj.updated = time.Now()
+	s.objects[key] = obj
+	w.Header().Set("Content-Type", "application/xml")
+	fmt.Fprintf(w, "<CopyObjectResult><ETag>%s</ETag><LastModified>%s</LastModified></CopyObjectResult>", etag(obj.data), obj.updated.UTC().Format("2006-01-02T15:04:05.000Z"))
+}
+
+func (s *fakeS3) list(w http.ResponseWriter, prefix string) {
+	result := fakeS3ListResult{
+		Name:    s.bucket,
+		Prefix:  prefix,
+		MaxKeys: 1000,
+	}
+	for key, obj := range s.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		result.Contents = append(result.Contents, fakeS3Contents{
+			Key:          key,
+			LastModified: obj.updated.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(obj.data),
+			Size:         int64(len(obj.data)),
+		})
+	}
+	sort.Slice(result.Contents, func(i, j int) bool {
+		return result.Contents[i].Key < result.Contents[j].Key
+	})
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	err := xml.NewEncoder(w).Encode(result)
+	if err != nil {
+		s.t.Errorf("list: %v", err)
+	}
+}
+
+func newTestS3(t *testing.T) (*S3, func()) {
+	t.Helper()
+	s := httptest.NewServer(&fakeS3{
+		t:       t,
+		bucket:  "goma-cache",
+		objects: make(map[string]fakeS3Object),
+	})
+	bkt, err := NewS3("goma-cache", S3Opts{
+		Endpoint:    s.URL,
+		Credentials: credentials.NewStaticCredentials("access-key", "secret-key", ""),
+	})
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	return bkt, s.Close
+}
+
+func TestS3(t *testing.T) {
+	bkt, cleanup := newTestS3(t)
+	defer cleanup()
+	testBucket(t, bkt)
+
+	_, err := bkt.Watch(context.Background(), "")
+	if err != ErrNotSupported {
+		t.Errorf("Watch()=_, %v; want ErrNotSupported", err)
+	}
+}
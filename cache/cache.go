@@ -5,173 +5,94 @@
 package cache
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"expvar"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"cloud.google.com/go/storage"
-	"github.com/golang/groupcache/lru"
+	"github.com/golang/protobuf/ptypes"
+	"go.opencensus.io/stats"
 	"go.opencensus.io/trace"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/cache/codec"
+	"go.chromium.org/goma/server/cache/disk"
 	"go.chromium.org/goma/server/cache/gcs"
 	"go.chromium.org/goma/server/log"
 	cachepb "go.chromium.org/goma/server/proto/cache"
 )
 
-// cache is a wrapper around an *lru.Cache that adds synchronization,
-// and counts the size of all keys and values.
-type memcache struct {
-	MaxBytes   int64
-	mu         sync.RWMutex
-	nbytes     int64 // of all keys and vlaues
-	lru        *lru.Cache
-	nhit, nget int64
-	nevict     int64 // number of evictions
-	nreplace   int64
-}
-
-var errNoChange = errors.New("cache: no change")
-
-type replaceError struct {
-	old []byte
-}
-
-func (r replaceError) Error() string {
-	return "cache: value is replaced"
-}
-
-// Put puts key-value pair in memcache.
-// It returns errNoChange if key-value pair was already stored.
-// It returns replaceError if value is replaced.
-func (c *memcache) Put(ctx context.Context, key string, value []byte) error {
-	span := trace.FromContext(ctx)
-	span.Annotatef(nil, "put %s (size:%d)", key, len(value))
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	err := c.add(ctx, key, value)
-	if c.MaxBytes == 0 {
-		return err
-	}
-	for {
-		if c.nbytes < c.MaxBytes {
-			return err
-		}
-		span.Annotatef(nil, "eviction %d exceeding max=%d", c.nbytes, c.MaxBytes)
-		c.lru.RemoveOldest()
-	}
-}
-
-// add adds key-value pair in memcache.
-// It returns errNoChange if key-value pair already exists in memcache.
-// It returns replaceError if key exists but value differs.
-func (c *memcache) add(ctx context.Context, key string, value []byte) error {
-	logger := log.FromContext(ctx)
-
-	if c.lru == nil {
-		c.lru = &lru.Cache{
-			OnEvicted: func(key lru.Key, value interface{}) {
-				logger := log.FromContext(context.Background())
-				logger.Infof("mem.evict %s %d", key.(string), len(value.([]byte)))
-				c.nbytes -= int64(len(key.(string))) + int64(len(value.([]byte)))
-				c.nevict++
-			},
-		}
-	}
-	var err error
-	vi, ok := c.lru.Get(key)
-	if ok {
-		ov := vi.([]byte)
-		if bytes.Equal(ov, value) {
-			logger.Infof("mem.put2  %s %d", key, len(value))
-			return errNoChange
-		}
-		logger.Errorf("mem.repl  %s %d <= %d", key, len(value), len(ov))
-		// replace won't call OnEvicted.
-		c.nbytes -= int64(len(key)) + int64(len(ov))
-		c.nreplace++
-		err = replaceError{old: ov}
-	} else {
-		logger.Infof("mem.put   %s %d", key, len(value))
-	}
-	c.lru.Add(key, value)
-	c.nbytes += int64(len(key)) + int64(len(value))
-	return err
-}
-
-func (c *memcache) Get(ctx context.Context, key string) (value []byte, ok bool) {
-	span := trace.FromContext(ctx)
-	span.Annotatef(nil, "get %s", key)
-	logger := log.FromContext(ctx)
-
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.nget++
-	if c.lru == nil {
-		logger.Infof("mem.miss  %s", key)
-		return nil, false
-	}
-	vi, ok := c.lru.Get(key)
-	if !ok {
-		logger.Infof("mem.miss  %s", key)
-		return nil, false
-	}
-	c.nhit++
-	logger.Infof("mem.hit   %s %d", key, len(vi.([]byte)))
-	return vi.([]byte), true
-}
-
-// TODO: use opencensus stats, view.
-type memstats struct {
-	MaxBytes int64
-
-	Bytes    int64
-	Num      int
-	Hits     int64
-	Gets     int64
-	Evicts   int64
-	Replaces int64
-}
-
-func (c *memcache) stats() memstats {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	var num int
-	if c.lru != nil {
-		num = c.lru.Len()
-	}
-	return memstats{
-		MaxBytes: c.MaxBytes,
-		Bytes:    c.nbytes,
-		Num:      num,
-		Hits:     c.nhit,
-		Gets:     c.nget,
-		Evicts:   c.nevict,
-		Replaces: c.nreplace,
-	}
-}
-
 // Config is a configuration for Cache.
 type Config struct {
 	// MaxBytes is maximum number of bytes used for cache.
 	MaxBytes int64
-	// TODO:
-	// Dir          string
-	// MaxDiskBytes int64
-
-	Bucket *storage.BucketHandle
+	// WindowRatio is ratio of MaxBytes used for window LRU of
+	// W-TinyLFU admission policy in memory. 0 disables admission
+	// policy, i.e. plain LRU.
+	WindowRatio float64
+
+	// Dir is a directory for disk cache, used as second tier
+	// between memory and Bucket. Empty disables disk cache.
+	Dir string
+	// MaxDiskBytes is maximum number of bytes used for disk cache.
+	// 0 means no limit.
+	MaxDiskBytes int64
+	// PromoteHits is number of hits in disk cache to promote
+	// key-value pair to memory. default is 2.
+	PromoteHits int64
+	// DemoteHits is number of hits in memory for key-value pair
+	// evicted from memory to be demoted to disk cache. Key-value pair
+	// with fewer hits is dropped, and will be fetched from Bucket
+	// if needed. default is 1.
+	DemoteHits int64
+
+	// Bucket is backing store. nil disables backing store.
+	Bucket blobstore.Bucket
+	// AdmissionController checks put to Bucket. Key-value pair
+	// rejected by *gcs.NotAdmittedError is only stored in memory
+	// and disk cache.
+	AdmissionController gcs.AdmissionController
+
+	// Codec encodes values stored in memory, disk and Bucket.
+	// nil stores values verbatim.
+	Codec *codec.Codec
 }
 
 // TODO: put it in Config?
 const writeBackSemaphore = 8
 
+const (
+	defaultPromoteHits = 2
+	defaultDemoteHits  = 1
+	// demoteQueueSize is the size of queue of key-value pairs evicted
+	// from memory to be written in disk cache. When the queue is full,
+	// evicted key-value pairs are dropped, and counted in
+	// DemoteDropped stats and demote-drops view.
+	demoteQueueSize = 128
+)
+
 // Cache represents key-value cache.
 type Cache struct {
-	mem memcache
-	gcs *gcs.Cache
+	mem  memcache
+	disk *disk.Cache
+	gcs  *gcs.Cache
+
+	codec *codec.Codec
+
+	promoteHits int64
+	demoteHits  int64
+	demoteq     chan *cachepb.KV
+	ndropped    int64 // should be accessed via atomic pkg.
+	done        chan struct{}
+	wg          sync.WaitGroup
 
 	wbsema chan bool
 }
@@ -183,14 +104,43 @@ var (
 
 // New creates new Cache for Config.
 func New(c Config) (*Cache, error) {
+	if c.WindowRatio < 0 || c.WindowRatio >= 1 {
+		return nil, fmt.Errorf("cache: bad window ratio %g: must be in [0, 1)", c.WindowRatio)
+	}
 	cache := &Cache{
 		mem: memcache{
-			MaxBytes: c.MaxBytes,
+			MaxBytes:    c.MaxBytes,
+			WindowRatio: c.WindowRatio,
 		},
+		codec: c.Codec,
+	}
+
+	if c.Dir != "" {
+		d, err := disk.Open(context.Background(), c.Dir, c.MaxDiskBytes)
+		if err != nil {
+			return nil, err
+		}
+		cache.disk = d
+		cache.promoteHits = c.PromoteHits
+		if cache.promoteHits <= 0 {
+			cache.promoteHits = defaultPromoteHits
+		}
+		cache.demoteHits = c.DemoteHits
+		if cache.demoteHits <= 0 {
+			cache.demoteHits = defaultDemoteHits
+		}
+		cache.demoteq = make(chan *cachepb.KV, demoteQueueSize)
+		cache.done = make(chan struct{})
+		cache.mem.onEvicted = cache.demote
+		cache.wg.Add(1)
+		go cache.demoteLoop()
 	}
 
 	if c.Bucket != nil {
 		cache.gcs = gcs.New(c.Bucket)
+		if c.AdmissionController != nil {
+			cache.gcs.AdmissionController = c.AdmissionController
+		}
 		cache.wbsema = make(chan bool, writeBackSemaphore)
 	}
 
@@ -200,20 +150,118 @@ func New(c Config) (*Cache, error) {
 	return cache, nil
 }
 
+// demote queues key-value pair evicted from memcache to write in disk cache,
+// if it has enough hits in memcache.
+// It is called while holding memcache's lock, so it must not block.
+func (c *Cache) demote(key string, value []byte, hits int64) {
+	if hits < c.demoteHits {
+		return
+	}
+	select {
+	case c.demoteq <- &cachepb.KV{Key: key, Value: value}:
+	default:
+		atomic.AddInt64(&c.ndropped, 1)
+		stats.Record(context.Background(), demoteDrops.M(1))
+	}
+}
+
+func (c *Cache) demoteLoop() {
+	defer c.wg.Done()
+	ctx := context.Background()
+	logger := log.FromContext(ctx)
+	put := func(kv *cachepb.KV) {
+		err := c.disk.Put(ctx, kv.Key, kv.Value)
+		if err != nil {
+			logger.Errorf("disk.put demote %s: %v", kv.Key, err)
+		}
+	}
+	for {
+		select {
+		case <-c.done:
+			// flush queued key-value pairs.
+			for {
+				select {
+				case kv := <-c.demoteq:
+					put(kv)
+				default:
+					return
+				}
+			}
+		case kv := <-c.demoteq:
+			put(kv)
+		}
+	}
+}
+
+// Close writes key-value pairs queued for demotion in disk cache,
+// and closes disk cache.
+func (c *Cache) Close() error {
+	mutex.Lock()
+	for i, cache := range caches {
+		if cache == c {
+			caches = append(caches[:i], caches[i+1:]...)
+			break
+		}
+	}
+	mutex.Unlock()
+	if c.disk == nil {
+		return nil
+	}
+	close(c.done)
+	c.wg.Wait()
+	return c.disk.Close()
+}
+
 // Put puts new key-value pair in memcache (always; i.e. overwrite existing one)
-// and cloud cache (if gcs is configured, and new value is put).
-// It returns error if it fails to put cache in cloud storage.
+// and cloud cache (if gcs is configured, and it is admitted).
+// If ttl is set, key-value pair expires after ttl.
+// Key-value pair will be demoted to disk cache when evicted from memcache.
+// It returns error if it fails to put cache in Bucket.
 func (c *Cache) Put(ctx context.Context, req *cachepb.PutReq) (*cachepb.PutResp, error) {
-	err := c.mem.Put(ctx, req.Kv.Key, req.Kv.Value)
+	var expire time.Time
+	if req.Ttl != nil {
+		ttl, err := ptypes.Duration(req.Ttl)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "cache.Put(%s): bad ttl: %v", req.Kv.Key, err)
+		}
+		expire = time.Now().Add(ttl)
+	}
+	// admission controller checks size of raw value.
+	rawSize := len(req.Kv.Value)
+	if c.codec != nil {
+		// all tiers store encoded value.
+		req = &cachepb.PutReq{
+			Kv: &cachepb.KV{
+				Key:   req.Kv.Key,
+				Value: c.codec.Encode(ctx, req.Kv.Value),
+			},
+			WriteBack: req.WriteBack,
+			Ttl:       req.Ttl,
+		}
+	}
+	err := c.mem.Put(ctx, req.Kv.Key, req.Kv.Value, expire)
 
-	if err == errNoChange {
-		return &cachepb.PutResp{}, nil
+	if err != errNoChange && c.disk != nil {
+		// old value in disk cache is stale.
+		c.disk.Delete(ctx, req.Kv.Key)
 	}
 	if c.gcs == nil {
 		return &cachepb.PutResp{}, nil
 	}
+	// repeated put with no change is also passed to gcs, so that
+	// admission controller (e.g. SecondHitAdmission) counts it.
+	// gcs won't rewrite the same object.
 	if req.WriteBack {
-		ctx, _ := trace.StartSpanWithRemoteParent(context.Background(), "go.chromium.org/goma/server/cache.Cache.Put.WriteBack", trace.FromContext(ctx).SpanContext())
+		wctx, _ := trace.StartSpanWithRemoteParent(context.Background(), "go.chromium.org/goma/server/cache.Cache.Put.WriteBack", trace.FromContext(ctx).SpanContext())
+		// pass requester for admission controller.
+		if p, ok := peer.FromContext(ctx); ok {
+			wctx = peer.NewContext(wctx, p)
+		}
+		wctx = gcs.WithRawSize(wctx, rawSize)
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			wctx = metadata.NewIncomingContext(wctx, md)
+		}
+		ctx := wctx
 		// TODO: pass tag?
 		go func(ctx context.Context) {
 			logger := log.FromContext(ctx)
@@ -224,6 +272,10 @@ func (c *Cache) Put(ctx context.Context, req *cachepb.PutReq) (*cachepb.PutResp,
 			logger.Infof("gcs.put write back %s", req.Kv.Key)
 
 			_, err := c.gcs.Put(ctx, req)
+			if isNotAdmitted(err) {
+				logger.Infof("gcs.put write back %s: %v", req.Kv.Key, err)
+				return
+			}
 			if err != nil {
 				logger.Errorf("gcs.put write back %s: %v", req.Kv.Key, err)
 				return
@@ -233,23 +285,30 @@ func (c *Cache) Put(ctx context.Context, req *cachepb.PutReq) (*cachepb.PutResp,
 		return &cachepb.PutResp{}, nil
 	}
 
-	return c.gcs.Put(ctx, req)
+	resp, err := c.gcs.Put(gcs.WithRawSize(ctx, rawSize), req)
+	if isNotAdmitted(err) {
+		return &cachepb.PutResp{}, nil
+	}
+	return resp, err
+}
+
+// isNotAdmitted reports whether err is rejection by admission policy.
+func isNotAdmitted(err error) bool {
+	var aerr *gcs.NotAdmittedError
+	return errors.As(err, &aerr)
 }
 
 // Get gets key-value for requested key.
+// It looks up memcache, disk cache, and cloud cache in order.
+// Key-value pair found in disk cache is promoted to memcache when it
+// hits PromoteHits times in disk cache. Key-value pair found in cloud
+// cache is put in memcache.
 // It returns codes.NotFound if value not found in cache.
 func (c *Cache) Get(ctx context.Context, req *cachepb.GetReq) (*cachepb.GetResp, error) {
-	resp := &cachepb.GetResp{}
-	v, ok := c.mem.Get(ctx, req.Key)
+	resp, ok := c.getLocal(ctx, req.Key)
 	if ok {
-		resp.Kv = &cachepb.KV{
-			Key:   req.Key,
-			Value: v,
-		}
-		resp.InMemory = true
-		return resp, nil
+		return c.decode(ctx, resp)
 	}
-
 	if req.Fast || c.gcs == nil {
 		return nil, grpc.Errorf(codes.NotFound, "cache.Get: not found %s", req.Key)
 	}
@@ -257,26 +316,167 @@ func (c *Cache) Get(ctx context.Context, req *cachepb.GetReq) (*cachepb.GetResp,
 	if err != nil || resp.Kv == nil {
 		return nil, grpc.Errorf(codes.NotFound, "cache.Get(%s): %v", req.Key, err)
 	}
-	c.mem.Put(ctx, req.Key, resp.Kv.Value)
+	c.mem.Put(ctx, req.Key, resp.Kv.Value, time.Time{})
+	return c.decode(ctx, resp)
+}
+
+// decode decodes value in resp.
+// It returns codes.NotFound if value is corrupted, so that caller
+// will put it again.
+func (c *Cache) decode(ctx context.Context, resp *cachepb.GetResp) (*cachepb.GetResp, error) {
+	if c.codec == nil || resp.Kv == nil {
+		return resp, nil
+	}
+	v, err := codec.Decode(resp.Kv.Value)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Errorf("cache.Get(%s): %v", resp.Kv.Key, err)
+		return nil, grpc.Errorf(codes.NotFound, "cache.Get(%s): %v", resp.Kv.Key, err)
+	}
+	resp.Kv.Value = v
 	return resp, nil
 }
 
-type stats struct {
-	Mem memstats
-	GCS gcs.Stats
+// getLocal gets key-value for key from memcache or disk cache.
+func (c *Cache) getLocal(ctx context.Context, key string) (*cachepb.GetResp, bool) {
+	v, ok := c.mem.Get(ctx, key)
+	if ok {
+		return &cachepb.GetResp{
+			Kv: &cachepb.KV{
+				Key:   key,
+				Value: v,
+			},
+			InMemory: true,
+		}, true
+	}
+	if c.disk == nil {
+		return nil, false
+	}
+	v, hits, ok := c.disk.Get(ctx, key)
+	if !ok {
+		return nil, false
+	}
+	if hits >= c.promoteHits {
+		c.mem.Put(ctx, key, v, time.Time{})
+	}
+	return &cachepb.GetResp{
+		Kv: &cachepb.KV{
+			Key:   key,
+			Value: v,
+		},
+	}, true
+}
+
+// BatchGet gets key-values for requested keys.
+// Keys not found in memcache nor disk cache are looked up in cloud
+// cache at once.
+// Response for key not found has no kv.
+// If cloud cache fails, it logs the error and returns hits in
+// memcache and disk cache only.
+func (c *Cache) BatchGet(ctx context.Context, req *cachepb.BatchGetReq) (*cachepb.BatchGetResp, error) {
+	resp := &cachepb.BatchGetResp{
+		Resps: make([]*cachepb.GetResp, len(req.Keys)),
+	}
+	var misses []int
+	for i, key := range req.Keys {
+		r, ok := c.getLocal(ctx, key)
+		if !ok {
+			r = &cachepb.GetResp{}
+			misses = append(misses, i)
+		}
+		resp.Resps[i] = r
+	}
+	if len(misses) == 0 || req.Fast || c.gcs == nil {
+		return c.batchDecode(ctx, resp), nil
+	}
+	greq := &cachepb.BatchGetReq{}
+	for _, i := range misses {
+		greq.Keys = append(greq.Keys, req.Keys[i])
+	}
+	gresp, err := c.gcs.BatchGet(ctx, greq)
+	if err != nil {
+		// keep hits in local tiers, and leave misses empty.
+		logger := log.FromContext(ctx)
+		logger.Warnf("gcs BatchGet %d keys: %v", len(greq.Keys), err)
+		return c.batchDecode(ctx, resp), nil
+	}
+	for j, i := range misses {
+		r := gresp.Resps[j]
+		if r.Kv == nil {
+			continue
+		}
+		c.mem.Put(ctx, r.Kv.Key, r.Kv.Value, time.Time{})
+		resp.Resps[i] = r
+	}
+	return c.batchDecode(ctx, resp), nil
+}
+
+// batchDecode decodes values in resp.
+// Corrupted value is treated as not found.
+func (c *Cache) batchDecode(ctx context.Context, resp *cachepb.BatchGetResp) *cachepb.BatchGetResp {
+	for i, r := range resp.Resps {
+		r, err := c.decode(ctx, r)
+		if err != nil {
+			r = &cachepb.GetResp{}
+		}
+		resp.Resps[i] = r
+	}
+	return resp
+}
+
+// BatchPut puts new key-value pairs, as Put does for each.
+func (c *Cache) BatchPut(ctx context.Context, req *cachepb.BatchPutReq) (*cachepb.BatchPutResp, error) {
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, r := range req.Reqs {
+		r := r
+		eg.Go(func() error {
+			_, err := c.Put(ctx, r)
+			return err
+		})
+	}
+	err := eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return &cachepb.BatchPutResp{}, nil
+}
+
+// Delete deletes key-value for requested key from memcache, disk cache
+// and cloud cache.
+func (c *Cache) Delete(ctx context.Context, req *cachepb.DeleteReq) (*cachepb.DeleteResp, error) {
+	c.mem.Delete(ctx, req.Key)
+	if c.disk != nil {
+		c.disk.Delete(ctx, req.Key)
+	}
+	if c.gcs != nil {
+		return c.gcs.Delete(ctx, req)
+	}
+	return &cachepb.DeleteResp{}, nil
+}
+
+type cacheStats struct {
+	Mem  memstats
+	Disk disk.Stats
+	GCS  gcs.Stats
+
+	// DemoteDropped is number of key-value pairs evicted from
+	// memcache but not written in disk cache.
+	DemoteDropped int64
 }
 
-func (c *Cache) stats() stats {
-	return stats{
-		Mem: c.mem.stats(),
-		GCS: c.gcs.Stats(),
+func (c *Cache) stats() cacheStats {
+	return cacheStats{
+		Mem:           c.mem.stats(),
+		Disk:          c.disk.Stats(),
+		GCS:           c.gcs.Stats(),
+		DemoteDropped: atomic.LoadInt64(&c.ndropped),
 	}
 }
 
 func publishStats() interface{} {
 	mutex.RLock()
 	defer mutex.RUnlock()
-	var r []stats
+	var r []cacheStats
 	for _, cache := range caches {
 		r = append(r, cache.stats())
 	}
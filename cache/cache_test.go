@@ -5,13 +5,22 @@
 package cache
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/cache/codec"
+	"go.chromium.org/goma/server/cache/gcs"
 	pb "go.chromium.org/goma/server/proto/cache"
 )
 
@@ -161,3 +170,375 @@ func TestPutReplace(t *testing.T) {
 	}
 
 }
+
+func TestDiskTier(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "cache.TestDiskTier.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := New(Config{
+		// memcache can hold only one key-value pair.
+		MaxBytes:    12,
+		Dir:         dir,
+		PromoteHits: 2,
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+	defer cache.Close()
+
+	for _, key := range []string{"key1", "key2"} {
+		cache.Put(ctx, &pb.PutReq{
+			Kv: &pb.KV{
+				Key:   key,
+				Value: []byte("value"),
+			},
+		})
+		if key == "key1" {
+			// hit in memcache to be demoted.
+			cache.Get(ctx, &pb.GetReq{Key: key})
+		}
+	}
+
+	// key1 is evicted from memcache and demoted to disk cache.
+	deadline := time.Now().Add(5 * time.Second)
+	for cache.disk.Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("key1 is not demoted to disk cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i, wantInMemory := range []bool{false, false, true} {
+		gotResp, err := cache.Get(ctx, &pb.GetReq{Key: "key1"})
+		if err != nil {
+			t.Fatalf("%d: cache.Get(key1): %v", i, err)
+		}
+		wantResp := &pb.GetResp{
+			Kv: &pb.KV{
+				Key:   "key1",
+				Value: []byte("value"),
+			},
+			InMemory: wantInMemory,
+		}
+		if !proto.Equal(gotResp, wantResp) {
+			t.Errorf("%d: got %#v; want %#v", i, gotResp, wantResp)
+		}
+	}
+
+	// new value replaces stale value in disk cache.
+	cache.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key1",
+			Value: []byte("value1"),
+		},
+	})
+	if _, _, ok := cache.disk.Get(ctx, "key1"); ok {
+		t.Errorf("stale key1 in disk cache")
+	}
+}
+
+func TestDemoteDropped(t *testing.T) {
+	c := &Cache{
+		demoteq: make(chan *pb.KV, 1),
+	}
+	c.demote("key1", []byte("value1"), 1)
+	c.demote("key2", []byte("value2"), 1)
+	if got, want := c.stats().DemoteDropped, int64(1); got != want {
+		t.Errorf("DemoteDropped=%d; want %d", got, want)
+	}
+	kv := <-c.demoteq
+	if kv.Key != "key1" {
+		t.Errorf("demoted key=%q; want key1", kv.Key)
+	}
+}
+
+func TestDemoteHits(t *testing.T) {
+	c := &Cache{
+		demoteHits: 2,
+		demoteq:    make(chan *pb.KV, 2),
+	}
+	c.demote("cold", []byte("value1"), 1)
+	c.demote("hot", []byte("value2"), 2)
+	if got, want := len(c.demoteq), 1; got != want {
+		t.Fatalf("demoted=%d; want %d", got, want)
+	}
+	kv := <-c.demoteq
+	if kv.Key != "hot" {
+		t.Errorf("demoted key=%q; want hot", kv.Key)
+	}
+}
+
+func TestCloseFlushesDemotion(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "cache.TestCloseFlushesDemotion.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := New(Config{
+		MaxBytes: 1024,
+		Dir:      dir,
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+	cache.demote("key1", []byte("value1"), 1)
+	err = cache.Close()
+	if err != nil {
+		t.Errorf("cache.Close()=%v; want nil error", err)
+	}
+	if _, _, ok := cache.disk.Get(ctx, "key1"); !ok {
+		t.Errorf("key1 not in disk cache after Close")
+	}
+}
+
+func TestBatchGetPut(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{
+		MaxBytes: 1024 * 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+
+	_, err = cache.BatchPut(ctx, &pb.BatchPutReq{
+		Reqs: []*pb.PutReq{
+			{
+				Kv: &pb.KV{Key: "key1", Value: []byte("value1")},
+			},
+			{
+				Kv: &pb.KV{Key: "key2", Value: []byte("value2")},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("cache.BatchPut(...)=%v; want nil error", err)
+	}
+
+	gotResp, err := cache.BatchGet(ctx, &pb.BatchGetReq{
+		Keys: []string{"key2", "key3", "key1"},
+	})
+	if err != nil {
+		t.Fatalf("cache.BatchGet(...)=%v; want nil error", err)
+	}
+	wantResp := &pb.BatchGetResp{
+		Resps: []*pb.GetResp{
+			{
+				Kv:       &pb.KV{Key: "key2", Value: []byte("value2")},
+				InMemory: true,
+			},
+			{},
+			{
+				Kv:       &pb.KV{Key: "key1", Value: []byte("value1")},
+				InMemory: true,
+			},
+		},
+	}
+	if !proto.Equal(gotResp, wantResp) {
+		t.Errorf("cache.BatchGet(...)=%v; want %v", gotResp, wantResp)
+	}
+
+	_, err = cache.Delete(ctx, &pb.DeleteReq{Key: "key1"})
+	if err != nil {
+		t.Errorf("cache.Delete(key1)=%v; want nil error", err)
+	}
+	_, err = cache.Get(ctx, &pb.GetReq{Key: "key1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("cache.Get(key1)=%v after delete; want NotFound error", err)
+	}
+}
+
+// unavailableBucket is a bucket that fails to access objects.
+type unavailableBucket struct {
+	blobstore.Bucket
+}
+
+func (unavailableBucket) Attrs(ctx context.Context, name string) (*blobstore.Attrs, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestBatchGetGCSError(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{
+		MaxBytes: 1024 * 1024 * 1024,
+		Bucket:   unavailableBucket{},
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+	cache.mem.Put(ctx, "key1", []byte("value1"), time.Time{})
+
+	gotResp, err := cache.BatchGet(ctx, &pb.BatchGetReq{
+		Keys: []string{"key2", "key1"},
+	})
+	if err != nil {
+		t.Fatalf("cache.BatchGet(...)=%v; want nil error", err)
+	}
+	wantResp := &pb.BatchGetResp{
+		Resps: []*pb.GetResp{
+			{},
+			{
+				Kv:       &pb.KV{Key: "key1", Value: []byte("value1")},
+				InMemory: true,
+			},
+		},
+	}
+	if !proto.Equal(gotResp, wantResp) {
+		t.Errorf("cache.BatchGet(...)=%v; want %v", gotResp, wantResp)
+	}
+}
+
+func TestPutTTL(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{
+		MaxBytes: 1024 * 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+
+	_, err = cache.Put(ctx, &pb.PutReq{
+		Kv:  &pb.KV{Key: "key", Value: []byte("value")},
+		Ttl: ptypes.DurationProto(10 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("cache.Put(key, ttl=10ms)=%v; want nil error", err)
+	}
+	_, err = cache.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != nil {
+		t.Errorf("cache.Get(key)=%v; want nil error", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, err = cache.Get(ctx, &pb.GetReq{Key: "key"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("cache.Get(key)=%v after ttl; want NotFound error", err)
+	}
+	if got, want := cache.stats().Mem.Bytes, int64(0); got != want {
+		t.Errorf("Mem.Bytes=%d; want=%d", got, want)
+	}
+}
+
+func TestCodec(t *testing.T) {
+	ctx := context.Background()
+	cache, err := New(Config{
+		MaxBytes: 1024 * 1024 * 1024,
+		Codec:    &codec.Codec{Format: codec.Flate},
+	})
+	if err != nil {
+		t.Fatalf("cache.New(...): %v", err)
+	}
+	kv := &pb.KV{
+		Key:   "key",
+		Value: bytes.Repeat([]byte("value"), 1024),
+	}
+	_, err = cache.Put(ctx, &pb.PutReq{
+		Kv: kv,
+	})
+	if err != nil {
+		t.Fatalf("cache.Put(%s)=%v; want nil error", kv.Key, err)
+	}
+	v, ok := cache.mem.Get(ctx, kv.Key)
+	if !ok || len(v) >= len(kv.Value) {
+		t.Errorf("stored %s=%d bytes, %t; want < %d bytes, true", kv.Key, len(v), ok, len(kv.Value))
+	}
+
+	// stored before compression was enabled.
+	legacy := &pb.KV{
+		Key:   "legacy",
+		Value: []byte("legacy-value"),
+	}
+	cache.mem.Put(ctx, legacy.Key, legacy.Value, time.Time{})
+
+	resp, err := cache.BatchGet(ctx, &pb.BatchGetReq{
+		Keys: []string{kv.Key, legacy.Key},
+	})
+	if err != nil {
+		t.Fatalf("cache.BatchGet(...)=%v; want nil error", err)
+	}
+	want := &pb.BatchGetResp{
+		Resps: []*pb.GetResp{
+			{Kv: kv, InMemory: true},
+			{Kv: legacy, InMemory: true},
+		},
+	}
+	if !proto.Equal(resp, want) {
+		t.Errorf("cache.BatchGet(...)=%v; want %v", resp, want)
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "cache.TestAdmission.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := blobstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := New(Config{
+		MaxBytes: 1024 * 1024,
+		Bucket:   bkt,
+		AdmissionController: gcs.AdmissionControllers{
+			// compressed value is smaller than MaxBytes, but
+			// raw value is not.
+			gcs.SizeAdmission{MaxBytes: 1024},
+			gcs.NewSecondHitAdmission(2, 0),
+		},
+		Codec: &codec.Codec{Format: codec.Flate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inBucket := func(key string) bool {
+		t.Helper()
+		_, err := bkt.Attrs(ctx, key)
+		if err != nil && err != blobstore.ErrNotExist {
+			t.Fatalf("Attrs(%s)=%v", key, err)
+		}
+		return err == nil
+	}
+
+	// repeated put of the same value is admitted by second hit.
+	req := &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("value"),
+		},
+	}
+	for i, want := range []bool{false, true} {
+		_, err := cache.Put(ctx, req)
+		if err != nil {
+			t.Fatalf("Put(key) #%d=%v; want nil error", i, err)
+		}
+		if got := inBucket("key"); got != want {
+			t.Errorf("after Put(key) #%d: in bucket=%t; want %t", i, got, want)
+		}
+	}
+
+	large := &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "large",
+			Value: bytes.Repeat([]byte("a"), 2048),
+		},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := cache.Put(ctx, large)
+		if err != nil {
+			t.Fatalf("Put(large) #%d=%v; want nil error", i, err)
+		}
+	}
+	if inBucket("large") {
+		t.Errorf("large in bucket; want rejected by raw size")
+	}
+	resp, err := cache.Get(ctx, &pb.GetReq{Key: "large"})
+	if err != nil || !bytes.Equal(resp.GetKv().GetValue(), large.Kv.Value) {
+		t.Errorf("Get(large)=%v, %v; want value in memory", resp, err)
+	}
+}
@@ -7,6 +7,7 @@ package cache
 import (
 	"context"
 
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 
 	"go.chromium.org/goma/server/rpc"
@@ -65,3 +66,102 @@ func (c Client) Put(ctx context.Context, in *pb.PutReq, opts ...grpc.CallOption)
 		})
 	return resp, err
 }
+
+// BatchGet gets key-value data for requested keys.
+// Keys are sent to each shard in a batch.
+func (c Client) BatchGet(ctx context.Context, in *pb.BatchGetReq, opts ...grpc.CallOption) (*pb.BatchGetResp, error) {
+	groups, err := c.client.ShardKeys(ctx, in.Keys)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.BatchGetResp{
+		Resps: make([]*pb.GetResp, len(in.Keys)),
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, idx := range groups {
+		idx := idx
+		req := &pb.BatchGetReq{
+			Fast: in.Fast,
+		}
+		for _, i := range idx {
+			req.Keys = append(req.Keys, in.Keys[i])
+		}
+		eg.Go(func() error {
+			var r *pb.BatchGetResp
+			var err error
+			err = c.client.Call(ctx, c.client.Shard, req.Keys[0],
+				func(client interface{}) error {
+					r, err = client.(pb.CacheServiceClient).BatchGet(ctx, req, opts...)
+					return err
+				})
+			if err != nil {
+				return err
+			}
+			for j, i := range idx {
+				if j < len(r.Resps) {
+					resp.Resps[i] = r.Resps[j]
+				}
+			}
+			return nil
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Resps {
+		if resp.Resps[i] == nil {
+			resp.Resps[i] = &pb.GetResp{}
+		}
+	}
+	return resp, nil
+}
+
+// BatchPut puts new key-value data.
+// Key-values are sent to each shard in a batch.
+func (c Client) BatchPut(ctx context.Context, in *pb.BatchPutReq, opts ...grpc.CallOption) (*pb.BatchPutResp, error) {
+	var reqs []*pb.PutReq
+	var keys []string
+	for _, req := range in.Reqs {
+		if req.Kv == nil {
+			continue
+		}
+		reqs = append(reqs, req)
+		keys = append(keys, req.Kv.Key)
+	}
+	groups, err := c.client.ShardKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, idx := range groups {
+		req := &pb.BatchPutReq{}
+		for _, i := range idx {
+			req.Reqs = append(req.Reqs, reqs[i])
+		}
+		eg.Go(func() error {
+			return c.client.Call(ctx, c.client.Shard, req.Reqs[0].Kv.Key,
+				func(client interface{}) error {
+					_, err := client.(pb.CacheServiceClient).BatchPut(ctx, req, opts...)
+					return err
+				})
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchPutResp{}, nil
+}
+
+// Delete deletes key-value data for requested key.
+func (c Client) Delete(ctx context.Context, in *pb.DeleteReq, opts ...grpc.CallOption) (*pb.DeleteResp, error) {
+	var resp *pb.DeleteResp
+	var err error
+	err = c.client.Call(ctx, c.client.Shard, in.Key,
+		func(client interface{}) error {
+			resp, err = client.(pb.CacheServiceClient).Delete(ctx, in, opts...)
+			return err
+		})
+	return resp, err
+}
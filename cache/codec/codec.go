@@ -0,0 +1,286 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+// Format is compression format of encoded value.
+type Format byte
+
+const (
+	// None stores value uncompressed.
+	None Format = 0
+	// Flate compresses value by DEFLATE (RFC 1951).
+	Flate Format = 1
+	// Zstd compresses value by Zstandard (RFC 8878).
+	Zstd Format = 2
+)
+
+// magic is the prefix of encoded value.
+var magic = []byte{0x00, 'G', 'C', 'V'}
+
+// version is the version of header, which follows magic.
+const version = 1
+
+// headerSize is size of header: magic, version and format.
+const headerSize = 6
+
+func (f Format) String() string {
+	switch f {
+	case None:
+		return "none"
+	case Flate:
+		return "flate"
+	case Zstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("Format(%d)", byte(f))
+}
+
+// ParseFormat parses s as Format.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "none":
+		return None, nil
+	case "flate":
+		return Flate, nil
+	case "zstd":
+		return Zstd, nil
+	}
+	return None, fmt.Errorf("codec: unknown format %q", s)
+}
+
+// DefaultMinSize is default minimum size of value to compress.
+const DefaultMinSize = 256
+
+// Codec encodes and decodes cache values.
+type Codec struct {
+	// Format is compression format used by Encode.
+	// Decode accepts any known format regardless of Format.
+	Format Format
+
+	// Level is compression level of flate.
+	// 0 means flate.DefaultCompression.
+	// zstd always uses its default level.
+	Level int
+
+	// MinSize is minimum size of value to compress.
+	// Smaller value is stored uncompressed.
+	// 0 means DefaultMinSize.
+	MinSize int
+}
+
+var flateWriters [flate.BestCompression + 1]sync.Pool
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// initZstd initializes zstd encoder and decoder shared by all Codecs.
+// EncodeAll and DecodeAll can be called concurrently.
+func initZstd() error {
+	zstdOnce.Do(func() {
+		zstdEncoder, zstdErr = zstd.NewWriter(nil)
+		if zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+func (c Codec) level() int {
+	if c.Level <= 0 || c.Level > flate.BestCompression {
+		return 6 // same as flate.DefaultCompression
+	}
+	return c.Level
+}
+
+func (c Codec) minSize() int {
+	if c.MinSize <= 0 {
+		return DefaultMinSize
+	}
+	return c.MinSize
+}
+
+func header(f Format) []byte {
+	h := make([]byte, 0, headerSize)
+	h = append(h, magic...)
+	return append(h, version, byte(f))
+}
+
+// Encode encodes value.
+// If value is not compressed well, it is stored uncompressed. Even then,
+// value starting with magic gets header, so that Decode can distinguish
+// it from encoded value.
+func (c Codec) Encode(ctx context.Context, value []byte) []byte {
+	if c.Format != None && len(value) >= c.minSize() {
+		var b []byte
+		var err error
+		switch c.Format {
+		case Flate:
+			b, err = c.flate(value)
+		case Zstd:
+			b, err = c.zstd(value)
+		default:
+			err = fmt.Errorf("codec: unknown format %s", c.Format)
+		}
+		if err == nil && len(b) < len(value) {
+			record(ctx, c.Format, len(value), len(b))
+			return b
+		}
+	}
+	if !bytes.HasPrefix(value, magic) {
+		record(ctx, None, len(value), len(value))
+		return value
+	}
+	b := append(header(None), value...)
+	record(ctx, None, len(value), len(b))
+	return b
+}
+
+func (c Codec) zstd(value []byte) ([]byte, error) {
+	err := initZstd()
+	if err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(value, header(Zstd)), nil
+}
+
+func (c Codec) flate(value []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(header(Flate))
+	level := c.level()
+	pool := &flateWriters[level]
+	w, ok := pool.Get().(*flate.Writer)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		var err error
+		w, err = flate.NewWriter(&buf, level)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer pool.Put(w)
+	_, err := w.Write(value)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode decodes value encoded by Encode.
+// Value without magic, i.e. stored before compression was enabled,
+// is returned as is.
+func Decode(value []byte) ([]byte, error) {
+	if !bytes.HasPrefix(value, magic) {
+		return value, nil
+	}
+	if len(value) < headerSize {
+		return nil, fmt.Errorf("codec: truncated header")
+	}
+	v, f := value[len(magic)], Format(value[len(magic)+1])
+	if v != version {
+		return nil, fmt.Errorf("codec: unsupported version %d", v)
+	}
+	body := value[headerSize:]
+	switch f {
+	case None:
+		return body, nil
+	case Flate:
+		r := flate.NewReader(bytes.NewReader(body))
+		defer r.Close()
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("codec: %s: %v", f, err)
+		}
+		return b, nil
+	case Zstd:
+		err := initZstd()
+		if err != nil {
+			return nil, fmt.Errorf("codec: %s: %v", f, err)
+		}
+		b, err := zstdDecoder.DecodeAll(body, nil)
+		if err != nil {
+			return nil, fmt.Errorf("codec: %s: %v", f, err)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("codec: unsupported format %s", f)
+}
+
+var (
+	rawBytes = stats.Int64(
+		"go.chromium.org/goma/server/cache/codec.raw-bytes",
+		"size of cache value before encoding",
+		stats.UnitBytes)
+	encodedBytes = stats.Int64(
+		"go.chromium.org/goma/server/cache/codec.encoded-bytes",
+		"size of cache value after encoding",
+		stats.UnitBytes)
+	compressionRatio = stats.Float64(
+		"go.chromium.org/goma/server/cache/codec.compression-ratio",
+		"ratio of raw size to encoded size of cache value",
+		stats.UnitDimensionless)
+
+	formatKey = tag.MustNewKey("format")
+
+	// DefaultViews are the default views provided by this package.
+	// You need to register the view for data to actually be collected.
+	DefaultViews = []*view.View{
+		{
+			Description: "total size of cache values before encoding",
+			TagKeys: []tag.Key{
+				formatKey,
+			},
+			Measure:     rawBytes,
+			Aggregation: view.Sum(),
+		},
+		{
+			Description: "total size of cache values after encoding",
+			TagKeys: []tag.Key{
+				formatKey,
+			},
+			Measure:     encodedBytes,
+			Aggregation: view.Sum(),
+		},
+		{
+			Description: "distribution of compression ratio of cache values",
+			TagKeys: []tag.Key{
+				formatKey,
+			},
+			Measure:     compressionRatio,
+			Aggregation: view.Distribution(1, 1.5, 2, 2.5, 3, 4, 5, 6, 8, 10, 15, 20, 50),
+		},
+	}
+)
+
+func record(ctx context.Context, f Format, raw, encoded int) {
+	ms := []stats.Measurement{rawBytes.M(int64(raw)), encodedBytes.M(int64(encoded))}
+	if encoded > 0 {
+		ms = append(ms, compressionRatio.M(float64(raw)/float64(encoded)))
+	}
+	stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(formatKey, f.String())}, ms...)
+}
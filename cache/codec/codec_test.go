@@ -0,0 +1,151 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codec
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	gomapb "go.chromium.org/goma/server/proto/api"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	ctx := context.Background()
+	blob, err := proto.Marshal(&gomapb.FileBlob{
+		BlobType: gomapb.FileBlob_FILE.Enum(),
+		Content:  []byte(strings.Repeat("#include <stdio.h>\n", 100)),
+		FileSize: proto.Int64(1900),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	random := make([]byte, 1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	for _, tc := range []struct {
+		desc       string
+		codec      Codec
+		value      []byte
+		wantHeader []byte
+	}{
+		{
+			desc:  "none",
+			codec: Codec{Format: None},
+			value: blob,
+		},
+		{
+			desc:       "flate",
+			codec:      Codec{Format: Flate},
+			value:      blob,
+			wantHeader: []byte("\x00GCV\x01\x01"),
+		},
+		{
+			desc:       "zstd",
+			codec:      Codec{Format: Zstd},
+			value:      blob,
+			wantHeader: []byte("\x00GCV\x01\x02"),
+		},
+		{
+			desc:  "small",
+			codec: Codec{Format: Flate},
+			value: []byte("value"),
+		},
+		{
+			desc:  "incompressible",
+			codec: Codec{Format: Flate},
+			value: random[1:],
+		},
+		{
+			desc:  "incompressible zstd",
+			codec: Codec{Format: Zstd},
+			value: random[1:],
+		},
+		{
+			// raw value starting with 0x00 is stored as is.
+			desc:  "raw zero",
+			codec: Codec{Format: Flate},
+			value: []byte{0x00, 0x11, 'a'},
+		},
+		{
+			desc:       "magic",
+			codec:      Codec{Format: Zstd},
+			value:      []byte("\x00GCV\x01\x02a"),
+			wantHeader: []byte("\x00GCV\x01\x00"),
+		},
+		{
+			desc:  "empty",
+			codec: Codec{Format: Flate},
+			value: []byte{},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			value := append([]byte{}, tc.value...)
+			b := tc.codec.Encode(ctx, value)
+			if tc.wantHeader == nil {
+				if !bytes.Equal(b, tc.value) {
+					t.Errorf("Encode(%q)=%q; want as is", tc.value, b)
+				}
+			} else if !bytes.HasPrefix(b, tc.wantHeader) {
+				t.Errorf("Encode(%q)=%q; want header %q", tc.value, b, tc.wantHeader)
+			}
+			if len(b) > len(tc.value)+headerSize {
+				t.Errorf("Encode(%q)=%d bytes; want <= %d", tc.value, len(b), len(tc.value)+headerSize)
+			}
+			got, err := Decode(b)
+			if err != nil || !bytes.Equal(got, tc.value) {
+				t.Errorf("Decode(%q)=%q, %v; want %q, nil", b, got, err, tc.value)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, value := range [][]byte{
+		[]byte("\x00GCV"),
+		[]byte("\x00GCV\x01"),
+		[]byte("\x00GCV\x02\x01a"),
+		[]byte("\x00GCV\x01\x03a"),
+		[]byte("\x00GCV\x01\x01bad"),
+		[]byte("\x00GCV\x01\x02not zstd frame"),
+	} {
+		_, err := Decode(value)
+		if err == nil {
+			t.Errorf("Decode(%q)=_, nil; want error", value)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{None, Flate, Zstd} {
+		got, err := ParseFormat(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q)=%v, %v; want %v, nil", f.String(), got, err, f)
+		}
+	}
+	_, err := ParseFormat("lz4")
+	if err == nil {
+		t.Errorf("ParseFormat(lz4)=_, nil; want error")
+	}
+}
+
+func TestDecodeRawZero(t *testing.T) {
+	// raw values starting with 0x00, which are not encoded by Codec.
+	for _, value := range [][]byte{
+		{0x00},
+		{0x00, 0x11, 'a'},
+		{0x00, 'G', 'C'},
+		[]byte("\x00GCX\x01\x01"),
+	} {
+		got, err := Decode(value)
+		if err != nil || !bytes.Equal(got, value) {
+			t.Errorf("Decode(%q)=%q, %v; want as is", value, got, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Package codec provides transparent compression of cache values.
+
+Encoded value starts with header of six bytes, magic "\x00GCV",
+version byte and format byte. Marshaled protocol buffer message never
+starts with 0x00, because field number 0 is invalid, so values stored
+verbatim before compression was enabled are returned as is by Decode.
+Other value starting with magic is stored with header of format none.
+
+Supported formats are flate and zstd.
+*/
+package codec
@@ -0,0 +1,412 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package disk
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// Cache is a key-value cache on local disk.
+//
+// Value is stored in a file named by sha256 of key,
+// "<dir>/<hash[:2]>/<hash>". File is written in "<dir>/tmp" and
+// renamed, so partially written file won't be seen after crash.
+// LRU index is kept in memory, and rebuilt from file's mtime on open.
+// Files are evicted in background when total size exceeds max bytes.
+//
+// Files are renamed and removed while holding mu, so the index and
+// files in dir are kept consistent with concurrent Put.
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu     sync.Mutex
+	lru    *list.List // of *entry. front is most recently used.
+	m      map[string]*list.Element
+	nbytes int64
+
+	nhit, nget int64
+	nevict     int64
+	nerror     int64
+
+	evictq chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+type entry struct {
+	name string
+	size int64
+	hits int64
+}
+
+const tmpDir = "tmp"
+
+// lowWatermark is ratio of max bytes that eviction reduces to,
+// so that eviction doesn't run on every put.
+const lowWatermark = 0.9
+
+// Open opens disk cache in dir.
+// It rebuilds index from files in dir.
+// maxBytes is maximum number of bytes of values. 0 means no limit.
+func Open(ctx context.Context, dir string, maxBytes int64) (*Cache, error) {
+	logger := log.FromContext(ctx)
+	// files in tmp were not completely written.
+	err := os.RemoveAll(filepath.Join(dir, tmpDir))
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Join(dir, tmpDir), 0755)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		m:        make(map[string]*list.Element),
+		evictq:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	err = c.load()
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("disk cache %s: %d entries %d bytes", dir, c.lru.Len(), c.nbytes)
+	c.wg.Add(1)
+	go c.evictLoop(ctx)
+	c.maybeEvict()
+	return c, nil
+}
+
+// load rebuilds index from files in dir.
+func (c *Cache) load() error {
+	type file struct {
+		entry
+		mtime time.Time
+	}
+	var files []file
+	fis, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if !fi.IsDir() || len(fi.Name()) != 2 {
+			continue
+		}
+		subfis, err := ioutil.ReadDir(filepath.Join(c.dir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		for _, sfi := range subfis {
+			name := sfi.Name()
+			if !sfi.Mode().IsRegular() || !validName(name) || name[:2] != fi.Name() {
+				continue
+			}
+			files = append(files, file{
+				entry: entry{
+					name: name,
+					size: sfi.Size(),
+				},
+				mtime: sfi.ModTime(),
+			})
+		}
+	}
+	// most recent first.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].mtime.After(files[j].mtime)
+	})
+	for _, f := range files {
+		e := f.entry
+		c.m[e.name] = c.lru.PushBack(&e)
+		c.nbytes += e.size
+	}
+	return nil
+}
+
+func validName(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+func keyName(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+func (c *Cache) filename(name string) string {
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// Get gets value for key.
+// It also returns number of hits of the key in disk cache,
+// including this get.
+func (c *Cache) Get(ctx context.Context, key string) (value []byte, hits int64, ok bool) {
+	span := trace.FromContext(ctx)
+	span.Annotatef(nil, "disk.get %s", key)
+	logger := log.FromContext(ctx)
+
+	name := keyName(key)
+	c.mu.Lock()
+	c.nget++
+	elem, ok := c.m[name]
+	if !ok {
+		c.mu.Unlock()
+		logger.Infof("disk.miss %s", key)
+		return nil, 0, false
+	}
+	c.lru.MoveToFront(elem)
+	e := elem.Value.(*entry)
+	size := e.size
+	c.mu.Unlock()
+
+	fname := c.filename(name)
+	value, err := ioutil.ReadFile(fname)
+	if err == nil && int64(len(value)) != size {
+		err = fmt.Errorf("size mismatch: %d != %d", len(value), size)
+	}
+	if err != nil {
+		logger.Errorf("disk.bad  %s: %v", key, err)
+		c.mu.Lock()
+		c.nerror++
+		// file may be replaced by Put after we read.
+		if c.m[name] == elem {
+			c.removeLocked(name)
+			os.Remove(fname)
+		}
+		c.mu.Unlock()
+		return nil, 0, false
+	}
+	// keep recency for index rebuild.
+	now := time.Now()
+	os.Chtimes(fname, now, now)
+
+	c.mu.Lock()
+	c.nhit++
+	e.hits++
+	hits = e.hits
+	c.mu.Unlock()
+	logger.Infof("disk.hit  %s %d hits:%d", key, len(value), hits)
+	return value, hits, true
+}
+
+// Put puts key-value pair in disk cache.
+// If key already exists with the same size, it is considered
+// as no change, since value is expected to be content-addressed.
+func (c *Cache) Put(ctx context.Context, key string, value []byte) error {
+	span := trace.FromContext(ctx)
+	span.Annotatef(nil, "disk.put %s (size:%d)", key, len(value))
+	logger := log.FromContext(ctx)
+
+	name := keyName(key)
+	c.mu.Lock()
+	elem, ok := c.m[name]
+	if ok && elem.Value.(*entry).size == int64(len(value)) {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		logger.Infof("disk.put2 %s %d", key, len(value))
+		return nil
+	}
+	c.mu.Unlock()
+
+	tmpname, err := c.writeTemp(name, value)
+	if err == nil {
+		c.mu.Lock()
+		err = os.Rename(tmpname, c.filename(name))
+		if err == nil {
+			c.removeLocked(name)
+			c.m[name] = c.lru.PushFront(&entry{
+				name: name,
+				size: int64(len(value)),
+			})
+			c.nbytes += int64(len(value))
+		}
+		c.mu.Unlock()
+		if err != nil {
+			os.Remove(tmpname)
+		}
+	}
+	if err != nil {
+		logger.Errorf("disk.put  %s %d: %v", key, len(value), err)
+		c.mu.Lock()
+		c.nerror++
+		c.mu.Unlock()
+		return err
+	}
+	logger.Infof("disk.put  %s %d", key, len(value))
+	c.maybeEvict()
+	return nil
+}
+
+// writeTemp writes value to a temporary file for name, and returns
+// its filename. The file should be renamed to c.filename(name).
+func (c *Cache) writeTemp(name string, value []byte) (string, error) {
+	f, err := ioutil.TempFile(filepath.Join(c.dir, tmpDir), name)
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(value)
+	if err == nil {
+		err = f.Sync()
+	}
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.MkdirAll(filepath.Join(c.dir, name[:2]), 0755)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Delete deletes key from disk cache.
+func (c *Cache) Delete(ctx context.Context, key string) {
+	name := keyName(key)
+	c.mu.Lock()
+	ok := c.removeLocked(name)
+	if ok {
+		os.Remove(c.filename(name))
+	}
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	logger.Infof("disk.del  %s", key)
+}
+
+// removeLocked removes name from index.
+// It returns true if name was in index.
+func (c *Cache) removeLocked(name string) bool {
+	elem, ok := c.m[name]
+	if !ok {
+		return false
+	}
+	c.lru.Remove(elem)
+	delete(c.m, name)
+	c.nbytes -= elem.Value.(*entry).size
+	return true
+}
+
+func (c *Cache) maybeEvict() {
+	if c.maxBytes == 0 {
+		return
+	}
+	c.mu.Lock()
+	over := c.nbytes > c.maxBytes
+	c.mu.Unlock()
+	if !over {
+		return
+	}
+	select {
+	case c.evictq <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Cache) evictLoop(ctx context.Context) {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.evictq:
+			c.evict(ctx)
+		}
+	}
+}
+
+// evict removes least recently used files until total size
+// is under low watermark of max bytes.
+func (c *Cache) evict(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	limit := int64(float64(c.maxBytes) * lowWatermark)
+	for {
+		c.mu.Lock()
+		if c.nbytes <= limit {
+			c.mu.Unlock()
+			return
+		}
+		elem := c.lru.Back()
+		if elem == nil {
+			c.mu.Unlock()
+			return
+		}
+		e := elem.Value.(*entry)
+		c.removeLocked(e.name)
+		c.nevict++
+		err := os.Remove(c.filename(e.name))
+		c.mu.Unlock()
+		logger.Infof("disk.evict %s %d", e.name, e.size)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Errorf("disk.evict %s: %v", e.name, err)
+		}
+	}
+}
+
+// Len returns number of entries in disk cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close stops background eviction.
+func (c *Cache) Close() error {
+	close(c.done)
+	c.wg.Wait()
+	return nil
+}
+
+// Stats represents stats of disk.Cache.
+// TODO: use opencensus stats, view.
+type Stats struct {
+	MaxBytes int64
+
+	Bytes  int64
+	Num    int
+	Hits   int64
+	Gets   int64
+	Evicts int64
+	Errors int64
+}
+
+func (c *Cache) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		MaxBytes: c.maxBytes,
+		Bytes:    c.nbytes,
+		Num:      c.lru.Len(),
+		Hits:     c.nhit,
+		Gets:     c.nget,
+		Evicts:   c.nevict,
+		Errors:   c.nerror,
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package disk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetPut(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "disk.TestGetPut.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(ctx, dir, 0)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, 0)=%v; want nil error", dir, err)
+	}
+	if got, _, ok := c.Get(ctx, "key"); ok {
+		t.Errorf("Get(ctx, key)=%q, true; want false", got)
+	}
+	err = c.Put(ctx, "key", []byte("value"))
+	if err != nil {
+		t.Errorf("Put(ctx, key, value)=%v; want nil error", err)
+	}
+	for i := 1; i <= 2; i++ {
+		got, hits, ok := c.Get(ctx, "key")
+		if !ok || string(got) != "value" || hits != int64(i) {
+			t.Errorf("Get(ctx, key)=%q, %d, %t; want value, %d, true", got, hits, ok, i)
+		}
+	}
+	c.Close()
+
+	// partially written file should be removed on open.
+	err = ioutil.WriteFile(filepath.Join(dir, tmpDir, "partial"), []byte("val"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = Open(ctx, dir, 0)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, 0)=%v; want nil error", dir, err)
+	}
+	defer c.Close()
+	if got, want := c.Len(), 1; got != want {
+		t.Errorf("Len()=%d; want %d", got, want)
+	}
+	got, _, ok := c.Get(ctx, "key")
+	if !ok || string(got) != "value" {
+		t.Errorf("Get(ctx, key)=%q, %t; want value, true", got, ok)
+	}
+	if _, err := os.Stat(filepath.Join(dir, tmpDir, "partial")); !os.IsNotExist(err) {
+		t.Errorf("partial file exists: %v", err)
+	}
+
+	c.Delete(ctx, "key")
+	if got, _, ok := c.Get(ctx, "key"); ok {
+		t.Errorf("Get(ctx, key)=%q, true after delete; want false", got)
+	}
+	if got, want := c.Stats().Bytes, int64(0); got != want {
+		t.Errorf("Stats().Bytes=%d; want %d", got, want)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "disk.TestEviction.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const maxBytes = 100
+	c, err := Open(ctx, dir, maxBytes)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, %d)=%v; want nil error", dir, maxBytes, err)
+	}
+	for i := 0; i < 20; i++ {
+		err := c.Put(ctx, fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%04d", i)))
+		if err != nil {
+			t.Fatalf("Put(ctx, key%d)=%v; want nil error", i, err)
+		}
+		// key0 is used recently.
+		c.Get(ctx, "key0")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Bytes > maxBytes {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats().Bytes=%d; want <= %d", c.Stats().Bytes, maxBytes)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, _, ok := c.Get(ctx, "key1"); ok {
+		t.Errorf("Get(ctx, key1)=_, _, true; want evicted")
+	}
+	for _, key := range []string{"key0", "key19"} {
+		if _, _, ok := c.Get(ctx, key); !ok {
+			t.Errorf("Get(ctx, %s)=_, _, false; want true", key)
+		}
+	}
+	st := c.Stats()
+	c.Close()
+
+	// index is rebuilt from files.
+	c, err = Open(ctx, dir, maxBytes)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, %d)=%v; want nil error", dir, maxBytes, err)
+	}
+	defer c.Close()
+	if got := c.Stats(); got.Num != st.Num || got.Bytes != st.Bytes {
+		t.Errorf("Stats()=%d entries %d bytes; want %d entries %d bytes", got.Num, got.Bytes, st.Num, st.Bytes)
+	}
+}
+
+func TestBadFile(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "disk.TestBadFile.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(ctx, dir, 0)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, 0)=%v; want nil error", dir, err)
+	}
+	defer c.Close()
+	err = c.Put(ctx, "key", []byte("value"))
+	if err != nil {
+		t.Fatalf("Put(ctx, key, value)=%v; want nil error", err)
+	}
+	// file is truncated outside of cache.
+	name := keyName("key")
+	err = ioutil.WriteFile(c.filename(name), []byte("val"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, ok := c.Get(ctx, "key"); ok {
+		t.Errorf("Get(ctx, key)=%q, true; want false for bad file", got)
+	}
+	if got, want := c.Len(), 0; got != want {
+		t.Errorf("Len()=%d; want %d", got, want)
+	}
+}
+
+func TestConcurrentPutEvict(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "disk.TestConcurrentPutEvict.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const maxBytes = 100
+	c, err := Open(ctx, dir, maxBytes)
+	if err != nil {
+		t.Fatalf("Open(ctx, %q, %d)=%v; want nil error", dir, maxBytes, err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				key := fmt.Sprintf("key%d", j%10)
+				// value size differs by goroutine to replace file.
+				value := bytes.Repeat([]byte{'v'}, 10+i)
+				if err := c.Put(ctx, key, value); err != nil {
+					t.Errorf("Put(ctx, %s)=%v; want nil error", key, err)
+				}
+				switch j % 3 {
+				case 1:
+					c.Get(ctx, key)
+				case 2:
+					c.Delete(ctx, key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	c.Close()
+
+	// every file in index must exist with the same size, and
+	// no file exists without index.
+	files := make(map[string]int64)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			files[info.Name()] = info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(files) != len(c.m) {
+		t.Errorf("%d files, %d entries in index; want same", len(files), len(c.m))
+	}
+	for name, elem := range c.m {
+		e := elem.Value.(*entry)
+		size, ok := files[name]
+		if !ok || size != e.size {
+			t.Errorf("file %s: size=%d, %t; want %d, true", name, size, ok, e.size)
+		}
+	}
+}
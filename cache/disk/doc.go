@@ -0,0 +1,9 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Package disk provides cache service by local disk.
+
+*/
+package disk
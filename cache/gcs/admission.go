@@ -0,0 +1,300 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/peer"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	pb "go.chromium.org/goma/server/proto/cache"
+)
+
+// NotAdmittedError is an error returned by admission controllers in
+// this package when they reject Put by policy.
+type NotAdmittedError struct {
+	// Reason is "size", "second-hit" or "rate".
+	Reason string
+	Key    string
+	Msg    string
+}
+
+func (e *NotAdmittedError) Error() string {
+	return fmt.Sprintf("gcs: not admitted %s: %s: %s", e.Key, e.Reason, e.Msg)
+}
+
+// AdmissionControllers admits Put if all controllers admit it.
+// Controllers are checked in order, and later controllers are not
+// checked once rejected.
+type AdmissionControllers []AdmissionController
+
+// AdmitPut checks in by all controllers.
+func (a AdmissionControllers) AdmitPut(ctx context.Context, in *pb.PutReq) error {
+	for _, c := range a {
+		err := c.AdmitPut(ctx, in)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rawSizeKey struct{}
+
+// WithRawSize returns context with size of raw value of PutReq, for
+// value encoded before Put, so that admission controllers check
+// the size of value before encoding.
+func WithRawSize(ctx context.Context, size int) context.Context {
+	return context.WithValue(ctx, rawSizeKey{}, int64(size))
+}
+
+// rawSize returns size of raw value of in.
+func rawSize(ctx context.Context, in *pb.PutReq) int64 {
+	if size, ok := ctx.Value(rawSizeKey{}).(int64); ok {
+		return size
+	}
+	return int64(len(in.Kv.Value))
+}
+
+// SizeAdmission admits Put by value size.
+// It checks size of raw value given by WithRawSize, if any.
+type SizeAdmission struct {
+	// MinBytes is minimum size of value to admit.
+	MinBytes int64
+	// MaxBytes is maximum size of value to admit. 0 means no limit.
+	MaxBytes int64
+}
+
+// AdmitPut checks value size of in.
+func (a SizeAdmission) AdmitPut(ctx context.Context, in *pb.PutReq) error {
+	size := rawSize(ctx, in)
+	if size < a.MinBytes {
+		return &NotAdmittedError{
+			Reason: "size",
+			Key:    in.Kv.Key,
+			Msg:    fmt.Sprintf("%d < min %d", size, a.MinBytes),
+		}
+	}
+	if a.MaxBytes > 0 && size > a.MaxBytes {
+		return &NotAdmittedError{
+			Reason: "size",
+			Key:    in.Kv.Key,
+			Msg:    fmt.Sprintf("%d > max %d", size, a.MaxBytes),
+		}
+	}
+	return nil
+}
+
+// DefaultSecondHitMaxKeys is default number of keys remembered by
+// SecondHitAdmission.
+const DefaultSecondHitMaxKeys = 1 << 20
+
+// SecondHitAdmission admits Put of a key only after the key is put
+// Hits times, so that one-off keys don't cost cloud storage operations.
+//
+// It remembers keys in two generations, up to maxKeys in each
+// generation. When current generation becomes full, previous generation
+// is dropped, so keys not put for long are forgotten.
+type SecondHitAdmission struct {
+	hits    int
+	maxKeys int
+
+	mu   sync.Mutex
+	cur  map[string]int
+	prev map[string]int
+}
+
+// NewSecondHitAdmission creates SecondHitAdmission admitting keys put
+// hits times. maxKeys <= 0 means DefaultSecondHitMaxKeys.
+func NewSecondHitAdmission(hits, maxKeys int) *SecondHitAdmission {
+	if maxKeys <= 0 {
+		maxKeys = DefaultSecondHitMaxKeys
+	}
+	return &SecondHitAdmission{
+		hits:    hits,
+		maxKeys: maxKeys,
+		cur:     make(map[string]int),
+	}
+}
+
+// AdmitPut counts the key of in, and checks it was put enough times.
+func (a *SecondHitAdmission) AdmitPut(ctx context.Context, in *pb.PutReq) error {
+	key := in.Kv.Key
+	a.mu.Lock()
+	n, ok := a.cur[key]
+	if !ok {
+		n = a.prev[key]
+	}
+	n++
+	a.cur[key] = n
+	if len(a.cur) >= a.maxKeys {
+		a.prev = a.cur
+		a.cur = make(map[string]int)
+	}
+	a.mu.Unlock()
+	if n < a.hits {
+		return &NotAdmittedError{
+			Reason: "second-hit",
+			Key:    key,
+			Msg:    fmt.Sprintf("seen %d < %d", n, a.hits),
+		}
+	}
+	return nil
+}
+
+// maxRateBuckets is number of requesters to trigger cleanup of idle
+// token buckets.
+const maxRateBuckets = 10000
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateAdmission limits rate of Put per requester by token bucket.
+type RateAdmission struct {
+	rate  float64
+	burst int
+
+	// Requester returns requester of the request in ctx.
+	// If nil, end user's email or group is used. If end user is not
+	// available, e.g. request from file server to cache server, host
+	// of grpc peer is used.
+	Requester func(ctx context.Context) string
+
+	// for test.
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// NewRateAdmission creates RateAdmission admitting rate Put per second
+// with burst for each requester.
+func NewRateAdmission(rate float64, burst int) *RateAdmission {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateAdmission{
+		rate:    rate,
+		burst:   burst,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func requester(ctx context.Context) string {
+	if u, _ := enduser.FromContext(ctx); u != nil {
+		if u.Email != "" {
+			return string(u.Email)
+		}
+		if u.Group != "" {
+			return u.Group
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// AdmitPut takes a token from the bucket of the requester.
+func (a *RateAdmission) AdmitPut(ctx context.Context, in *pb.PutReq) error {
+	f := a.Requester
+	if f == nil {
+		f = requester
+	}
+	who := f(ctx)
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.buckets) >= maxRateBuckets {
+		a.cleanup(now)
+	}
+	b, ok := a.buckets[who]
+	if !ok {
+		b = &tokenBucket{
+			tokens: float64(a.burst),
+			last:   now,
+		}
+		a.buckets[who] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * a.rate
+	if b.tokens > float64(a.burst) {
+		b.tokens = float64(a.burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return &NotAdmittedError{
+			Reason: "rate",
+			Key:    in.Kv.Key,
+			Msg:    fmt.Sprintf("exceeds %g/s burst %d", a.rate, a.burst),
+		}
+	}
+	b.tokens--
+	return nil
+}
+
+// cleanup drops buckets that are full at now, which are the same as
+// new buckets.
+func (a *RateAdmission) cleanup(now time.Time) {
+	for who, b := range a.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*a.rate >= float64(a.burst) {
+			delete(a.buckets, who)
+		}
+	}
+}
+
+var (
+	admissions = stats.Int64(
+		"go.chromium.org/goma/server/cache/gcs.admission",
+		"admission of put to cloud storage",
+		stats.UnitDimensionless)
+
+	admissionResultKey = tag.MustNewKey("result")
+	admissionReasonKey = tag.MustNewKey("reason")
+
+	// DefaultViews are the default views provided by this package.
+	// You need to register the view for data to actually be collected.
+	DefaultViews = []*view.View{
+		{
+			Description: `counts admission of put to cloud storage. result is "admit" or "reject". reason is reject reason`,
+			TagKeys: []tag.Key{
+				admissionResultKey,
+				admissionReasonKey,
+			},
+			Measure:     admissions,
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+func recordAdmission(ctx context.Context, err error) {
+	result, reason := "admit", ""
+	if err != nil {
+		result, reason = "reject", "other"
+		if aerr, ok := err.(*NotAdmittedError); ok {
+			reason = aerr.Reason
+		}
+	}
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(admissionResultKey, result),
+		tag.Upsert(admissionReasonKey, reason),
+	}, admissions.M(1))
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package gcs
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/peer"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	pb "go.chromium.org/goma/server/proto/cache"
+)
+
+func putReq(key string, size int) *pb.PutReq {
+	return &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   key,
+			Value: make([]byte, size),
+		},
+	}
+}
+
+func checkAdmit(t *testing.T, a AdmissionController, ctx context.Context, in *pb.PutReq, wantReason string) {
+	t.Helper()
+	err := a.AdmitPut(ctx, in)
+	if wantReason == "" {
+		if err != nil {
+			t.Errorf("AdmitPut(%s)=%v; want nil error", in.Kv.Key, err)
+		}
+		return
+	}
+	aerr, ok := err.(*NotAdmittedError)
+	if !ok || aerr.Reason != wantReason {
+		t.Errorf("AdmitPut(%s)=%v; want not admitted by %s", in.Kv.Key, err, wantReason)
+	}
+}
+
+func TestSizeAdmission(t *testing.T) {
+	ctx := context.Background()
+	a := SizeAdmission{
+		MinBytes: 10,
+		MaxBytes: 100,
+	}
+	checkAdmit(t, a, ctx, putReq("small", 9), "size")
+	checkAdmit(t, a, ctx, putReq("min", 10), "")
+	checkAdmit(t, a, ctx, putReq("max", 100), "")
+	checkAdmit(t, a, ctx, putReq("large", 101), "size")
+
+	// raw size is checked for encoded value.
+	checkAdmit(t, a, WithRawSize(ctx, 101), putReq("encoded", 50), "size")
+	checkAdmit(t, a, WithRawSize(ctx, 50), putReq("encoded", 101), "")
+
+	a.MaxBytes = 0
+	checkAdmit(t, a, ctx, putReq("large", 101), "")
+}
+
+func TestSecondHitAdmission(t *testing.T) {
+	ctx := context.Background()
+	a := NewSecondHitAdmission(2, 3)
+	checkAdmit(t, a, ctx, putReq("key1", 1), "second-hit")
+	checkAdmit(t, a, ctx, putReq("key1", 1), "")
+	checkAdmit(t, a, ctx, putReq("key1", 1), "")
+
+	// key2 and key3 fill current generation, and key1 moves to
+	// previous generation.
+	checkAdmit(t, a, ctx, putReq("key2", 1), "second-hit")
+	checkAdmit(t, a, ctx, putReq("key3", 1), "second-hit")
+	checkAdmit(t, a, ctx, putReq("key1", 1), "")
+
+	// key4..key6 fill current generation twice, so key3 is forgotten.
+	for _, key := range []string{"key4", "key5", "key6"} {
+		checkAdmit(t, a, ctx, putReq(key, 1), "second-hit")
+	}
+	checkAdmit(t, a, ctx, putReq("key3", 1), "second-hit")
+}
+
+type requesterKey struct{}
+
+func TestRateAdmission(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	a := NewRateAdmission(2, 3)
+	a.now = func() time.Time { return now }
+	a.Requester = func(ctx context.Context) string {
+		return ctx.Value(requesterKey{}).(string)
+	}
+	alice := context.WithValue(ctx, requesterKey{}, "alice")
+	bob := context.WithValue(ctx, requesterKey{}, "bob")
+
+	for i := 0; i < 3; i++ {
+		checkAdmit(t, a, alice, putReq("key", 1), "")
+	}
+	checkAdmit(t, a, alice, putReq("key", 1), "rate")
+	// bob has own bucket.
+	checkAdmit(t, a, bob, putReq("key", 1), "")
+
+	now = now.Add(500 * time.Millisecond)
+	checkAdmit(t, a, alice, putReq("key", 1), "")
+	checkAdmit(t, a, alice, putReq("key", 1), "rate")
+
+	// refilled up to burst.
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		checkAdmit(t, a, alice, putReq("key", 1), "")
+	}
+	checkAdmit(t, a, alice, putReq("key", 1), "rate")
+}
+
+func TestAdmissionControllers(t *testing.T) {
+	ctx := context.Background()
+	hits := NewSecondHitAdmission(2, 0)
+	a := AdmissionControllers{
+		SizeAdmission{MinBytes: 10},
+		hits,
+	}
+	checkAdmit(t, a, ctx, putReq("key", 1), "size")
+	// rejected by size, so not counted by second-hit.
+	checkAdmit(t, a, ctx, putReq("key", 10), "second-hit")
+	checkAdmit(t, a, ctx, putReq("key", 10), "")
+}
+
+func TestRequester(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 12345},
+	})
+	if got, want := requester(ctx), "10.0.0.1"; got != want {
+		t.Errorf("requester(peer)=%q; want %q", got, want)
+	}
+	ctx = enduser.NewContext(ctx, enduser.New("user@example.com", "group", &oauth2.Token{}))
+	if got, want := requester(ctx), "user@example.com"; got != want {
+		t.Errorf("requester(enduser)=%q; want %q", got, want)
+	}
+}
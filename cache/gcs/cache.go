@@ -13,14 +13,16 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
-	"io"
 	"math/rand"
+	"sync"
 	"sync/atomic"
 	"time"
 
-	"cloud.google.com/go/storage"
+	"github.com/golang/protobuf/ptypes"
 	"google.golang.org/api/googleapi"
 
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/cache/codec"
 	"go.chromium.org/goma/server/log"
 	pb "go.chromium.org/goma/server/proto/cache"
 )
@@ -34,16 +36,20 @@ type nullAdmissionController struct{}
 
 func (nullAdmissionController) AdmitPut(context.Context, *pb.PutReq) error { return nil }
 
-// Cache represents key-value cache using google cloud storage.
+// Cache represents key-value cache using blob store bucket,
+// e.g. google cloud storage.
 type Cache struct {
-	bkt                 *storage.BucketHandle
+	bkt                 blobstore.Bucket
 	AdmissionController AdmissionController
+	// Codec encodes values stored in the bucket.
+	// nil stores values verbatim.
+	Codec *codec.Codec
 	// should be accessed via stomic pkg.
 	nhit, nget int64
 }
 
 // New creates new cache.
-func New(bkt *storage.BucketHandle) *Cache {
+func New(bkt blobstore.Bucket) *Cache {
 	return &Cache{
 		bkt:                 bkt,
 		AdmissionController: nullAdmissionController{},
@@ -63,12 +69,15 @@ func md5sumStr(b []byte) string {
 }
 
 // checkAttrs checks attr matches with value.
-// use hashes for integrity check.
+// use hashes for integrity check, if the bucket provides them.
 // https://cloud.google.com/storage/docs/hashes-etags
-func checkAttrs(attr *storage.ObjectAttrs, value []byte) error {
+func checkAttrs(attr *blobstore.Attrs, value []byte) error {
 	if attr.Size != int64(len(value)) {
 		return fmt.Errorf("storage: size: attr:%d != value:%d", attr.Size, len(value))
 	}
+	if attr.MD5 == nil {
+		return nil
+	}
 	crc32cSum := crc32.Checksum(value, crc32cTable)
 	if attr.CRC32C != crc32cSum {
 		return fmt.Errorf("storage: crc32: attr:%s != value:%s", crc32cStr(attr.CRC32C), crc32cStr(crc32cSum))
@@ -80,58 +89,90 @@ func checkAttrs(attr *storage.ObjectAttrs, value []byte) error {
 	return nil
 }
 
-func (c *Cache) put(ctx context.Context, obj *storage.ObjectHandle, key string, value []byte, t time.Time) (*pb.PutResp, error) {
+// expired reports whether object of attr is expired at now.
+// Bucket (e.g. lifecycle rule on cloud storage) may delete
+// expired objects.
+func expired(attr *blobstore.Attrs, now time.Time) bool {
+	return !attr.Expire.IsZero() && !now.Before(attr.Expire)
+}
+
+func (c *Cache) put(ctx context.Context, key string, value []byte, expire, t time.Time) (*pb.PutResp, error) {
 	logger := log.FromContext(ctx)
-	attr, err := obj.Attrs(ctx)
+	attr, err := c.bkt.Attrs(ctx, key)
 	if err == nil {
 		err = checkAttrs(attr, value)
-		if err == nil {
-			logger.Infof("gcs.put   %s %d %s: no change gen:%d %d", key, len(value), time.Since(t), attr.Generation, attr.Metageneration)
-			return &pb.PutResp{}, nil
+		if err == nil && attr.MD5 == nil {
+			// written by others. write it to verify later.
+			err = errors.New("storage: no checksums")
 		}
-		if ctx.Err() != nil {
-			logger.Infof("gcs.put  %s %d %s: %v", key, len(value), time.Since(t), err)
-			return nil, err
+		if err == nil {
+			switch {
+			case !attr.Expire.IsZero() && expire.IsZero():
+				// expiration can't be removed by SetExpire,
+				// so write it again.
+				logger.Infof("gcs.put   %s %d %s: remove expiration", key, len(value), time.Since(t))
+			case expire.After(attr.Expire):
+				if s, ok := c.bkt.(blobstore.ExpireSetter); ok {
+					_, err = s.SetExpire(ctx, key, expire)
+					if err == nil {
+						logger.Infof("gcs.put   %s %d %s: extend expiration %s -> %s", key, len(value), time.Since(t), attr.Expire, expire)
+						return &pb.PutResp{}, nil
+					}
+					logger.Warnf("gcs.put   %s %d %s: failed to extend expiration: %v", key, len(value), time.Since(t), err)
+				}
+				// write it again to extend expiration.
+				logger.Infof("gcs.put   %s %d %s: extend expiration by write %s -> %s", key, len(value), time.Since(t), attr.Expire, expire)
+			default:
+				logger.Infof("gcs.put   %s %d %s: no change", key, len(value), time.Since(t))
+				return &pb.PutResp{}, nil
+			}
+		} else {
+			if ctx.Err() != nil {
+				logger.Infof("gcs.put  %s %d %s: %v", key, len(value), time.Since(t), err)
+				return nil, err
+			}
+			// attr mismatch. need overwrite.
+			logger.Errorf("gcs.put   %s %d %s: %v", key, len(value), time.Since(t), err)
 		}
-		// attr mismatch. need overwrite.
-		logger.Errorf("gcs.put   %s %d %s: %v", key, len(value), time.Since(t), err)
 		t = time.Now()
 	}
-	w := obj.NewWriter(ctx)
-	w.CRC32C = crc32.Checksum(value, crc32cTable)
-	w.SendCRC32C = true
-	w.ChunkSize = len(value)
-	if w.ChunkSize > googleapi.DefaultUploadChunkSize {
-		w.ChunkSize = googleapi.DefaultUploadChunkSize
-	}
-
-	if _, err := w.Write(value); err != nil {
-		w.CloseWithError(err)
-		logger.Errorf("gcs.put   %s %d %s: write:%v", key, len(value), time.Since(t), err)
-		return nil, err
-	}
-	if err := w.Close(); err != nil {
-		logger.Errorf("gcs.put   %s %d %s: close:%v", key, len(value), time.Since(t), err)
+	attr, err = c.bkt.Put(ctx, key, value, blobstore.PutOpts{
+		Expire: expire,
+	})
+	if err != nil {
+		logger.Errorf("gcs.put   %s %d %s: %v", key, len(value), time.Since(t), err)
 		return nil, err
 	}
-	attr = w.Attrs()
-	logger.Infof("gcs.put   %s %d %s crc32c:%s md5:%s gen:%d %d", key, len(value), time.Since(t), crc32cStr(attr.CRC32C), md5sumStr(attr.MD5), attr.Generation, attr.Metageneration)
+	logger.Infof("gcs.put   %s %d %s crc32c:%s md5:%s", key, len(value), time.Since(t), crc32cStr(attr.CRC32C), md5sumStr(attr.MD5))
 	return &pb.PutResp{}, nil
 }
 
 func (c *Cache) Put(ctx context.Context, in *pb.PutReq) (*pb.PutResp, error) {
 	logger := log.FromContext(ctx)
-	if err := c.AdmissionController.AdmitPut(ctx, in); err != nil {
-		logger.Warnf("admission error: %v", err)
+	err := c.AdmissionController.AdmitPut(ctx, in)
+	recordAdmission(ctx, err)
+	if err != nil {
+		// rejections are counted by admission view.
+		logger.Debugf("admission error: %v", err)
 		return nil, err
 	}
 	key := in.Kv.Key
 	value := in.Kv.Value
+	if c.Codec != nil {
+		value = c.Codec.Encode(ctx, value)
+	}
 	t := time.Now()
+	var expire time.Time
+	if in.Ttl != nil {
+		ttl, err := ptypes.Duration(in.Ttl)
+		if err != nil {
+			return nil, fmt.Errorf("key:%s bad ttl: %v", key, err)
+		}
+		expire = t.Add(ttl)
+	}
 
-	obj := c.bkt.Object(key)
 	for retry := 0; ; retry++ {
-		resp, err := c.put(ctx, obj, key, value, t)
+		resp, err := c.put(ctx, key, value, expire, t)
 		if err == nil {
 			return resp, err
 		}
@@ -175,9 +216,8 @@ func (c *Cache) Get(ctx context.Context, in *pb.GetReq) (*pb.GetResp, error) {
 	t := time.Now()
 
 	atomic.AddInt64(&c.nget, 1)
-	obj := c.bkt.Object(key)
-	attr, err := obj.Attrs(ctx)
-	if err == storage.ErrObjectNotExist {
+	attr, err := c.bkt.Attrs(ctx, key)
+	if err == blobstore.ErrNotExist {
 		logger.Infof("gcs.miss  %s %s: %v", key, time.Since(t), err)
 		return nil, err
 	}
@@ -185,16 +225,12 @@ func (c *Cache) Get(ctx context.Context, in *pb.GetReq) (*pb.GetResp, error) {
 		logger.Errorf("gcs.attrs %s %s: %v", key, time.Since(t), err)
 		return nil, err
 	}
-
-	r, err := obj.NewReader(ctx)
-	if err != nil {
-		logger.Errorf("gcs.miss  %s %s: %v", key, time.Since(t), err)
-		return nil, err
+	if expired(attr, t) {
+		logger.Infof("gcs.miss  %s %s: expired at %s", key, time.Since(t), attr.Expire)
+		return nil, blobstore.ErrNotExist
 	}
-	defer r.Close()
 
-	b := make([]byte, attr.Size)
-	_, err = io.ReadFull(r, b)
+	b, err := c.bkt.Get(ctx, key)
 	if err != nil {
 		logger.Errorf("gcs.miss  %s %s: %v", key, time.Since(t), err)
 		return nil, err
@@ -204,6 +240,13 @@ func (c *Cache) Get(ctx context.Context, in *pb.GetReq) (*pb.GetResp, error) {
 		logger.Errorf("gcs.bad   %s %d %s: %v", key, len(b), time.Since(t), err)
 		return nil, fmt.Errorf("key:%s %v", key, err)
 	}
+	if c.Codec != nil {
+		b, err = codec.Decode(b)
+		if err != nil {
+			logger.Errorf("gcs.bad   %s %d %s: %v", key, attr.Size, time.Since(t), err)
+			return nil, fmt.Errorf("key:%s %v", key, err)
+		}
+	}
 	atomic.AddInt64(&c.nhit, 1)
 	logger.Infof("gcs.hit   %s %d %s", key, len(b), time.Since(t))
 	return &pb.GetResp{
@@ -214,6 +257,89 @@ func (c *Cache) Get(ctx context.Context, in *pb.GetReq) (*pb.GetResp, error) {
 	}, nil
 }
 
+// batchConcurrency is max number of concurrent requests to the bucket
+// in a batch request.
+const batchConcurrency = 16
+
+// BatchGet gets key-values for requested keys concurrently.
+// Response for key not found has no kv.
+// It returns the first error other than not found, if any.
+func (c *Cache) BatchGet(ctx context.Context, in *pb.BatchGetReq) (*pb.BatchGetResp, error) {
+	resp := &pb.BatchGetResp{
+		Resps: make([]*pb.GetResp, len(in.Keys)),
+	}
+	sema := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	errs := make([]error, len(in.Keys))
+	for i, key := range in.Keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			sema <- struct{}{}
+			defer func() { <-sema }()
+			r, err := c.Get(ctx, &pb.GetReq{
+				Key:  key,
+				Fast: in.Fast,
+			})
+			if err != nil {
+				if err != blobstore.ErrNotExist {
+					errs[i] = err
+				}
+				r = &pb.GetResp{}
+			}
+			resp.Resps[i] = r
+		}(i, key)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// BatchPut puts key-values concurrently.
+// It returns the first error, if any.
+func (c *Cache) BatchPut(ctx context.Context, in *pb.BatchPutReq) (*pb.BatchPutResp, error) {
+	sema := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	errs := make([]error, len(in.Reqs))
+	for i, req := range in.Reqs {
+		wg.Add(1)
+		go func(i int, req *pb.PutReq) {
+			defer wg.Done()
+			sema <- struct{}{}
+			defer func() { <-sema }()
+			_, errs[i] = c.Put(ctx, req)
+		}(i, req)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &pb.BatchPutResp{}, nil
+}
+
+// Delete deletes key-value for requested key.
+// It is not an error if key doesn't exist.
+func (c *Cache) Delete(ctx context.Context, in *pb.DeleteReq) (*pb.DeleteResp, error) {
+	logger := log.FromContext(ctx)
+	t := time.Now()
+	err := c.bkt.Delete(ctx, in.Key)
+	if err != nil && err != blobstore.ErrNotExist {
+		logger.Errorf("gcs.del   %s %s: %v", in.Key, time.Since(t), err)
+		return nil, err
+	}
+	logger.Infof("gcs.del   %s %s", in.Key, time.Since(t))
+	return &pb.DeleteResp{}, nil
+}
+
 // Stats represents stats of gcs.Cache.
 // TODO: use opencensus stats, view.
 type Stats struct {
@@ -5,9 +5,22 @@
 package gcs
 
 import (
+	"context"
 	"crypto/md5"
+	"errors"
 	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/log"
+	pb "go.chromium.org/goma/server/proto/cache"
 )
 
 // hash value was retrieved from
@@ -53,3 +66,142 @@ int main(int argc, char *argv[]) {
 		}
 	}
 }
+
+func TestCache(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	dir, err := ioutil.TempDir("", "gcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := blobstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(bkt)
+
+	_, err = c.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != blobstore.ErrNotExist {
+		t.Errorf("Get(key)=_, %v; want ErrNotExist", err)
+	}
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("value"),
+		},
+		Ttl: ptypes.DurationProto(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=_, %v; want nil error", err)
+	}
+	_, err = c.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != blobstore.ErrNotExist {
+		t.Errorf("Get(key) expired=_, %v; want ErrNotExist", err)
+	}
+
+	// extends expiration without rewriting the object.
+	oattrs, err := bkt.Attrs(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("value"),
+		},
+		Ttl: ptypes.DurationProto(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=_, %v; want nil error", err)
+	}
+	nattrs, err := bkt.Attrs(ctx, "key")
+	if err != nil || !nattrs.Updated.Equal(oattrs.Updated) || !nattrs.Expire.After(oattrs.Expire) {
+		t.Errorf("Attrs(key)=%#v, %v; want updated:%s, expire after %s", nattrs, err, oattrs.Updated, oattrs.Expire)
+	}
+	resp, err := c.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != nil || string(resp.Kv.Value) != "value" {
+		t.Errorf("Get(key)=%v, %v; want value", resp, err)
+	}
+
+	// corrupted in the bucket.
+	err = ioutil.WriteFile(filepath.Join(dir, "key"), []byte("VALUE"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("value"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=_, %v; want nil error", err)
+	}
+	attrs, err := bkt.Attrs(ctx, "key")
+	if err != nil || attrs.MD5 == nil || !attrs.Expire.IsZero() {
+		t.Errorf("Attrs(key)=%#v, %v; want rewritten without expiration", attrs, err)
+	}
+
+	_, err = c.Delete(ctx, &pb.DeleteReq{Key: "key"})
+	if err != nil {
+		t.Errorf("Delete(key)=_, %v; want nil error", err)
+	}
+	_, err = c.Delete(ctx, &pb.DeleteReq{Key: "key"})
+	if err != nil {
+		t.Errorf("Delete(key) again=_, %v; want nil error", err)
+	}
+	if got, want := c.Stats(), (Stats{Hits: 1, Gets: 3}); got != want {
+		t.Errorf("Stats()=%v; want %v", got, want)
+	}
+}
+
+// errBucket is a bucket that fails to access errKey.
+type errBucket struct {
+	blobstore.Bucket
+	errKey string
+}
+
+func (b errBucket) Attrs(ctx context.Context, name string) (*blobstore.Attrs, error) {
+	if name == b.errKey {
+		return nil, errors.New("access denied")
+	}
+	return b.Bucket.Attrs(ctx, name)
+}
+
+func TestBatchGet(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	dir, err := ioutil.TempDir("", "gcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := blobstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(errBucket{Bucket: bkt, errKey: "bad"})
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("value"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=_, %v; want nil error", err)
+	}
+
+	req := &pb.BatchGetReq{Keys: []string{"missing", "key"}}
+	resp, err := c.BatchGet(ctx, req)
+	if err != nil {
+		t.Fatalf("BatchGet(%v)=_, %v; want nil error", req.Keys, err)
+	}
+	if len(resp.Resps) != 2 || resp.Resps[0].Kv != nil || string(resp.Resps[1].GetKv().GetValue()) != "value" {
+		t.Errorf("BatchGet(%v)=%v; want [not found, value]", req.Keys, resp)
+	}
+
+	req = &pb.BatchGetReq{Keys: []string{"missing", "bad", "key"}}
+	resp, err = c.BatchGet(ctx, req)
+	if err == nil {
+		t.Errorf("BatchGet(%v)=%v, nil; want error", req.Keys, resp)
+	}
+}
@@ -3,7 +3,8 @@
 // found in the LICENSE file.
 
 /*
-Package gcs provides cache service by google cloud storage.
+Package gcs provides cache service by blob store bucket,
+such as google cloud storage.
 
 */
 package gcs
@@ -26,3 +26,15 @@ func (c LocalClient) Get(ctx context.Context, in *pb.GetReq, opts ...grpc.CallOp
 func (c LocalClient) Put(ctx context.Context, in *pb.PutReq, opts ...grpc.CallOption) (*pb.PutResp, error) {
 	return c.CacheServiceServer.Put(ctx, in)
 }
+
+func (c LocalClient) BatchGet(ctx context.Context, in *pb.BatchGetReq, opts ...grpc.CallOption) (*pb.BatchGetResp, error) {
+	return c.CacheServiceServer.BatchGet(ctx, in)
+}
+
+func (c LocalClient) BatchPut(ctx context.Context, in *pb.BatchPutReq, opts ...grpc.CallOption) (*pb.BatchPutResp, error) {
+	return c.CacheServiceServer.BatchPut(ctx, in)
+}
+
+func (c LocalClient) Delete(ctx context.Context, in *pb.DeleteReq, opts ...grpc.CallOption) (*pb.DeleteResp, error) {
+	return c.CacheServiceServer.Delete(ctx, in)
+}
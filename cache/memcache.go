@@ -0,0 +1,445 @@
+// Copyright 2017 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// memcache is a LRU cache in memory with synchronization,
+// and counts the size of all keys and values.
+//
+// If WindowRatio is positive, it uses W-TinyLFU admission policy.
+// New key-value pair is put in window LRU, which is WindowRatio
+// of MaxBytes. When it is evicted from window LRU, it is admitted to
+// main LRU only if it is more frequently used than key-value pairs
+// that would be evicted from main LRU to make room for it.
+// It prevents one-time scan of many keys from flushing frequently
+// used keys.
+type memcache struct {
+	MaxBytes    int64
+	WindowRatio float64
+
+	mu     sync.RWMutex
+	nbytes int64 // of all keys and vlaues
+	m      map[string]*list.Element
+	window memSegment
+	main   memSegment
+	sketch *countMinSketch
+
+	nhit, nget int64
+	nevict     int64 // number of evictions
+	nreplace   int64
+	nadmit     int64 // number of admissions from window to main
+	nreject    int64 // number of rejections from window to main
+
+	// onEvicted is called with key-value pair evicted from memcache
+	// and its number of hits in memcache, while holding lock.
+	onEvicted func(key string, value []byte, hits int64)
+}
+
+// memSegment is a LRU list of *memEntry.
+type memSegment struct {
+	ll     *list.List
+	nbytes int64
+}
+
+type memEntry struct {
+	key   string
+	value []byte
+	seg   *memSegment
+	// expire is time when the entry expires. zero means no expiration.
+	expire time.Time
+	// hits is number of hits by Get.
+	hits int64
+}
+
+func (e *memEntry) expired(now time.Time) bool {
+	return !e.expire.IsZero() && !now.Before(e.expire)
+}
+
+func (e *memEntry) size() int64 {
+	return int64(len(e.key)) + int64(len(e.value))
+}
+
+var errNoChange = errors.New("cache: no change")
+
+type replaceError struct {
+	old []byte
+}
+
+func (r replaceError) Error() string {
+	return "cache: value is replaced"
+}
+
+func (c *memcache) init() {
+	if c.m != nil {
+		return
+	}
+	c.m = make(map[string]*list.Element)
+	c.window.ll = list.New()
+	c.main.ll = list.New()
+	if c.WindowRatio > 0 {
+		c.sketch = newCountMinSketch(sketchWidth(c.MaxBytes))
+	}
+}
+
+// Put puts key-value pair in memcache.
+// expire is time when key-value pair expires. zero means no expiration.
+// It returns errNoChange if key-value pair was already stored.
+// It returns replaceError if value is replaced.
+func (c *memcache) Put(ctx context.Context, key string, value []byte, expire time.Time) error {
+	span := trace.FromContext(ctx)
+	span.Annotatef(nil, "put %s (size:%d)", key, len(value))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.add(ctx, key, value, expire)
+	if c.MaxBytes == 0 {
+		return err
+	}
+	if c.sketch != nil {
+		c.evictWindow(ctx)
+		// main LRU may exceed by replacing value with larger one.
+		for c.nbytes > c.MaxBytes {
+			span.Annotatef(nil, "eviction %d exceeding max=%d", c.nbytes, c.MaxBytes)
+			if !c.removeOldest(ctx, &c.main) && !c.removeOldest(ctx, &c.window) {
+				return err
+			}
+		}
+		return err
+	}
+	for {
+		if c.nbytes < c.MaxBytes {
+			return err
+		}
+		span.Annotatef(nil, "eviction %d exceeding max=%d", c.nbytes, c.MaxBytes)
+		if !c.removeOldest(ctx, &c.main) {
+			return err
+		}
+	}
+}
+
+// add adds key-value pair in memcache.
+// It returns errNoChange if key-value pair already exists in memcache.
+// It returns replaceError if key exists but value differs.
+func (c *memcache) add(ctx context.Context, key string, value []byte, expire time.Time) error {
+	logger := log.FromContext(ctx)
+
+	c.init()
+	if c.sketch != nil {
+		c.sketch.Increment(key)
+	}
+	elem, ok := c.m[key]
+	if ok {
+		e := elem.Value.(*memEntry)
+		e.seg.ll.MoveToFront(elem)
+		e.expire = expire
+		if bytes.Equal(e.value, value) {
+			logger.Infof("mem.put2  %s %d", key, len(value))
+			return errNoChange
+		}
+		logger.Errorf("mem.repl  %s %d <= %d", key, len(value), len(e.value))
+		// replace won't call onEvicted.
+		old := e.value
+		c.nbytes -= e.size()
+		e.seg.nbytes -= e.size()
+		e.value = value
+		c.nbytes += e.size()
+		e.seg.nbytes += e.size()
+		c.nreplace++
+		return replaceError{old: old}
+	}
+	logger.Infof("mem.put   %s %d", key, len(value))
+	seg := &c.main
+	if c.sketch != nil {
+		seg = &c.window
+	}
+	c.push(seg, &memEntry{
+		key:    key,
+		value:  value,
+		expire: expire,
+	})
+	return nil
+}
+
+func (c *memcache) push(seg *memSegment, e *memEntry) {
+	e.seg = seg
+	c.m[e.key] = seg.ll.PushFront(e)
+	seg.nbytes += e.size()
+	c.nbytes += e.size()
+}
+
+func (c *memcache) remove(elem *list.Element) *memEntry {
+	e := elem.Value.(*memEntry)
+	e.seg.ll.Remove(elem)
+	e.seg.nbytes -= e.size()
+	c.nbytes -= e.size()
+	delete(c.m, e.key)
+	return e
+}
+
+// removeOldest evicts the least recently used entry in seg.
+// It returns false if seg is empty.
+func (c *memcache) removeOldest(ctx context.Context, seg *memSegment) bool {
+	elem := seg.ll.Back()
+	if elem == nil {
+		return false
+	}
+	e := c.remove(elem)
+	c.nevict++
+	c.evicted(ctx, "mem.evict", e)
+	return true
+}
+
+func (c *memcache) evicted(ctx context.Context, msg string, e *memEntry) {
+	logger := log.FromContext(ctx)
+	logger.Infof("%s %s %d", msg, e.key, len(e.value))
+	// entry with expiration is not passed to onEvicted,
+	// since it is expected to be short-lived.
+	if c.onEvicted != nil && e.expire.IsZero() {
+		c.onEvicted(e.key, e.value, e.hits)
+	}
+}
+
+// evictWindow moves entries exceeding window size to main LRU
+// if admitted.
+func (c *memcache) evictWindow(ctx context.Context) {
+	windowMax := int64(float64(c.MaxBytes) * c.WindowRatio)
+	for c.window.nbytes > windowMax {
+		elem := c.window.ll.Back()
+		if elem == nil {
+			return
+		}
+		c.admit(ctx, c.remove(elem))
+	}
+}
+
+// admit admits candidate entry to main LRU, if it is more frequently
+// used than all victims in main LRU, which need to be evicted for
+// candidate's size.
+func (c *memcache) admit(ctx context.Context, cand *memEntry) {
+	mainMax := c.MaxBytes - int64(float64(c.MaxBytes)*c.WindowRatio)
+	need := c.main.nbytes + cand.size() - mainMax
+	admitted := cand.size() <= mainMax
+	if admitted && need > 0 {
+		freq := c.sketch.Estimate(cand.key)
+		var freed int64
+		for elem := c.main.ll.Back(); elem != nil && freed < need; elem = elem.Prev() {
+			victim := elem.Value.(*memEntry)
+			if c.sketch.Estimate(victim.key) >= freq {
+				admitted = false
+				break
+			}
+			freed += victim.size()
+		}
+	}
+	if !admitted {
+		c.nreject++
+		recordAdmission(ctx, "reject")
+		c.evicted(ctx, "mem.reject", cand)
+		return
+	}
+	for c.main.nbytes+cand.size() > mainMax {
+		c.removeOldest(ctx, &c.main)
+	}
+	c.nadmit++
+	recordAdmission(ctx, "admit")
+	c.push(&c.main, cand)
+}
+
+func (c *memcache) Get(ctx context.Context, key string) (value []byte, ok bool) {
+	span := trace.FromContext(ctx)
+	span.Annotatef(nil, "get %s", key)
+	logger := log.FromContext(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nget++
+	if c.m == nil {
+		logger.Infof("mem.miss  %s", key)
+		return nil, false
+	}
+	if c.sketch != nil {
+		c.sketch.Increment(key)
+	}
+	elem, ok := c.m[key]
+	if !ok {
+		logger.Infof("mem.miss  %s", key)
+		return nil, false
+	}
+	e := elem.Value.(*memEntry)
+	if e.expired(time.Now()) {
+		logger.Infof("mem.expire %s", key)
+		c.remove(elem)
+		return nil, false
+	}
+	e.seg.ll.MoveToFront(elem)
+	e.hits++
+	c.nhit++
+	logger.Infof("mem.hit   %s %d", key, len(e.value))
+	return e.value, true
+}
+
+// Delete deletes key from memcache.
+// It won't call onEvicted.
+func (c *memcache) Delete(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.m[key]
+	if !ok {
+		return
+	}
+	logger := log.FromContext(ctx)
+	logger.Infof("mem.del   %s", key)
+	c.remove(elem)
+}
+
+// snapshotEntry is key-value pair in memcache snapshot.
+type snapshotEntry struct {
+	key    string
+	value  []byte
+	expire time.Time
+}
+
+// snapshot returns key-value pairs in memcache, in recency order,
+// i.e. most recently used first. Entries in window LRU comes first,
+// then entries in main LRU. Expired entries are not included.
+func (c *memcache) snapshot() []snapshotEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.m == nil {
+		return nil
+	}
+	now := time.Now()
+	entries := make([]snapshotEntry, 0, len(c.m))
+	for _, seg := range []*memSegment{&c.window, &c.main} {
+		for elem := seg.ll.Front(); elem != nil; elem = elem.Next() {
+			e := elem.Value.(*memEntry)
+			if e.expired(now) {
+				continue
+			}
+			entries = append(entries, snapshotEntry{
+				key:    e.key,
+				value:  e.value,
+				expire: e.expire,
+			})
+		}
+	}
+	return entries
+}
+
+// restore adds e as the least recently used entry in main LRU.
+// It is used to load entries from snapshot in recency order.
+// It returns errSnapshotFull if memcache has no room for e.
+// It returns errNoChange if e's key already exists or e was expired.
+func (c *memcache) restore(e snapshotEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	if _, ok := c.m[e.key]; ok {
+		return errNoChange
+	}
+	ent := &memEntry{
+		key:    e.key,
+		value:  e.value,
+		expire: e.expire,
+	}
+	if ent.expired(time.Now()) {
+		return errNoChange
+	}
+	mainMax := c.MaxBytes - int64(float64(c.MaxBytes)*c.WindowRatio)
+	if c.MaxBytes > 0 && c.main.nbytes+ent.size() > mainMax {
+		return errSnapshotFull
+	}
+	if c.sketch != nil {
+		// restored entry was used at least once.
+		c.sketch.Increment(ent.key)
+	}
+	ent.seg = &c.main
+	c.m[ent.key] = c.main.ll.PushBack(ent)
+	c.main.nbytes += ent.size()
+	c.nbytes += ent.size()
+	return nil
+}
+
+var (
+	memAdmissions = stats.Int64(
+		"go.chromium.org/goma/server/cache.mem-admission",
+		"memcache admission from window to main LRU",
+		stats.UnitDimensionless)
+
+	demoteDrops = stats.Int64(
+		"go.chromium.org/goma/server/cache.demote-drops",
+		"key-value pairs evicted from memcache but dropped before written in disk cache",
+		stats.UnitDimensionless)
+
+	admissionResultKey = tag.MustNewKey("result")
+
+	// DefaultViews are the default views provided by this package.
+	// You need to register the view for data to actually be collected.
+	DefaultViews = []*view.View{
+		{
+			Description: `counts memcache admission. result is "admit" or "reject"`,
+			TagKeys: []tag.Key{
+				admissionResultKey,
+			},
+			Measure:     memAdmissions,
+			Aggregation: view.Count(),
+		},
+		{
+			Description: "counts key-value pairs dropped from full demote queue",
+			Measure:     demoteDrops,
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+func recordAdmission(ctx context.Context, result string) {
+	stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(admissionResultKey, result)}, memAdmissions.M(1))
+}
+
+// TODO: use opencensus stats, view.
+type memstats struct {
+	MaxBytes    int64
+	WindowBytes int64
+
+	Bytes    int64
+	Num      int
+	Hits     int64
+	Gets     int64
+	Evicts   int64
+	Replaces int64
+	Admits   int64
+	Rejects  int64
+}
+
+func (c *memcache) stats() memstats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return memstats{
+		MaxBytes:    c.MaxBytes,
+		WindowBytes: c.window.nbytes,
+		Bytes:       c.nbytes,
+		Num:         len(c.m),
+		Hits:        c.nhit,
+		Gets:        c.nget,
+		Evicts:      c.nevict,
+		Replaces:    c.nreplace,
+		Admits:      c.nadmit,
+		Rejects:     c.nreject,
+	}
+}
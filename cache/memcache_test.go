@@ -0,0 +1,241 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/log"
+)
+
+var memcacheTrace = flag.String("memcache_trace", "", `trace file to replay in memcache benchmarks. each line is "<key> <size>"`)
+
+// traceAccess is an access to cache in a trace.
+type traceAccess struct {
+	key  string
+	size int
+}
+
+// loadTrace loads trace from fname.
+func loadTrace(fname string) ([]traceAccess, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var trace []traceAccess
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("bad trace line %q", s.Text())
+		}
+		size, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("bad trace line %q: %v", s.Text(), err)
+		}
+		trace = append(trace, traceAccess{key: fields[0], size: size})
+	}
+	return trace, s.Err()
+}
+
+// syntheticTrace generates trace of builds, that repeatedly access
+// hot set of toolchain headers, and sometimes a build of rarely used
+// files scans many keys once.
+func syntheticTrace(seed int64, n int) []traceAccess {
+	r := rand.New(rand.NewSource(seed))
+	const hotKeys = 200
+	zipf := rand.NewZipf(r, 1.2, 1, hotKeys-1)
+	var trace []traceAccess
+	scan := 0
+	for len(trace) < n {
+		if r.Intn(1000) == 0 {
+			for i := 0; i < 1000; i++ {
+				trace = append(trace, traceAccess{
+					key:  fmt.Sprintf("scan%d-%d", scan, i),
+					size: 4096,
+				})
+			}
+			scan++
+			continue
+		}
+		trace = append(trace, traceAccess{
+			key:  fmt.Sprintf("header%d", zipf.Uint64()),
+			size: 4096,
+		})
+	}
+	return trace
+}
+
+// replay replays trace in memcache, and returns hit ratio.
+// It puts value on cache miss.
+func replay(ctx context.Context, c *memcache, trace []traceAccess) float64 {
+	var buf []byte
+	hits := 0
+	for _, a := range trace {
+		if _, ok := c.Get(ctx, a.key); ok {
+			hits++
+			continue
+		}
+		if len(buf) < a.size {
+			buf = make([]byte, a.size)
+		}
+		c.Put(ctx, a.key, buf[:a.size], time.Time{})
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+func quietContext() context.Context {
+	return log.NewContext(context.Background(), zap.NewNop().Sugar())
+}
+
+func TestMemcacheScanResistance(t *testing.T) {
+	ctx := quietContext()
+	trace := syntheticTrace(1, 100000)
+	const maxBytes = 300 * 4096
+
+	lru := &memcache{MaxBytes: maxBytes}
+	lruHit := replay(ctx, lru, trace)
+
+	tinylfu := &memcache{MaxBytes: maxBytes, WindowRatio: 0.01}
+	tinylfuHit := replay(ctx, tinylfu, trace)
+
+	t.Logf("hit ratio: lru=%.3f tinylfu=%.3f", lruHit, tinylfuHit)
+	if tinylfuHit <= lruHit {
+		t.Errorf("hit ratio: tinylfu=%.3f <= lru=%.3f; want tinylfu > lru", tinylfuHit, lruHit)
+	}
+	st := tinylfu.stats()
+	if st.Admits == 0 || st.Rejects == 0 {
+		t.Errorf("admits=%d rejects=%d; want both > 0", st.Admits, st.Rejects)
+	}
+	if st.Bytes > maxBytes {
+		t.Errorf("bytes=%d; want <= %d", st.Bytes, maxBytes)
+	}
+}
+
+func TestMemcacheAdmission(t *testing.T) {
+	ctx := quietContext()
+	var evicted []string
+	c := &memcache{
+		// window can hold two entries of 9 bytes, and main can hold
+		// 8 entries.
+		MaxBytes:    100,
+		WindowRatio: 0.2,
+		onEvicted: func(key string, value []byte, hits int64) {
+			evicted = append(evicted, key)
+		},
+	}
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		c.Put(ctx, key, []byte("value"), time.Time{})
+		c.Get(ctx, key)
+	}
+	// push hot keys out of window.
+	c.Put(ctx, "cold", []byte("value1"), time.Time{})
+	if len(evicted) != 0 {
+		t.Errorf("evicted=%q; want none", evicted)
+	}
+	// cold is less frequently used than hot keys, so rejected
+	// when it is pushed out of window.
+	c.Put(ctx, "cold2", []byte("value2"), time.Time{})
+	if got, want := evicted, []string{"cold"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("evicted=%q; want %q", got, want)
+	}
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		if _, ok := c.Get(ctx, key); !ok {
+			t.Errorf("Get(%s)=_, false; want true", key)
+		}
+	}
+	st := c.stats()
+	if st.Admits != 8 || st.Rejects != 1 {
+		t.Errorf("admits=%d rejects=%d; want 8, 1", st.Admits, st.Rejects)
+	}
+
+	// too large value for main LRU is rejected.
+	evicted = nil
+	c.Put(ctx, "large", make([]byte, 100), time.Time{})
+	if got, want := evicted, []string{"cold2", "large"}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("evicted=%q; want %q", got, want)
+	}
+}
+
+func TestMemcacheAdmissionReplaceLarger(t *testing.T) {
+	ctx := quietContext()
+	var evicted []string
+	c := &memcache{
+		MaxBytes:    100,
+		WindowRatio: 0.2,
+		onEvicted: func(key string, value []byte, hits int64) {
+			evicted = append(evicted, key)
+		},
+	}
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("hot%d", i)
+		c.Put(ctx, key, []byte("value"), time.Time{})
+		c.Get(ctx, key)
+	}
+	c.Put(ctx, "cold", []byte("value1"), time.Time{})
+	c.Put(ctx, "cold2", []byte("value2"), time.Time{})
+	evicted = nil
+	// replacing value in main LRU with larger one exceeds max bytes.
+	err := c.Put(ctx, "hot7", make([]byte, 40), time.Time{})
+	if _, ok := err.(replaceError); !ok {
+		t.Errorf("Put(hot7)=%v; want replaceError", err)
+	}
+	if st := c.stats(); st.Bytes > c.MaxBytes {
+		t.Errorf("bytes=%d; want <= %d", st.Bytes, c.MaxBytes)
+	}
+	if len(evicted) == 0 || evicted[0] != "hot0" {
+		t.Errorf("evicted=%q; want hot0 first", evicted)
+	}
+}
+
+func BenchmarkMemcacheReplay(b *testing.B) {
+	trace := syntheticTrace(1, 100000)
+	if *memcacheTrace != "" {
+		var err error
+		trace, err = loadTrace(*memcacheTrace)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	var total int64
+	for _, a := range trace {
+		total += int64(a.size)
+	}
+	ctx := quietContext()
+	for _, tc := range []struct {
+		name        string
+		windowRatio float64
+	}{
+		{name: "lru", windowRatio: 0},
+		{name: "tinylfu-1%", windowRatio: 0.01},
+		{name: "tinylfu-10%", windowRatio: 0.1},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			var hit float64
+			for i := 0; i < b.N; i++ {
+				c := &memcache{
+					MaxBytes:    total / 100,
+					WindowRatio: tc.windowRatio,
+				}
+				hit = replay(ctx, c, trace)
+			}
+			b.ReportMetric(hit*100, "hit%")
+		})
+	}
+}
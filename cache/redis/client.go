@@ -8,16 +8,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/golang/protobuf/ptypes"
 	"github.com/gomodule/redigo/redis"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"go.chromium.org/goma/server/cache/codec"
 	"go.chromium.org/goma/server/log"
 	pb "go.chromium.org/goma/server/proto/cache"
 	"go.chromium.org/goma/server/rpc"
@@ -26,13 +31,21 @@ import (
 // Client is cache service client for redis.
 type Client struct {
 	prefix string
-	pool   *redis.Pool
+	addrs  []string
+	pools  *poolSet
 
-	// to workaround pool.wait. maintain active conns.
-	sema chan struct{}
+	// cluster is non-nil in Cluster mode.
+	cluster *clusterSlots
+	// sentinel is non-nil in Sentinel mode.
+	sentinel *sentinel
+
+	// codec is nil if values are stored verbatim.
+	codec *codec.Codec
 }
 
 // AddrFromEnv returns redis server address from environment variables.
+// REDISHOST may be comma separated hosts for Cluster or Sentinel mode,
+// and it returns comma separated addresses.
 func AddrFromEnv() (string, error) {
 	host := os.Getenv("REDISHOST")
 	port := os.Getenv("REDISPORT")
@@ -42,7 +55,56 @@ func AddrFromEnv() (string, error) {
 	if port == "" {
 		port = "6379" // redis default port
 	}
-	return fmt.Sprintf("%s:%s", host, port), nil
+	var addrs []string
+	for _, h := range strings.Split(host, ",") {
+		addrs = append(addrs, fmt.Sprintf("%s:%s", h, port))
+	}
+	return strings.Join(addrs, ","), nil
+}
+
+// Mode is redis deployment mode.
+type Mode int
+
+const (
+	// Standalone uses single redis server.
+	Standalone Mode = iota
+	// Cluster uses redis cluster. addresses are seed nodes of the cluster.
+	Cluster
+	// Sentinel uses primary discovered by redis sentinels.
+	// addresses are sentinels.
+	Sentinel
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Standalone:
+		return "standalone"
+	case Cluster:
+		return "cluster"
+	case Sentinel:
+		return "sentinel"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ModeFromEnv returns redis mode from REDISMODE environment variable,
+// and primary name from REDIS_SENTINEL_MASTER for Sentinel mode.
+// REDISMODE is "standalone" (default), "cluster" or "sentinel".
+func ModeFromEnv() (Mode, string, error) {
+	switch mode := os.Getenv("REDISMODE"); mode {
+	case "", "standalone":
+		return Standalone, "", nil
+	case "cluster":
+		return Cluster, "", nil
+	case "sentinel":
+		name := os.Getenv("REDIS_SENTINEL_MASTER")
+		if name == "" {
+			return Sentinel, "", errors.New("no REDIS_SENTINEL_MASTER environment for sentinel mode")
+		}
+		return Sentinel, name, nil
+	default:
+		return Standalone, "", fmt.Errorf("unknown REDISMODE=%q", mode)
+	}
 }
 
 // Opts is redis client option.
@@ -50,11 +112,22 @@ type Opts struct {
 	// Prefix is key prefix used by the client.
 	Prefix string
 
-	// MaxIdleConns is max number of idle connections.
+	// MaxIdleConns is max number of idle connections per server.
 	MaxIdleConns int
 
-	// MaxActiveConns is max number of active connections.
+	// MaxActiveConns is max number of active connections per server.
 	MaxActiveConns int
+
+	// Mode is redis deployment mode.
+	Mode Mode
+
+	// MasterName is primary name monitored by sentinels.
+	// Used in Sentinel mode.
+	MasterName string
+
+	// Codec encodes values stored in redis.
+	// nil stores values verbatim.
+	Codec *codec.Codec
 }
 
 // default max number of connections.
@@ -65,25 +138,36 @@ const (
 )
 
 // NewClient creates new cache client for redis.
+// addr is comma separated addresses of redis servers.
+// In Standalone mode, only first address is used.
 func NewClient(ctx context.Context, addr string, opts Opts) Client {
-	return Client{
+	c := Client{
 		prefix: opts.Prefix,
-		pool: &redis.Pool{
-			DialContext: func(ctx context.Context) (redis.Conn, error) {
-				return redis.DialContext(ctx, "tcp", addr)
-			},
-			MaxIdle:   opts.MaxIdleConns,
-			MaxActive: opts.MaxActiveConns,
-			// https://github.com/gomodule/redigo/issues/520
-			Wait: false,
+		addrs:  strings.Split(addr, ","),
+		pools: &poolSet{
+			maxIdle:   opts.MaxIdleConns,
+			maxActive: opts.MaxActiveConns,
+			pools:     make(map[string]*pool),
 		},
-		sema: make(chan struct{}, opts.MaxActiveConns),
+		codec: opts.Codec,
+	}
+	switch opts.Mode {
+	case Cluster:
+		c.cluster = &clusterSlots{
+			seeds: c.addrs,
+		}
+	case Sentinel:
+		c.sentinel = &sentinel{
+			addrs: c.addrs,
+			name:  opts.MasterName,
+		}
 	}
+	return c
 }
 
 // Close releases the resources used by the client.
 func (c Client) Close() error {
-	return c.pool.Close()
+	return c.pools.close()
 }
 
 type temporary interface {
@@ -130,61 +214,195 @@ func retryErr(err error) error {
 	return err
 }
 
-type activeConn struct {
-	redis.Conn
-	c Client
+// isFailoverErr reports whether err indicates the server is no longer
+// available, or no longer serves as primary.
+func isFailoverErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rerr redis.Error
+	if errors.As(err, &rerr) {
+		for _, p := range []string{"READONLY ", "MASTERDOWN ", "CLUSTERDOWN ", "LOADING "} {
+			if strings.HasPrefix(string(rerr), p) {
+				return true
+			}
+		}
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var operr *net.OpError
+	return errors.As(err, &operr)
+}
+
+// addrFor returns address of redis server for the key.
+func (c Client) addrFor(ctx context.Context, key string) (string, error) {
+	switch {
+	case c.cluster != nil:
+		return c.cluster.addr(ctx, c.pools, key)
+	case c.sentinel != nil:
+		return c.sentinel.primary(ctx)
+	}
+	return c.addrs[0], nil
+}
+
+// failover handles err from redis server at addr.
+// It returns true if err is caused by failover or resharding, and
+// the request should be retried with new address.
+func (c Client) failover(ctx context.Context, addr string, err error) bool {
+	if c.cluster == nil && c.sentinel == nil {
+		return false
+	}
+	if !isFailoverErr(err) {
+		return false
+	}
+	logger := log.FromContext(ctx)
+	logger.Warnf("redis %s failed: %v", addr, err)
+	switch {
+	case c.cluster != nil:
+		c.cluster.invalidate()
+	case c.sentinel != nil:
+		c.sentinel.invalidate(addr)
+	}
+	return true
+}
+
+// maxRedirects is max number of MOVED or ASK redirections for a request.
+const maxRedirects = 5
+
+// do runs f with connection to redis server for key, with retry.
+// In Cluster mode, it follows MOVED or ASK redirection.
+func (c Client) do(ctx context.Context, key string, f func(redis.Conn) error) error {
+	return rpc.Retry{
+		MaxRetry: -1,
+	}.Do(ctx, func() error {
+		addr, err := c.addrFor(ctx, key)
+		if err != nil {
+			return retryErr(err)
+		}
+		asking := false
+		for i := 0; ; i++ {
+			err = c.doAt(ctx, addr, asking, f)
+			r, ok := parseRedirect(err)
+			if !ok || c.cluster == nil || i >= maxRedirects {
+				break
+			}
+			if !r.ask {
+				c.cluster.moved(r.slot, r.addr)
+			}
+			addr, asking = r.addr, r.ask
+		}
+		if c.failover(ctx, addr, err) {
+			return rpc.RetriableError{
+				Err: err,
+			}
+		}
+		return retryErr(err)
+	})
 }
 
-func (c activeConn) Close() error {
-	<-c.c.sema
-	return c.Conn.Close()
+// doAt runs f with connection to redis server at addr.
+// If asking is true, it sends ASKING before f.
+func (c Client) doAt(ctx context.Context, addr string, asking bool, f func(redis.Conn) error) error {
+	conn, err := c.pools.get(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if asking {
+		_, err = conn.Do("ASKING")
+		if err != nil {
+			return err
+		}
+	}
+	return f(conn)
+}
+
+// command is redis command for key.
+type command struct {
+	key  string
+	name string
+	args redis.Args
 }
 
-func (c Client) poolGetContext(ctx context.Context) (redis.Conn, error) {
-	t := time.Now()
-	select {
-	case c.sema <- struct{}{}:
-		d := time.Since(t)
-		if d > 100*time.Millisecond {
-			logger := log.FromContext(ctx)
-			logger.Warnf("redis pool wait %s actives=%d", d, len(c.sema))
+// pipeline runs cmds by pipelining to each redis server, and returns
+// replies of cmds.
+// Commands failed in pipeline (e.g. by redirection) are retried one by
+// one.
+func (c Client) pipeline(ctx context.Context, cmds []command) ([]interface{}, error) {
+	replies := make([]interface{}, len(cmds))
+	errs := make([]error, len(cmds))
+	groups := make(map[string][]int)
+	for i, cmd := range cmds {
+		addr, err := c.addrFor(ctx, cmd.key)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		groups[addr] = append(groups[addr], i)
+	}
+	var wg sync.WaitGroup
+	for addr, idx := range groups {
+		wg.Add(1)
+		go func(addr string, idx []int) {
+			defer wg.Done()
+			err := c.doAt(ctx, addr, false, func(conn redis.Conn) error {
+				for _, i := range idx {
+					err := conn.Send(cmds[i].name, cmds[i].args...)
+					if err != nil {
+						return err
+					}
+				}
+				err := conn.Flush()
+				if err != nil {
+					return err
+				}
+				for _, i := range idx {
+					replies[i], errs[i] = conn.Receive()
+				}
+				return nil
+			})
+			if err != nil {
+				for _, i := range idx {
+					errs[i] = err
+				}
+			}
+		}(addr, idx)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err == nil {
+			continue
 		}
-		conn, err := c.pool.GetContext(ctx)
+		cmd := cmds[i]
+		err = c.do(ctx, cmd.key, func(conn redis.Conn) error {
+			var err error
+			replies[i], err = conn.Do(cmd.name, cmd.args...)
+			return err
+		})
 		if err != nil {
-			<-c.sema
 			return nil, err
 		}
-		return activeConn{
-			Conn: conn,
-			c:    c,
-		}, nil
-	case <-ctx.Done():
-		d := time.Since(t)
-		if d > 100*time.Millisecond {
-			logger := log.FromContext(ctx)
-			logger.Warnf("redis pool timed-out wait %s actives=%d", d, len(c.sema))
-		}
-		return nil, ctx.Err()
 	}
+	return replies, nil
 }
 
 // Get fetches value for the key from redis.
 func (c Client) Get(ctx context.Context, in *pb.GetReq, opts ...grpc.CallOption) (*pb.GetResp, error) {
-	conn, err := c.poolGetContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
 	var v []byte
-	err = rpc.Retry{
-		MaxRetry: -1,
-	}.Do(ctx, func() error {
+	err := c.do(ctx, c.prefix+in.Key, func(conn redis.Conn) error {
+		var err error
 		v, err = redis.Bytes(conn.Do("GET", c.prefix+in.Key))
-		return retryErr(err)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
+	v, err = c.decode(v)
+	if err != nil {
+		return nil, status.Errorf(codes.DataLoss, "bad value for %s: %v", in.Key, err)
+	}
 	return &pb.GetResp{
 		Kv: &pb.KV{
 			Key:   in.Key,
@@ -194,21 +412,164 @@ func (c Client) Get(ctx context.Context, in *pb.GetReq, opts ...grpc.CallOption)
 	}, nil
 }
 
+// decode decodes value v got from redis.
+func (c Client) decode(v []byte) ([]byte, error) {
+	if c.codec == nil {
+		return v, nil
+	}
+	return codec.Decode(v)
+}
+
+// setArgs returns args of SET command for in.
+func (c Client) setArgs(ctx context.Context, in *pb.PutReq) (redis.Args, error) {
+	value := in.Kv.Value
+	if c.codec != nil {
+		value = c.codec.Encode(ctx, value)
+	}
+	args := redis.Args{c.prefix + in.Kv.Key, value}
+	if in.Ttl != nil {
+		ttl, err := ptypes.Duration(in.Ttl)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "bad ttl for %s: %v", in.Kv.Key, err)
+		}
+		ms := int64(ttl / time.Millisecond)
+		if ms <= 0 {
+			ms = 1
+		}
+		args = args.Add("PX", ms)
+	}
+	return args, nil
+}
+
 // Put stores key:value pair on redis.
+// If ttl is set, key expires after ttl.
 func (c Client) Put(ctx context.Context, in *pb.PutReq, opts ...grpc.CallOption) (*pb.PutResp, error) {
-	conn, err := c.poolGetContext(ctx)
+	args, err := c.setArgs(ctx, in)
 	if err != nil {
 		return nil, err
 	}
-	defer conn.Close()
-	err = rpc.Retry{
-		MaxRetry: -1,
-	}.Do(ctx, func() error {
-		_, err := conn.Do("SET", c.prefix+in.Kv.Key, in.Kv.Value)
-		return retryErr(err)
+	err = c.do(ctx, c.prefix+in.Kv.Key, func(conn redis.Conn) error {
+		_, err := conn.Do("SET", args...)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 	return &pb.PutResp{}, nil
 }
+
+// BatchGet fetches values for the keys from redis by MGET.
+// In Cluster mode, MGET is sent for each hash slot.
+// Response for key not found has no kv.
+func (c Client) BatchGet(ctx context.Context, in *pb.BatchGetReq, opts ...grpc.CallOption) (*pb.BatchGetResp, error) {
+	resp := &pb.BatchGetResp{
+		Resps: make([]*pb.GetResp, len(in.Keys)),
+	}
+	if len(in.Keys) == 0 {
+		return resp, nil
+	}
+	// keys in MGET must be in the same hash slot in Cluster mode.
+	var groups [][]int
+	if c.cluster == nil {
+		idx := make([]int, len(in.Keys))
+		for i := range in.Keys {
+			idx[i] = i
+		}
+		groups = append(groups, idx)
+	} else {
+		m := make(map[int]int)
+		for i, key := range in.Keys {
+			slot := keySlot(c.prefix + key)
+			j, ok := m[slot]
+			if !ok {
+				j = len(groups)
+				m[slot] = j
+				groups = append(groups, nil)
+			}
+			groups[j] = append(groups[j], i)
+		}
+	}
+	cmds := make([]command, 0, len(groups))
+	for _, idx := range groups {
+		args := make(redis.Args, 0, len(idx))
+		for _, i := range idx {
+			args = append(args, c.prefix+in.Keys[i])
+		}
+		cmds = append(cmds, command{
+			key:  c.prefix + in.Keys[idx[0]],
+			name: "MGET",
+			args: args,
+		})
+	}
+	replies, err := c.pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	for j, idx := range groups {
+		vs, err := redis.ByteSlices(replies[j], nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range idx {
+			resp.Resps[i] = &pb.GetResp{}
+			if k >= len(vs) || vs[k] == nil {
+				continue
+			}
+			v, err := c.decode(vs[k])
+			if err != nil {
+				logger := log.FromContext(ctx)
+				logger.Errorf("bad value for %s: %v", in.Keys[i], err)
+				continue
+			}
+			resp.Resps[i] = &pb.GetResp{
+				Kv: &pb.KV{
+					Key:   in.Keys[i],
+					Value: v,
+				},
+				InMemory: true,
+			}
+		}
+	}
+	return resp, nil
+}
+
+// BatchPut stores key:value pairs on redis by pipelining SET.
+// In Cluster mode, SETs are pipelined to each redis server.
+func (c Client) BatchPut(ctx context.Context, in *pb.BatchPutReq, opts ...grpc.CallOption) (*pb.BatchPutResp, error) {
+	var cmds []command
+	for _, req := range in.Reqs {
+		if req.Kv == nil {
+			continue
+		}
+		args, err := c.setArgs(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, command{
+			key:  c.prefix + req.Kv.Key,
+			name: "SET",
+			args: args,
+		})
+	}
+	if len(cmds) == 0 {
+		return &pb.BatchPutResp{}, nil
+	}
+	_, err := c.pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.BatchPutResp{}, nil
+}
+
+// Delete deletes the key from redis.
+// It is not an error if key doesn't exist.
+func (c Client) Delete(ctx context.Context, in *pb.DeleteReq, opts ...grpc.CallOption) (*pb.DeleteResp, error) {
+	err := c.do(ctx, c.prefix+in.Key, func(conn redis.Conn) error {
+		_, err := conn.Do("DEL", c.prefix+in.Key)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteResp{}, nil
+}
@@ -3,14 +3,22 @@
 package redis
 
 import (
+	"bytes"
 	"context"
 	"flag"
+	"fmt"
+	"net"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"go.chromium.org/goma/server/cache/codec"
 	"go.chromium.org/goma/server/log"
 	pb "go.chromium.org/goma/server/proto/cache"
 )
@@ -29,6 +37,15 @@ func BenchmarkGet(b *testing.B) {
 		MaxActiveConns: DefaultMaxActiveConns,
 	})
 	defer c.Close()
+	_, err := c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{
+			Key:   "key",
+			Value: []byte("0123456789"),
+		},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
 
 	b.Logf("b.N=%d", b.N)
 	var wg sync.WaitGroup
@@ -67,3 +84,301 @@ func BenchmarkGet(b *testing.B) {
 	b.Logf("nerrs=%d", nerrs)
 	mu.Unlock()
 }
+
+func TestKeySlot(t *testing.T) {
+	if got, want := crc16([]byte("123456789")), uint16(0x31c3); got != want {
+		t.Errorf("crc16(123456789)=%#x; want %#x", got, want)
+	}
+	for _, tc := range []struct {
+		key  string
+		want int
+	}{
+		// redis-cli CLUSTER KEYSLOT <key>
+		{key: "foo", want: 12182},
+		{key: "bar", want: 5061},
+		{key: "{foo}.bar", want: 12182},
+		{key: "prefix:{foo}", want: 12182},
+		{key: "{}foo", want: keySlot("{}foo")},
+	} {
+		if got := keySlot(tc.key); got != tc.want {
+			t.Errorf("keySlot(%q)=%d; want %d", tc.key, got, tc.want)
+		}
+	}
+	if keySlot("{}foo") == keySlot("foo") {
+		t.Errorf("keySlot({}foo) should use whole key")
+	}
+}
+
+// testClient tests basic operations of c, which uses "test:" prefix.
+// lookup returns value stored in redis for the key.
+func testClient(ctx context.Context, t *testing.T, c Client, lookup func(key string) ([]byte, bool)) {
+	t.Helper()
+	_, err := c.Get(ctx, &pb.GetReq{Key: "key"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Get(key)=%v; want NotFound", err)
+	}
+
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{Key: "key", Value: []byte("value")},
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=%v; want nil error", err)
+	}
+	resp, err := c.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != nil {
+		t.Fatalf("Get(key)=%v; want nil error", err)
+	}
+	if got, want := string(resp.Kv.Value), "value"; got != want {
+		t.Errorf("Get(key)=%q; want %q", got, want)
+	}
+	if v, ok := lookup("test:key"); !ok || string(v) != "value" {
+		t.Errorf("stored test:key=%q, %t; want %q, true", v, ok, "value")
+	}
+
+	req := &pb.BatchPutReq{}
+	var keys []string
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key%d", i)
+		keys = append(keys, key)
+		req.Reqs = append(req.Reqs, &pb.PutReq{
+			Kv: &pb.KV{Key: key, Value: []byte("value-" + key)},
+		})
+	}
+	_, err = c.BatchPut(ctx, req)
+	if err != nil {
+		t.Fatalf("BatchPut=%v; want nil error", err)
+	}
+	bresp, err := c.BatchGet(ctx, &pb.BatchGetReq{
+		Keys: append(keys, "unknown"),
+	})
+	if err != nil {
+		t.Fatalf("BatchGet=%v; want nil error", err)
+	}
+	want := &pb.BatchGetResp{}
+	for _, key := range keys {
+		want.Resps = append(want.Resps, &pb.GetResp{
+			Kv:       &pb.KV{Key: key, Value: []byte("value-" + key)},
+			InMemory: true,
+		})
+	}
+	want.Resps = append(want.Resps, &pb.GetResp{})
+	if !proto.Equal(bresp, want) {
+		t.Errorf("BatchGet=%v; want %v", bresp, want)
+	}
+
+	_, err = c.Delete(ctx, &pb.DeleteReq{Key: "key"})
+	if err != nil {
+		t.Errorf("Delete(key)=%v; want nil error", err)
+	}
+	_, err = c.Get(ctx, &pb.GetReq{Key: "key"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Get(key)=%v after delete; want NotFound", err)
+	}
+
+	_, err = c.Put(ctx, &pb.PutReq{
+		Kv:  &pb.KV{Key: "ttl", Value: []byte("value")},
+		Ttl: ptypes.DurationProto(10 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("Put(ttl)=%v; want nil error", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.Get(ctx, &pb.GetReq{Key: "ttl"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Get(ttl)=%v after ttl; want NotFound", err)
+	}
+}
+
+func testContext(t *testing.T) (context.Context, context.CancelFunc) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	return context.WithTimeout(ctx, 10*time.Second)
+}
+
+func TestClientStandalone(t *testing.T) {
+	ctx, cancel := testContext(t)
+	defer cancel()
+	s := NewFakeServer(t)
+	c := NewClient(ctx, s.Addr().String(), Opts{
+		Prefix:         "test:",
+		MaxIdleConns:   DefaultMaxIdleConns,
+		MaxActiveConns: DefaultMaxActiveConns,
+	})
+	defer c.Close()
+	testClient(ctx, t, c, s.get)
+}
+
+func TestClientCodec(t *testing.T) {
+	ctx, cancel := testContext(t)
+	defer cancel()
+	s := NewFakeServer(t)
+	c := NewClient(ctx, s.Addr().String(), Opts{
+		Prefix:         "test:",
+		MaxIdleConns:   DefaultMaxIdleConns,
+		MaxActiveConns: DefaultMaxActiveConns,
+		Codec:          &codec.Codec{Format: codec.Flate},
+	})
+	defer c.Close()
+	testClient(ctx, t, c, s.get)
+
+	value := bytes.Repeat([]byte("value"), 1024)
+	_, err := c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{Key: "large", Value: value},
+	})
+	if err != nil {
+		t.Fatalf("Put(large)=%v; want nil error", err)
+	}
+	if v, ok := s.get("test:large"); !ok || len(v) >= len(value) {
+		t.Errorf("stored test:large=%d bytes, %t; want < %d bytes, true", len(v), ok, len(value))
+	}
+	resp, err := c.Get(ctx, &pb.GetReq{Key: "large"})
+	if err != nil || !bytes.Equal(resp.Kv.Value, value) {
+		t.Errorf("Get(large)=%v, %v; want original value", resp, err)
+	}
+
+	// stored before compression was enabled.
+	s.set("test:legacy", []byte("legacy-value"), time.Time{})
+	resp, err = c.Get(ctx, &pb.GetReq{Key: "legacy"})
+	if err != nil || string(resp.Kv.Value) != "legacy-value" {
+		t.Errorf("Get(legacy)=%v, %v; want %q", resp, err, "legacy-value")
+	}
+}
+
+func TestClientCluster(t *testing.T) {
+	ctx, cancel := testContext(t)
+	defer cancel()
+	cluster := NewFakeCluster(t, 3)
+	c := NewClient(ctx, cluster.Addr(), Opts{
+		Prefix:         "test:",
+		MaxIdleConns:   DefaultMaxIdleConns,
+		MaxActiveConns: DefaultMaxActiveConns,
+		Mode:           Cluster,
+	})
+	defer c.Close()
+	testClient(ctx, t, c, func(key string) ([]byte, bool) {
+		return cluster.Owner(keySlot(key)).get(key)
+	})
+
+	slot := keySlot("test:key1")
+	owner := cluster.Owner(slot)
+	var to *FakeServer
+	for _, s := range cluster.Servers {
+		if s != owner {
+			to = s
+			break
+		}
+	}
+
+	t.Logf("migrating slot %d", slot)
+	cluster.MigrateSlot(slot, to)
+	resp, err := c.Get(ctx, &pb.GetReq{Key: "key1"})
+	if err != nil {
+		t.Fatalf("Get(key1)=%v while migrating; want nil error", err)
+	}
+	if got, want := string(resp.Kv.Value), "value-key1"; got != want {
+		t.Errorf("Get(key1)=%q while migrating; want %q", got, want)
+	}
+
+	t.Logf("moved slot %d", slot)
+	cluster.MoveSlot(slot, to)
+	resp, err = c.Get(ctx, &pb.GetReq{Key: "key1"})
+	if err != nil {
+		t.Fatalf("Get(key1)=%v after moved; want nil error", err)
+	}
+	if got, want := string(resp.Kv.Value), "value-key1"; got != want {
+		t.Errorf("Get(key1)=%q after moved; want %q", got, want)
+	}
+	addr, err := c.addrFor(ctx, "test:key1")
+	if err != nil || addr != to.Addr().String() {
+		t.Errorf("addrFor(test:key1)=%q, %v; want %q", addr, err, to.Addr())
+	}
+}
+
+func TestClientSentinel(t *testing.T) {
+	ctx, cancel := testContext(t)
+	defer cancel()
+	primary := NewFakeServer(t)
+	replica := NewFakeServer(t)
+	sentinel := NewFakeSentinel(t, "mymaster", primary)
+	c := NewClient(ctx, "127.0.0.1:1,"+sentinel.Addr().String(), Opts{
+		Prefix:         "test:",
+		MaxIdleConns:   DefaultMaxIdleConns,
+		MaxActiveConns: DefaultMaxActiveConns,
+		Mode:           Sentinel,
+		MasterName:     "mymaster",
+	})
+	defer c.Close()
+	testClient(ctx, t, c, primary.get)
+
+	t.Logf("failover: primary becomes read only replica")
+	sentinel.Failover(replica)
+	primary.SetReadOnly(true)
+	_, err := c.Put(ctx, &pb.PutReq{
+		Kv: &pb.KV{Key: "key", Value: []byte("value2")},
+	})
+	if err != nil {
+		t.Fatalf("Put(key)=%v after failover; want nil error", err)
+	}
+	if v, ok := replica.get("test:key"); !ok || string(v) != "value2" {
+		t.Errorf("new primary test:key=%q, %t; want %q, true", v, ok, "value2")
+	}
+
+	t.Logf("failover: primary is down")
+	sentinel.Failover(primary)
+	primary.SetReadOnly(false)
+	primary.set("test:key", []byte("value3"), time.Time{})
+	replica.Close()
+	resp, err := c.Get(ctx, &pb.GetReq{Key: "key"})
+	if err != nil {
+		t.Fatalf("Get(key)=%v after failover; want nil error", err)
+	}
+	if got, want := string(resp.Kv.Value), "value3"; got != want {
+		t.Errorf("Get(key)=%q after failover; want %q", got, want)
+	}
+}
+
+func TestSentinelPrimaryUnlocked(t *testing.T) {
+	ctx, cancel := testContext(t)
+	defer cancel()
+	// slow sentinel accepts connection, but never replies.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	s := &sentinel{
+		addrs: []string{l.Addr().String()},
+		name:  "mymaster",
+	}
+	pctx, pcancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		_, err := s.primary(pctx)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// invalidate is not blocked by primary discovery.
+	invalidated := make(chan struct{})
+	go func() {
+		s.invalidate("127.0.0.1:1")
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(time.Second):
+		t.Errorf("invalidate is blocked by primary discovery")
+	}
+	pcancel()
+	if err := <-done; err == nil {
+		t.Errorf("primary()=nil error; want error for canceled context")
+	}
+}
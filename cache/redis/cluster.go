@@ -0,0 +1,250 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// numSlots is number of hash slots in redis cluster.
+const numSlots = 16384
+
+// crc16 computes CRC16-XMODEM used by redis cluster.
+func crc16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// keySlot returns hash slot of the key.
+// If key has non-empty hash tag, i.e. substring in first "{" and next "}",
+// hash tag is used to compute hash slot.
+// https://redis.io/topics/cluster-spec#keys-hash-tags
+func keySlot(key string) int {
+	if s := strings.IndexByte(key, '{'); s >= 0 {
+		if e := strings.IndexByte(key[s+1:], '}'); e > 0 {
+			key = key[s+1 : s+1+e]
+		}
+	}
+	return int(crc16([]byte(key))) % numSlots
+}
+
+// redirect is MOVED or ASK redirection of redis cluster.
+type redirect struct {
+	ask  bool
+	slot int
+	addr string
+}
+
+// parseRedirect parses err as MOVED or ASK error, which is
+// "MOVED <slot> <addr>" or "ASK <slot> <addr>".
+func parseRedirect(err error) (redirect, bool) {
+	var rerr redis.Error
+	if !errors.As(err, &rerr) {
+		return redirect{}, false
+	}
+	f := strings.Fields(string(rerr))
+	if len(f) != 3 {
+		return redirect{}, false
+	}
+	var r redirect
+	switch f[0] {
+	case "MOVED":
+	case "ASK":
+		r.ask = true
+	default:
+		return redirect{}, false
+	}
+	slot, err := strconv.Atoi(f[1])
+	if err != nil || slot < 0 || slot >= numSlots {
+		return redirect{}, false
+	}
+	r.slot = slot
+	r.addr = f[2]
+	return r, true
+}
+
+// clusterSlots maintains hash slot to redis server address mapping
+// of redis cluster.
+type clusterSlots struct {
+	seeds []string
+
+	// refreshMu serializes refresh.
+	refreshMu sync.Mutex
+
+	mu    sync.RWMutex
+	addrs []string // indexed by slot. nil if needs refresh.
+}
+
+// addr returns address of redis server for the key.
+func (s *clusterSlots) addr(ctx context.Context, pools *poolSet, key string) (string, error) {
+	slot := keySlot(key)
+	s.mu.RLock()
+	var addr string
+	if s.addrs != nil {
+		addr = s.addrs[slot]
+	}
+	s.mu.RUnlock()
+	if addr != "" {
+		return addr, nil
+	}
+	err := s.refresh(ctx, pools)
+	if err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.addrs == nil || s.addrs[slot] == "" {
+		return "", fmt.Errorf("redis cluster: no server for slot %d", slot)
+	}
+	return s.addrs[slot], nil
+}
+
+// moved records slot is moved to addr.
+func (s *clusterSlots) moved(slot int, addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.addrs == nil {
+		return
+	}
+	s.addrs[slot] = addr
+}
+
+// invalidate marks mapping needs refresh.
+func (s *clusterSlots) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addrs = nil
+}
+
+// nodes returns addresses known to s, including seeds.
+func (s *clusterSlots) nodes() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool)
+	var addrs []string
+	for _, addr := range append(append([]string{}, s.addrs...), s.seeds...) {
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+// refresh refreshes mapping by CLUSTER SLOTS.
+func (s *clusterSlots) refresh(ctx context.Context, pools *poolSet) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	s.mu.RLock()
+	fresh := s.addrs != nil
+	s.mu.RUnlock()
+	if fresh {
+		// refreshed by other goroutine.
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	var lastErr error
+	for _, node := range s.nodes() {
+		addrs, err := clusterSlotsAt(ctx, pools, node)
+		if err != nil {
+			logger.Warnf("redis cluster slots from %s: %v", node, err)
+			lastErr = err
+			continue
+		}
+		s.mu.Lock()
+		s.addrs = addrs
+		s.mu.Unlock()
+		logger.Infof("redis cluster slots refreshed from %s", node)
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("redis cluster: no nodes")
+	}
+	return lastErr
+}
+
+// clusterSlotsAt gets slot to address mapping from redis server at node.
+func clusterSlotsAt(ctx context.Context, pools *poolSet, node string) ([]string, error) {
+	conn, err := pools.get(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	ranges, err := redis.Values(conn.Do("CLUSTER", "SLOTS"))
+	if err != nil {
+		return nil, err
+	}
+	nodeHost, _, err := net.SplitHostPort(node)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]string, numSlots)
+	for _, r := range ranges {
+		// [start, end, [host, port, id], replicas...]
+		v, err := redis.Values(r, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(v) < 3 {
+			return nil, fmt.Errorf("unexpected slot range %q", v)
+		}
+		start, err := redis.Int(v[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		end, err := redis.Int(v[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		primary, err := redis.Values(v[2], nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(primary) < 2 {
+			return nil, fmt.Errorf("unexpected slot node %q", primary)
+		}
+		host, err := redis.String(primary[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		if host == "" {
+			// unknown endpoint means the same host as the node.
+			host = nodeHost
+		}
+		port, err := redis.Int(primary[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		if start < 0 || end >= numSlots || start > end {
+			return nil, fmt.Errorf("bad slot range %d-%d", start, end)
+		}
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		for slot := start; slot <= end; slot++ {
+			addrs[slot] = addr
+		}
+	}
+	return addrs, nil
+}
@@ -5,5 +5,10 @@
 /*
 Package redis provides cache service by redis (cloud memorystore).
 
+It supports single redis server, redis cluster (routing by hash slot,
+following MOVED and ASK redirection), and redis sentinel (discovering
+primary, and rediscovering it on failover).
+Mode is configured by REDISMODE environment, see ModeFromEnv.
+
 */
 package redis
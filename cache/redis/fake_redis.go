@@ -6,24 +6,49 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"net"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
-// FakeServer is a fake redis server for stress test.
+// FakeServer is a fake redis server for test.
+// It supports PING, GET, SET (with PX), MGET and DEL.
+// In FakeCluster, it also supports CLUSTER SLOTS and ASKING, and replies
+// MOVED or ASK for keys it doesn't serve.
+// As FakeSentinel, it supports SENTINEL get-master-addr-by-name.
 type FakeServer struct {
 	ln net.Listener
 	tb testing.TB
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	data     map[string]fakeEntry
+	readOnly bool
+	cluster  *FakeCluster
+	sentinel *FakeSentinel
+}
+
+type fakeEntry struct {
+	value  []byte
+	expire time.Time
 }
 
 // NewFakeServer starts a new fake redis server.
 func NewFakeServer(tb testing.TB) *FakeServer {
-	ln, err := net.Listen("tcp", "")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
 		tb.Fatal(err)
 	}
-	s := &FakeServer{ln: ln, tb: tb}
+	s := &FakeServer{
+		ln:    ln,
+		tb:    tb,
+		conns: make(map[net.Conn]bool),
+		data:  make(map[string]fakeEntry),
+	}
 	go s.serve()
 	tb.Cleanup(func() { s.Close() })
 	return s
@@ -34,9 +59,23 @@ func (s *FakeServer) Addr() net.Addr {
 	return s.ln.Addr()
 }
 
-// Close shuts down the fake redis server.
+// Close shuts down the fake redis server, and closes all connections
+// to the server.
 func (s *FakeServer) Close() {
 	s.ln.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// SetReadOnly sets the server read only, as replica.
+// The server replies READONLY error for write commands.
+func (s *FakeServer) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readOnly = readOnly
 }
 
 func (s *FakeServer) serve() {
@@ -45,65 +84,452 @@ func (s *FakeServer) serve() {
 		if err != nil {
 			return
 		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
 		go s.handle(conn)
 	}
 }
 
 func (s *FakeServer) handle(conn net.Conn) {
-	defer conn.Close()
-	b := bufio.NewReader(conn)
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	asking := false
 	for {
-		_, err := s.readRequest(b)
+		args, err := s.readRequest(r)
 		if err != nil {
 			return
 		}
-		// s.tb.Logf("request: %q", line)
-		// assume GET
-		// *2\r\n$3\r\nGET\r\n$3\r\nkey\r\n
+		if len(args) == 0 {
+			continue
+		}
+		// ASKING flag is valid only for the next command.
+		askingNow := asking
+		asking = false
+		name := strings.ToUpper(string(args[0]))
+		if name == "ASKING" {
+			asking = true
+		}
+		s.dispatch(w, name, args[1:], askingNow)
+		err = w.Flush()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *FakeServer) dispatch(w *bufio.Writer, name string, args [][]byte, asking bool) {
+	s.mu.Lock()
+	cluster := s.cluster
+	sentinel := s.sentinel
+	readOnly := s.readOnly
+	s.mu.Unlock()
+
+	switch name {
+	case "PING":
+		writeSimple(w, "PONG")
+		return
+	case "ASKING":
+		writeSimple(w, "OK")
+		return
+	case "CLUSTER":
+		if cluster == nil {
+			writeError(w, "ERR This instance has cluster support disabled")
+			return
+		}
+		if len(args) != 1 || strings.ToUpper(string(args[0])) != "SLOTS" {
+			writeError(w, "ERR unsupported CLUSTER subcommand")
+			return
+		}
+		cluster.writeSlots(w)
+		return
+	case "SENTINEL":
+		if sentinel == nil {
+			writeError(w, "ERR unknown command 'SENTINEL'")
+			return
+		}
+		if len(args) != 2 || strings.ToLower(string(args[0])) != "get-master-addr-by-name" {
+			writeError(w, "ERR unsupported SENTINEL subcommand")
+			return
+		}
+		sentinel.writePrimary(w, string(args[1]))
+		return
+	}
+
+	var keys []string
+	switch name {
+	case "GET", "DEL":
+		if len(args) < 1 {
+			writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+			return
+		}
+		keys = []string{string(args[0])}
+	case "SET":
+		if len(args) < 2 {
+			writeError(w, "ERR wrong number of arguments for 'set' command")
+			return
+		}
+		keys = []string{string(args[0])}
+	case "MGET":
+		if len(args) < 1 {
+			writeError(w, "ERR wrong number of arguments for 'mget' command")
+			return
+		}
+		for _, arg := range args {
+			keys = append(keys, string(arg))
+		}
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", name))
+		return
+	}
+	if cluster != nil {
+		if msg := cluster.route(s, keys, asking); msg != "" {
+			writeError(w, msg)
+			return
+		}
+	}
+	switch name {
+	case "GET":
+		v, ok := s.get(keys[0])
+		if !ok {
+			writeBulk(w, nil)
+			return
+		}
+		writeBulk(w, v)
+	case "MGET":
+		writeArray(w, len(keys))
+		for _, key := range keys {
+			v, ok := s.get(key)
+			if !ok {
+				writeBulk(w, nil)
+				continue
+			}
+			writeBulk(w, v)
+		}
+	case "SET":
+		if readOnly {
+			writeError(w, "READONLY You can't write against a read only replica.")
+			return
+		}
+		var expire time.Time
+		if len(args) == 4 && strings.ToUpper(string(args[2])) == "PX" {
+			ms, err := strconv.ParseInt(string(args[3]), 10, 64)
+			if err != nil || ms <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			expire = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		} else if len(args) != 2 {
+			writeError(w, "ERR syntax error")
+			return
+		}
+		s.set(keys[0], append([]byte{}, args[1]...), expire)
+		writeSimple(w, "OK")
+	case "DEL":
+		if readOnly {
+			writeError(w, "READONLY You can't write against a read only replica.")
+			return
+		}
+		n := 0
+		for _, arg := range args {
+			if s.del(string(arg)) {
+				n++
+			}
+		}
+		writeInt(w, n)
+	}
+}
 
-		conn.Write([]byte("$10\r\n0123456789\r\n"))
+func (s *FakeServer) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.data[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expire.IsZero() && !time.Now().Before(e.expire) {
+		delete(s.data, key)
+		return nil, false
 	}
+	return e.value, true
+}
+
+func (s *FakeServer) set(key string, value []byte, expire time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = fakeEntry{value: value, expire: expire}
 }
 
-func (s *FakeServer) readRequest(r *bufio.Reader) ([]byte, error) {
-	var line []byte
+func (s *FakeServer) del(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.data[key]
+	delete(s.data, key)
+	return ok
+}
+
+// takeSlot removes entries in slot and returns them.
+func (s *FakeServer) takeSlot(slot int) map[string]fakeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := make(map[string]fakeEntry)
+	for k, e := range s.data {
+		if keySlot(k) == slot {
+			m[k] = e
+			delete(s.data, k)
+		}
+	}
+	return m
+}
+
+func (s *FakeServer) putAll(m map[string]fakeEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, e := range m {
+		s.data[k] = e
+	}
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "+%s\r\n", s)
+}
+
+func writeError(w *bufio.Writer, msg string) {
+	fmt.Fprintf(w, "-%s\r\n", msg)
+}
+
+func writeInt(w *bufio.Writer, n int) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+// writeBulk writes bulk string b, or null bulk string if b is nil.
+func writeBulk(w *bufio.Writer, b []byte) {
+	if b == nil {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "$%d\r\n", len(b))
+	w.Write(b)
+	w.WriteString("\r\n")
+}
+
+func writeArray(w *bufio.Writer, n int) {
+	fmt.Fprintf(w, "*%d\r\n", n)
+}
+
+// readRequest reads a request of array of bulk strings, and returns
+// the array.
+func (s *FakeServer) readRequest(r *bufio.Reader) ([][]byte, error) {
 	nline, _, err := r.ReadLine()
 	if err != nil {
 		return nil, err
 	}
-	line = append(line, nline...)
 	if !bytes.HasPrefix(nline, []byte("*")) {
-		return line, err
+		// inline command.
+		return bytes.Fields(nline), nil
 	}
 	// *<n> array
 	n, err := strconv.Atoi(string(nline[1:]))
 	if err != nil {
-		return line, fmt.Errorf("wrong array %q: %v", nline, err)
+		return nil, fmt.Errorf("wrong array %q: %v", nline, err)
 	}
+	args := make([][]byte, 0, n)
 	for i := 0; i < n; i++ {
 		nline, _, err := r.ReadLine()
 		if err != nil {
-			return line, err
+			return nil, err
 		}
-		line = append(line, '\n')
-		line = append(line, nline...)
 		if !bytes.HasPrefix(nline, []byte("$")) {
-			continue
+			return nil, fmt.Errorf("wrong bulk string %q", nline)
 		}
 		// $<n>\r\n<value>\r\n
 		sz, err := strconv.Atoi(string(nline[1:]))
 		if err != nil {
-			return line, fmt.Errorf("wrong bytes %q: %v", nline, err)
+			return nil, fmt.Errorf("wrong bytes %q: %v", nline, err)
 		}
-		nline, _, err = r.ReadLine()
+		buf := make([]byte, sz+2)
+		_, err = io.ReadFull(r, buf)
 		if err != nil {
-			return line, err
+			return nil, err
 		}
-		line = append(line, '\n')
-		line = append(line, nline...)
-		if sz != len(nline) {
-			return line, fmt.Errorf("unexpected value sz=%d v=%q", sz, nline)
+		if !bytes.HasSuffix(buf, []byte("\r\n")) {
+			return nil, fmt.Errorf("unexpected value sz=%d v=%q", sz, buf)
 		}
+		args = append(args, buf[:sz])
+	}
+	return args, nil
+}
+
+// FakeCluster is a fake redis cluster for test.
+type FakeCluster struct {
+	// Servers are servers in the cluster.
+	Servers []*FakeServer
+
+	mu        sync.Mutex
+	owner     [numSlots]*FakeServer
+	migrating map[int]*FakeServer
+}
+
+// NewFakeCluster starts a new fake redis cluster with n servers.
+// Hash slots are evenly assigned to the servers.
+func NewFakeCluster(tb testing.TB, n int) *FakeCluster {
+	c := &FakeCluster{
+		migrating: make(map[int]*FakeServer),
+	}
+	for i := 0; i < n; i++ {
+		s := NewFakeServer(tb)
+		s.mu.Lock()
+		s.cluster = c
+		s.mu.Unlock()
+		c.Servers = append(c.Servers, s)
+	}
+	for slot := range c.owner {
+		c.owner[slot] = c.Servers[slot*n/numSlots]
+	}
+	return c
+}
+
+// Addr returns comma separated addresses of the servers in the cluster.
+func (c *FakeCluster) Addr() string {
+	var addrs []string
+	for _, s := range c.Servers {
+		addrs = append(addrs, s.Addr().String())
+	}
+	return strings.Join(addrs, ",")
+}
+
+// Owner returns the server that owns the slot.
+func (c *FakeCluster) Owner(slot int) *FakeServer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.owner[slot]
+}
+
+// MigrateSlot starts migrating slot to server to, and moves keys in
+// the slot to the server.
+// Until MoveSlot is called, the owner replies ASK for the keys in the slot.
+func (c *FakeCluster) MigrateSlot(slot int, to *FakeServer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.migrating[slot] = to
+	to.putAll(c.owner[slot].takeSlot(slot))
+}
+
+// MoveSlot moves slot to server to.
+// Old owner replies MOVED for the keys in the slot.
+func (c *FakeCluster) MoveSlot(slot int, to *FakeServer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.migrating, slot)
+	to.putAll(c.owner[slot].takeSlot(slot))
+	c.owner[slot] = to
+}
+
+// route checks server s could serve keys.
+// It returns error message if s couldn't serve keys.
+func (c *FakeCluster) route(s *FakeServer, keys []string, asking bool) string {
+	slot := keySlot(keys[0])
+	for _, key := range keys[1:] {
+		if keySlot(key) != slot {
+			return "CROSSSLOT Keys in request don't hash to the same slot"
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	owner := c.owner[slot]
+	target, migrating := c.migrating[slot]
+	if owner == s {
+		if !migrating {
+			return ""
+		}
+		for _, key := range keys {
+			if _, ok := s.get(key); !ok {
+				return fmt.Sprintf("ASK %d %s", slot, target.Addr())
+			}
+		}
+		return ""
+	}
+	if migrating && target == s && asking {
+		return ""
+	}
+	return fmt.Sprintf("MOVED %d %s", slot, owner.Addr())
+}
+
+// writeSlots writes reply of CLUSTER SLOTS.
+func (c *FakeCluster) writeSlots(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	type slotRange struct {
+		start, end int
+		s          *FakeServer
+	}
+	var ranges []slotRange
+	for slot, s := range c.owner {
+		if len(ranges) > 0 && ranges[len(ranges)-1].s == s {
+			ranges[len(ranges)-1].end = slot
+			continue
+		}
+		ranges = append(ranges, slotRange{start: slot, end: slot, s: s})
+	}
+	writeArray(w, len(ranges))
+	for i, r := range ranges {
+		addr := r.s.Addr().(*net.TCPAddr)
+		writeArray(w, 3)
+		writeInt(w, r.start)
+		writeInt(w, r.end)
+		writeArray(w, 3)
+		writeBulk(w, []byte(addr.IP.String()))
+		writeInt(w, addr.Port)
+		writeBulk(w, []byte(fmt.Sprintf("node%d", i)))
+	}
+}
+
+// FakeSentinel is a fake redis sentinel for test.
+type FakeSentinel struct {
+	*FakeServer
+	name string
+
+	mu      sync.Mutex
+	primary *FakeServer
+}
+
+// NewFakeSentinel starts a new fake redis sentinel that monitors
+// primary as name.
+func NewFakeSentinel(tb testing.TB, name string, primary *FakeServer) *FakeSentinel {
+	s := &FakeSentinel{
+		FakeServer: NewFakeServer(tb),
+		name:       name,
+		primary:    primary,
+	}
+	s.FakeServer.mu.Lock()
+	s.FakeServer.sentinel = s
+	s.FakeServer.mu.Unlock()
+	return s
+}
+
+// Failover changes primary.
+func (s *FakeSentinel) Failover(primary *FakeServer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.primary = primary
+}
+
+// writePrimary writes reply of SENTINEL get-master-addr-by-name.
+func (s *FakeSentinel) writePrimary(w *bufio.Writer, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name != s.name || s.primary == nil {
+		w.WriteString("*-1\r\n")
+		return
 	}
-	return line, nil
+	addr := s.primary.Addr().(*net.TCPAddr)
+	writeArray(w, 2)
+	writeBulk(w, []byte(addr.IP.String()))
+	writeBulk(w, []byte(strconv.Itoa(addr.Port)))
 }
@@ -0,0 +1,106 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// pool is connection pool for a redis server.
+type pool struct {
+	*redis.Pool
+
+	// to workaround pool.wait. maintain active conns.
+	sema chan struct{}
+}
+
+type activeConn struct {
+	redis.Conn
+	sema chan struct{}
+}
+
+func (c activeConn) Close() error {
+	<-c.sema
+	return c.Conn.Close()
+}
+
+func (p *pool) getContext(ctx context.Context) (redis.Conn, error) {
+	t := time.Now()
+	select {
+	case p.sema <- struct{}{}:
+		d := time.Since(t)
+		if d > 100*time.Millisecond {
+			logger := log.FromContext(ctx)
+			logger.Warnf("redis pool wait %s actives=%d", d, len(p.sema))
+		}
+		conn, err := p.Pool.GetContext(ctx)
+		if err != nil {
+			<-p.sema
+			return nil, err
+		}
+		return activeConn{
+			Conn: conn,
+			sema: p.sema,
+		}, nil
+	case <-ctx.Done():
+		d := time.Since(t)
+		if d > 100*time.Millisecond {
+			logger := log.FromContext(ctx)
+			logger.Warnf("redis pool timed-out wait %s actives=%d", d, len(p.sema))
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// poolSet is a set of connection pools for each redis server.
+type poolSet struct {
+	maxIdle   int
+	maxActive int
+
+	mu    sync.Mutex
+	pools map[string]*pool
+}
+
+// get gets a connection to redis server at addr.
+func (ps *poolSet) get(ctx context.Context, addr string) (redis.Conn, error) {
+	ps.mu.Lock()
+	p, ok := ps.pools[addr]
+	if !ok {
+		p = &pool{
+			Pool: &redis.Pool{
+				DialContext: func(ctx context.Context) (redis.Conn, error) {
+					return redis.DialContext(ctx, "tcp", addr)
+				},
+				MaxIdle:   ps.maxIdle,
+				MaxActive: ps.maxActive,
+				// https://github.com/gomodule/redigo/issues/520
+				Wait: false,
+			},
+			sema: make(chan struct{}, ps.maxActive),
+		}
+		ps.pools[addr] = p
+	}
+	ps.mu.Unlock()
+	return p.getContext(ctx)
+}
+
+func (ps *poolSet) close() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var err error
+	for addr, p := range ps.pools {
+		if cerr := p.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(ps.pools, addr)
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/gomodule/redigo/redis"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// sentinel discovers primary address by redis sentinels.
+type sentinel struct {
+	addrs []string
+	name  string
+
+	mu   sync.Mutex
+	addr string // current primary address. empty if unknown.
+}
+
+// primary returns current primary address.
+// It asks sentinels without holding mu, so slow sentinels won't block
+// callers that already know the primary.
+func (s *sentinel) primary(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	addr := s.addr
+	s.mu.Unlock()
+	if addr != "" {
+		return addr, nil
+	}
+	logger := log.FromContext(ctx)
+	var lastErr error
+	for _, saddr := range s.addrs {
+		addr, err := s.primaryAt(ctx, saddr)
+		if err != nil {
+			logger.Warnf("redis sentinel %s: %v", saddr, err)
+			lastErr = err
+			continue
+		}
+		logger.Infof("redis sentinel %s: primary %s=%s", saddr, s.name, addr)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.addr == "" {
+			s.addr = addr
+		}
+		return s.addr, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("redis sentinel: no sentinels")
+	}
+	return "", lastErr
+}
+
+// primaryAt asks primary address to sentinel at saddr.
+func (s *sentinel) primaryAt(ctx context.Context, saddr string) (string, error) {
+	conn, err := redis.DialContext(ctx, "tcp", saddr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	// redis.Conn doesn't take context, so close conn to cancel.
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	v, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", s.name))
+	if err == redis.ErrNil {
+		// don't return ErrNil, which will be NotFound.
+		return "", fmt.Errorf("unknown primary %q", s.name)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(v) != 2 {
+		return "", fmt.Errorf("unexpected reply for primary %q: %q", s.name, v)
+	}
+	return net.JoinHostPort(v[0], v[1]), nil
+}
+
+// invalidate forgets primary address if it is addr.
+func (s *sentinel) invalidate(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.addr == addr {
+		s.addr = ""
+	}
+}
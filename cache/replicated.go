@@ -0,0 +1,417 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.chromium.org/goma/server/log"
+	"go.chromium.org/goma/server/rpc"
+
+	pb "go.chromium.org/goma/server/proto/cache"
+)
+
+const (
+	// DefaultHedgeDelay is default delay to send hedged request
+	// to next replica.
+	DefaultHedgeDelay = 50 * time.Millisecond
+
+	// repairTimeout is timeout to write back key-value to replicas
+	// that missed it.
+	repairTimeout = 10 * time.Second
+)
+
+// ReplicaOpts is options for ReplicatedClient.
+type ReplicaOpts struct {
+	// Replicas is number of replicas to store each key.
+	// If it is less than 1, 1 is used.
+	Replicas int
+
+	// HedgeDelay is delay to send Get request to next replica
+	// when the replica doesn't respond.
+	// If it is zero, DefaultHedgeDelay is used.
+	HedgeDelay time.Duration
+
+	// DialOptions is used to dial cache servers.
+	DialOptions []grpc.DialOption
+
+	// RPCOptions is additional options for rpc.Client.
+	RPCOptions []rpc.Option
+}
+
+// ReplicatedClient is a client to access cache service via gRPC, that
+// stores each key in multiple cache servers on the consistent hash ring.
+//
+// It writes each key to all replicas, and reads from the closest healthy
+// replica. If the replica doesn't respond in HedgeDelay, it also sends
+// the request to the next replica. If the key is found in other than
+// the first replica, it writes back the key-value to replicas that
+// missed the key (read-repair).
+type ReplicatedClient struct {
+	client     *rpc.Client
+	replicas   int
+	hedgeDelay time.Duration
+}
+
+// NewReplicatedClient creates new client to access cache service serving
+// on address with replicas.
+func NewReplicatedClient(ctx context.Context, address string, opts ReplicaOpts) ReplicatedClient {
+	if opts.Replicas < 1 {
+		opts.Replicas = 1
+	}
+	if opts.HedgeDelay == 0 {
+		opts.HedgeDelay = DefaultHedgeDelay
+	}
+	return ReplicatedClient{
+		client: rpc.NewClient(ctx, address,
+			func(cc *grpc.ClientConn) interface{} {
+				return pb.NewCacheServiceClient(cc)
+			},
+			append(rpc.DialOptions(opts.DialOptions...), opts.RPCOptions...)...),
+		replicas:   opts.Replicas,
+		hedgeDelay: opts.HedgeDelay,
+	}
+}
+
+// Close releases the resources used by the client.
+func (c ReplicatedClient) Close() error {
+	return c.client.Close()
+}
+
+// call calls f for cache server at addr.
+func (c ReplicatedClient) call(ctx context.Context, addr string, f func(pb.CacheServiceClient) error) error {
+	return c.client.Call(ctx, c.client.PickAddr, addr,
+		func(client interface{}) error {
+			return f(client.(pb.CacheServiceClient))
+		})
+}
+
+// Get gets key-value data for requested key from replicas.
+func (c ReplicatedClient) Get(ctx context.Context, in *pb.GetReq, opts ...grpc.CallOption) (*pb.GetResp, error) {
+	addrs, err := c.client.Replicas(ctx, in.Key, c.replicas)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		addr string
+		resp *pb.GetResp
+		err  error
+	}
+	ch := make(chan result, len(addrs))
+	next := 0
+	pending := 0
+	var hedge <-chan time.Time
+	send := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			var resp *pb.GetResp
+			err := c.call(ctx, addr, func(client pb.CacheServiceClient) error {
+				var err error
+				resp, err = client.Get(ctx, in, opts...)
+				return err
+			})
+			ch <- result{addr: addr, resp: resp, err: err}
+		}()
+		hedge = nil
+		if next < len(addrs) {
+			hedge = time.After(c.hedgeDelay)
+		}
+	}
+
+	send()
+	var missed []string
+	var notFoundErr, lastErr error
+	for pending > 0 {
+		select {
+		case <-hedge:
+			send()
+
+		case r := <-ch:
+			pending--
+			if r.err == nil {
+				if len(missed) > 0 && r.resp.GetKv() != nil {
+					repairs := make(map[string][]*pb.KV)
+					for _, addr := range missed {
+						repairs[addr] = []*pb.KV{r.resp.Kv}
+					}
+					c.repair(ctx, repairs)
+				}
+				return r.resp, nil
+			}
+			if status.Code(r.err) == codes.NotFound {
+				missed = append(missed, r.addr)
+				notFoundErr = r.err
+			} else {
+				lastErr = r.err
+			}
+			if next < len(addrs) {
+				send()
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if notFoundErr != nil {
+		return nil, notFoundErr
+	}
+	return nil, lastErr
+}
+
+// repair writes back key-values to cache servers in background.
+// repairs is key-values for each cache server address.
+func (c ReplicatedClient) repair(ctx context.Context, repairs map[string][]*pb.KV) {
+	logger := log.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(log.NewContext(context.Background(), logger), repairTimeout)
+	go func() {
+		defer cancel()
+		for addr, kvs := range repairs {
+			req := &pb.BatchPutReq{}
+			for _, kv := range kvs {
+				req.Reqs = append(req.Reqs, &pb.PutReq{Kv: kv})
+			}
+			err := c.call(ctx, addr, func(client pb.CacheServiceClient) error {
+				_, err := client.BatchPut(ctx, req)
+				return err
+			})
+			if err != nil {
+				logger.Warnf("read-repair %d keys to %s: %v", len(kvs), addr, err)
+				continue
+			}
+			logger.Debugf("read-repair %d keys to %s", len(kvs), addr)
+		}
+	}()
+}
+
+// Put puts new key-value data to replicas.
+// It succeeds if any replica stores the key-value.
+// If no key-value is given, do nothing.
+func (c ReplicatedClient) Put(ctx context.Context, in *pb.PutReq, opts ...grpc.CallOption) (*pb.PutResp, error) {
+	if in.Kv == nil {
+		return nil, nil
+	}
+	_, err := c.BatchPut(ctx, &pb.BatchPutReq{
+		Reqs: []*pb.PutReq{in},
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PutResp{}, nil
+}
+
+// replicaGroups groups keys by replica addresses.
+// It returns indexes of keys for each address, and replica addresses
+// for each key.
+func (c ReplicatedClient) replicaGroups(ctx context.Context, keys []string) (map[string][]int, [][]string, error) {
+	groups := make(map[string][]int)
+	replicas := make([][]string, len(keys))
+	for i, key := range keys {
+		addrs, err := c.client.Replicas(ctx, key, c.replicas)
+		if err != nil {
+			return nil, nil, err
+		}
+		replicas[i] = addrs
+		for _, addr := range addrs {
+			groups[addr] = append(groups[addr], i)
+		}
+	}
+	return groups, replicas, nil
+}
+
+// BatchGet gets key-value data for requested keys.
+// Keys are sent to the closest replica in a batch, and keys not found
+// are sent to the next replica. Keys found in other than the first
+// replica are written back to replicas that missed them.
+// Unlike Get, it doesn't send hedged requests.
+func (c ReplicatedClient) BatchGet(ctx context.Context, in *pb.BatchGetReq, opts ...grpc.CallOption) (*pb.BatchGetResp, error) {
+	_, replicas, err := c.replicaGroups(ctx, in.Keys)
+	if err != nil {
+		return nil, err
+	}
+	resp := &pb.BatchGetResp{
+		Resps: make([]*pb.GetResp, len(in.Keys)),
+	}
+	missed := make([][]string, len(in.Keys))
+	pending := make([]int, len(in.Keys))
+	for i := range in.Keys {
+		pending[i] = i
+	}
+	answered := false
+	var lastErr error
+	for n := 0; n < c.replicas && len(pending) > 0; n++ {
+		groups := make(map[string][]int)
+		for _, i := range pending {
+			if n < len(replicas[i]) {
+				addr := replicas[i][n]
+				groups[addr] = append(groups[addr], i)
+			}
+		}
+		if len(groups) == 0 {
+			break
+		}
+		results := make([]*pb.GetResp, len(in.Keys))
+		var eg errgroup.Group
+		for addr, idx := range groups {
+			addr, idx := addr, idx
+			req := &pb.BatchGetReq{
+				Fast: in.Fast,
+			}
+			for _, i := range idx {
+				req.Keys = append(req.Keys, in.Keys[i])
+			}
+			eg.Go(func() error {
+				var r *pb.BatchGetResp
+				err := c.call(ctx, addr, func(client pb.CacheServiceClient) error {
+					var err error
+					r, err = client.BatchGet(ctx, req, opts...)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				for j, i := range idx {
+					if j < len(r.Resps) {
+						results[i] = r.Resps[j]
+					}
+				}
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			// keys of failed replica remain pending.
+			lastErr = err
+		}
+		var next []int
+		for _, i := range pending {
+			if n >= len(replicas[i]) {
+				continue
+			}
+			r := results[i]
+			if r != nil {
+				answered = true
+			}
+			if r.GetKv() != nil {
+				resp.Resps[i] = r
+				continue
+			}
+			if r != nil {
+				missed[i] = append(missed[i], replicas[i][n])
+			}
+			next = append(next, i)
+		}
+		pending = next
+	}
+	if !answered && lastErr != nil {
+		return nil, lastErr
+	}
+	repairs := make(map[string][]*pb.KV)
+	for i, r := range resp.Resps {
+		if r == nil {
+			resp.Resps[i] = &pb.GetResp{}
+			continue
+		}
+		for _, addr := range missed[i] {
+			repairs[addr] = append(repairs[addr], r.Kv)
+		}
+	}
+	if len(repairs) > 0 {
+		c.repair(ctx, repairs)
+	}
+	return resp, nil
+}
+
+// BatchPut puts new key-value data to replicas.
+// Key-values are sent to each replica in a batch.
+// It succeeds if any replica stores each key-value.
+func (c ReplicatedClient) BatchPut(ctx context.Context, in *pb.BatchPutReq, opts ...grpc.CallOption) (*pb.BatchPutResp, error) {
+	logger := log.FromContext(ctx)
+	var reqs []*pb.PutReq
+	var keys []string
+	for _, req := range in.Reqs {
+		if req.Kv == nil {
+			continue
+		}
+		reqs = append(reqs, req)
+		keys = append(keys, req.Kv.Key)
+	}
+	groups, _, err := c.replicaGroups(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	type result struct {
+		idx []int
+		err error
+	}
+	ch := make(chan result, len(groups))
+	for addr, idx := range groups {
+		addr, idx := addr, idx
+		req := &pb.BatchPutReq{}
+		for _, i := range idx {
+			req.Reqs = append(req.Reqs, reqs[i])
+		}
+		go func() {
+			err := c.call(ctx, addr, func(client pb.CacheServiceClient) error {
+				_, err := client.BatchPut(ctx, req, opts...)
+				return err
+			})
+			if err != nil {
+				logger.Warnf("put %d keys to %s: %v", len(idx), addr, err)
+			}
+			ch <- result{idx: idx, err: err}
+		}()
+	}
+	stored := make([]bool, len(reqs))
+	var lastErr error
+	for range groups {
+		r := <-ch
+		if r.err != nil {
+			lastErr = r.err
+			continue
+		}
+		for _, i := range r.idx {
+			stored[i] = true
+		}
+	}
+	for _, ok := range stored {
+		if !ok {
+			return nil, lastErr
+		}
+	}
+	return &pb.BatchPutResp{}, nil
+}
+
+// Delete deletes key-value data for requested key from all replicas.
+func (c ReplicatedClient) Delete(ctx context.Context, in *pb.DeleteReq, opts ...grpc.CallOption) (*pb.DeleteResp, error) {
+	addrs, err := c.client.Replicas(ctx, in.Key, c.replicas)
+	if err != nil {
+		return nil, err
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, addr := range addrs {
+		addr := addr
+		eg.Go(func() error {
+			return c.call(ctx, addr, func(client pb.CacheServiceClient) error {
+				_, err := client.Delete(ctx, in, opts...)
+				return err
+			})
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteResp{}, nil
+}
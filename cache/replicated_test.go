@@ -0,0 +1,268 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.chromium.org/goma/server/log"
+	"go.chromium.org/goma/server/rpc"
+
+	pb "go.chromium.org/goma/server/proto/cache"
+)
+
+// slowServer is cache server that delays Get.
+type slowServer struct {
+	*Cache
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func (s *slowServer) setDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+func (s *slowServer) Get(ctx context.Context, in *pb.GetReq) (*pb.GetResp, error) {
+	s.mu.Lock()
+	delay := s.delay
+	s.mu.Unlock()
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.Cache.Get(ctx, in)
+}
+
+type replicaTestEnv struct {
+	servers map[string]*slowServer
+	client  ReplicatedClient
+	stops   []func()
+}
+
+func (e *replicaTestEnv) close() {
+	e.client.Close()
+	for _, stop := range e.stops {
+		stop()
+	}
+}
+
+// newReplicaTestEnv starts cache servers on 127.0.0.x with the same port,
+// and creates ReplicatedClient for them.
+func newReplicaTestEnv(t *testing.T, ctx context.Context, nservers, replicas int) *replicaTestEnv {
+	t.Helper()
+	e := &replicaTestEnv{
+		servers: make(map[string]*slowServer),
+	}
+	var hosts []string
+	port := "0"
+	for i := 1; i <= nservers; i++ {
+		host := "127.0.0." + strconv.Itoa(i)
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, port))
+		if err != nil {
+			e.close()
+			t.Skipf("listen %s: %v", host, err)
+		}
+		_, port, _ = net.SplitHostPort(lis.Addr().String())
+		c, err := New(Config{
+			MaxBytes: 1024 * 1024,
+		})
+		if err != nil {
+			t.Fatalf("cache.New(...): %v", err)
+		}
+		s := &slowServer{Cache: c}
+		srv := grpc.NewServer()
+		pb.RegisterCacheServiceServer(srv, s)
+		go srv.Serve(lis)
+		e.stops = append(e.stops, func() {
+			srv.Stop()
+			lis.Close()
+		})
+		e.servers[host] = s
+		hosts = append(hosts, host)
+	}
+	e.client = NewReplicatedClient(ctx, net.JoinHostPort("cache.test", port), ReplicaOpts{
+		Replicas:    replicas,
+		HedgeDelay:  10 * time.Millisecond,
+		DialOptions: []grpc.DialOption{grpc.WithInsecure()},
+		RPCOptions: []rpc.Option{
+			rpc.LookupHost(func(string) ([]string, error) {
+				return hosts, nil
+			}),
+		},
+	})
+	return e
+}
+
+func TestReplicatedClientPutGet(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	e := newReplicaTestEnv(t, ctx, 3, 2)
+	defer e.close()
+
+	kv := &pb.KV{
+		Key:   "key",
+		Value: []byte("value"),
+	}
+	_, err := e.client.Put(ctx, &pb.PutReq{Kv: kv})
+	if err != nil {
+		t.Fatalf("Put(%s)=%v; want nil error", kv.Key, err)
+	}
+	addrs, err := e.client.client.Replicas(ctx, kv.Key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica := make(map[string]bool)
+	for _, addr := range addrs {
+		replica[addr] = true
+	}
+	for addr, s := range e.servers {
+		_, err := s.Cache.Get(ctx, &pb.GetReq{Key: kv.Key})
+		if replica[addr] && err != nil {
+			t.Errorf("replica %s: Get(%s)=%v; want nil error", addr, kv.Key, err)
+		}
+		if !replica[addr] && status.Code(err) != codes.NotFound {
+			t.Errorf("non-replica %s: Get(%s)=%v; want NotFound", addr, kv.Key, err)
+		}
+	}
+
+	resp, err := e.client.Get(ctx, &pb.GetReq{Key: kv.Key})
+	if err != nil {
+		t.Fatalf("Get(%s)=%v; want nil error", kv.Key, err)
+	}
+	if !proto.Equal(resp.Kv, kv) {
+		t.Errorf("Get(%s)=%v; want %v", kv.Key, resp.Kv, kv)
+	}
+
+	_, err = e.client.Get(ctx, &pb.GetReq{Key: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Get(unknown)=%v; want NotFound", err)
+	}
+
+	_, err = e.client.Delete(ctx, &pb.DeleteReq{Key: kv.Key})
+	if err != nil {
+		t.Fatalf("Delete(%s)=%v; want nil error", kv.Key, err)
+	}
+	for addr, s := range e.servers {
+		_, err := s.Cache.Get(ctx, &pb.GetReq{Key: kv.Key})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("%s: Get(%s)=%v after delete; want NotFound", addr, kv.Key, err)
+		}
+	}
+}
+
+func TestReplicatedClientReadRepair(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	e := newReplicaTestEnv(t, ctx, 3, 2)
+	defer e.close()
+
+	kvs := []*pb.KV{
+		{Key: "key1", Value: []byte("value1")},
+		{Key: "key2", Value: []byte("value2")},
+	}
+	req := &pb.BatchPutReq{}
+	for _, kv := range kvs {
+		req.Reqs = append(req.Reqs, &pb.PutReq{Kv: kv})
+	}
+	_, err := e.client.BatchPut(ctx, req)
+	if err != nil {
+		t.Fatalf("BatchPut=%v; want nil error", err)
+	}
+
+	// the closest replica lost its content, e.g. by restart.
+	var lost []*slowServer
+	for _, kv := range kvs {
+		addrs, err := e.client.client.Replicas(ctx, kv.Key, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := e.servers[addrs[0]]
+		s.Cache.Delete(ctx, &pb.DeleteReq{Key: kv.Key})
+		lost = append(lost, s)
+	}
+
+	resp, err := e.client.Get(ctx, &pb.GetReq{Key: kvs[0].Key})
+	if err != nil {
+		t.Fatalf("Get(%s)=%v; want nil error", kvs[0].Key, err)
+	}
+	if !proto.Equal(resp.Kv, kvs[0]) {
+		t.Errorf("Get(%s)=%v; want %v", kvs[0].Key, resp.Kv, kvs[0])
+	}
+	bresp, err := e.client.BatchGet(ctx, &pb.BatchGetReq{
+		Keys: []string{kvs[1].Key, "unknown"},
+	})
+	if err != nil {
+		t.Fatalf("BatchGet=%v; want nil error", err)
+	}
+	want := &pb.BatchGetResp{
+		Resps: []*pb.GetResp{
+			{Kv: kvs[1], InMemory: true},
+			{},
+		},
+	}
+	if !proto.Equal(bresp, want) {
+		t.Errorf("BatchGet=%v; want %v", bresp, want)
+	}
+
+	for i, kv := range kvs {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			_, err := lost[i].Cache.Get(ctx, &pb.GetReq{Key: kv.Key})
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s is not repaired: %v", kv.Key, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestReplicatedClientHedge(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	e := newReplicaTestEnv(t, ctx, 3, 2)
+	defer e.close()
+
+	kv := &pb.KV{
+		Key:   "key",
+		Value: []byte("value"),
+	}
+	_, err := e.client.Put(ctx, &pb.PutReq{Kv: kv})
+	if err != nil {
+		t.Fatalf("Put(%s)=%v; want nil error", kv.Key, err)
+	}
+	addrs, err := e.client.client.Replicas(ctx, kv.Key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const delay = 5 * time.Second
+	e.servers[addrs[0]].setDelay(delay)
+
+	t0 := time.Now()
+	resp, err := e.client.Get(ctx, &pb.GetReq{Key: kv.Key})
+	if err != nil {
+		t.Fatalf("Get(%s)=%v; want nil error", kv.Key, err)
+	}
+	if d := time.Since(t0); d >= delay {
+		t.Errorf("Get(%s) took %s; want hedged response", kv.Key, d)
+	}
+	if !proto.Equal(resp.Kv, kv) {
+		t.Errorf("Get(%s)=%v; want %v", kv.Key, resp.Kv, kv)
+	}
+}
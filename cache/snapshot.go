@@ -0,0 +1,367 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.chromium.org/goma/server/log"
+)
+
+// snapshotMagic is magic of snapshot, which is a stream of key-value
+// pairs in memcache in recency order.
+//
+//	snapshot = magic record* trailer
+//	magic    = "GOMASNP1"
+//	record   = keyLen:u32 valueLen:u32 expire:i64 key value crc:u32
+//	trailer  = 0xffffffff count:u64 crc:u32
+//
+// Integers are big endian. expire is unix time in nanoseconds, or 0 for
+// no expiration. crc is CRC-32C of the preceding fields of the record
+// or trailer (excluding 0xffffffff of trailer).
+const snapshotMagic = "GOMASNP1"
+
+const snapshotTrailer = 0xffffffff
+
+// maxSnapshotRecordSize is max size of key and value in a record,
+// to detect corrupted length before allocating buffer for it.
+const maxSnapshotRecordSize = 1 << 30
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errSnapshotFull = errors.New("cache: memory is full")
+
+// SnapshotWriter writes snapshot.
+type SnapshotWriter struct {
+	w     *bufio.Writer
+	count uint64
+}
+
+// NewSnapshotWriter creates SnapshotWriter writing to w, and writes
+// snapshot header.
+func NewSnapshotWriter(w io.Writer) (*SnapshotWriter, error) {
+	bw := bufio.NewWriter(w)
+	_, err := bw.WriteString(snapshotMagic)
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotWriter{w: bw}, nil
+}
+
+// Write writes a key-value pair. zero expire means no expiration.
+func (sw *SnapshotWriter) Write(key string, value []byte, expire time.Time) error {
+	if len(key)+len(value) > maxSnapshotRecordSize {
+		return fmt.Errorf("cache: too large key-value for snapshot: %s", key)
+	}
+	var hdr [16]byte
+	binary.BigEndian.PutUint32(hdr[0:], uint32(len(key)))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(len(value)))
+	var exp int64
+	if !expire.IsZero() {
+		exp = expire.UnixNano()
+	}
+	binary.BigEndian.PutUint64(hdr[8:], uint64(exp))
+	crc := crc32.Update(0, crcTable, hdr[:])
+	crc = crc32.Update(crc, crcTable, []byte(key))
+	crc = crc32.Update(crc, crcTable, value)
+	for _, b := range [][]byte{hdr[:], []byte(key), value} {
+		_, err := sw.w.Write(b)
+		if err != nil {
+			return err
+		}
+	}
+	err := binary.Write(sw.w, binary.BigEndian, crc)
+	if err != nil {
+		return err
+	}
+	sw.count++
+	return nil
+}
+
+// Close writes snapshot trailer and flushes.
+// It doesn't close underlying writer.
+func (sw *SnapshotWriter) Close() error {
+	var buf [16]byte
+	binary.BigEndian.PutUint32(buf[0:], snapshotTrailer)
+	binary.BigEndian.PutUint64(buf[4:], sw.count)
+	binary.BigEndian.PutUint32(buf[12:], crc32.Checksum(buf[4:12], crcTable))
+	_, err := sw.w.Write(buf[:])
+	if err != nil {
+		return err
+	}
+	return sw.w.Flush()
+}
+
+// ScanSnapshot reads snapshot from r, and calls f for each key-value pair.
+// If f returns error, ScanSnapshot stops and returns the error.
+// It returns error if snapshot is corrupted or truncated, after calling
+// f for valid key-value pairs before the corruption.
+func ScanSnapshot(r io.Reader, f func(key string, value []byte, expire time.Time) error) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(snapshotMagic))
+	_, err := io.ReadFull(br, magic)
+	if err != nil {
+		return fmt.Errorf("cache: snapshot header: %v", err)
+	}
+	if string(magic) != snapshotMagic {
+		return fmt.Errorf("cache: not snapshot: magic=%q", magic)
+	}
+	var count uint64
+	for {
+		var hdr [16]byte
+		_, err := io.ReadFull(br, hdr[:4])
+		if err != nil {
+			return fmt.Errorf("cache: snapshot truncated after %d records: %v", count, err)
+		}
+		keyLen := binary.BigEndian.Uint32(hdr[0:])
+		if keyLen == snapshotTrailer {
+			_, err = io.ReadFull(br, hdr[4:])
+			if err != nil {
+				return fmt.Errorf("cache: snapshot trailer: %v", err)
+			}
+			if crc := binary.BigEndian.Uint32(hdr[12:]); crc != crc32.Checksum(hdr[4:12], crcTable) {
+				return errors.New("cache: snapshot trailer checksum mismatch")
+			}
+			if n := binary.BigEndian.Uint64(hdr[4:]); n != count {
+				return fmt.Errorf("cache: snapshot has %d records; want %d", count, n)
+			}
+			return nil
+		}
+		_, err = io.ReadFull(br, hdr[4:])
+		if err != nil {
+			return fmt.Errorf("cache: snapshot truncated in record %d: %v", count, err)
+		}
+		valueLen := binary.BigEndian.Uint32(hdr[4:])
+		if int64(keyLen)+int64(valueLen) > maxSnapshotRecordSize {
+			return fmt.Errorf("cache: snapshot bad record size in record %d: key=%d value=%d", count, keyLen, valueLen)
+		}
+		exp := int64(binary.BigEndian.Uint64(hdr[8:]))
+		buf := make([]byte, int(keyLen)+int(valueLen)+4)
+		_, err = io.ReadFull(br, buf)
+		if err != nil {
+			return fmt.Errorf("cache: snapshot truncated in record %d: %v", count, err)
+		}
+		key := buf[:keyLen]
+		value := buf[keyLen : keyLen+valueLen]
+		crc := crc32.Update(0, crcTable, hdr[:])
+		crc = crc32.Update(crc, crcTable, key)
+		crc = crc32.Update(crc, crcTable, value)
+		if crc != binary.BigEndian.Uint32(buf[keyLen+valueLen:]) {
+			return fmt.Errorf("cache: snapshot checksum mismatch in record %d", count)
+		}
+		var expire time.Time
+		if exp != 0 {
+			expire = time.Unix(0, exp)
+		}
+		err = f(string(key), value, expire)
+		if err != nil {
+			return err
+		}
+		count++
+	}
+}
+
+// WriteSnapshot writes snapshot of memcache to w.
+// It returns number of key-value pairs written.
+func (c *Cache) WriteSnapshot(ctx context.Context, w io.Writer) (int, error) {
+	sw, err := NewSnapshotWriter(w)
+	if err != nil {
+		return 0, err
+	}
+	entries := c.mem.snapshot()
+	for i, e := range entries {
+		if i%1024 == 0 && ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		err := sw.Write(e.key, e.value, e.expire)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(entries), sw.Close()
+}
+
+// LoadSnapshot loads snapshot from r in memcache, until memcache
+// becomes full.
+// It returns number of key-value pairs loaded.
+// If snapshot is corrupted, it returns error, but key-value pairs
+// before the corruption are loaded.
+func (c *Cache) LoadSnapshot(ctx context.Context, r io.Reader) (int, error) {
+	n := 0
+	err := ScanSnapshot(r, func(key string, value []byte, expire time.Time) error {
+		err := c.mem.restore(snapshotEntry{key: key, value: value, expire: expire})
+		switch err {
+		case nil:
+			n++
+		case errNoChange:
+			err = nil
+		}
+		return err
+	})
+	if err == errSnapshotFull {
+		err = nil
+	}
+	return n, err
+}
+
+// SaveSnapshotFile saves snapshot of memcache in fname.
+// Snapshot is written in temporary file and renamed to fname, so
+// fname is not partially written.
+func (c *Cache) SaveSnapshotFile(ctx context.Context, fname string) (int, error) {
+	logger := log.FromContext(ctx)
+	t := time.Now()
+	f, err := ioutil.TempFile(filepath.Dir(fname), filepath.Base(fname)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	n, err := c.WriteSnapshot(ctx, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fname)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return n, err
+	}
+	logger.Infof("saved snapshot %s: %d entries in %s", fname, n, time.Since(t))
+	return n, nil
+}
+
+// LoadSnapshotFile loads snapshot in fname.
+func (c *Cache) LoadSnapshotFile(ctx context.Context, fname string) (int, error) {
+	logger := log.FromContext(ctx)
+	t := time.Now()
+	f, err := os.Open(fname)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	n, err := c.LoadSnapshot(ctx, f)
+	logger.Infof("loaded snapshot %s: %d entries in %s: %v", fname, n, time.Since(t), err)
+	return n, err
+}
+
+// ReadSnapshotToken reads token for SnapshotHandler from fname.
+// Leading and trailing white spaces are trimmed.
+func ReadSnapshotToken(fname string) (string, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("empty snapshot token in %s", fname)
+	}
+	return token, nil
+}
+
+// SnapshotHandler returns http handler to stream snapshot of memcache.
+// Request must have "Authorization: Bearer <token>" header.
+// Empty token rejects all requests.
+// It should be registered on admin or monitoring mux, since snapshot
+// contains all cached values.
+func (c *Cache) SnapshotHandler(token string) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		ctx := req.Context()
+		logger := log.FromContext(ctx)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		n, err := c.WriteSnapshot(ctx, w)
+		if err != nil {
+			// response is partially written, so client will
+			// detect truncated snapshot.
+			logger.Errorf("snapshot: %d entries: %v", n, err)
+			return
+		}
+		logger.Infof("snapshot: %d entries", n)
+	})
+}
+
+// SnapshotLoop saves snapshot in fname every interval until ctx is done.
+func (c *Cache) SnapshotLoop(ctx context.Context, fname string, interval time.Duration) {
+	logger := log.FromContext(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		_, err := c.SaveSnapshotFile(ctx, fname)
+		if err != nil {
+			logger.Errorf("snapshot %s: %v", fname, err)
+		}
+	}
+}
+
+// SnapshotServer saves snapshot of memcache periodically, and on
+// shutdown. It implements server.Server, so snapshot is saved on SIGTERM
+// by server.Run.
+type SnapshotServer struct {
+	cache    *Cache
+	fname    string
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewSnapshotServer creates SnapshotServer to save snapshot of c in fname.
+// If interval is positive, snapshot is also saved every interval.
+func NewSnapshotServer(c *Cache, fname string, interval time.Duration) *SnapshotServer {
+	return &SnapshotServer{
+		cache:    c,
+		fname:    fname,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// ListenAndServe saves snapshot periodically until Shutdown is called.
+func (s *SnapshotServer) ListenAndServe() error {
+	if s.interval <= 0 {
+		<-s.done
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-s.done
+		cancel()
+	}()
+	s.cache.SnapshotLoop(ctx, s.fname, s.interval)
+	return nil
+}
+
+// Shutdown stops periodic snapshot, and saves snapshot.
+func (s *SnapshotServer) Shutdown(ctx context.Context) error {
+	close(s.done)
+	_, err := s.cache.SaveSnapshotFile(ctx, s.fname)
+	return err
+}
@@ -0,0 +1,268 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/log"
+)
+
+func snapshotKeys(t *testing.T, b []byte) []string {
+	t.Helper()
+	var keys []string
+	err := ScanSnapshot(bytes.NewReader(b), func(key string, value []byte, expire time.Time) error {
+		if got, want := string(value), "value-"+key; got != want {
+			t.Errorf("snapshot %s=%q; want %q", key, got, want)
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanSnapshot=%v; want nil error", err)
+	}
+	return keys
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	c, err := New(Config{
+		MaxBytes: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key%d", i)
+		c.mem.Put(ctx, key, []byte("value-"+key), time.Time{})
+	}
+	// key1 is most recently used.
+	c.mem.Get(ctx, "key1")
+	// expired entry is not in snapshot.
+	c.mem.Put(ctx, "expired", []byte("value-expired"), time.Now().Add(-time.Second))
+
+	var buf bytes.Buffer
+	n, err := c.WriteSnapshot(ctx, &buf)
+	if err != nil || n != 5 {
+		t.Fatalf("WriteSnapshot=%d, %v; want 5, nil", n, err)
+	}
+	want := []string{"key1", "key4", "key3", "key2", "key0"}
+	if got := snapshotKeys(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot keys=%q; want %q", got, want)
+	}
+
+	// restore up to MaxBytes. each entry is 4+10 bytes.
+	rc, err := New(Config{
+		MaxBytes: 14 * 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.mem.Put(ctx, "key4", []byte("value-key4"), time.Time{})
+	n, err = rc.LoadSnapshot(ctx, bytes.NewReader(buf.Bytes()))
+	if err != nil || n != 2 {
+		t.Errorf("LoadSnapshot=%d, %v; want 2, nil", n, err)
+	}
+	buf.Reset()
+	_, err = rc.WriteSnapshot(ctx, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"key4", "key1", "key3"}
+	if got := snapshotKeys(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored keys=%q; want %q", got, want)
+	}
+}
+
+func TestSnapshotCorrupted(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	var buf bytes.Buffer
+	sw, err := NewSnapshotWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("key%d", i)
+		err = sw.Write(key, []byte("value-"+key), time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = sw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := buf.Bytes()
+	// header(8) + record(16+4+10+4)
+	const recordSize = 34
+
+	for _, tc := range []struct {
+		desc  string
+		data  func() []byte
+		wantN int
+	}{
+		{
+			desc: "checksum mismatch",
+			data: func() []byte {
+				b := append([]byte{}, snapshot...)
+				b[8+recordSize+20] ^= 0xff
+				return b
+			},
+			wantN: 1,
+		},
+		{
+			desc: "truncated",
+			data: func() []byte {
+				return snapshot[:8+recordSize*2+10]
+			},
+			wantN: 2,
+		},
+		{
+			desc: "no trailer",
+			data: func() []byte {
+				return snapshot[:8+recordSize*3]
+			},
+			wantN: 3,
+		},
+		{
+			desc: "bad magic",
+			data: func() []byte {
+				b := append([]byte{}, snapshot...)
+				b[0] = 'X'
+				return b
+			},
+			wantN: 0,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c, err := New(Config{
+				MaxBytes: 1024 * 1024,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			n, err := c.LoadSnapshot(ctx, bytes.NewReader(tc.data()))
+			if err == nil || n != tc.wantN {
+				t.Errorf("LoadSnapshot=%d, %v; want %d, error", n, err, tc.wantN)
+			}
+		})
+	}
+}
+
+func TestSnapshotServer(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	dir, err := ioutil.TempDir("", "cache.TestSnapshotServer.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "snapshot")
+
+	c, err := New(Config{
+		MaxBytes: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mem.Put(ctx, "key", []byte("value-key"), time.Time{})
+
+	s := NewSnapshotServer(c, fname, time.Hour)
+	done := make(chan error)
+	go func() {
+		done <- s.ListenAndServe()
+	}()
+	err = s.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Shutdown=%v; want nil error", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ListenAndServe=%v; want nil error", err)
+	}
+
+	rc, err := New(Config{
+		MaxBytes: 1024 * 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := rc.LoadSnapshotFile(ctx, fname)
+	if err != nil || n != 1 {
+		t.Fatalf("LoadSnapshotFile=%d, %v; want 1, nil", n, err)
+	}
+	v, ok := rc.mem.Get(ctx, "key")
+	if !ok || string(v) != "value-key" {
+		t.Errorf("restored key=%q, %t; want %q, true", v, ok, "value-key")
+	}
+
+	// snapshot via http handler.
+	req := httptest.NewRequest("GET", "/cache/snapshot", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	rc.SnapshotHandler("token").ServeHTTP(rec, req)
+	if got, want := snapshotKeys(t, rec.Body.Bytes()), []string{"key"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot from handler=%q; want %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		token, auth string
+	}{
+		{token: "token"},
+		{token: "token", auth: "Bearer other"},
+		{token: "token", auth: "token"},
+		// empty token rejects all requests.
+		{auth: "Bearer "},
+	} {
+		req := httptest.NewRequest("GET", "/cache/snapshot", nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		rc.SnapshotHandler(tc.token).ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized || rec.Body.Len() > len("unauthorized\n") {
+			t.Errorf("SnapshotHandler(%q) with %q=%d; want %d", tc.token, tc.auth, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestReadSnapshotToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache-snapshot-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "token")
+	for _, tc := range []struct {
+		content string
+		want    string
+		wantErr bool
+	}{
+		{content: "secret\n", want: "secret"},
+		{content: " \n", wantErr: true},
+	} {
+		err := ioutil.WriteFile(fname, []byte(tc.content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadSnapshotToken(fname)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ReadSnapshotToken(%q)=%q, %v; want %q, error %t", tc.content, got, err, tc.want, tc.wantErr)
+		}
+	}
+	if got, err := ReadSnapshotToken(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("ReadSnapshotToken(missing)=%q, nil; want error", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"hash/fnv"
+)
+
+const (
+	sketchDepth = 4
+	// sketchMaxCount is max count of a counter, as 4-bit counter
+	// in TinyLFU.
+	sketchMaxCount = 15
+	// sketchResetFactor is the number of increments, relative to
+	// width, to halve all counters, so that old frequency decays.
+	sketchResetFactor = 10
+
+	minSketchWidth = 1 << 10
+	maxSketchWidth = 1 << 22
+	// avgEntryBytes is assumed average size of entries, to decide
+	// sketch width from max bytes.
+	avgEntryBytes = 4096
+)
+
+// countMinSketch is a count-min sketch to estimate frequency of keys,
+// used in TinyLFU admission policy.
+type countMinSketch struct {
+	rows  [sketchDepth][]uint8
+	mask  uint64
+	adds  int
+	reset int
+}
+
+func sketchWidth(maxBytes int64) int {
+	w := minSketchWidth
+	for w < maxSketchWidth && int64(w) < maxBytes/avgEntryBytes {
+		w <<= 1
+	}
+	return w
+}
+
+// newCountMinSketch creates new count-min sketch with width,
+// which must be power of 2.
+func newCountMinSketch(width int) *countMinSketch {
+	s := &countMinSketch{
+		mask:  uint64(width - 1),
+		reset: width * sketchResetFactor,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func (s *countMinSketch) indexes(key string) [sketchDepth]uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h1 := h.Sum64()
+	h2 := h1>>32 | 1
+	var idx [sketchDepth]uint64
+	for i := range idx {
+		idx[i] = (h1 + uint64(i)*h2) & s.mask
+	}
+	return idx
+}
+
+// Increment increments frequency of key.
+func (s *countMinSketch) Increment(key string) {
+	for i, idx := range s.indexes(key) {
+		if s.rows[i][idx] < sketchMaxCount {
+			s.rows[i][idx]++
+		}
+	}
+	s.adds++
+	if s.adds >= s.reset {
+		s.halve()
+	}
+}
+
+// Estimate returns estimated frequency of key.
+func (s *countMinSketch) Estimate(key string) uint8 {
+	min := uint8(sketchMaxCount)
+	for i, idx := range s.indexes(key) {
+		if v := s.rows[i][idx]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+func (s *countMinSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.adds /= 2
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	s := newCountMinSketch(1024)
+	for i := 0; i < 5; i++ {
+		s.Increment("hot")
+	}
+	s.Increment("warm")
+	for _, tc := range []struct {
+		key  string
+		want uint8
+	}{
+		{key: "hot", want: 5},
+		{key: "warm", want: 1},
+		{key: "cold", want: 0},
+	} {
+		// count-min sketch never underestimates, and with few keys
+		// collision is unlikely.
+		if got := s.Estimate(tc.key); got != tc.want {
+			t.Errorf("Estimate(%q)=%d; want %d", tc.key, got, tc.want)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		s.Increment("hot")
+	}
+	if got, want := s.Estimate("hot"), uint8(sketchMaxCount); got != want {
+		t.Errorf("Estimate(hot)=%d; want %d (saturated)", got, want)
+	}
+}
+
+func TestCountMinSketchReset(t *testing.T) {
+	s := newCountMinSketch(1024)
+	// reset early to avoid collision of many keys.
+	s.reset = 16
+	for i := 0; i < 8; i++ {
+		s.Increment("hot")
+	}
+	for i := 0; i < 8; i++ {
+		s.Increment(fmt.Sprintf("key%d", i))
+	}
+	if got, want := s.adds, 8; got != want {
+		t.Errorf("adds=%d after reset; want %d", got, want)
+	}
+	if got, want := s.Estimate("hot"), uint8(4); got != want {
+		t.Errorf("Estimate(hot)=%d after reset; want %d", got, want)
+	}
+}
+
+func TestSketchWidth(t *testing.T) {
+	for _, tc := range []struct {
+		maxBytes int64
+		want     int
+	}{
+		{maxBytes: 0, want: minSketchWidth},
+		{maxBytes: 1024 * 1024, want: minSketchWidth},
+		{maxBytes: 1024 * 1024 * 1024, want: 1 << 18},
+		{maxBytes: 1 << 50, want: maxSketchWidth},
+	} {
+		if got := sketchWidth(tc.maxBytes); got != tc.want {
+			t.Errorf("sketchWidth(%d)=%d; want %d", tc.maxBytes, got, tc.want)
+		}
+	}
+}
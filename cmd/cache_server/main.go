@@ -11,13 +11,17 @@ package main
 import (
 	"context"
 	"flag"
+	"os"
 	"runtime/debug"
 
-	"cloud.google.com/go/storage"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/zpages"
 	"google.golang.org/api/option"
 
+	"go.chromium.org/goma/server/blobstore"
 	"go.chromium.org/goma/server/cache"
+	"go.chromium.org/goma/server/cache/codec"
+	"go.chromium.org/goma/server/cache/gcs"
 	"go.chromium.org/goma/server/log"
 	"go.chromium.org/goma/server/profiler"
 	pb "go.chromium.org/goma/server/proto/cache"
@@ -31,8 +35,22 @@ import (
 var (
 	port               = flag.Int("port", 5050, "rpc port")
 	mport              = flag.Int("mport", 8081, "monitor port")
-	bucket             = flag.String("bucket", "", "backing store bucket")
+	bucket             = flag.String("bucket", "", "backing store bucket. gs://<bucket>, s3://<bucket> or file://<dir>. name without scheme is cloud storage bucket")
+	s3Endpoint         = flag.String("s3-endpoint", "", "endpoint URL of S3 compatible storage for s3:// bucket, e.g. http://minio:9000. empty uses AWS")
+	s3Region           = flag.String("s3-region", "", "region of s3:// bucket")
 	serviceAccountFile = flag.String("service-account-file", "", "service account json file")
+	bucketMinBytes     = flag.Int64("bucket-min-bytes", 0, "minimum size of value to put in bucket. smaller value is only cached in memory and disk")
+	bucketMaxBytes     = flag.Int64("bucket-max-bytes", 0, "maximum size of value to put in bucket. 0 means no limit")
+	bucketAdmitHits    = flag.Int("bucket-admit-hits", 1, "number of puts of a key to put it in bucket. 2 enables second-hit caching")
+	bucketPutRate      = flag.Float64("bucket-put-rate", 0, "max rate of puts to bucket per requester per second. 0 means no limit")
+	bucketPutBurst     = flag.Int("bucket-put-burst", 100, "max burst of puts to bucket per requester, used with --bucket-put-rate")
+	windowRatio        = flag.Float64("window-ratio", 0, "ratio of memory used for window LRU of TinyLFU admission policy. 0 disables admission policy")
+	cacheDir           = flag.String("cache-dir", "", "local disk cache directory, used between memory and bucket. empty disables disk cache")
+	maxDiskBytes       = flag.Int64("max-disk-bytes", 64*1024*1024*1024, "maximum bytes of local disk cache. 0 means unlimited")
+	snapshotFile       = flag.String("snapshot-file", "", "memory cache snapshot file, loaded at startup and saved on shutdown. empty disables snapshot")
+	compression        = flag.String("compression", "none", `compression format of values stored in memory, disk and bucket. "none", "flate" or "zstd". values stored in other format are still readable.`)
+	snapshotInterval   = flag.Duration("snapshot-interval", 0, "interval to save memory cache snapshot periodically. 0 saves only on shutdown")
+	snapshotTokenFile  = flag.String("snapshot-token-file", "", "file of bearer token to fetch memory cache snapshot from /cache/snapshot on monitor port. empty disables the endpoint")
 	// config = flag.String("config", "", "config file")
 
 	traceProjectID = flag.String("trace-project-id", "", "project id for cloud tracing")
@@ -53,19 +71,49 @@ func main() {
 	if err != nil {
 		logger.Fatal(err)
 	}
+	err = view.Register(cache.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	err = view.Register(codec.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	err = view.Register(gcs.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	format, err := codec.ParseFormat(*compression)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
-	var bucketHandle *storage.BucketHandle
+	var bkt blobstore.Bucket
 	if *bucket != "" {
 		var opts []option.ClientOption
 		if *serviceAccountFile != "" {
 			opts = append(opts, option.WithServiceAccountFile(*serviceAccountFile))
 		}
-		gsclient, err := storage.NewClient(ctx, opts...)
+		bkt, err = blobstore.Open(ctx, *bucket, blobstore.Options{
+			GCSClientOptions: opts,
+			S3Endpoint:       *s3Endpoint,
+			S3Region:         *s3Region,
+		})
 		if err != nil {
-			logger.Fatalf("storage client failed: %v", err)
+			logger.Fatalf("bucket %s failed: %v", *bucket, err)
 		}
-		defer gsclient.Close()
-		bucketHandle = gsclient.Bucket(*bucket)
+	}
+	admission := gcs.AdmissionControllers{
+		gcs.SizeAdmission{
+			MinBytes: *bucketMinBytes,
+			MaxBytes: *bucketMaxBytes,
+		},
+	}
+	if *bucketAdmitHits > 1 {
+		admission = append(admission, gcs.NewSecondHitAdmission(*bucketAdmitHits, 0))
+	}
+	if *bucketPutRate > 0 {
+		admission = append(admission, gcs.NewRateAdmission(*bucketPutRate, *bucketPutBurst))
 	}
 
 	s, err := server.NewGRPC(*port)
@@ -73,15 +121,39 @@ func main() {
 		logger.Fatal(err)
 	}
 	c, err := cache.New(cache.Config{
-		MaxBytes: 1 * 1024 * 1024 * 1024,
-		Bucket:   bucketHandle,
+		MaxBytes:            1 * 1024 * 1024 * 1024,
+		WindowRatio:         *windowRatio,
+		Dir:                 *cacheDir,
+		MaxDiskBytes:        *maxDiskBytes,
+		Bucket:              bkt,
+		AdmissionController: admission,
+		Codec:               &codec.Codec{Format: format},
 	})
 	if err != nil {
 		logger.Fatalf("failed to create cache client: %v", err)
 	}
 	pb.RegisterCacheServiceServer(s.Server, c)
 
+	// closes disk cache on shutdown.
+	servers := []server.Server{s, server.NewCloser(c)}
+	if *snapshotFile != "" {
+		_, err := c.LoadSnapshotFile(ctx, *snapshotFile)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Warnf("failed to load snapshot %s: %v", *snapshotFile, err)
+		}
+		servers = append(servers, cache.NewSnapshotServer(c, *snapshotFile, *snapshotInterval))
+	}
+	if *snapshotTokenFile != "" {
+		token, err := cache.ReadSnapshotToken(*snapshotTokenFile)
+		if err != nil {
+			logger.Fatalf("snapshot token: %v", err)
+		}
+		// DefaultServeMux is served only on monitor port.
+		http.Handle("/cache/snapshot", c.SnapshotHandler(token))
+	}
+
 	hs := server.NewHTTP(*mport, nil)
 	zpages.Handle(http.DefaultServeMux, "/debug")
-	server.Run(ctx, s, hs)
+	servers = append(servers, hs)
+	server.Run(ctx, servers...)
 }
@@ -17,8 +17,10 @@ import (
 	"io"
 	"math/rand"
 	"net/http"
+	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
@@ -26,7 +28,6 @@ import (
 	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/storage"
 	"github.com/golang/protobuf/proto"
-	"github.com/googleapis/google-cloud-go-testing/storage/stiface"
 	"go.opencensus.io/plugin/ocgrpc"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
@@ -38,8 +39,10 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
+	"go.chromium.org/goma/server/blobstore"
 	"go.chromium.org/goma/server/cache/redis"
 	"go.chromium.org/goma/server/command"
+	"go.chromium.org/goma/server/command/pathconv"
 	"go.chromium.org/goma/server/exec"
 	"go.chromium.org/goma/server/file"
 	"go.chromium.org/goma/server/log"
@@ -50,6 +53,7 @@ import (
 	filepb "go.chromium.org/goma/server/proto/file"
 	"go.chromium.org/goma/server/remoteexec"
 	"go.chromium.org/goma/server/remoteexec/digest"
+	"go.chromium.org/goma/server/remoteexec/merkletree"
 	"go.chromium.org/goma/server/rpc"
 	"go.chromium.org/goma/server/server"
 )
@@ -61,6 +65,7 @@ var (
 	configMapURI          = flag.String("configmap_uri", "", "deprecated: configmap uri. e.g. gs://$project-toolchain-config/$name.config, text proto of command.ConfigMap.")
 	configMap             = flag.String("configmap", "", "configmap text proto")
 	toolchainConfigBucket = flag.String("toolchain-config-bucket", "", "cloud storage bucket for toolchain config")
+	toolchainConfigDir    = flag.String("toolchain-config-dir", "", "local directory for toolchain config, which has the same layout as toolchain-config-bucket")
 	configMapFile         = flag.String("configmap_file", "", "filename for configmap text proto")
 
 	traceProjectID     = flag.String("trace-project-id", "", "project id for cloud tracing")
@@ -77,6 +82,8 @@ var (
 	cmdFilesBucket      = flag.String("cmd-files-bucket", "", "cloud storage bucket for command binary files")
 	fetchConfigParallel = flag.Bool("fetch-config-parallel", true, "fetch toolchain configs in parallel")
 
+	rolloutEvaluateInterval = flag.Duration("rollout-evaluate-interval", 5*time.Minute, "interval to evaluate candidate toolchain configs in staged rollout")
+
 	// Needed for b/120582303, but will be deprecated by b/80508682.
 	fileLookupConcurrency = flag.Int("file-lookup-concurrency", 20, "concurrency to look up files from file-server")
 
@@ -85,6 +92,21 @@ var (
 	// thinlto would upload *.o and *.thinlto.
 	// rbe-staging1 uses 2.2M keys (< 512MB memory usage in redis).
 	maxDigestCacheEntries = flag.Int("max-digest-cache-entries", 2e6, "maximum entries in in-memory digest cache. 0 means unimited")
+	digestCacheFile       = flag.String("digest-cache-file", "", "filename of local disk tier of digest cache. empty disables disk tier")
+	digestCacheFileBytes  = flag.Int64("digest-cache-file-max-bytes", 512*1024*1024, "maximum bytes of digest cache file. digest cache entries in the file are also kept in memory")
+
+	maxMerkleTreeCacheEntries = flag.Int("max-merkletree-cache-entries", 1e5, "maximum entries in in-memory merkletree subtree cache for toolchain files. negative disables the cache. 0 means unlimited")
+
+	windowsMountRoot = flag.String("windows-mount-root", "", `server directory where windows client's drives are mounted, e.g. "/mnt/win" for C:\ on "/mnt/win/c". empty uses "/mnt/win"`)
+	prefixMap        = flag.String("prefix-map", "", "comma separated <client>=<server> path prefix mappings for posix client paths")
+
+	maxInputs          = flag.Int("max-inputs", 0, "maximum number of inputs in a request. 0 means unlimited")
+	maxInputTotalBytes = flag.Int64("max-input-total-bytes", 0, "maximum total bytes of inputs in a request. 0 means unlimited")
+	maxInputTreeDepth  = flag.Int("max-input-tree-depth", 0, "maximum depth of input paths in a request. 0 means unlimited")
+	maxInputFileBytes  = flag.Int64("max-input-file-bytes", 0, "maximum bytes of a single input file in a request. 0 means unlimited")
+
+	crashReproducerDir    = flag.String("crash-reproducer-dir", "", "local directory to store reproducer bundles of compiler crashes in remote")
+	crashReproducerBucket = flag.String("crash-reproducer-bucket", "", "cloud storage bucket to store reproducer bundles of compiler crashes in remote. takes precedence over --crash-reproducer-dir")
 
 	// nsjail is applied in hardened request.
 	// note windows and chroot reqs are out of scope for the ratio.
@@ -153,20 +175,11 @@ func configMapToConfigResp(ctx context.Context, cm *cmdpb.ConfigMap) *cmdpb.Conf
 		VersionId: time.Now().UTC().Format(time.RFC3339),
 	}
 	for _, rt := range cm.Runtimes {
-		c := &cmdpb.Config{
-			Target: &cmdpb.Target{
-				Addr: *remoteexecAddr,
-			},
-			BuildInfo:          &cmdpb.BuildInfo{},
-			RemoteexecPlatform: &cmdpb.RemoteexecPlatform{},
-			Dimensions:         rt.PlatformRuntimeConfig.Dimensions,
-		}
-		for _, p := range rt.Platform.Properties {
-			c.RemoteexecPlatform.Properties = append(c.RemoteexecPlatform.Properties, &cmdpb.RemoteexecPlatform_Property{
-				Name:  p.Name,
-				Value: p.Value,
-			})
+		c := command.PlatformConfig(rt)
+		c.Target = &cmdpb.Target{
+			Addr: *remoteexecAddr,
 		}
+		c.BuildInfo = &cmdpb.BuildInfo{}
 		resp.Configs = append(resp.Configs, c)
 	}
 	return resp
@@ -197,6 +210,42 @@ func (b cmdStorageBucket) Open(ctx context.Context, hash string) (io.ReadCloser,
 	return b.Bucket.Object(path.Join("sha256", hash)).NewReader(ctx)
 }
 
+// reproducerBucket is a remoteexec.ReproducerStore in cloud storage bucket.
+type reproducerBucket struct {
+	Bucket *storage.BucketHandle
+}
+
+func (b reproducerBucket) Create(ctx context.Context, name string) (remoteexec.ReproducerWriter, error) {
+	obj := b.Bucket.Object(path.Join("reproducers", name))
+	_, err := obj.Attrs(ctx)
+	if err == nil {
+		return nil, &os.PathError{Op: "create", Path: obj.ObjectName(), Err: os.ErrExist}
+	}
+	if err != storage.ErrObjectNotExist {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := obj.If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	w.ContentType = "application/gzip"
+	return reproducerObjWriter{Writer: w, cancel: cancel}, nil
+}
+
+type reproducerObjWriter struct {
+	*storage.Writer
+	cancel func()
+}
+
+func (w reproducerObjWriter) Close() error {
+	defer w.cancel()
+	return w.Writer.Close()
+}
+
+// Abort cancels the upload, so the object won't be created.
+func (w reproducerObjWriter) Abort() {
+	w.cancel()
+	w.Writer.Close()
+}
+
 type nullServer struct {
 	ch chan error
 }
@@ -212,8 +261,12 @@ type configServer struct {
 	configmap command.ConfigMap
 	psclient  *pubsub.Client
 	w         command.ConfigMapWatcher
-	loader    *command.ConfigMapLoader
 	cancel    func()
+
+	// mu protects loader, which is used by config update and
+	// staged rollout evaluation.
+	mu     sync.Mutex
+	loader *command.ConfigMapLoader
 }
 
 func newConfigServer(ctx context.Context, inventory *exec.Inventory, bucket, configMapFile string, cm *cmdpb.ConfigMap, gsclient *storage.Client, opts ...option.ClientOption) (*configServer, error) {
@@ -231,20 +284,48 @@ func newConfigServer(ctx context.Context, inventory *exec.Inventory, bucket, con
 	if err != nil {
 		return nil, fmt.Errorf("pubsub client failed: %v", err)
 	}
-	cs.configmap = command.ConfigMapBucket{
+	store := blobstore.NewGCS(gsclient, bucket)
+	store.PubsubClient = cs.psclient
+	store.SubscriberID = fmt.Sprintf("toolchain-config-%s-%s", server.ClusterName(ctx), server.HostName(ctx))
+	configmap := command.ConfigMapBucket{
 		URI:            fmt.Sprintf("gs://%s/", bucket),
 		ConfigMap:      cm,
 		ConfigMapFile:  configMapFile,
-		StorageClient:  stiface.AdaptClient(gsclient),
-		PubsubClient:   cs.psclient,
-		SubscriberID:   fmt.Sprintf("toolchain-config-%s-%s", server.ClusterName(ctx), server.HostName(ctx)),
+		Store:          store,
 		RemoteexecAddr: *remoteexecAddr,
 	}
+	cs.configmap = configmap
 	cs.w = cs.configmap.Watcher(ctx)
 	cs.loader = &command.ConfigMapLoader{
 		ConfigMap: cs.configmap,
 		ConfigLoader: command.ConfigLoader{
-			StorageClient:  stiface.AdaptClient(gsclient),
+			Store:          store,
+			EnableParallel: *fetchConfigParallel,
+		},
+	}
+	return cs, nil
+}
+
+func newConfigServerDir(ctx context.Context, inventory *exec.Inventory, dir, configMapFile string, cm *cmdpb.ConfigMap) (*configServer, error) {
+	cs := &configServer{
+		inventory: inventory,
+	}
+	configmap := command.ConfigMapDir{
+		Dir:            dir,
+		ConfigMap:      cm,
+		ConfigMapFile:  configMapFile,
+		RemoteexecAddr: *remoteexecAddr,
+	}
+	store, err := configmap.Store()
+	if err != nil {
+		return nil, err
+	}
+	cs.configmap = configmap
+	cs.w = cs.configmap.Watcher(ctx)
+	cs.loader = &command.ConfigMapLoader{
+		ConfigMap: cs.configmap,
+		ConfigLoader: command.ConfigLoader{
+			Store:          store,
 			EnableParallel: *fetchConfigParallel,
 		},
 	}
@@ -253,6 +334,8 @@ func newConfigServer(ctx context.Context, inventory *exec.Inventory, bucket, con
 
 func (cs *configServer) configure(ctx context.Context) error {
 	logger := log.FromContext(ctx)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
 	id, err := configureByLoader(ctx, cs.loader, cs.inventory)
 	if err != nil {
 		if err != command.ErrNoUpdate {
@@ -266,10 +349,61 @@ func (cs *configServer) configure(ctx context.Context) error {
 	return nil
 }
 
+// evaluateRollouts promotes or rolls back candidate configs
+// in staged rollout, and configures inventory if any.
+func (cs *configServer) evaluateRollouts(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	decisions := cs.inventory.EvaluateRollouts(ctx)
+	if len(decisions) == 0 {
+		return
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	updated := false
+	for _, d := range decisions {
+		var ok bool
+		if d.Promote {
+			ok = cs.loader.ConfigStore.Promote(d.Runtime, d.Seq)
+		} else {
+			ok = cs.loader.ConfigStore.Rollback(d.Runtime, d.Seq)
+		}
+		if !ok {
+			logger.Warnf("rollout: candidate was updated. ignore %s", d)
+			continue
+		}
+		logger.Infof("rollout: %s", d)
+		updated = true
+	}
+	if !updated {
+		return
+	}
+	resp := cs.loader.ConfigStore.ConfigResp()
+	err := cs.inventory.Configure(ctx, resp)
+	if err != nil {
+		logger.Errorf("rollout: failed to configure %s: %v", resp.VersionId, err)
+		return
+	}
+	logger.Infof("rollout: configure %s", resp.VersionId)
+}
+
 func (cs *configServer) ListenAndServe() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	cs.cancel = cancel
 	logger := log.FromContext(ctx)
+	if *rolloutEvaluateInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(*rolloutEvaluateInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					cs.evaluateRollouts(ctx)
+				}
+			}
+		}()
+	}
 	for {
 		logger.Infof("waiting for config update...")
 		err := cs.w.Next(ctx)
@@ -301,19 +435,40 @@ func (cs *configServer) Shutdown(ctx context.Context) error {
 	return cs.w.Close()
 }
 
-func newDigestCache(ctx context.Context) remoteexec.DigestCache {
+// newDigestCache creates digest cache.
+// It also returns disk tier of the cache, which should be closed on shutdown,
+// or nil if disk tier is not used.
+func newDigestCache(ctx context.Context) (remoteexec.DigestCache, *digest.DiskTier) {
 	logger := log.FromContext(ctx)
+	var c *digest.Cache
 	addr, err := redis.AddrFromEnv()
 	if err != nil {
 		logger.Warnf("redis disabled for gomafile-digest: %v", err)
-		return digest.NewCache(nil, *maxDigestCacheEntries)
-	}
-	logger.Infof("redis enabled for gomafile-digest: %v idle=%d active=%d", addr, *redisMaxIdleConns, *redisMaxActiveConns)
-	return digest.NewCache(redis.NewClient(ctx, addr, redis.Opts{
-		Prefix:         "gomafile-digest:",
-		MaxIdleConns:   *redisMaxIdleConns,
-		MaxActiveConns: *redisMaxActiveConns,
-	}), *maxDigestCacheEntries)
+		c = digest.NewCache(nil, *maxDigestCacheEntries)
+	} else {
+		mode, masterName, err := redis.ModeFromEnv()
+		if err != nil {
+			logger.Fatalf("redis mode: %v", err)
+		}
+		logger.Infof("redis enabled for gomafile-digest: %v mode=%s idle=%d active=%d", addr, mode, *redisMaxIdleConns, *redisMaxActiveConns)
+		c = digest.NewCache(redis.NewClient(ctx, addr, redis.Opts{
+			Prefix:         "gomafile-digest:",
+			MaxIdleConns:   *redisMaxIdleConns,
+			MaxActiveConns: *redisMaxActiveConns,
+			Mode:           mode,
+			MasterName:     masterName,
+		}), *maxDigestCacheEntries)
+	}
+	if *digestCacheFile != "" {
+		d, err := digest.OpenDiskTier(*digestCacheFile, *digestCacheFileBytes)
+		if err != nil {
+			logger.Fatalf("digest cache file %s: %v", *digestCacheFile, err)
+		}
+		logger.Infof("digest cache file %s: %d entries", *digestCacheFile, d.Len())
+		c.SetDiskTier(d)
+		return c, d
+	}
+	return c, nil
 }
 
 func main() {
@@ -326,6 +481,7 @@ func main() {
 	flag.DurationVar(&spanTimeout.UploadBlobs, "exec-upload-blobs-timeout", spanTimeout.UploadBlobs, "timeout of exec-upload-blobs")
 	flag.DurationVar(&spanTimeout.Execute, "exec-execute-timeout", spanTimeout.Execute, "timeout of exec-execute")
 	flag.DurationVar(&spanTimeout.Response, "exec-response-timeout", spanTimeout.Response, "timeout of exec-response")
+	flag.DurationVar(&spanTimeout.Reproducer, "exec-reproducer-timeout", spanTimeout.Reproducer, "timeout of exec-reproducer")
 	flag.Parse()
 	rand.Seed(time.Now().UnixNano())
 
@@ -336,8 +492,8 @@ func main() {
 	logger := log.FromContext(ctx)
 	defer logger.Sync()
 
-	if (*toolchainConfigBucket == "" || *configMapFile == "") && *configMap == "" {
-		logger.Fatalf("--toolchain-config-bucket,--configmap_file or --configmap must be given")
+	if ((*toolchainConfigBucket == "" && *toolchainConfigDir == "") || *configMapFile == "") && *configMap == "" {
+		logger.Fatalf("--toolchain-config-bucket or --toolchain-config-dir,--configmap_file or --configmap must be given")
 	}
 	if *remoteexecAddr == "" {
 		logger.Fatalf("--remoteexec-addr must be given")
@@ -360,6 +516,14 @@ func main() {
 	if err != nil {
 		logger.Fatal(err)
 	}
+	err = view.Register(exec.DefaultFallbackViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	err = view.Register(exec.DefaultRolloutViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
 	err = view.Register(remoteexec.DefaultViews...)
 	if err != nil {
 		logger.Fatal(err)
@@ -368,6 +532,10 @@ func main() {
 	if err != nil {
 		logger.Fatal(err)
 	}
+	err = view.Register(merkletree.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
 	trace.ApplyConfig(trace.Config{
 		DefaultSampler: server.NewLimitedSampler(server.DefaultTraceFraction, server.DefaultTraceQPS),
 	})
@@ -388,8 +556,8 @@ func main() {
 
 	var gsclient *storage.Client
 	var opts []option.ClientOption
-	if *toolchainConfigBucket != "" || *cmdFilesBucket != "" {
-		logger.Infof("toolchain-config-bucket or cmd-files-bucket is specified. use cloud storage")
+	if *toolchainConfigBucket != "" || *cmdFilesBucket != "" || *crashReproducerBucket != "" {
+		logger.Infof("toolchain-config-bucket, cmd-files-bucket or crash-reproducer-bucket is specified. use cloud storage")
 		if *serviceAccountFile != "" {
 			opts = append(opts, option.WithServiceAccountFile(*serviceAccountFile))
 		}
@@ -421,6 +589,11 @@ func main() {
 	casBlobLookupConcurrency := 20
 	outputFileConcurrency := 20
 	logger.Infof("span timeout = %#v", spanTimeout)
+	prefixMappings, err := pathconv.ParsePrefixMappings(*prefixMap)
+	if err != nil {
+		logger.Fatalf("--prefix-map: %v", err)
+	}
+	digestCache, digestDisk := newDigestCache(ctx)
 	re := &remoteexec.Adapter{
 		InstancePrefix:   *remoteInstancePrefix,
 		InstanceBaseName: *remoteInstanceBaseName,
@@ -433,7 +606,7 @@ func main() {
 			},
 		},
 		GomaFile:    filepb.NewFileServiceClient(fileConn),
-		DigestCache: newDigestCache(ctx),
+		DigestCache: digestCache,
 		ToolDetails: &rpb.ToolDetails{
 			ToolName:    "goma/exec-server",
 			ToolVersion: "0.0.0-experimental",
@@ -444,8 +617,21 @@ func main() {
 		HardeningRatio:    *experimentHardeningRatio,
 		NsjailRatio:       *experimentNsjailRatio,
 		DisableHardenings: strings.Split(*disableHardenings, ","),
+		InputLimits: remoteexec.InputLimits{
+			MaxInputs:     *maxInputs,
+			MaxTotalBytes: *maxInputTotalBytes,
+			MaxDepth:      *maxInputTreeDepth,
+			MaxFileBytes:  *maxInputFileBytes,
+		},
+		PathConverterOptions: pathconv.Options{
+			WindowsMountRoot: *windowsMountRoot,
+			PrefixMappings:   prefixMappings,
+		},
 	}
 	logger.Infof("hardeniong=%f nsjail=%f", re.HardeningRatio, re.NsjailRatio)
+	if *maxMerkleTreeCacheEntries >= 0 {
+		re.MerkleTreeCache = merkletree.NewCache(*maxMerkleTreeCacheEntries)
+	}
 
 	if *cmdFilesBucket == "" {
 		logger.Warnf("--cmd-files-bucket is not given. support only ARBITRARY_TOOLCHAIN_SUPPORT enabled client")
@@ -455,6 +641,16 @@ func main() {
 			Bucket: gsclient.Bucket(*cmdFilesBucket),
 		}
 	}
+	switch {
+	case *crashReproducerBucket != "":
+		logger.Infof("use gs://%s for crash reproducers", *crashReproducerBucket)
+		re.CrashReproducers = reproducerBucket{
+			Bucket: gsclient.Bucket(*crashReproducerBucket),
+		}
+	case *crashReproducerDir != "":
+		logger.Infof("use %s for crash reproducers", *crashReproducerDir)
+		re.CrashReproducers = remoteexec.ReproducerDir(*crashReproducerDir)
+	}
 
 	inventory := &re.Inventory
 
@@ -505,6 +701,17 @@ func main() {
 			ready <- cs.configure(ctx)
 		}()
 		confServer = cs
+
+	case *toolchainConfigDir != "":
+		logger.Infof("use %s for toolchain config", *toolchainConfigDir)
+		cs, err := newConfigServerDir(ctx, inventory, *toolchainConfigDir, *configMapFile, &cmdpb.ConfigMap{})
+		if err != nil {
+			logger.Fatalf("configServer: %v", err)
+		}
+		go func() {
+			ready <- cs.configure(ctx)
+		}()
+		confServer = cs
 	}
 	http.Handle("/configz", inventory)
 	pb.RegisterExecServiceServer(s.Server, re)
@@ -533,5 +740,10 @@ func main() {
 	}
 	hs := server.NewHTTP(*mport, nil)
 	zpages.Handle(http.DefaultServeMux, "/debug")
-	server.Run(ctx, s, hs, confServer)
+	servers := []server.Server{s, hs, confServer}
+	if digestDisk != nil {
+		// flushes digest cache file on shutdown.
+		servers = append(servers, server.NewCloser(digestDisk))
+	}
+	server.Run(ctx, servers...)
 }
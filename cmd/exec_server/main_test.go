@@ -0,0 +1,95 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	"go.chromium.org/goma/server/exec"
+	"go.chromium.org/goma/server/log"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func TestConfigMapToConfigRespACL(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	cm := &cmdpb.ConfigMap{
+		Runtimes: []*cmdpb.RuntimeConfig{
+			{
+				Name: "linux",
+				Platform: &cmdpb.Platform{
+					Properties: []*cmdpb.Platform_Property{
+						{
+							Name:  "container-image",
+							Value: "docker://gcr.io/example/image@sha256:abc",
+						},
+					},
+				},
+				PlatformRuntimeConfig: &cmdpb.PlatformRuntimeConfig{
+					Dimensions: []string{"os:linux"},
+				},
+				Acl: &cmdpb.ACL{
+					AllowedGroups: []string{"admins"},
+				},
+				InputLimits: &cmdpb.InputLimits{
+					MaxInputs: 100,
+				},
+			},
+		},
+	}
+	resp := configMapToConfigResp(ctx, cm)
+	if len(resp.Configs) != 1 {
+		t.Fatalf("configMapToConfigResp: configs=%d; want 1", len(resp.Configs))
+	}
+	if got, want := resp.Configs[0].GetInputLimits(), cm.Runtimes[0].InputLimits; !proto.Equal(got, want) {
+		t.Errorf("input limits=%v; want %v", got, want)
+	}
+
+	in := &exec.Inventory{}
+	err := in.Configure(ctx, resp)
+	if err != nil {
+		t.Fatalf("Configure()=%v; want nil error", err)
+	}
+	req := func() *gomapb.ExecReq {
+		return &gomapb.ExecReq{
+			CommandSpec: &gomapb.CommandSpec{
+				Name:    proto.String("clang"),
+				Version: proto.String("12"),
+				Target:  proto.String("x86_64-unknown-linux-gnu"),
+			},
+			RequesterInfo: &gomapb.RequesterInfo{
+				Dimensions: []string{"os:linux"},
+				PathStyle:  gomapb.RequesterInfo_POSIX_STYLE.Enum(),
+			},
+			ToolchainIncluded: proto.Bool(true),
+		}
+	}
+
+	for _, tc := range []struct {
+		group   string
+		allowed bool
+	}{
+		{group: "admins", allowed: true},
+		{group: "users"},
+	} {
+		uctx := enduser.NewContext(ctx, enduser.New("someone@example.com", tc.group, nil))
+		eresp := &gomapb.ExecResp{}
+		_, _, err := in.Pick(uctx, req(), eresp)
+		if tc.allowed {
+			if err != nil {
+				t.Errorf("Pick(group=%s)=_, _, %v; want nil error", tc.group, err)
+			}
+			continue
+		}
+		if err == nil || eresp.GetBadRequestReasonCode() != gomapb.ExecResp_ACCESS_DENIED {
+			t.Errorf("Pick(group=%s)=_, _, %v; reason=%v; want ACCESS_DENIED", tc.group, err, eresp.GetBadRequestReasonCode())
+		}
+	}
+}
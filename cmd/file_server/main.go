@@ -13,7 +13,7 @@ import (
 	"flag"
 	"fmt"
 
-	"cloud.google.com/go/storage"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/trace"
 	k8sapi "golang.org/x/build/kubernetes/api"
 	"google.golang.org/api/option"
@@ -21,7 +21,9 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"go.chromium.org/goma/server/blobstore"
 	"go.chromium.org/goma/server/cache"
+	"go.chromium.org/goma/server/cache/codec"
 	"go.chromium.org/goma/server/cache/gcs"
 	"go.chromium.org/goma/server/cache/redis"
 	"go.chromium.org/goma/server/file"
@@ -38,7 +40,14 @@ var (
 	port      = flag.Int("port", 5050, "rpc port")
 	mport     = flag.Int("mport", 8081, "monitor port")
 	cacheAddr = flag.String("file-cache-addr", "", "cache server address")
-	bucket    = flag.String("bucket", "", "backing store bucket")
+
+	cacheReplicas   = flag.Int("file-cache-replicas", 1, "number of cache servers to store each key. if >1, read from replicas with hedged requests and read-repair.")
+	cacheHedgeDelay = flag.Duration("file-cache-hedge-delay", cache.DefaultHedgeDelay, "delay to send hedged request to next cache server replica.")
+	bucket          = flag.String("bucket", "", "backing store bucket. gs://<bucket>, s3://<bucket> or file://<dir>. name without scheme is cloud storage bucket")
+	s3Endpoint      = flag.String("s3-endpoint", "", "endpoint URL of S3 compatible storage for s3:// bucket, e.g. http://minio:9000. empty uses AWS")
+	s3Region        = flag.String("s3-region", "", "region of s3:// bucket")
+
+	compression = flag.String("file-cache-compression", "none", `compression format of values stored in redis or bucket. "none", "flate" or "zstd". values stored in other format are still readable.`)
 
 	traceProjectID = flag.String("trace-project-id", "", "project id for cloud tracing")
 
@@ -90,6 +99,18 @@ func main() {
 	trace.ApplyConfig(trace.Config{
 		DefaultSampler: server.NewLimitedSampler(server.DefaultTraceFraction, server.DefaultTraceQPS),
 	})
+	err = view.Register(codec.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	err = view.Register(gcs.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	format, err := codec.ParseFormat(*compression)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	s, err := server.NewGRPC(*port,
 		grpc.MaxSendMsgSize(file.DefaultMaxMsgSize),
@@ -102,36 +123,57 @@ func main() {
 	addr, err := redis.AddrFromEnv()
 	switch {
 	case err == nil:
-		logger.Infof("redis enabled for gomafile: %s  idle=%d active=%d", addr, *redisMaxIdleConns, *redisMaxActiveConns)
+		mode, masterName, err := redis.ModeFromEnv()
+		if err != nil {
+			logger.Fatalf("redis mode: %v", err)
+		}
+		logger.Infof("redis enabled for gomafile: %s mode=%s idle=%d active=%d", addr, mode, *redisMaxIdleConns, *redisMaxActiveConns)
 		c := redis.NewClient(ctx, addr, redis.Opts{
 			Prefix:         "gomafile:",
 			MaxIdleConns:   *redisMaxIdleConns,
 			MaxActiveConns: *redisMaxActiveConns,
+			Mode:           mode,
+			MasterName:     masterName,
+			Codec:          &codec.Codec{Format: format},
 		})
 		defer c.Close()
 		cclient = c
 
 	case *cacheAddr != "":
-		logger.Infof("use cache server: %s", *cacheAddr)
-		c := cache.NewClient(ctx, *cacheAddr,
-			append([]grpc.DialOption{
-				grpc.WithDefaultCallOptions(grpc.FailFast(false)),
-			}, server.DefaultDialOption()...)...)
+		logger.Infof("use cache server: %s replicas=%d", *cacheAddr, *cacheReplicas)
+		dialOpts := append([]grpc.DialOption{
+			grpc.WithDefaultCallOptions(grpc.FailFast(false)),
+		}, server.DefaultDialOption()...)
+		if *cacheReplicas > 1 {
+			c := cache.NewReplicatedClient(ctx, *cacheAddr, cache.ReplicaOpts{
+				Replicas:    *cacheReplicas,
+				HedgeDelay:  *cacheHedgeDelay,
+				DialOptions: dialOpts,
+			})
+			defer c.Close()
+			cclient = c
+			break
+		}
+		c := cache.NewClient(ctx, *cacheAddr, dialOpts...)
 		defer c.Close()
 		cclient = c
 
 	case *bucket != "":
-		logger.Infof("use cloud storage bucket: %s", *bucket)
+		logger.Infof("use bucket: %s", *bucket)
 		var opts []option.ClientOption
 		if *serviceAccountFile != "" {
 			opts = append(opts, option.WithServiceAccountFile(*serviceAccountFile))
 		}
-		gsclient, err := storage.NewClient(ctx, opts...)
+		bkt, err := blobstore.Open(ctx, *bucket, blobstore.Options{
+			GCSClientOptions: opts,
+			S3Endpoint:       *s3Endpoint,
+			S3Region:         *s3Region,
+		})
 		if err != nil {
-			logger.Fatalf("storage client failed: %v", err)
+			logger.Fatalf("bucket %s failed: %v", *bucket, err)
 		}
-		defer gsclient.Close()
-		c := gcs.New(gsclient.Bucket(*bucket))
+		c := gcs.New(bkt)
+		c.Codec = &codec.Codec{Format: format}
 		limit, err := server.MemoryLimit()
 		if err != nil {
 			logger.Errorf("unknown memory limit: %v", err)
@@ -0,0 +1,131 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Binary goma_cache_snapshot saves or verifies memory cache snapshot.
+
+ $ goma_cache_snapshot save --token-file <token-file> <url> <file>
+ $ goma_cache_snapshot verify <file>
+
+save fetches snapshot from <url>, i.e. http://<host>:<mport>/cache/snapshot
+of cache_server or remoteexec_proxy, verifies it and writes it in <file>.
+It sends bearer token in --token-file, which must be the same as
+--snapshot-token-file of cache_server or --file-cache-snapshot-token-file
+of remoteexec_proxy.
+<file> can be used for --snapshot-file of cache_server or
+--file-cache-snapshot-file of remoteexec_proxy.
+
+verify checks checksums of snapshot in <file>.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.chromium.org/goma/server/cache"
+)
+
+var tokenFile = flag.String("token-file", "", "file of bearer token to fetch snapshot")
+
+// verify verifies snapshot in r, and returns number of entries and
+// total bytes of keys and values.
+func verify(r io.Reader) (int, int64, error) {
+	var n int
+	var size int64
+	err := cache.ScanSnapshot(r, func(key string, value []byte, expire time.Time) error {
+		n++
+		size += int64(len(key) + len(value))
+		return nil
+	})
+	return n, size, err
+}
+
+func save(url, fname string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if *tokenFile != "" {
+		token, err := cache.ReadSnapshotToken(*tokenFile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(fname), filepath.Base(fname)+".tmp")
+	if err != nil {
+		return err
+	}
+	n, size, err := verify(io.TeeReader(resp.Body, f))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fname)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	fmt.Printf("%s: %d entries, %d bytes\n", fname, n, size)
+	return nil
+}
+
+func verifyFile(fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, size, err := verify(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d entries, %d bytes\n", fname, n, size)
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(w, "%s save --token-file <token-file> <url> <file>\n", os.Args[0])
+		fmt.Fprintf(w, " <url> ; snapshot endpoint. e.g. http://localhost:8081/cache/snapshot\n")
+		fmt.Fprintf(w, " <file>; snapshot file to write\n")
+		fmt.Fprintf(w, "%s verify <file>\n", os.Args[0])
+		fmt.Fprintf(w, " <file>; snapshot file to verify\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	switch {
+	case flag.NArg() == 3 && flag.Arg(0) == "save":
+		err = save(flag.Arg(1), flag.Arg(2))
+	case flag.NArg() == 2 && flag.Arg(0) == "verify":
+		err = verifyFile(flag.Arg(1))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Binary goma_config_check checks toolchain config.
+
+It loads toolchain config as exec_server does, and reports all problems
+with location and suggested fix. It exits with non-zero status if
+it finds any error, so it can be used to gate config pushes.
+
+ $ goma_config_check --toolchain-config-bucket <bucket> --configmap_file <file>
+ $ goma_config_check --toolchain-config-dir <dir> --configmap_file <file>
+ $ goma_config_check --config-resp <file>
+
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"cloud.google.com/go/storage"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/command"
+	"go.chromium.org/goma/server/command/normalizer"
+	"go.chromium.org/goma/server/exec"
+	"go.chromium.org/goma/server/log"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+var (
+	toolchainConfigBucket = flag.String("toolchain-config-bucket", "", "cloud storage bucket for toolchain config")
+	toolchainConfigDir    = flag.String("toolchain-config-dir", "", "local directory for toolchain config")
+	configMapFile         = flag.String("configmap_file", "", "filename for configmap text proto. required for --toolchain-config-bucket or --toolchain-config-dir")
+	configRespFile        = flag.String("config-resp", "", "filename for ConfigResp text proto")
+	remoteexecAddr        = flag.String("remoteexec-addr", "", "remoteexec API address used if runtime config doesn't have service_addr")
+	serviceAccountFile    = flag.String("service-account-file", "", "service account json file")
+	verbose               = flag.Bool("v", false, "verbose flag")
+)
+
+// problem is a problem found in toolchain config.
+type problem struct {
+	location string
+	warning  bool
+	msg      string
+	fix      string
+}
+
+// checker collects problems.
+type checker struct {
+	problems []problem
+}
+
+func (c *checker) add(p problem) {
+	c.problems = append(c.problems, p)
+}
+
+func (c *checker) report() (errors, warnings int) {
+	for _, p := range c.problems {
+		severity := "error"
+		if p.warning {
+			severity = "warning"
+			warnings++
+		} else {
+			errors++
+		}
+		fmt.Printf("%s: %s: %s\n", p.location, severity, p.msg)
+		if p.fix != "" {
+			fmt.Printf("\tfix: %s\n", p.fix)
+		}
+	}
+	return errors, warnings
+}
+
+// located is a config with its location.
+type located struct {
+	location string
+	config   *cmdpb.Config
+}
+
+// checkConfigs checks configs as Inventory.Configure does.
+func (c *checker) checkConfigs(ctx context.Context, confs []located, versionID string) {
+	resp := &cmdpb.ConfigResp{
+		VersionId: versionID,
+	}
+	// key: normalized selector and addr.
+	seen := make(map[string]string)
+	for _, lc := range confs {
+		resp.Configs = append(resp.Configs, lc.config)
+		err := exec.CheckConfig(lc.config)
+		if err != nil {
+			p := problem{
+				location: lc.location,
+				msg:      err.Error(),
+			}
+			if ce, ok := err.(*exec.ConfigError); ok {
+				p.fix = ce.Fix
+			}
+			c.add(p)
+			continue
+		}
+		if lc.config.CmdDescriptor == nil {
+			// platform config for arbitrary toolchain support.
+			continue
+		}
+		sel, err := normalizer.Selector(lc.config.CmdDescriptor.Selector)
+		if err != nil {
+			// already reported by exec.CheckConfig.
+			continue
+		}
+		key := fmt.Sprintf("%s@%s", sel, lc.config.Target.Addr)
+		if loc, ok := seen[key]; ok {
+			c.add(problem{
+				location: lc.location,
+				warning:  true,
+				msg:      fmt.Sprintf("duplicate selector %s with %s. only one of them is used", sel, loc),
+				fix:      "remove one of the descriptors",
+			})
+			continue
+		}
+		seen[key] = lc.location
+	}
+	var inventory exec.Inventory
+	err := inventory.Configure(ctx, resp)
+	if err != nil {
+		c.add(problem{
+			location: versionID,
+			msg:      err.Error(),
+			fix:      "add valid descriptors, or platform_runtime_config in runtime config",
+		})
+	}
+}
+
+func checkConfigResp(ctx context.Context, c *checker, fname string) error {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	resp := &cmdpb.ConfigResp{}
+	err = proto.UnmarshalText(string(b), resp)
+	if err != nil {
+		return fmt.Errorf("parse %s: %v", fname, err)
+	}
+	var confs []located
+	for i, cfg := range resp.Configs {
+		confs = append(confs, located{
+			location: fmt.Sprintf("%s: configs[%d]", fname, i),
+			config:   cfg,
+		})
+	}
+	c.checkConfigs(ctx, confs, fname)
+	return nil
+}
+
+func checkConfigMap(ctx context.Context, c *checker, cm command.ConfigMap, store blobstore.Bucket, location func(string) string) error {
+	seqs, err := cm.Seqs(ctx)
+	if err != nil {
+		return err
+	}
+	rcs, err := cm.RuntimeConfigs(ctx)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range rcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	loader := &command.ConfigLoader{
+		Store: store,
+	}
+	var confs []located
+	for _, name := range names {
+		rc := rcs[name]
+		if _, ok := seqs[name]; !ok {
+			c.add(problem{
+				location: location(name + "/seq"),
+				warning:  true,
+				msg:      fmt.Sprintf("no seq for runtime %s. runtime is not loaded", name),
+				fix:      "upload seq after uploading descriptors, or remove the runtime from configmap",
+			})
+			continue
+		}
+		if rc.ServiceAddr == "" {
+			c.add(problem{
+				location: *configMapFile,
+				msg:      fmt.Sprintf("no service_addr for runtime %s. runtime is ignored", name),
+				fix:      "set service_addr in runtime config, or --remoteexec-addr",
+			})
+			continue
+		}
+		checked, problems, err := loader.Check(ctx, name+"/", rc)
+		if err != nil {
+			return fmt.Errorf("runtime %s: %v", name, err)
+		}
+		for _, p := range problems {
+			c.add(problem{
+				location: location(p.Object),
+				warning:  p.Warning,
+				msg:      p.Message,
+				fix:      p.Fix,
+			})
+		}
+		for _, cc := range checked {
+			loc := fmt.Sprintf("%s: runtime %s", *configMapFile, name)
+			if cc.Object != "" {
+				loc = location(cc.Object)
+			}
+			confs = append(confs, located{
+				location: loc,
+				config:   cc.Config,
+			})
+		}
+	}
+	c.checkConfigs(ctx, confs, *configMapFile)
+	return nil
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+	if !*verbose {
+		log.SetZapLogger(zap.NewNop())
+	}
+	fatalf := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		os.Exit(2)
+	}
+
+	c := &checker{}
+	switch {
+	case *configRespFile != "":
+		err := checkConfigResp(ctx, c, *configRespFile)
+		if err != nil {
+			fatalf("config-resp %s: %v", *configRespFile, err)
+		}
+
+	case *toolchainConfigBucket != "":
+		if *configMapFile == "" {
+			fatalf("--configmap_file must be given")
+		}
+		var opts []option.ClientOption
+		if *serviceAccountFile != "" {
+			opts = append(opts, option.WithServiceAccountFile(*serviceAccountFile))
+		}
+		gsclient, err := storage.NewClient(ctx, opts...)
+		if err != nil {
+			fatalf("storage client failed: %v", err)
+		}
+		defer gsclient.Close()
+		cmBucket := command.ConfigMapBucket{
+			URI:            fmt.Sprintf("gs://%s/", *toolchainConfigBucket),
+			ConfigMap:      &cmdpb.ConfigMap{},
+			ConfigMapFile:  *configMapFile,
+			Store:          blobstore.NewGCS(gsclient, *toolchainConfigBucket),
+			RemoteexecAddr: *remoteexecAddr,
+		}
+		err = checkConfigMap(ctx, c, cmBucket, cmBucket.Store, func(obj string) string {
+			return fmt.Sprintf("gs://%s/%s", *toolchainConfigBucket, obj)
+		})
+		if err != nil {
+			fatalf("check gs://%s: %v", *toolchainConfigBucket, err)
+		}
+
+	case *toolchainConfigDir != "":
+		if *configMapFile == "" {
+			fatalf("--configmap_file must be given")
+		}
+		cmDir := command.ConfigMapDir{
+			Dir:            *toolchainConfigDir,
+			ConfigMap:      &cmdpb.ConfigMap{},
+			ConfigMapFile:  *configMapFile,
+			RemoteexecAddr: *remoteexecAddr,
+		}
+		store, err := cmDir.Store()
+		if err != nil {
+			fatalf("toolchain-config-dir: %v", err)
+		}
+		err = checkConfigMap(ctx, c, cmDir, store, func(obj string) string {
+			return filepath.Join(*toolchainConfigDir, filepath.FromSlash(obj))
+		})
+		if err != nil {
+			fatalf("check %s: %v", *toolchainConfigDir, err)
+		}
+
+	default:
+		fatalf("--toolchain-config-bucket, --toolchain-config-dir or --config-resp must be given")
+	}
+
+	errors, warnings := c.report()
+	fmt.Printf("%d errors, %d warnings\n", errors, warnings)
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Binary goma_descriptor generates command descriptor of a local compiler.
+
+It runs version/target probes of the compiler, collects files needed to
+run the compiler (subprograms, shared libraries, symlink targets), and
+writes descriptor in <output>/descriptors/<descriptorHash>, which
+ConfigLoader reads from <bucket>/<runtime>/<prebuilt-item>/descriptors.
+
+If compiler path is relative, it is considered as relocatable. Compiler
+path in the descriptor will be relative to the current directory (cmd_dir),
+and other file paths will be relative to the compiler's directory
+(e.g. ../lib/libLLVM.so for bin/clang), as FileSpec in descriptor.
+Run it in the prebuilt-item directory for relocatable compiler.
+
+ $ cd <prebuilt-item>
+ $ goma_descriptor -key clang -output . bin/clang
+
+ $ goma_descriptor -key clang++ -target x86_64-linux-android \
+     -absolute_binary_hash_from bin/clang++ -clang_need_target \
+     -output . bin/clang++
+
+With -upload_bucket or -upload_dir, it also uploads the files to
+cmd-files store in sha256/<hash>, which exec_server reads with
+--cmd-files-bucket.
+
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/api/option"
+
+	"go.chromium.org/goma/server/command/descriptor"
+	"go.chromium.org/goma/server/command/pathconv"
+	"go.chromium.org/goma/server/hash"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+var (
+	key                       = flag.String("key", "", "selector name. e.g. gcc, g++, clang, clang++, clang-cl, cl.exe, javac, dartanalyzer. basename of compiler path if empty")
+	output                    = flag.String("output", ".", "output directory. descriptor is written in <output>/descriptors/<hash>")
+	target                    = flag.String("target", "", "cross target. requires -absolute_binary_hash_from")
+	absoluteBinaryHashFrom    = flag.String("absolute_binary_hash_from", "", "file to compute binary hash from, instead of compiler path")
+	pathType                  = flag.String("path_type", "posix", "path type of the command. posix or windows")
+	clangNeedTarget           = flag.Bool("clang_need_target", false, "add -target in args if args doesn't have -target. clang specific")
+	respectClientIncludePaths = flag.Bool("respect_client_include_paths", false, "respect include paths sent from client. always true for absolute compiler path")
+	files                     = flag.String("files", "", "comma separated files to add in descriptor, relative to current directory or absolute path")
+	windowsMountRoot          = flag.String("windows_mount_root", "", "directory where windows client's drives are mounted, e.g. /mnt/win for C:\\ on /mnt/win/c. required for -path_type=windows")
+	prefixMap                 = flag.String("prefix_map", "", "comma separated <client>=<server> path prefix mappings for posix client")
+
+	uploadBucket       = flag.String("upload_bucket", "", "cloud storage bucket for cmd files to upload")
+	uploadDir          = flag.String("upload_dir", "", "local directory for cmd files to upload")
+	serviceAccountFile = flag.String("service-account-file", "", "service account json file")
+)
+
+func runner(cmds ...string) ([]byte, error) {
+	return exec.Command(cmds[0], cmds[1:]...).CombinedOutput()
+}
+
+func parsePathType(s string) (cmdpb.CmdDescriptor_PathType, error) {
+	switch strings.ToLower(s) {
+	case "posix":
+		return cmdpb.CmdDescriptor_POSIX, nil
+	case "windows":
+		return cmdpb.CmdDescriptor_WINDOWS, nil
+	}
+	return cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE, fmt.Errorf("unknown path type %q", s)
+}
+
+func newPathConverter(pt cmdpb.CmdDescriptor_PathType) (pathconv.PathConverter, error) {
+	mappings, err := pathconv.ParsePrefixMappings(*prefixMap)
+	if err != nil {
+		return nil, err
+	}
+	style := gomapb.RequesterInfo_POSIX_STYLE
+	if pt == cmdpb.CmdDescriptor_WINDOWS {
+		style = gomapb.RequesterInfo_WINDOWS_STYLE
+	}
+	return pathconv.ForPathStyle(style, pathconv.Options{
+		WindowsMountRoot: *windowsMountRoot,
+		PrefixMappings:   mappings,
+	})
+}
+
+// cmdFilesStore stores cmd files by its hash.
+type cmdFilesStore interface {
+	// Put stores file fname as hash.
+	Put(ctx context.Context, hash, fname string) error
+}
+
+// cmdFilesBucket stores cmd files in sha256/<hash> in cloud storage bucket.
+type cmdFilesBucket struct {
+	Bucket *storage.BucketHandle
+}
+
+func (b cmdFilesBucket) Put(ctx context.Context, hash, fname string) error {
+	obj := b.Bucket.Object(path.Join("sha256", hash))
+	_, err := obj.Attrs(ctx)
+	if err == nil {
+		// already uploaded.
+		return nil
+	}
+	if err != storage.ErrObjectNotExist {
+		return err
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := obj.If(storage.Conditions{DoesNotExist: true}).NewWriter(ctx)
+	_, err = io.Copy(w, f)
+	if err != nil {
+		// cancel aborts the upload.
+		return err
+	}
+	return w.Close()
+}
+
+// cmdFilesDir stores cmd files in sha256/<hash> in local directory.
+type cmdFilesDir struct {
+	Dir string
+}
+
+func (d cmdFilesDir) Put(ctx context.Context, hash, fname string) error {
+	dst := filepath.Join(d.Dir, "sha256", hash)
+	if _, err := os.Stat(dst); err == nil {
+		// already stored.
+		return nil
+	}
+	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), hash+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// upload uploads files in setup to store.
+// cmddir is directory of the command in server path, used for
+// relative paths in setup.Files.
+func upload(ctx context.Context, store cmdFilesStore, setup *cmdpb.CmdDescriptor_Setup, cmdfile, cmddir string) error {
+	put := func(fs *cmdpb.FileSpec, fname string) error {
+		if fs.Hash == "" {
+			// symlink.
+			return nil
+		}
+		err := store.Put(ctx, fs.Hash, fname)
+		if err != nil {
+			return fmt.Errorf("upload %s: %v", fname, err)
+		}
+		fmt.Printf("uploaded %s %s\n", fs.Hash, fs.Path)
+		return nil
+	}
+	err := put(setup.CmdFile, cmdfile)
+	if err != nil {
+		return err
+	}
+	for _, fs := range setup.Files {
+		fname := fs.Path
+		if !filepath.IsAbs(fname) {
+			fname = filepath.Join(cmddir, fname)
+		}
+		err = put(fs, fname)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newCmdFilesStore(ctx context.Context) (cmdFilesStore, error) {
+	switch {
+	case *uploadBucket != "" && *uploadDir != "":
+		return nil, fmt.Errorf("both -upload_bucket and -upload_dir are specified")
+	case *uploadDir != "":
+		return cmdFilesDir{Dir: *uploadDir}, nil
+	case *uploadBucket != "":
+		var opts []option.ClientOption
+		if *serviceAccountFile != "" {
+			opts = append(opts, option.WithServiceAccountFile(*serviceAccountFile))
+		}
+		client, err := storage.NewClient(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return cmdFilesBucket{Bucket: client.Bucket(*uploadBucket)}, nil
+	}
+	return nil, nil
+}
+
+// newDescriptor generates command descriptor of compiler fname by flags.
+// run is used to run compiler to get its version, target etc.
+func newDescriptor(fname string, run descriptor.Runner) (*descriptor.Descriptor, error) {
+	k := *key
+	if k == "" {
+		k = filepath.Base(fname)
+	}
+	pt, err := parsePathType(*pathType)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := newPathConverter(pt)
+	if err != nil {
+		return nil, fmt.Errorf("path converter: %v", err)
+	}
+	d, err := descriptor.New(descriptor.Config{
+		Key:                    k,
+		Filename:               fname,
+		AbsoluteBinaryHashFrom: *absoluteBinaryHashFrom,
+		Target:                 *target,
+		Runner:                 run,
+		ToClientPath:           pc.ToClientPath,
+		PathType:               pt,
+		ClangNeedTarget:        *clangNeedTarget,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("descriptor for %s: %v", fname, err)
+	}
+	err = d.CmdSetup()
+	if err != nil {
+		return nil, fmt.Errorf("setup %s: %v", fname, err)
+	}
+	if *files != "" {
+		for _, f := range strings.Split(*files, ",") {
+			err = d.Add(f)
+			if err != nil {
+				return nil, fmt.Errorf("add %s: %v", f, err)
+			}
+		}
+	}
+	if *respectClientIncludePaths {
+		d.EmulationOpts.RespectClientIncludePaths = true
+	}
+	return d, nil
+}
+
+// saveDescriptor saves cd in <dir>/descriptors, and returns its filename.
+func saveDescriptor(dir string, cd *cmdpb.CmdDescriptor) (string, error) {
+	err := os.MkdirAll(filepath.Join(dir, "descriptors"), 0755)
+	if err != nil {
+		return "", fmt.Errorf("output dir: %v", err)
+	}
+	err = descriptor.Save(dir, cd)
+	if err != nil {
+		return "", fmt.Errorf("save: %v", err)
+	}
+	b, err := proto.Marshal(cd)
+	if err != nil {
+		return "", fmt.Errorf("marshal: %v", err)
+	}
+	return filepath.Join(dir, "descriptors", hash.SHA256Content(b)), nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <compiler-path>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	fatalf := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		os.Exit(1)
+	}
+
+	fname := flag.Arg(0)
+	store, err := newCmdFilesStore(ctx)
+	if err != nil {
+		fatalf("cmd files store: %v", err)
+	}
+	d, err := newDescriptor(fname, runner)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	saved, err := saveDescriptor(*output, d.CmdDescriptor)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Println(proto.MarshalTextString(d.CmdDescriptor))
+	fmt.Printf("saved %s\n", saved)
+
+	if store == nil {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fatalf("getwd: %v", err)
+	}
+	cmdfile := fname
+	if !filepath.IsAbs(cmdfile) {
+		cmdfile = filepath.Join(cwd, cmdfile)
+	}
+	err = upload(ctx, store, d.Setup, cmdfile, filepath.Dir(cmdfile))
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"go.chromium.org/goma/server/command/descriptor"
+	"go.chromium.org/goma/server/hash"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// testdataDir has minimal compiler binaries.
+// see command/descriptor/library_test.go.
+const testdataDir = "../../command/descriptor/testdata"
+
+// setFlags sets flags, and returns func to reset them to default values.
+func setFlags(t *testing.T, flags map[string]string) func() {
+	t.Helper()
+	for name, value := range flags {
+		if err := flag.Set(name, value); err != nil {
+			t.Fatalf("flag.Set(%q, %q)=%v; want nil error", name, value, err)
+		}
+	}
+	return func() {
+		for name := range flags {
+			f := flag.Lookup(name)
+			f.Value.Set(f.DefValue)
+		}
+	}
+}
+
+// copyTestdata copies testdata/src to dst, keeping file mode.
+func copyTestdata(t *testing.T, src, dst string) {
+	t.Helper()
+	src = filepath.Join(testdataDir, src)
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dst, rel), b, info.Mode())
+	})
+	if err != nil {
+		t.Fatalf("copy %s to %s: %v", src, dst, err)
+	}
+}
+
+const (
+	testClangVersion = "clang version 12.0.0 (https://github.com/llvm/llvm-project 0123456789)"
+	testClangTarget  = "x86_64-apple-darwin"
+)
+
+func fakeRunner(cmds ...string) ([]byte, error) {
+	switch {
+	case len(cmds) == 2 && cmds[1] == "-dumpversion":
+		return []byte("12.0.0\n"), nil
+	case len(cmds) == 2 && cmds[1] == "--version":
+		return []byte(testClangVersion + "\nTarget: " + testClangTarget + "\n"), nil
+	case len(cmds) == 2 && cmds[1] == "-dumpmachine":
+		return []byte(testClangTarget + "\n"), nil
+	case len(cmds) > 2 && cmds[1] == "-E":
+		// no COMPILER_PATH.
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected command %q", cmds)
+}
+
+func fileSpec(t *testing.T, fname, path string) *cmdpb.FileSpec {
+	t.Helper()
+	fi, err := os.Stat(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := hash.SHA256File(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cmdpb.FileSpec{
+		Path:         path,
+		Hash:         h,
+		Size:         fi.Size(),
+		IsExecutable: fi.Mode()&0111 != 0,
+	}
+}
+
+func TestParsePathType(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    cmdpb.CmdDescriptor_PathType
+		wantErr bool
+	}{
+		{input: "posix", want: cmdpb.CmdDescriptor_POSIX},
+		{input: "Windows", want: cmdpb.CmdDescriptor_WINDOWS},
+		{input: "", wantErr: true},
+		{input: "mac", wantErr: true},
+	} {
+		got, err := parsePathType(tc.input)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("parsePathType(%q)=%v, %v; want %v, error %t", tc.input, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestNewPathConverter(t *testing.T) {
+	for _, tc := range []struct {
+		flags      map[string]string
+		pathType   cmdpb.CmdDescriptor_PathType
+		serverPath string
+		want       string
+		wantErr    bool
+	}{
+		{
+			pathType:   cmdpb.CmdDescriptor_POSIX,
+			serverPath: "/b/clang/bin/clang",
+			want:       "/b/clang/bin/clang",
+		},
+		{
+			flags: map[string]string{
+				"prefix_map": "/home/user/clang=/b/clang",
+			},
+			pathType:   cmdpb.CmdDescriptor_POSIX,
+			serverPath: "/b/clang/bin/clang",
+			want:       "/home/user/clang/bin/clang",
+		},
+		{
+			flags: map[string]string{
+				"prefix_map": "/home/user/clang",
+			},
+			pathType: cmdpb.CmdDescriptor_POSIX,
+			wantErr:  true,
+		},
+		{
+			flags: map[string]string{
+				"windows_mount_root": "/mnt/win",
+			},
+			pathType:   cmdpb.CmdDescriptor_WINDOWS,
+			serverPath: "/mnt/win/c/clang/bin/clang-cl.exe",
+			want:       `C:\clang\bin\clang-cl.exe`,
+		},
+		{
+			// windows requires -windows_mount_root.
+			pathType: cmdpb.CmdDescriptor_WINDOWS,
+			wantErr:  true,
+		},
+	} {
+		func() {
+			defer setFlags(t, tc.flags)()
+			pc, err := newPathConverter(tc.pathType)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("newPathConverter(%v) with %v=_, nil; want error", tc.pathType, tc.flags)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("newPathConverter(%v) with %v=_, %v; want nil error", tc.pathType, tc.flags, err)
+				return
+			}
+			got, err := pc.ToClientPath(tc.serverPath)
+			if got != tc.want || err != nil {
+				t.Errorf("ToClientPath(%q) with %v=%q, %v; want %q, nil", tc.serverPath, tc.flags, got, err, tc.want)
+			}
+		}()
+	}
+}
+
+func TestNewDescriptorAbsolute(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goma_descriptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	serverDir := filepath.Join(tmpdir, "server")
+	copyTestdata(t, "macho", serverDir)
+
+	fname := filepath.Join(serverDir, "bin", "clang")
+	lib := filepath.Join(serverDir, "lib", "libLLVM.dylib")
+	defer setFlags(t, map[string]string{
+		"prefix_map": "/client=" + serverDir,
+		"files":      lib,
+	})()
+
+	d, err := newDescriptor(fname, fakeRunner)
+	if err != nil {
+		t.Fatalf("newDescriptor(%q)=_, %v; want nil error", fname, err)
+	}
+	binaryHash, err := hash.SHA256File(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &cmdpb.CmdDescriptor{
+		Selector: &cmdpb.Selector{
+			// key is basename of compiler path.
+			Name:       "clang",
+			Version:    "12.0.0[" + testClangVersion + "]",
+			Target:     testClangTarget,
+			BinaryHash: binaryHash,
+		},
+		Setup: &cmdpb.CmdDescriptor_Setup{
+			CmdFile:  fileSpec(t, fname, "/client/bin/clang"),
+			PathType: cmdpb.CmdDescriptor_POSIX,
+			Files: []*cmdpb.FileSpec{
+				fileSpec(t, lib, "/client/lib/libLLVM.dylib"),
+			},
+		},
+		Cross: &cmdpb.CmdDescriptor_Cross{},
+		EmulationOpts: &cmdpb.CmdDescriptor_EmulationOpts{
+			// always true for absolute compiler path.
+			RespectClientIncludePaths: true,
+		},
+	}
+	if !proto.Equal(d.CmdDescriptor, want) {
+		t.Errorf("newDescriptor(%q)=%s; want %s", fname, proto.MarshalTextString(d.CmdDescriptor), proto.MarshalTextString(want))
+	}
+}
+
+func TestNewDescriptorRelocatable(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goma_descriptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	copyTestdata(t, "macho", tmpdir)
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	defer setFlags(t, map[string]string{
+		"key":                          "clang++",
+		"target":                       "x86_64-apple-darwin19",
+		"absolute_binary_hash_from":    "bin/clang",
+		"clang_need_target":            "true",
+		"respect_client_include_paths": "true",
+	})()
+
+	fname := "bin/clang"
+	d, err := newDescriptor(fname, fakeRunner)
+	if err != nil {
+		t.Fatalf("newDescriptor(%q)=_, %v; want nil error", fname, err)
+	}
+	sel := d.CmdDescriptor.Selector
+	if sel.Name != "clang++" || sel.Target != "x86_64-apple-darwin19" {
+		t.Errorf("selector=%v; want name=clang++ target=x86_64-apple-darwin19", sel)
+	}
+	if !d.CmdDescriptor.Cross.ClangNeedTarget || !d.CmdDescriptor.EmulationOpts.RespectClientIncludePaths {
+		t.Errorf("cross=%v emulation_opts=%v; want clang_need_target and respect_client_include_paths", d.CmdDescriptor.Cross, d.CmdDescriptor.EmulationOpts)
+	}
+	setup := d.CmdDescriptor.Setup
+	if setup.CmdFile.Path != fname || setup.CmdDir != tmpdir {
+		t.Errorf("cmd_file=%q cmd_dir=%q; want %q, %q", setup.CmdFile.Path, setup.CmdDir, fname, tmpdir)
+	}
+	// libraries are relative to cmd dir. absolute paths are
+	// system subprograms found in the host.
+	var libs []string
+	for _, f := range setup.Files {
+		if !filepath.IsAbs(f.Path) {
+			libs = append(libs, f.Path)
+		}
+	}
+	wantLibs := "../lib/libLLVM.dylib,../lib/libweak.dylib"
+	if got := strings.Join(libs, ","); got != wantLibs {
+		t.Errorf("files=%q; want %q", got, wantLibs)
+	}
+}
+
+func TestNewDescriptorError(t *testing.T) {
+	fname := filepath.Join(testdataDir, "macho", "bin", "clang")
+	for _, flags := range []map[string]string{
+		{"path_type": "mac"},
+		{"path_type": "windows"},
+		{"prefix_map": "/client"},
+		// target requires absolute_binary_hash_from.
+		{"target": "x86_64-apple-darwin"},
+		{"files": "no-such-file"},
+	} {
+		func() {
+			defer setFlags(t, flags)()
+			_, err := newDescriptor(fname, fakeRunner)
+			if err == nil {
+				t.Errorf("newDescriptor(%q) with %v=_, nil; want error", fname, flags)
+			}
+		}()
+	}
+}
+
+func TestSaveDescriptor(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goma_descriptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	cd := &cmdpb.CmdDescriptor{
+		Selector: &cmdpb.Selector{
+			Name:       "clang",
+			Version:    "12.0.0",
+			Target:     "x86_64-apple-darwin",
+			BinaryHash: "hash",
+		},
+	}
+	saved, err := saveDescriptor(tmpdir, cd)
+	if err != nil {
+		t.Fatalf("saveDescriptor(%q)=_, %v; want nil error", tmpdir, err)
+	}
+	b, err := proto.Marshal(cd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(tmpdir, "descriptors", hash.SHA256Content(b)); saved != want {
+		t.Errorf("saveDescriptor(%q)=%q; want %q", tmpdir, saved, want)
+	}
+	cds, err := descriptor.Load(tmpdir)
+	if err != nil || len(cds) != 1 || !proto.Equal(cds[0], cd) {
+		t.Errorf("Load(%q)=%v, %v; want %v", tmpdir, cds, err, cd)
+	}
+}
+
+func TestUploadCmdFilesDir(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "goma_descriptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	copyTestdata(t, "macho", filepath.Join(tmpdir, "src"))
+	cmddir := filepath.Join(tmpdir, "src", "bin")
+	cmdfile := filepath.Join(cmddir, "clang")
+	lib := filepath.Join(tmpdir, "src", "lib", "libLLVM.dylib")
+
+	setup := &cmdpb.CmdDescriptor_Setup{
+		CmdFile: fileSpec(t, cmdfile, "bin/clang"),
+		CmdDir:  filepath.Join(tmpdir, "src"),
+		Files: []*cmdpb.FileSpec{
+			// relative to cmd dir.
+			fileSpec(t, lib, "../lib/libLLVM.dylib"),
+			{
+				Path:    "../lib/libLLVM.so",
+				Symlink: "libLLVM.dylib",
+			},
+		},
+		PathType: cmdpb.CmdDescriptor_POSIX,
+	}
+	store := cmdFilesDir{Dir: filepath.Join(tmpdir, "out")}
+	ctx := context.Background()
+	// second upload is no-op for stored files.
+	for i := 0; i < 2; i++ {
+		err = upload(ctx, store, setup, cmdfile, cmddir)
+		if err != nil {
+			t.Fatalf("upload #%d=%v; want nil error", i, err)
+		}
+	}
+	for _, fname := range []string{cmdfile, lib} {
+		h, err := hash.SHA256File(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join(tmpdir, "out", "sha256", h))
+		if err != nil {
+			t.Errorf("%s not uploaded: %v", fname, err)
+			continue
+		}
+		want, err := ioutil.ReadFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("uploaded %s differs", fname)
+		}
+	}
+	fis, err := ioutil.ReadDir(filepath.Join(tmpdir, "out", "sha256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 2 {
+		var names []string
+		for _, fi := range fis {
+			names = append(names, fi.Name())
+		}
+		t.Errorf("uploaded files=%q; want 2 files", names)
+	}
+}
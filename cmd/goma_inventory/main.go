@@ -0,0 +1,267 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Binary goma_inventory queries toolchain inventory of exec_server.
+
+It fetches /configz of exec_server's monitor port, and prints configs
+matched with filters with their usages, and missed toolchains.
+
+ $ goma_inventory [-name clang] [-version 13.0.0] [-target x86_64-linux] \
+     [-binary_hash <hash>] [-dimension os:linux,...] [-json] <addr>
+
+With -report, it prints toolchains unused for -unused_days days, and
+toolchains missed at least -min_misses times in the last week.
+
+ $ goma_inventory -report [-unused_days 7] [-min_misses 1] [-json] <addr>
+
+<addr> is host:port of exec_server's monitor port, or URL of configz.
+
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+var (
+	name       = flag.String("name", "", "selector name. e.g. clang")
+	version    = flag.String("version", "", "selector version. e.g. 13.0.0")
+	target     = flag.String("target", "", "selector target. e.g. x86_64-linux")
+	binaryHash = flag.String("binary_hash", "", "selector binary_hash")
+	dimension  = flag.String("dimension", "", "comma separated dimensions. e.g. os:linux")
+	jsonOutput = flag.Bool("json", false, "print json response as is")
+
+	report     = flag.Bool("report", false, "print report of unused and missing toolchains")
+	unusedDays = flag.Int("unused_days", 7, "report toolchains unused for the days")
+	minMisses  = flag.Int64("min_misses", 1, "report missing toolchains missed at least the times in the last week")
+)
+
+func configzURL(addr string) (*url.URL, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/configz"
+	}
+	q := u.Query()
+	set := func(k, v string) {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	set("name", *name)
+	set("version", *version)
+	set("target", *target)
+	set("binary_hash", *binaryHash)
+	set("dimension", *dimension)
+	if *report {
+		q.Set("view", "report")
+		q.Set("unused_days", strconv.Itoa(*unusedDays))
+		q.Set("min_misses", strconv.FormatInt(*minMisses, 10))
+	}
+	q.Set("format", "json")
+	u.RawQuery = q.Encode()
+	return u, nil
+}
+
+func fetch(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
+
+func timestamp(ts *tspb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return "?"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+func printInventory(resp *cmdpb.InventoryResp) error {
+	fmt.Printf("version-id: %s\n\n", resp.VersionId)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "ADDR\tNAME\tVERSION\tTARGET\tBINARY_HASH\tDIMENSIONS\tROLLOUT\tLAST_PICKED\tPICKS_1D\tPICKS_7D")
+	for i, cfg := range resp.Configs {
+		sel := cfg.GetCmdDescriptor().GetSelector()
+		rollout := ""
+		if r := cfg.GetRollout(); r != nil {
+			rollout = fmt.Sprintf("%s@%s", r.Runtime, r.Seq)
+			if r.Candidate {
+				rollout += "(candidate)"
+			}
+		}
+		// resp.Usages[i] is usage of resp.Configs[i].
+		usage := &cmdpb.ToolchainUsage{}
+		if i < len(resp.Usages) {
+			usage = resp.Usages[i]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			cfg.GetTarget().GetAddr(),
+			sel.GetName(),
+			sel.GetVersion(),
+			sel.GetTarget(),
+			sel.GetBinaryHash(),
+			strings.Join(cfg.GetDimensions(), ","),
+			rollout,
+			timestamp(usage.LastPicked),
+			usage.Picks_1D,
+			usage.Picks_7D)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	if len(resp.PlatformConfigs) > 0 {
+		fmt.Println()
+		tw = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(tw, "PLATFORM DIMENSIONS\tPROPERTIES")
+		for _, cfg := range resp.PlatformConfigs {
+			var props []string
+			for _, p := range cfg.GetRemoteexecPlatform().GetProperties() {
+				props = append(props, p.Name+"="+p.Value)
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", strings.Join(cfg.GetDimensions(), ","), strings.Join(props, ","))
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	if len(resp.Misses) > 0 {
+		fmt.Println()
+		err = printMisses(resp.Misses)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printMisses(misses []*cmdpb.ToolchainMiss) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "MISSED NAME\tVERSION\tTARGET\tBINARY_HASH\tLAST_MISSED\tMISSES_1D\tMISSES_7D")
+	for _, m := range misses {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			m.GetSelector().GetName(),
+			m.GetSelector().GetVersion(),
+			m.GetSelector().GetTarget(),
+			m.GetSelector().GetBinaryHash(),
+			timestamp(m.LastMissed),
+			m.Misses_1D,
+			m.Misses_7D)
+	}
+	return tw.Flush()
+}
+
+func printReport(report *cmdpb.ToolchainReport) error {
+	fmt.Printf("version-id: %s\n", report.VersionId)
+	fmt.Printf("tracking-since: %s\n\n", timestamp(report.TrackingSince))
+	fmt.Printf("unused in %d days:\n", *unusedDays)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(tw, "ADDR\tNAME\tVERSION\tTARGET\tBINARY_HASH\tLAST_PICKED")
+	for _, u := range report.Unused {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			u.Addr,
+			u.GetSelector().GetName(),
+			u.GetSelector().GetVersion(),
+			u.GetSelector().GetTarget(),
+			u.GetSelector().GetBinaryHash(),
+			timestamp(u.LastPicked))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nmissing at least %d times in the last week:\n", *minMisses)
+	return printMisses(report.Missing)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <addr>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := context.Background()
+
+	u, err := configzURL(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad addr %q: %v\n", flag.Arg(0), err)
+		os.Exit(2)
+	}
+	b, err := fetch(ctx, u)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetch %s: %v\n", u, err)
+		os.Exit(1)
+	}
+	if *jsonOutput {
+		os.Stdout.Write(b)
+		fmt.Println()
+		return
+	}
+	if *report {
+		resp := &cmdpb.ToolchainReport{}
+		err = jsonpb.UnmarshalString(string(b), resp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse response: %v\n", err)
+			os.Exit(1)
+		}
+		err = printReport(resp)
+	} else {
+		resp := &cmdpb.InventoryResp{}
+		err = jsonpb.UnmarshalString(string(b), resp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "parse response: %v\n", err)
+			os.Exit(1)
+		}
+		err = printInventory(resp)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "print: %v\n", err)
+		os.Exit(1)
+	}
+}
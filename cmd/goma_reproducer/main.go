@@ -0,0 +1,119 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+/*
+Binary goma_reproducer downloads a reproducer bundle of a compiler crash
+in remote, and materializes its input tree locally.
+
+ $ goma_reproducer [-service_account_json <file>] <bundle> <dir>
+
+<bundle> is a local filename or gs://<bucket>/<object>.
+It materializes the input tree in <dir>, writes stdout and stderr of
+the crashed command in <dir>, and prints the command to reproduce.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+
+	"go.chromium.org/goma/server/remoteexec"
+)
+
+var (
+	serviceAccountJSON = flag.String("service_account_json", "", "service account json file to access cloud storage")
+)
+
+func openBundle(ctx context.Context, name string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(name, "gs://") {
+		return os.Open(name)
+	}
+	bucket := strings.TrimPrefix(name, "gs://")
+	i := strings.IndexByte(bucket, '/')
+	if i < 0 {
+		return nil, fmt.Errorf("no object name in %q", name)
+	}
+	bucket, obj := bucket[:i], bucket[i+1:]
+	var opts []option.ClientOption
+	if *serviceAccountJSON != "" {
+		opts = append(opts, option.WithServiceAccountFile(*serviceAccountJSON))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// client is used until the end of the process.
+	return client.Bucket(bucket).Object(obj).NewReader(ctx)
+}
+
+func main() {
+	flag.Usage = func() {
+		w := flag.CommandLine.Output()
+		fmt.Fprintf(w, "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(w, "%s <bundle> <dir>\n", os.Args[0])
+		fmt.Fprintf(w, " <bundle>; reproducer bundle. local file or gs://<bucket>/<object>\n")
+		fmt.Fprintf(w, " <dir>   ; directory to materialize input tree\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	bundle := flag.Arg(0)
+	dir := flag.Arg(1)
+
+	r, err := openBundle(ctx, bundle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open %s: %v\n", bundle, err)
+		os.Exit(1)
+	}
+	blobDir := filepath.Join(dir, "blobs")
+	rp, err := remoteexec.ReadReproducer(r, blobDir)
+	r.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", bundle, err)
+		os.Exit(1)
+	}
+	inputDir := filepath.Join(dir, "input")
+	err = rp.Materialize(inputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "materialize %s: %v\n", inputDir, err)
+		os.Exit(1)
+	}
+	err = os.RemoveAll(blobDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "remove %s: %v\n", blobDir, err)
+	}
+	for fname, b := range map[string][]byte{
+		"stdout": rp.Stdout,
+		"stderr": rp.Stderr,
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, fname), b, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "write %s: %v\n", fname, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("cause: %s\n", rp.Cause)
+	fmt.Printf("input tree: %s\n", inputDir)
+	fmt.Printf("platform: %s\n", rp.Command.GetPlatform())
+	fmt.Printf("to reproduce:\n")
+	fmt.Printf(" cd %s\n", filepath.Join(inputDir, filepath.FromSlash(rp.Command.GetWorkingDirectory())))
+	for _, e := range rp.Command.GetEnvironmentVariables() {
+		fmt.Printf(" export %s=%q\n", e.Name, e.Value)
+	}
+	fmt.Printf(" %s\n", strings.Join(rp.Command.GetArguments(), " "))
+}
@@ -24,10 +24,10 @@ import (
 	"strings"
 	"time"
 
-	"cloud.google.com/go/storage"
 	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
 	"github.com/golang/protobuf/proto"
 	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/stats/view"
 	"go.opencensus.io/trace"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc"
@@ -36,9 +36,12 @@ import (
 	"go.chromium.org/goma/server/auth"
 	"go.chromium.org/goma/server/auth/account"
 	"go.chromium.org/goma/server/auth/acl"
+	"go.chromium.org/goma/server/blobstore"
 	"go.chromium.org/goma/server/cache"
+	"go.chromium.org/goma/server/cache/codec"
 	"go.chromium.org/goma/server/cache/gcs"
 	"go.chromium.org/goma/server/cache/redis"
+	"go.chromium.org/goma/server/command/pathconv"
 	"go.chromium.org/goma/server/file"
 	"go.chromium.org/goma/server/frontend"
 	"go.chromium.org/goma/server/httprpc"
@@ -55,12 +58,14 @@ import (
 	filepb "go.chromium.org/goma/server/proto/file"
 	"go.chromium.org/goma/server/remoteexec"
 	"go.chromium.org/goma/server/remoteexec/digest"
+	"go.chromium.org/goma/server/remoteexec/merkletree"
 	"go.chromium.org/goma/server/rpc"
 	"go.chromium.org/goma/server/server"
 )
 
 var (
-	port = flag.Int("port", 8090, "listening port (goma api endpoints)")
+	port  = flag.Int("port", 8090, "listening port (goma api endpoints)")
+	mport = flag.Int("mport", 0, "monitor port to serve /cache/snapshot with --file-cache-snapshot-token-file. 0 disables")
 
 	remoteexecAddr           = flag.String("remoteexec-addr", "", "remoteexec API endpoint")
 	remoteInstanceName       = flag.String("remote-instance-name", "", "remote instance name")
@@ -72,11 +77,34 @@ var (
 	additionalTLSCertificate = flag.String("additional-tls-certificate", "", "additional TLS root certificate for verifying the server certificate")
 	execMaxRetryCount        = flag.Int("exec-max-retry-count", 5, "max retry count for exec call. 0 is unlimited count, but bound to ctx timtout. Use small number for powerful clients to run local fallback quickly. Use large number for powerless clients to use remote more than local.")
 
-	fileCacheBucket = flag.String("file-cache-bucket", "", "file cache bucking store bucket")
+	fileCacheBucket           = flag.String("file-cache-bucket", "", "file cache bucking store bucket. gs://<bucket>, s3://<bucket> or file://<dir>. name without scheme is cloud storage bucket")
+	fileCacheS3Endpoint       = flag.String("file-cache-s3-endpoint", "", "endpoint URL of S3 compatible storage for s3:// file cache bucket, e.g. http://minio:9000. empty uses AWS")
+	fileCacheS3Region         = flag.String("file-cache-s3-region", "", "region of s3:// file cache bucket")
+	fileCacheWindowRatio      = flag.Float64("file-cache-window-ratio", 0, "ratio of memory used for window LRU of TinyLFU admission policy of file cache. 0 disables admission policy")
+	fileCacheDir              = flag.String("file-cache-dir", "", "local disk cache directory for file cache, used if --file-cache-bucket is not set. empty disables disk cache")
+	fileCacheMaxDiskBytes     = flag.Int64("file-cache-max-disk-bytes", 16*1024*1024*1024, "maximum bytes of local disk file cache. 0 means unlimited")
+	fileCacheCompression      = flag.String("file-cache-compression", "none", `compression format of file cache values. "none", "flate" or "zstd". values stored in other format are still readable.`)
+	fileCacheSnapshotFile     = flag.String("file-cache-snapshot-file", "", "memory file cache snapshot file, loaded at startup and saved on shutdown. used if --file-cache-bucket is not set. empty disables snapshot")
+	fileCacheSnapshotInterval = flag.Duration("file-cache-snapshot-interval", 0, "interval to save memory file cache snapshot periodically. 0 saves only on shutdown")
+	fileCacheSnapshotToken    = flag.String("file-cache-snapshot-token-file", "", "file of bearer token to fetch memory file cache snapshot from /cache/snapshot on --mport. empty disables the endpoint")
 
 	execConfigFile = flag.String("exec-config-file", "", "exec inventory config file")
 
 	maxDigestCacheEntries = flag.Int("max-digest-cache-entries", 2e6, "maximum entries in in-memory digest cache")
+	digestCacheFile       = flag.String("digest-cache-file", "", "filename of local disk tier of digest cache. empty disables disk tier")
+	digestCacheFileBytes  = flag.Int64("digest-cache-file-max-bytes", 512*1024*1024, "maximum bytes of digest cache file. digest cache entries in the file are also kept in memory")
+
+	maxMerkleTreeCacheEntries = flag.Int("max-merkletree-cache-entries", 1e5, "maximum entries in in-memory merkletree subtree cache for toolchain files. negative disables the cache. 0 means unlimited")
+
+	windowsMountRoot = flag.String("windows-mount-root", "", `server directory where windows client's drives are mounted, e.g. "/mnt/win" for C:\ on "/mnt/win/c". empty uses "/mnt/win"`)
+	prefixMap        = flag.String("prefix-map", "", "comma separated <client>=<server> path prefix mappings for posix client paths")
+
+	maxInputs          = flag.Int("max-inputs", 0, "maximum number of inputs in a request. 0 means unlimited")
+	maxInputTotalBytes = flag.Int64("max-input-total-bytes", 0, "maximum total bytes of inputs in a request. 0 means unlimited")
+	maxInputTreeDepth  = flag.Int("max-input-tree-depth", 0, "maximum depth of input paths in a request. 0 means unlimited")
+	maxInputFileBytes  = flag.Int64("max-input-file-bytes", 0, "maximum bytes of a single input file in a request. 0 means unlimited")
+
+	crashReproducerDir = flag.String("crash-reproducer-dir", "", "local directory to store reproducer bundles of compiler crashes in remote")
 
 	traceProjectID = flag.String("trace-project-id", "", "project id for cloud tracing")
 	traceFraction  = flag.Float64("trace-sampling-fraction", 1.0, "sampling fraction for stackdriver trace")
@@ -144,6 +172,18 @@ func (c cacheClient) Put(ctx context.Context, req *cachepb.PutReq, opts ...grpc.
 	return c.Service.Put(ctx, req)
 }
 
+func (c cacheClient) BatchGet(ctx context.Context, req *cachepb.BatchGetReq, opts ...grpc.CallOption) (*cachepb.BatchGetResp, error) {
+	return c.Service.BatchGet(ctx, req)
+}
+
+func (c cacheClient) BatchPut(ctx context.Context, req *cachepb.BatchPutReq, opts ...grpc.CallOption) (*cachepb.BatchPutResp, error) {
+	return c.Service.BatchPut(ctx, req)
+}
+
+func (c cacheClient) Delete(ctx context.Context, req *cachepb.DeleteReq, opts ...grpc.CallOption) (*cachepb.DeleteResp, error) {
+	return c.Service.Delete(ctx, req)
+}
+
 const gomaClientClientID = "687418631491-r6m1c3pr0lth5atp4ie07f03ae8omefc.apps.googleusercontent.com"
 
 type defaultACL struct {
@@ -273,6 +313,7 @@ func main() {
 	flag.DurationVar(&spanTimeout.UploadBlobs, "exec-upload-blobs-timeout", spanTimeout.UploadBlobs, "timeout of exec-upload-blobs")
 	flag.DurationVar(&spanTimeout.Execute, "exec-execute-timeout", spanTimeout.Execute, "timeout of exec-execute")
 	flag.DurationVar(&spanTimeout.Response, "exec-response-timeout", spanTimeout.Response, "timeout of exec-response")
+	flag.DurationVar(&spanTimeout.Reproducer, "exec-reproducer-timeout", spanTimeout.Reproducer, "timeout of exec-reproducer")
 
 	flag.Parse()
 	ctx := context.Background()
@@ -301,6 +342,14 @@ func main() {
 	if err != nil {
 		logger.Fatal(err)
 	}
+	err = view.Register(cache.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	err = view.Register(codec.DefaultViews...)
+	if err != nil {
+		logger.Fatal(err)
+	}
 
 	trace.ApplyConfig(trace.Config{
 		DefaultSampler: server.NewLimitedSampler(*traceFraction, *traceQPS),
@@ -336,24 +385,39 @@ func main() {
 		CheckToken: aclCheck.CheckToken,
 	}
 
+	// servers are run with main http server.
+	var servers []server.Server
 	var cclient cachepb.CacheServiceClient
+	fileCacheFormat, err := codec.ParseFormat(*fileCacheCompression)
+	if err != nil {
+		logger.Fatal(err)
+	}
 	if *fileCacheBucket != "" {
-		logger.Infof("use cloud storage bucket: %s", *fileCacheBucket)
+		logger.Infof("use bucket: %s", *fileCacheBucket)
 		var opts []option.ClientOption
 		if *serviceAccountJSON != "" {
 			opts = append(opts, option.WithServiceAccountFile(*serviceAccountJSON))
 		}
-		gsclient, err := storage.NewClient(ctx, opts...)
+		bkt, err := blobstore.Open(ctx, *fileCacheBucket, blobstore.Options{
+			GCSClientOptions: opts,
+			S3Endpoint:       *fileCacheS3Endpoint,
+			S3Region:         *fileCacheS3Region,
+		})
 		if err != nil {
-			logger.Fatalf("storage client failed: %v", err)
+			logger.Fatalf("bucket %s failed: %v", *fileCacheBucket, err)
 		}
-		defer gsclient.Close()
+		gcsCache := gcs.New(bkt)
+		gcsCache.Codec = &codec.Codec{Format: fileCacheFormat}
 		cclient = cache.LocalClient{
-			CacheServiceServer: gcs.New(gsclient.Bucket(*fileCacheBucket)),
+			CacheServiceServer: gcsCache,
 		}
 	} else {
 		cacheService, err := cache.New(cache.Config{
-			MaxBytes: 1 * 1024 * 1024 * 1024,
+			MaxBytes:     1 * 1024 * 1024 * 1024,
+			WindowRatio:  *fileCacheWindowRatio,
+			Dir:          *fileCacheDir,
+			MaxDiskBytes: *fileCacheMaxDiskBytes,
+			Codec:        &codec.Codec{Format: fileCacheFormat},
 		})
 		if err != nil {
 			logger.Fatal(err)
@@ -361,6 +425,25 @@ func main() {
 		cclient = cacheClient{
 			Service: cacheService,
 		}
+		// closes disk cache on shutdown.
+		servers = append(servers, server.NewCloser(cacheService))
+		if *fileCacheSnapshotFile != "" {
+			_, err := cacheService.LoadSnapshotFile(ctx, *fileCacheSnapshotFile)
+			if err != nil && !os.IsNotExist(err) {
+				logger.Warnf("failed to load snapshot %s: %v", *fileCacheSnapshotFile, err)
+			}
+			servers = append(servers, cache.NewSnapshotServer(cacheService, *fileCacheSnapshotFile, *fileCacheSnapshotInterval))
+		}
+		if *fileCacheSnapshotToken != "" && *mport != 0 {
+			token, err := cache.ReadSnapshotToken(*fileCacheSnapshotToken)
+			if err != nil {
+				logger.Fatalf("file cache snapshot token: %v", err)
+			}
+			// not on main mux, which serves goma api.
+			monitoring := http.NewServeMux()
+			monitoring.Handle("/cache/snapshot", cacheService.SnapshotHandler(token))
+			servers = append(servers, server.NewHTTP(*mport, monitoring))
+		}
 	}
 
 	fileServiceClient := fileClient{
@@ -404,20 +487,41 @@ func main() {
 	}
 	defer reConn.Close()
 
-	var digestCache remoteexec.DigestCache
+	var digestCache *digest.Cache
 	redisAddr, err := redis.AddrFromEnv()
 	if err != nil {
 		logger.Warnf("redis disabled for gomafile-digest: %v", err)
 		digestCache = digest.NewCache(nil, *maxDigestCacheEntries)
 	} else {
-		logger.Infof("redis enabled for gomafile-digest: %v idle=%d active=%d", redisAddr, *redisMaxIdleConns, *redisMaxActiveConns)
+		mode, masterName, err := redis.ModeFromEnv()
+		if err != nil {
+			logger.Fatalf("redis mode: %v", err)
+		}
+		logger.Infof("redis enabled for gomafile-digest: %v mode=%s idle=%d active=%d", redisAddr, mode, *redisMaxIdleConns, *redisMaxActiveConns)
 		digestCache = digest.NewCache(redis.NewClient(ctx, redisAddr, redis.Opts{
 			Prefix:         "gomafile-digest:",
 			MaxIdleConns:   *redisMaxIdleConns,
 			MaxActiveConns: *redisMaxActiveConns,
+			Mode:           mode,
+			MasterName:     masterName,
 		}), *maxDigestCacheEntries)
 	}
+	if *digestCacheFile != "" {
+		d, err := digest.OpenDiskTier(*digestCacheFile, *digestCacheFileBytes)
+		if err != nil {
+			logger.Fatalf("digest cache file %s: %v", *digestCacheFile, err)
+		}
+		// flushes digest cache file on shutdown.
+		// deferred function won't run, as server.Run exits.
+		servers = append(servers, server.NewCloser(d))
+		logger.Infof("digest cache file %s: %d entries", *digestCacheFile, d.Len())
+		digestCache.SetDiskTier(d)
+	}
 
+	prefixMappings, err := pathconv.ParsePrefixMappings(*prefixMap)
+	if err != nil {
+		logger.Fatalf("--prefix-map: %v", err)
+	}
 	re := &remoteexec.Adapter{
 		InstancePrefix: path.Dir(*remoteInstanceName),
 		ExecTimeout:    15 * time.Minute,
@@ -437,6 +541,22 @@ func main() {
 		},
 		FileLookupSema:    make(chan struct{}, 2),
 		CASBlobLookupSema: make(chan struct{}, 20),
+		InputLimits: remoteexec.InputLimits{
+			MaxInputs:     *maxInputs,
+			MaxTotalBytes: *maxInputTotalBytes,
+			MaxDepth:      *maxInputTreeDepth,
+			MaxFileBytes:  *maxInputFileBytes,
+		},
+		PathConverterOptions: pathconv.Options{
+			WindowsMountRoot: *windowsMountRoot,
+			PrefixMappings:   prefixMappings,
+		},
+	}
+	if *maxMerkleTreeCacheEntries >= 0 {
+		re.MerkleTreeCache = merkletree.NewCache(*maxMerkleTreeCacheEntries)
+	}
+	if *crashReproducerDir != "" {
+		re.CrashReproducers = remoteexec.ReproducerDir(*crashReproducerDir)
 	}
 
 	configResp := &cmdpb.ConfigResp{
@@ -545,6 +665,6 @@ func main() {
 	hsMain := server.NewHTTP(*port, mux)
 
 	if *preLoad == false {
-		server.Run(ctx, hsMain)
+		server.Run(ctx, append([]server.Server{hsMain}, servers...)...)
 	}
 }
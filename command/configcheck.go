@@ -0,0 +1,65 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// ConfigProblem is a problem of an object in toolchain config bucket.
+type ConfigProblem struct {
+	// Object is the object name in the bucket.
+	Object string
+
+	// Warning is true if the object is ignored by policy
+	// in runtime config, rather than broken.
+	Warning bool
+
+	// Message describes the problem.
+	Message string
+
+	// Fix suggests how to fix the problem.
+	Fix string
+
+	// err is set if the object fails to be read or parsed.
+	// Load fails with err, rather than ignoring the object.
+	err error
+}
+
+func (p ConfigProblem) String() string {
+	severity := "error"
+	if p.Warning {
+		severity = "warning"
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Object, severity, p.Message)
+}
+
+// CheckedConfig is a config loaded from an object.
+type CheckedConfig struct {
+	// Object is the object name of the descriptor in the bucket.
+	// It is empty for platform config of the runtime config.
+	Object string
+	Config *cmdpb.Config
+}
+
+// Check checks toolchain config in objects whose name has prefix as Load does, but reports
+// all problems instead of skipping bad objects.
+// It returns configs that Load would return, and problems found.
+// It returns error only if it fails to list objects.
+func (c *ConfigLoader) Check(ctx context.Context, prefix string, rc *cmdpb.RuntimeConfig) ([]CheckedConfig, []ConfigProblem, error) {
+	confs, problems, err := checkConfigs(ctx, c.Store, prefix, rc, remoteexecPlatform(rc), c.EnableParallel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rc.PlatformRuntimeConfig != nil {
+		confs = append(confs, CheckedConfig{
+			Config: PlatformConfig(rc),
+		})
+	}
+	return confs, problems, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"go.chromium.org/goma/server/blobstore"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func TestConfigLoaderCheck(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "configcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bkt, err := blobstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeString := func(name, data string) {
+		t.Helper()
+		_, err := bkt.Put(ctx, name, []byte(data), blobstore.PutOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := func(name string, d *cmdpb.CmdDescriptor) {
+		t.Helper()
+		b, err := proto.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		storeString(name, string(b))
+	}
+	selector := &cmdpb.Selector{
+		Name:       "clang",
+		Version:    "1",
+		Target:     "x86_64-unknown-linux-gnu",
+		BinaryHash: "clang-hash",
+	}
+	setup := &cmdpb.CmdDescriptor_Setup{
+		PathType: cmdpb.CmdDescriptor_POSIX,
+	}
+	storeString("linux/seq", "1")
+	store("linux/chrome/descriptors/good", &cmdpb.CmdDescriptor{
+		Selector: selector,
+		Setup:    setup,
+	})
+	store("linux/chrome/descriptors/nosetup", &cmdpb.CmdDescriptor{
+		Selector: selector,
+	})
+	store("linux/chrome/descriptors/nopathtype", &cmdpb.CmdDescriptor{
+		Selector: selector,
+		Setup:    &cmdpb.CmdDescriptor_Setup{},
+	})
+	store("linux/chrome/descriptors/disallowed", &cmdpb.CmdDescriptor{
+		Selector: &cmdpb.Selector{
+			Name:       "clang",
+			Version:    "bad",
+			Target:     "x86_64-unknown-linux-gnu",
+			BinaryHash: "bad-hash",
+		},
+		Setup: setup,
+	})
+	storeString("linux/chrome/descriptors/broken", "\xff\xff")
+	storeString("linux/chrome/README", "readme")
+	store("linux/experimental/descriptors/exp", &cmdpb.CmdDescriptor{
+		Selector: selector,
+		Setup:    setup,
+	})
+
+	loader := &ConfigLoader{
+		Store: bkt,
+	}
+	rc := &cmdpb.RuntimeConfig{
+		Name:             "linux",
+		ServiceAddr:      "rbe.example.com:443",
+		AllowedPrebuilts: []string{"chrome"},
+		DisallowedCommands: []*cmdpb.Selector{
+			{
+				BinaryHash: "bad-hash",
+			},
+		},
+	}
+	confs, problems, err := loader.Check(ctx, "linux/", rc)
+	if err != nil {
+		t.Fatalf("Check()=_, _, %v; want nil error", err)
+	}
+	var gotObjs []string
+	for _, c := range confs {
+		gotObjs = append(gotObjs, c.Object)
+	}
+	if diff := cmp.Diff([]string{"linux/chrome/descriptors/good"}, gotObjs); diff != "" {
+		t.Errorf("Check() configs diff -want +got:\n%s", diff)
+	}
+	type problem struct {
+		Object  string
+		Warning bool
+	}
+	var got []problem
+	for _, p := range problems {
+		if p.Message == "" || p.Fix == "" {
+			t.Errorf("problem %s: no message or fix: %#v", p.Object, p)
+		}
+		got = append(got, problem{Object: p.Object, Warning: p.Warning})
+	}
+	want := []problem{
+		{Object: "linux/chrome/README", Warning: true},
+		{Object: "linux/chrome/descriptors/broken"},
+		{Object: "linux/chrome/descriptors/disallowed"},
+		{Object: "linux/chrome/descriptors/nopathtype"},
+		{Object: "linux/chrome/descriptors/nosetup"},
+		{Object: "linux/experimental/descriptors/exp", Warning: true},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b problem) bool { return a.Object < b.Object })); diff != "" {
+		t.Errorf("Check() problems diff -want +got:\n%s", diff)
+	}
+
+	// Load fails for broken descriptor, while Check reports it.
+	_, err = loader.Load(ctx, "linux/", rc)
+	if err == nil || !strings.Contains(err.Error(), "linux/chrome/descriptors/broken") {
+		t.Errorf("Load()=_, %v; want error for linux/chrome/descriptors/broken", err)
+	}
+	// platform config is checked with the same platform as Load.
+	rc.PlatformRuntimeConfig = &cmdpb.PlatformRuntimeConfig{
+		Dimensions: []string{"os:linux"},
+		HasNsjail:  true,
+	}
+	rc.InputLimits = &cmdpb.InputLimits{
+		MaxInputs: 100,
+	}
+	confs, _, err = loader.Check(ctx, "linux/", rc)
+	if err != nil {
+		t.Fatalf("Check() with platform runtime config=_, _, %v; want nil error", err)
+	}
+	last := confs[len(confs)-1]
+	if last.Object != "" || !last.Config.GetRemoteexecPlatform().GetHasNsjail() || !proto.Equal(last.Config.GetInputLimits(), rc.InputLimits) {
+		t.Errorf("Check() platform config=%q %v; want no object, has_nsjail and input limits %v", last.Object, last.Config, rc.InputLimits)
+	}
+}
@@ -5,7 +5,6 @@
 package command
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -15,18 +14,16 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	"cloud.google.com/go/pubsub"
-	"cloud.google.com/go/storage"
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
-	"github.com/googleapis/google-cloud-go-testing/storage/stiface"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
-	"golang.org/x/sync/errgroup"
-	"google.golang.org/api/iterator"
 
+	"go.chromium.org/goma/server/blobstore"
 	"go.chromium.org/goma/server/log"
 	cmdpb "go.chromium.org/goma/server/proto/command"
 )
@@ -84,7 +81,7 @@ type ConfigMapWatcher interface {
 	Close() error
 }
 
-// ConfigMapBucket access config on cloud storage bucket.
+// ConfigMapBucket access config on blob store bucket.
 //
 // <bucket> is <project>-toolchain-config.
 // in the <bucket>
@@ -93,85 +90,45 @@ type ConfigMapWatcher interface {
 //           seq: text, sequence number.
 //           <prebuilt-item>/descriptors/<descriptorHash>: proto CmdDescriptor
 //
-// Watcher watches */seq files via default notification topic on the bucket.
+// Watcher watches */seq files by Store's Watch, e.g. via default
+// notification topic on cloud storage bucket.
 // Seqs and RuntimeConfigs will read ConfigMapFile everytime.
+// Use Store for ConfigLoader to load descriptors in the bucket.
 type ConfigMapBucket struct {
 	// URI of config data.
-	// gs://<bucket>/
+	// <scheme>://<bucket>/
 	// e.g. gs://$project-toolchain-config/
 	URI string
 
 	ConfigMap     *cmdpb.ConfigMap
 	ConfigMapFile string
 
-	PubsubClient *pubsub.Client
-
-	// StorageClient is an interface for accessing Cloud Storage. It can
-	// be a Cloud Storage client or a fake for testing.
-	StorageClient stiface.Client
-
-	// SubscriberID should be unique per each server instance
-	// to get notification in every server instance.
-	SubscriberID string
+	// Store is the bucket of URI.
+	Store blobstore.Bucket
 
 	// Remoteexec API address, if RBE API is used.
 	// Otherwise, use service_addr in RuntimeConfig proto.
 	RemoteexecAddr string
 }
 
+// configMapBucketWatcher waits for updates of seq files in the bucket.
 type configMapBucketWatcher struct {
-	s      *pubsub.Subscription
-	cancel func()
-	ch     chan *pubsub.Message
-}
-
-func (w configMapBucketWatcher) run(ctx context.Context) {
-	logger := log.FromContext(ctx)
-	logger.Infof("watch start")
-	err := w.s.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
-		logger.Debugf("receive message: %s", msg.ID)
-		w.ch <- msg
-	})
-	if err != nil {
-		logger.Errorf("configMapBucketWatcher.run: %v", err)
-	}
-	close(w.ch)
-	logger.Infof("watch finished")
+	w blobstore.Watcher
 }
 
 func (w configMapBucketWatcher) Next(ctx context.Context) error {
 	logger := log.FromContext(ctx)
 	for {
-		msg, ok := <-w.ch
-		if !ok {
-			return errors.New("watcher closed")
-		}
-		// https://cloud.google.com/storage/docs/pubsub-notifications#attributes
-		eventType := msg.Attributes["eventType"]
-		objectId := msg.Attributes["objectId"]
-		objectGeneration := msg.Attributes["objectGeneration"]
-		eventTime := msg.Attributes["eventTime"]
-		publishTime := msg.PublishTime
-		logger.Debugf("handle message: %s eventType:%s objectId:%s", msg.ID, eventType, objectId)
-
-		msg.Ack()
-
-		if eventType != storage.ObjectFinalizeEvent {
-			continue
-		}
-		if path.Base(objectId) != "seq" {
-			continue
+		// names contains all pending updates. they were
+		// generated before we call Seqs or Data, so we won't
+		// need to handle them later.
+		names, err := w.w.Next(ctx)
+		if err != nil {
+			return err
 		}
-		logger.Infof("%s was updated gen:%s at %s (published:%s)", objectId, objectGeneration, eventTime, publishTime)
-		// drain pending messages. these messages were generated
-		// before we call Seqs or Data, so we won't need to handle
-		// them later.
-		for {
-			select {
-			case msg := <-w.ch:
-				logger.Debugf("drain message: %s", msg.ID)
-				msg.Ack()
-			default:
+		for _, name := range names {
+			if path.Base(name) == "seq" {
+				logger.Infof("%s was updated", name)
 				return nil
 			}
 		}
@@ -179,20 +136,20 @@ func (w configMapBucketWatcher) Next(ctx context.Context) error {
 }
 
 func (w configMapBucketWatcher) Close() error {
-	ctx := context.Background()
-	logger := log.FromContext(ctx)
+	logger := log.FromContext(context.Background())
 	logger.Infof("watcher close")
-	w.cancel() // finish w.s.Receive in run.
-	// drain ch
-	go func() {
-		for msg := range w.ch {
-			// ok to ack because we use notification as trigger only.
-			logger.Debugf("drain message: %s", msg.ID)
-			msg.Ack()
-		}
-	}()
-	logger.Infof("delete subscription: %s", w.s)
-	return w.s.Delete(ctx)
+	return w.w.Close()
+}
+
+// pollInterval is base interval to poll config map, used when
+// the config map can't be watched.
+const pollInterval = 1 * time.Hour
+
+func newConfigMapPoller() configMapBucketPoller {
+	return configMapBucketPoller{
+		baseDelay: pollInterval,
+		done:      make(chan bool),
+	}
 }
 
 type configMapBucketPoller struct {
@@ -224,125 +181,45 @@ func (w configMapBucketPoller) Close() error {
 }
 
 func (c ConfigMapBucket) configMap(ctx context.Context) (*cmdpb.ConfigMap, error) {
-	if c.ConfigMapFile == "" {
-		return proto.Clone(c.ConfigMap).(*cmdpb.ConfigMap), nil
-	}
-	buf, err := ioutil.ReadFile(c.ConfigMapFile)
-	if err != nil {
-		return nil, err
-	}
-	err = proto.UnmarshalText(string(buf), c.ConfigMap)
-	if err != nil {
-		return nil, err
-	}
-	return proto.Clone(c.ConfigMap).(*cmdpb.ConfigMap), nil
+	return readConfigMap(c.ConfigMap, c.ConfigMapFile)
 }
 
-func cloudStorageNotification(ctx context.Context, s stiface.Client, bucket string) (*storage.Notification, error) {
-	bkt := s.Bucket(bucket)
-	nm, err := bkt.Notifications(ctx)
+// readConfigMap reads config map from configMapFile into cm if
+// configMapFile is given, and returns a copy of cm.
+func readConfigMap(cm *cmdpb.ConfigMap, configMapFile string) (*cmdpb.ConfigMap, error) {
+	if configMapFile == "" {
+		return proto.Clone(cm).(*cmdpb.ConfigMap), nil
+	}
+	buf, err := ioutil.ReadFile(configMapFile)
 	if err != nil {
 		return nil, err
 	}
-	var notification *storage.Notification
-	for _, n := range nm {
-		// use default topic, created by
-		//  $ gsutil notification create -f json <bucket>
-		// json payload will be:
-		// https://cloud.google.com/storage/docs/json_api/v1/objects#resource-representations
-		// we don't use json payload, so '-f none' is ok too.
-		if n.TopicID == bucket {
-			notification = n
-			break
-		}
-	}
-	if notification == nil {
-		return nil, fmt.Errorf("notification:%s not found in %v", bucket, nm)
+	err = proto.UnmarshalText(string(buf), cm)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", configMapFile, err)
 	}
-	return notification, nil
+	return proto.Clone(cm).(*cmdpb.ConfigMap), nil
 }
 
-var storageNotification = cloudStorageNotification
-
 func (c ConfigMapBucket) Watcher(ctx context.Context) ConfigMapWatcher {
 	logger := log.FromContext(ctx)
-	w, err := c.pubsubWatcher(ctx)
+	w, err := c.Store.Watch(ctx, "")
 	if err == nil {
 		stats.Record(ctx, pubsubErrors.M(0))
-		logger.Infof("use pubsub watcher")
-		return w
-	}
-	stats.Record(ctx, pubsubErrors.M(1))
-	logger.Errorf("failed to use pubsub watcher: %v", err)
-	return configMapBucketPoller{
-		baseDelay: 1 * time.Hour,
-		done:      make(chan bool),
+		logger.Infof("use bucket watcher")
+		return configMapBucketWatcher{w: w}
 	}
-}
-
-func (c ConfigMapBucket) pubsubWatcher(ctx context.Context) (ConfigMapWatcher, error) {
-	bucket, _, err := splitGCSPath(c.URI)
-	if err != nil {
-		return nil, err
-	}
-	logger := log.FromContext(ctx)
-	notification, err := storageNotification(ctx, c.StorageClient, bucket)
-	if err != nil {
-		return nil, err
-	}
-	logger.Infof("topic: %s in %s", notification.TopicID, notification.TopicProjectID)
-	topic := c.PubsubClient.TopicInProject(notification.TopicID, notification.TopicProjectID)
-	ok, err := topic.Exists(ctx)
-	if !ok || err != nil {
-		return nil, fmt.Errorf("notification topic:%s (notification:%#v): not exist: %v", topic, notification, err)
-	}
-	if c.SubscriberID == "" {
-		return nil, errors.New("SubscriberID is not specified")
-	}
-	subscription := c.PubsubClient.Subscription(c.SubscriberID)
-	ok, err = subscription.Exists(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("subscription:%s err:%v", c.SubscriberID, err)
-	}
-	if ok {
-		sc, err := subscription.Config(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("subscription config:%s err:%v", c.SubscriberID, err)
-		}
-		if sc.Topic.String() != topic.String() {
-			return nil, fmt.Errorf("topic mismatch? %s != %s. delete subscription:%s", sc.Topic, topic, c.SubscriberID)
-		}
+	if err == blobstore.ErrNotSupported {
+		logger.Infof("bucket watcher is not supported. use poller")
 	} else {
-		logger.Infof("subscriber:%s not found. creating", c.SubscriberID)
-		subscription, err = c.PubsubClient.CreateSubscription(ctx, c.SubscriberID, pubsub.SubscriptionConfig{
-			Topic: topic,
-			// experimental config.
-			// minimum is 1 day
-			// +12 hours margin, to cover summar time switch (+1 hour)
-			// b/112820308
-			ExpirationPolicy: 36 * time.Hour,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("create subscription:%s err:%v", c.SubscriberID, err)
-		}
+		stats.Record(ctx, pubsubErrors.M(1))
+		logger.Errorf("failed to use bucket watcher: %v", err)
 	}
-	ctx, cancel := context.WithCancel(context.Background())
-	// TODO: watch configMapFile.
-	w := configMapBucketWatcher{
-		s:      subscription,
-		cancel: cancel,
-		ch:     make(chan *pubsub.Message),
-	}
-	go w.run(ctx)
-	return w, nil
+	return newConfigMapPoller()
 }
 
 func (c ConfigMapBucket) Seqs(ctx context.Context) (map[string]string, error) {
 	logger := log.FromContext(ctx)
-	bucket, _, err := splitGCSPath(c.URI)
-	if err != nil {
-		return nil, err
-	}
 	cm, err := c.configMap(ctx)
 	if err != nil {
 		return nil, err
@@ -350,8 +227,8 @@ func (c ConfigMapBucket) Seqs(ctx context.Context) (map[string]string, error) {
 	m := map[string]string{}
 	for _, r := range cm.Runtimes {
 		obj := path.Join(r.Name, "seq")
-		buf, err := storageReadAll(ctx, c.StorageClient, bucket, obj)
-		if err == storage.ErrObjectNotExist {
+		buf, err := c.Store.Get(ctx, obj)
+		if err == blobstore.ErrNotExist {
 			logger.Infof("ignore %s: %v", obj, err)
 			continue
 		}
@@ -363,9 +240,17 @@ func (c ConfigMapBucket) Seqs(ctx context.Context) (map[string]string, error) {
 	return m, nil
 }
 
+// Bucket returns bucket name in URI.
 func (c ConfigMapBucket) Bucket(ctx context.Context) (string, error) {
-	bucket, _, err := splitGCSPath(c.URI)
-	return bucket, err
+	i := strings.Index(c.URI, "://")
+	if i < 0 {
+		return "", fmt.Errorf("no scheme in URI: %q", c.URI)
+	}
+	bucket := strings.SplitN(c.URI[i+len("://"):], "/", 2)[0]
+	if bucket == "" {
+		return "", fmt.Errorf("no bucket in URI: %q", c.URI)
+	}
+	return bucket, nil
 }
 
 func (c ConfigMapBucket) RuntimeConfigs(ctx context.Context) (map[string]*cmdpb.RuntimeConfig, error) {
@@ -373,19 +258,26 @@ func (c ConfigMapBucket) RuntimeConfigs(ctx context.Context) (map[string]*cmdpb.
 	if err != nil {
 		return nil, err
 	}
+	return runtimeConfigs(cm, c.RemoteexecAddr), nil
+}
+
+// runtimeConfigs returns a map of runtime name to RuntimeConfig in cm.
+// remoteexecAddr is used if RuntimeConfig doesn't have service addr.
+func runtimeConfigs(cm *cmdpb.ConfigMap, remoteexecAddr string) map[string]*cmdpb.RuntimeConfig {
 	m := make(map[string]*cmdpb.RuntimeConfig)
 	for _, rt := range cm.Runtimes {
 		if rt.ServiceAddr == "" {
-			rt.ServiceAddr = c.RemoteexecAddr
+			rt.ServiceAddr = remoteexecAddr
 		}
 		m[rt.Name] = rt
 	}
-	return m, nil
+	return m
 }
 
-// ConfigLoader loads toolchain_config from cloud storage.
+// ConfigLoader loads toolchain_config from blob store bucket.
 type ConfigLoader struct {
-	StorageClient  stiface.Client
+	// Store is the bucket that has descriptors.
+	Store          blobstore.Bucket
 	EnableParallel bool
 
 	// for test
@@ -393,9 +285,20 @@ type ConfigLoader struct {
 }
 
 // ConfigStore holds latest config.
+//
+// If runtime config enables staged rollout, new version is held as
+// candidate, in addition to stable (previous) version.
+// Candidate will be promoted to stable, or rolled back by Promote or
+// Rollback.
 type ConfigStore struct {
 	lastConfigs map[string]configs // key: toolchain_runtime_name
 
+	// candidate configs in staged rollout.
+	candidates map[string]configs // key: toolchain_runtime_name
+
+	// rolled back seq. key: toolchain_runtime_name
+	rejected map[string]string
+
 	// for test
 	versionID func() string
 }
@@ -403,6 +306,7 @@ type ConfigStore struct {
 type configs struct {
 	seq     string
 	configs []*cmdpb.Config
+	rollout *cmdpb.RolloutConfig
 }
 
 // ErrNoUpdate indicates no update in configmap, returned by ConfigMapLoader.Load.
@@ -449,7 +353,6 @@ func (c *ConfigMapLoader) Load(ctx context.Context) (*cmdpb.ConfigResp, error) {
 
 	for name, seq := range updated {
 		logger.Infof("update config for %s", name)
-		uri := fmt.Sprintf("gs://%s/%s", bucket, name)
 		runtime := runtimeConfigs[name]
 		if runtime == nil {
 			return nil, fmt.Errorf("runtime config %s not found", name)
@@ -459,10 +362,16 @@ func (c *ConfigMapLoader) Load(ctx context.Context) (*cmdpb.ConfigResp, error) {
 			logger.Warnf("no addr for %s. ignoring", name)
 			continue
 		}
-		confs, err := c.ConfigLoader.Load(ctx, uri, runtime)
+		logger.Infof("load config for %s from %s", name, bucket)
+		confs, err := c.ConfigLoader.Load(ctx, name+"/", runtime)
 		if err != nil {
 			return nil, err
 		}
+		if runtime.GetRollout().GetCandidatePercent() > 0 {
+			logger.Infof("rollout config for %s: seq=%s %s", name, seq, runtime.Rollout)
+			c.ConfigStore.SetCandidate(name, seq, confs, runtime.Rollout)
+			continue
+		}
 		c.ConfigStore.Set(name, seq, confs)
 	}
 	resp := c.ConfigStore.ConfigResp()
@@ -491,20 +400,14 @@ func mergePlatformProperties(rbePlatform *cmdpb.RemoteexecPlatform, platform *cm
 	}
 }
 
-// Load loads toolchain config from <uri>.
+// Load loads toolchain config from objects in Store whose name has prefix,
+// i.e. <runtime>/.
 // It sets rc.ServiceAddr  as target addr.
-func (c *ConfigLoader) Load(ctx context.Context, uri string, rc *cmdpb.RuntimeConfig) ([]*cmdpb.Config, error) {
-	platform := &cmdpb.RemoteexecPlatform{}
+func (c *ConfigLoader) Load(ctx context.Context, prefix string, rc *cmdpb.RuntimeConfig) ([]*cmdpb.Config, error) {
+	platform := remoteexecPlatform(rc)
 	parallel := c.EnableParallel
-	for _, p := range rc.Platform.GetProperties() {
-		platform.Properties = append(platform.Properties, &cmdpb.RemoteexecPlatform_Property{
-			Name:  p.Name,
-			Value: p.Value,
-		})
-	}
-	platform.HasNsjail = rc.GetPlatformRuntimeConfig().GetHasNsjail()
 
-	confs, err := loadConfigs(ctx, c.StorageClient, uri, rc, platform, parallel)
+	confs, err := loadConfigs(ctx, c.Store, prefix, rc, platform, parallel)
 	if err != nil {
 		return nil, err
 	}
@@ -513,16 +416,38 @@ func (c *ConfigLoader) Load(ctx context.Context, uri string, rc *cmdpb.RuntimeCo
 	// also add a config for that. Just define RemoteexecPlatform here.
 	// CmdDescriptor will be dynamically generated by a compile request.
 	if rc.PlatformRuntimeConfig != nil {
-		confs = append(confs, &cmdpb.Config{
-			RemoteexecPlatform: platform,
-			Dimensions:         rc.PlatformRuntimeConfig.Dimensions,
-			Acl:                rc.Acl,
-		})
+		confs = append(confs, PlatformConfig(rc))
 	}
 
 	return confs, nil
 }
 
+// PlatformConfig returns a config of rc for arbitrary toolchain support.
+// It has no CmdDescriptor, which will be dynamically generated by
+// a compile request.
+func PlatformConfig(rc *cmdpb.RuntimeConfig) *cmdpb.Config {
+	return &cmdpb.Config{
+		RemoteexecPlatform:   remoteexecPlatform(rc),
+		Dimensions:           rc.GetPlatformRuntimeConfig().GetDimensions(),
+		DimensionExpressions: rc.GetPlatformRuntimeConfig().GetDimensionExpressions(),
+		Acl:                  rc.Acl,
+		InputLimits:          rc.InputLimits,
+	}
+}
+
+// remoteexecPlatform returns RemoteexecPlatform of rc.
+func remoteexecPlatform(rc *cmdpb.RuntimeConfig) *cmdpb.RemoteexecPlatform {
+	platform := &cmdpb.RemoteexecPlatform{}
+	for _, p := range rc.Platform.GetProperties() {
+		platform.Properties = append(platform.Properties, &cmdpb.RemoteexecPlatform_Property{
+			Name:  p.Name,
+			Value: p.Value,
+		})
+	}
+	platform.HasNsjail = rc.GetPlatformRuntimeConfig().GetHasNsjail()
+	return platform
+}
+
 // List returns a list of config names.
 func (c *ConfigStore) List() []string {
 	var names []string
@@ -534,6 +459,7 @@ func (c *ConfigStore) List() []string {
 }
 
 // Set sets name's confs with seq.
+// It discards candidate of name, if any.
 func (c *ConfigStore) Set(name, seq string, confs []*cmdpb.Config) {
 	if c.lastConfigs == nil {
 		c.lastConfigs = make(map[string]configs)
@@ -542,16 +468,79 @@ func (c *ConfigStore) Set(name, seq string, confs []*cmdpb.Config) {
 		seq:     seq,
 		configs: confs,
 	}
+	delete(c.candidates, name)
+	delete(c.rejected, name)
+}
+
+// SetCandidate sets name's confs with seq as candidate in staged rollout.
+// If name has no stable confs, or seq is the same as stable seq,
+// it sets confs as stable.
+func (c *ConfigStore) SetCandidate(name, seq string, confs []*cmdpb.Config, rollout *cmdpb.RolloutConfig) {
+	stable, ok := c.lastConfigs[name]
+	if !ok || stable.seq == seq {
+		c.Set(name, seq, confs)
+		return
+	}
+	if c.candidates == nil {
+		c.candidates = make(map[string]configs)
+	}
+	c.candidates[name] = configs{
+		seq:     seq,
+		configs: confs,
+		rollout: rollout,
+	}
+	delete(c.rejected, name)
+}
+
+// Promote promotes name's candidate with seq to stable.
+// It returns false if name has no candidate with seq.
+func (c *ConfigStore) Promote(name, seq string) bool {
+	cand, ok := c.candidates[name]
+	if !ok || cand.seq != seq {
+		return false
+	}
+	c.Set(name, cand.seq, cand.configs)
+	return true
+}
+
+// Rollback discards name's candidate with seq.
+// Seq will return the rolled back seq, so the seq won't be loaded again.
+// It returns false if name has no candidate with seq.
+func (c *ConfigStore) Rollback(name, seq string) bool {
+	cand, ok := c.candidates[name]
+	if !ok || cand.seq != seq {
+		return false
+	}
+	delete(c.candidates, name)
+	if c.rejected == nil {
+		c.rejected = make(map[string]string)
+	}
+	c.rejected[name] = cand.seq
+	return true
 }
 
 // Seq returns seq of name's config.
+// It returns candidate's seq, or rolled back seq if any.
 func (c *ConfigStore) Seq(name string) string {
+	if cand, ok := c.candidates[name]; ok {
+		return cand.seq
+	}
+	if seq, ok := c.rejected[name]; ok {
+		return seq
+	}
+	return c.lastConfigs[name].seq
+}
+
+// StableSeq returns seq of name's stable config.
+func (c *ConfigStore) StableSeq(name string) string {
 	return c.lastConfigs[name].seq
 }
 
 // Delete deletes name's config.
 func (c *ConfigStore) Delete(name string) {
 	delete(c.lastConfigs, name)
+	delete(c.candidates, name)
+	delete(c.rejected, name)
 }
 
 func versionID() string {
@@ -574,57 +563,36 @@ func (c *ConfigStore) ConfigResp() *cmdpb.ConfigResp {
 	}
 	for _, name := range names {
 		confs := c.lastConfigs[name]
-		// TODO: dedup?
-		resp.Configs = append(resp.Configs, confs.configs...)
+		cand, ok := c.candidates[name]
+		if !ok {
+			// TODO: dedup?
+			resp.Configs = append(resp.Configs, confs.configs...)
+			continue
+		}
+		resp.Configs = append(resp.Configs, withRollout(confs.configs, &cmdpb.ConfigRollout{
+			Runtime: name,
+			Seq:     confs.seq,
+			Config:  cand.rollout,
+		})...)
+		resp.Configs = append(resp.Configs, withRollout(cand.configs, &cmdpb.ConfigRollout{
+			Runtime:   name,
+			Seq:       cand.seq,
+			Candidate: true,
+			Config:    cand.rollout,
+		})...)
 	}
 	return resp
 }
 
-func splitGCSPath(uri string) (string, string, error) {
-	if !strings.HasPrefix(uri, "gs://") {
-		return "", "", fmt.Errorf("not gs: URI: %q", uri)
-	}
-	p := strings.SplitN(uri[len("gs://"):], "/", 2)
-	if len(p) != 2 {
-		return p[0], "", nil
-	}
-	return p[0], p[1], nil
-}
-
-func storageReadAll(ctx context.Context, client stiface.Client, bucket, name string) ([]byte, error) {
-	bkt := client.Bucket(bucket)
-	if bkt == nil {
-		return nil, fmt.Errorf("could not find bucket %s", bucket)
-	}
-	obj := bkt.Object(name)
-	if obj == nil {
-		return nil, fmt.Errorf("could not find object %s/%s", bucket, name)
-	}
-	rd, err := obj.NewReader(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer rd.Close()
-	var buf bytes.Buffer
-	buf.Grow(int(rd.Size()))
-	_, err = buf.ReadFrom(rd)
-	if err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
-}
-
-func loadDescriptor(ctx context.Context, client stiface.Client, bucket, name string) (*cmdpb.CmdDescriptor, error) {
-	buf, err := storageReadAll(ctx, client, bucket, name)
-	if err != nil {
-		return nil, fmt.Errorf("load %s: %v", name, err)
+// withRollout returns copy of confs annotated with rollout.
+func withRollout(confs []*cmdpb.Config, rollout *cmdpb.ConfigRollout) []*cmdpb.Config {
+	var r []*cmdpb.Config
+	for _, cfg := range confs {
+		cfg = proto.Clone(cfg).(*cmdpb.Config)
+		cfg.Rollout = rollout
+		r = append(r, cfg)
 	}
-	d := &cmdpb.CmdDescriptor{}
-	err = proto.Unmarshal(buf, d)
-	if err != nil {
-		return nil, fmt.Errorf("parse %s: %v", name, err)
-	}
-	return d, nil
+	return r
 }
 
 func checkPrebuilt(rc *cmdpb.RuntimeConfig, objName string) error {
@@ -676,49 +644,121 @@ func checkSelector(rc *cmdpb.RuntimeConfig, sel *cmdpb.Selector) error {
 	return nil
 }
 
-func loadConfigs(ctx context.Context, client stiface.Client, uri string, rc *cmdpb.RuntimeConfig, platform *cmdpb.RemoteexecPlatform, parallel bool) ([]*cmdpb.Config, error) {
-	logger := log.FromContext(ctx)
-	bucket, obj, err := splitGCSPath(uri)
-	if err != nil {
-		return nil, err
+// descriptorError is an error in descriptor with suggested fix.
+type descriptorError struct {
+	msg string
+	fix string
+}
+
+func (e descriptorError) Error() string {
+	return e.msg
+}
+
+// checkDescriptor checks descriptor d is valid and allowed in rc.
+func checkDescriptor(rc *cmdpb.RuntimeConfig, d *cmdpb.CmdDescriptor) error {
+	if err := checkSelector(rc, d.Selector); err != nil {
+		return descriptorError{
+			msg: fmt.Sprintf("selector: %v", err),
+			fix: "remove the descriptor, or update disallowed_commands in runtime config",
+		}
 	}
+	if d.Setup == nil {
+		return descriptorError{
+			msg: "no setup",
+			fix: "set setup in the descriptor",
+		}
+	}
+	if d.Setup.PathType == cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE {
+		return descriptorError{
+			msg: "unknown path type",
+			fix: "set setup.path_type to POSIX or WINDOWS in the descriptor",
+		}
+	}
+	return nil
+}
 
-	bkt := client.Bucket(bucket)
-	if bkt == nil {
-		return nil, fmt.Errorf("could not find storage bucket %s", bucket)
+func newConfig(rc *cmdpb.RuntimeConfig, platform *cmdpb.RemoteexecPlatform, d *cmdpb.CmdDescriptor, ts *tspb.Timestamp) *cmdpb.Config {
+	// TODO: fix config definition.
+	// BuildInfo is used for key for cache key.
+	//  include cmd_server hash etc?
+	// BuildInfo.Timestamp is used for dedup in exec_server.
+	return &cmdpb.Config{
+		Target: &cmdpb.Target{
+			Addr: rc.ServiceAddr,
+		},
+		BuildInfo: &cmdpb.BuildInfo{
+			Timestamp: ts,
+		},
+		CmdDescriptor:      d,
+		RemoteexecPlatform: platform,
+		Acl:                rc.Acl,
+		InputLimits:        rc.InputLimits,
+		Fallback:           rc.Fallback,
 	}
-	iter := bkt.Objects(ctx, &storage.Query{
-		Prefix: obj,
-	})
+}
 
-	// pagination?
-	var confs []*cmdpb.Config
-	logger.Infof("load from %s", bucket)
+func loadConfigs(ctx context.Context, store blobstore.Bucket, prefix string, rc *cmdpb.RuntimeConfig, platform *cmdpb.RemoteexecPlatform, parallel bool) ([]*cmdpb.Config, error) {
+	logger := log.FromContext(ctx)
+	logger.Infof("load from %s", prefix)
 	start := time.Now()
-	var attrsList []*storage.ObjectAttrs
-	for {
-		// iter does not have an API to read all, so just iterate everything.
-		attrs, err := iter.Next()
-		if err == iterator.Done {
-			break
+	checked, problems, err := checkConfigs(ctx, store, prefix, rc, platform, parallel)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range problems {
+		if p.err != nil {
+			return nil, fmt.Errorf("%s: %v", p.Object, p.err)
 		}
-		if err != nil {
-			return nil, err
+		if p.Warning {
+			logger.Infof("%s", p)
+			continue
 		}
+		logger.Errorf("%s", p)
+	}
+	var confs []*cmdpb.Config
+	for _, cc := range checked {
+		confs = append(confs, cc.Config)
+		logger.Infof("%s: %s", cc.Object, cc.Config.CmdDescriptor.GetSelector())
+	}
+	logger.Infof("loaded from %s: %d configs using %v", prefix, len(confs), time.Since(start))
+	return confs, nil
+}
+
+// checkConfigs loads configs from descriptors in store whose name has
+// prefix. Objects ignored by rc or broken are reported as problems.
+// It returns error only if it fails to list objects.
+func checkConfigs(ctx context.Context, store blobstore.Bucket, prefix string, rc *cmdpb.RuntimeConfig, platform *cmdpb.RemoteexecPlatform, parallel bool) ([]CheckedConfig, []ConfigProblem, error) {
+	list, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	var problems []ConfigProblem
+	var attrsList []*blobstore.Attrs
+	for _, attrs := range list {
 		// Some string ops, no need to be paralleled.
-		if err := checkPrebuilt(rc, attrs.Name); err != nil {
-			logger.Infof("prebuilt %s: %v", attrs.Name, err)
+		if path.Base(attrs.Name) == "seq" {
 			continue
 		}
-
 		if path.Base(path.Dir(attrs.Name)) != "descriptors" {
-			logger.Infof("ignore %s", attrs.Name)
+			problems = append(problems, ConfigProblem{
+				Object:  attrs.Name,
+				Warning: true,
+				Message: "not in descriptors dir. ignored",
+				Fix:     "move it to <runtime>/<prebuilt>/descriptors/<hash>, or remove it",
+			})
+			continue
+		}
+		if err := checkPrebuilt(rc, attrs.Name); err != nil {
+			problems = append(problems, ConfigProblem{
+				Object:  attrs.Name,
+				Warning: true,
+				Message: err.Error(),
+				Fix:     "remove the prebuilt, or update allowed_prebuilts/disallowed_prebuilts in runtime config",
+			})
 			continue
 		}
 		attrsList = append(attrsList, attrs)
 	}
-	logger.Infof("iterate over %s took %v", bucket, time.Since(start))
-	start = time.Now()
 	concurrent := 1
 	if parallel {
 		// Limit concurrent requests to NumCPU * 4.
@@ -726,66 +766,76 @@ func loadConfigs(ctx context.Context, client stiface.Client, uri string, rc *cmd
 	}
 	// The ordering of the output should be guaranteed
 	// as unit tests using proto.Equal.
-	var eg errgroup.Group
+	var wg sync.WaitGroup
 	confList := make([]*cmdpb.Config, len(attrsList))
+	problemList := make([]*ConfigProblem, len(attrsList))
 	sema := make(chan struct{}, concurrent)
 	for i := range attrsList {
 		i := i
 		sema <- struct{}{}
-		eg.Go(func() error {
+		wg.Add(1)
+		go func() {
 			// Limit number of goroutines.
 			defer func() { <-sema }()
-			attrs := attrsList[i]
-			d, err := loadDescriptor(ctx, client, bucket, attrs.Name)
-			if err != nil {
-				return err
-			}
-			ts, err := ptypes.TimestampProto(attrs.Updated)
-			if err != nil {
-				return err
-			}
-			if err = checkSelector(rc, d.Selector); err != nil {
-				logger.Errorf("selector in %s/%s: %v", bucket, attrs.Name, err)
-				return nil
-			}
-			if d.Setup == nil {
-				logger.Errorf("no setup in %s/%s", bucket, attrs.Name)
-				return nil
-			}
-			if d.Setup.PathType == cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE {
-				logger.Errorf("unknown path type in %s/%s", bucket, attrs.Name)
-				return nil
-			}
-			// TODO: fix config definition.
-			// BuildInfo is used for key for cache key.
-			//  include cmd_server hash etc?
-			// BuildInfo.Timestamp is used for dedup in exec_server.
-			confList[i] = &cmdpb.Config{
-				Target: &cmdpb.Target{
-					Addr: rc.ServiceAddr,
-				},
-				BuildInfo: &cmdpb.BuildInfo{
-					Timestamp: ts,
-				},
-				CmdDescriptor:      d,
-				RemoteexecPlatform: platform,
-				Acl:                rc.Acl,
-			}
-			return nil
+			defer wg.Done()
+			confList[i], problemList[i] = loadConfig(ctx, store, rc, platform, attrsList[i])
+		}()
+	}
+	wg.Wait()
+	var confs []CheckedConfig
+	for i, attrs := range attrsList {
+		if p := problemList[i]; p != nil {
+			problems = append(problems, *p)
+			continue
+		}
+		confs = append(confs, CheckedConfig{
+			Object: attrs.Name,
+			Config: confList[i],
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return nil, err
+	return confs, problems, nil
+}
+
+// loadConfig loads config from the descriptor object of attrs.
+// It returns problem if the object is broken or not allowed in rc.
+func loadConfig(ctx context.Context, store blobstore.Bucket, rc *cmdpb.RuntimeConfig, platform *cmdpb.RemoteexecPlatform, attrs *blobstore.Attrs) (*cmdpb.Config, *ConfigProblem) {
+	buf, err := store.Get(ctx, attrs.Name)
+	if err != nil {
+		return nil, &ConfigProblem{
+			Object:  attrs.Name,
+			Message: fmt.Sprintf("failed to read: %v", err),
+			Fix:     "check permission of the object",
+			err:     fmt.Errorf("load: %v", err),
+		}
 	}
-	for i := range attrsList {
-		attrs := attrsList[i]
-		conf := confList[i]
-		if conf == nil {
-			continue
+	d := &cmdpb.CmdDescriptor{}
+	err = proto.Unmarshal(buf, d)
+	if err != nil {
+		return nil, &ConfigProblem{
+			Object:  attrs.Name,
+			Message: fmt.Sprintf("failed to parse CmdDescriptor: %v", err),
+			Fix:     "upload binary proto of CmdDescriptor",
+			err:     fmt.Errorf("parse: %v", err),
 		}
-		confs = append(confs, conf)
-		logger.Infof("%s/%s: %s", bucket, attrs.Name, conf.CmdDescriptor.GetSelector())
 	}
-	logger.Infof("loaded from %s: %d configs using %v", bucket, len(confs), time.Since(start))
-	return confs, nil
+	ts, err := ptypes.TimestampProto(attrs.Updated)
+	if err != nil {
+		return nil, &ConfigProblem{
+			Object:  attrs.Name,
+			Message: fmt.Sprintf("bad updated time: %v", err),
+			Fix:     "re-upload the object",
+			err:     err,
+		}
+	}
+	if err := checkDescriptor(rc, d); err != nil {
+		p := &ConfigProblem{
+			Object:  attrs.Name,
+			Message: err.Error(),
+		}
+		if de, ok := err.(descriptorError); ok {
+			p.Fix = de.fix
+		}
+		return nil, p
+	}
+	return newConfig(rc, platform, d, ts), nil
 }
@@ -0,0 +1,313 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go.chromium.org/goma/server/blobstore"
+	"go.chromium.org/goma/server/fswatch"
+	"go.chromium.org/goma/server/log"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// ConfigMapDir access config on local directory.
+//
+// It has the same layout with ConfigMapBucket.
+// in the <dir>
+//
+//  <runtime>/
+//           seq: text, sequence number.
+//           <prebuilt-item>/descriptors/<descriptorHash>: proto CmdDescriptor
+//
+// Watcher watches <dir> and <runtime> directories with fsnotify.
+// Seqs and RuntimeConfigs will read ConfigMapFile everytime.
+// Use Store for ConfigLoader to load descriptors in the dir.
+type ConfigMapDir struct {
+	Dir string
+
+	ConfigMap     *cmdpb.ConfigMap
+	ConfigMapFile string
+
+	// Remoteexec API address, if RBE API is used.
+	// Otherwise, use service_addr in RuntimeConfig proto.
+	RemoteexecAddr string
+}
+
+// Watcher returns a watcher of the dir.
+// If it fails to watch the dir, it falls back to polling.
+func (c ConfigMapDir) Watcher(ctx context.Context) ConfigMapWatcher {
+	logger := log.FromContext(ctx)
+	w, err := newConfigMapDirWatcher(c.Dir)
+	if err == nil {
+		logger.Infof("use fswatch watcher for %s", c.Dir)
+		return w
+	}
+	logger.Errorf("failed to use fswatch watcher for %s: %v", c.Dir, err)
+	return newConfigMapPoller()
+}
+
+// Seqs returns a map of runtime name to sequence in <dir>/<runtime>/seq.
+func (c ConfigMapDir) Seqs(ctx context.Context) (map[string]string, error) {
+	logger := log.FromContext(ctx)
+	cm, err := c.configMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	for _, r := range cm.Runtimes {
+		fname := filepath.Join(c.Dir, r.Name, "seq")
+		buf, err := ioutil.ReadFile(fname)
+		if os.IsNotExist(err) {
+			logger.Infof("ignore %s: %v", fname, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[r.Name] = string(buf)
+	}
+	return m, nil
+}
+
+// Bucket returns the dir.
+func (c ConfigMapDir) Bucket(ctx context.Context) (string, error) {
+	return c.Dir, nil
+}
+
+// RuntimeConfigs returns a map of RuntimeConfigs.
+func (c ConfigMapDir) RuntimeConfigs(ctx context.Context) (map[string]*cmdpb.RuntimeConfig, error) {
+	cm, err := c.configMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runtimeConfigs(cm, c.RemoteexecAddr), nil
+}
+
+// Store returns blob store bucket on the dir.
+func (c ConfigMapDir) Store() (*blobstore.Local, error) {
+	return blobstore.NewLocal(c.Dir)
+}
+
+func (c ConfigMapDir) configMap(ctx context.Context) (*cmdpb.ConfigMap, error) {
+	return readConfigMap(c.ConfigMap, c.ConfigMapFile)
+}
+
+// configMapDirWatcher watches <dir> and its subdirectories (i.e. <runtime>).
+type configMapDirWatcher struct {
+	dir    string
+	ctx    context.Context
+	cancel func()
+	ch     chan dirEvent
+
+	mu       sync.Mutex
+	watchers map[string]dirWatch
+	wg       sync.WaitGroup
+}
+
+// dirWatch is a watcher of a dir, and cancel to stop forwarding its events.
+type dirWatch struct {
+	fw     *fswatch.Watcher
+	cancel func()
+}
+
+type dirEvent struct {
+	dir   string
+	event fsnotify.Event
+	err   error
+}
+
+func newConfigMapDirWatcher(dir string) (*configMapDirWatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &configMapDirWatcher{
+		dir:      dir,
+		ctx:      ctx,
+		cancel:   cancel,
+		ch:       make(chan dirEvent),
+		watchers: make(map[string]dirWatch),
+	}
+	err := w.watch(dir)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	for _, fi := range fis {
+		if strings.HasPrefix(fi.Name(), ".") || !isDir(dir, fi) {
+			continue
+		}
+		err = w.watch(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// watch starts watching dir, and sends its events to w.ch.
+// If dir is already watched, it stops the old watcher and watches again,
+// since dir may be a symlink swapped to new target.
+func (w *configMapDirWatcher) watch(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.unwatchLocked(dir)
+	if err != nil {
+		log.FromContext(w.ctx).Warnf("close old watcher for %s: %v", dir, err)
+	}
+	ctx, cancel := context.WithCancel(w.ctx)
+	fw, err := fswatch.New(ctx, dir)
+	if err != nil {
+		cancel()
+		return err
+	}
+	w.watchers[dir] = dirWatch{fw: fw, cancel: cancel}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for {
+			ev, err := fw.Next(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case w.ch <- dirEvent{dir: dir, event: ev, err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// unwatch stops watching dir.
+func (w *configMapDirWatcher) unwatch(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.unwatchLocked(dir)
+}
+
+func (w *configMapDirWatcher) unwatchLocked(dir string) error {
+	dw, ok := w.watchers[dir]
+	if !ok {
+		return nil
+	}
+	delete(w.watchers, dir)
+	dw.cancel()
+	return dw.fw.Close()
+}
+
+// Next waits for updates of seq files, or updates in the top dir
+// (e.g. new runtime dir, or atomic update of the dir by symlink swap).
+func (w *configMapDirWatcher) Next(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	for {
+		var ev dirEvent
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.ctx.Done():
+			return errors.New("watcher closed")
+		case ev = <-w.ch:
+		}
+		if ev.err != nil {
+			logger.Errorf("watch %s: %v", ev.dir, ev.err)
+			continue
+		}
+		logger.Debugf("handle event: %s", ev.event)
+		switch {
+		case ev.dir == w.dir:
+			w.updateRuntimeDir(ctx, ev.event)
+		case filepath.Base(ev.event.Name) == "seq":
+		default:
+			continue
+		}
+		logger.Infof("%s was updated: %s", ev.event.Name, ev.event.Op)
+		// drain pending events. these events were generated
+		// before we call Seqs or Data, so we won't need to handle
+		// them later, except runtime dir changes in the top dir.
+		for {
+			select {
+			case ev := <-w.ch:
+				logger.Debugf("drain event: %s", ev.event)
+				if ev.err == nil && ev.dir == w.dir {
+					w.updateRuntimeDir(ctx, ev.event)
+				}
+			default:
+				return nil
+			}
+		}
+	}
+}
+
+// updateRuntimeDir updates watchers for runtime dir changed by ev in the top dir.
+// Runtime dir may be replaced by symlink swap, so it watches the dir again
+// to follow the new target.
+func (w *configMapDirWatcher) updateRuntimeDir(ctx context.Context, ev fsnotify.Event) {
+	logger := log.FromContext(ctx)
+	if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		err := w.unwatch(ev.Name)
+		if err != nil {
+			logger.Warnf("failed to unwatch %s: %v", ev.Name, err)
+		}
+	}
+	if ev.Op&fsnotify.Create == 0 || strings.HasPrefix(filepath.Base(ev.Name), ".") {
+		return
+	}
+	fi, err := os.Stat(ev.Name)
+	if err != nil || !fi.IsDir() {
+		return
+	}
+	err = w.watch(ev.Name)
+	if err != nil {
+		logger.Errorf("failed to watch %s: %v", ev.Name, err)
+	}
+}
+
+// Close closes the watcher.
+func (w *configMapDirWatcher) Close() error {
+	logger := log.FromContext(context.Background())
+	logger.Infof("watcher close")
+	w.cancel()
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errs []string
+	for dir, dw := range w.watchers {
+		err := dw.fw.Close()
+		if err != nil {
+			errs = append(errs, dir+": "+err.Error())
+		}
+	}
+	w.watchers = nil
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// isDir reports whether fi in dir is a directory or a symlink to directory.
+func isDir(dir string, fi os.FileInfo) bool {
+	if fi.IsDir() {
+		return true
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(dir, fi.Name()))
+	return err == nil && fi.IsDir()
+}
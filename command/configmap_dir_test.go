@@ -0,0 +1,277 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func writeDescriptor(t *testing.T, fname string, d *cmdpb.CmdDescriptor) {
+	t.Helper()
+	b, err := proto.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(filepath.Dir(fname), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(fname, b, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigMapDir(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "configmap_dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	descriptor := func(version string) *cmdpb.CmdDescriptor {
+		return &cmdpb.CmdDescriptor{
+			Selector: &cmdpb.Selector{
+				Name:       "clang",
+				Version:    version,
+				Target:     "x86_64-unknown-linux-gnu",
+				BinaryHash: "clang-" + version,
+			},
+			Setup: &cmdpb.CmdDescriptor_Setup{
+				PathType: cmdpb.CmdDescriptor_POSIX,
+			},
+		}
+	}
+	writeDescriptor(t, filepath.Join(dir, "linux", "chrome", "descriptors", "hash1"), descriptor("1"))
+	// not under descriptors dir.
+	writeDescriptor(t, filepath.Join(dir, "linux", "chrome", "other", "hash2"), descriptor("2"))
+	err = ioutil.WriteFile(filepath.Join(dir, "linux", "seq"), []byte("1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := ConfigMapDir{
+		Dir: dir,
+		ConfigMap: &cmdpb.ConfigMap{
+			Runtimes: []*cmdpb.RuntimeConfig{
+				{
+					Name: "linux",
+				},
+				{
+					Name: "windows",
+				},
+			},
+		},
+		RemoteexecAddr: "rbe.example.com:443",
+	}
+	w := cm.Watcher(ctx)
+	defer w.Close()
+	if _, ok := w.(*configMapDirWatcher); !ok {
+		t.Fatalf("Watcher=%T; want *configMapDirWatcher", w)
+	}
+
+	store, err := cm.Store()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := &ConfigMapLoader{
+		ConfigMap: cm,
+		ConfigLoader: ConfigLoader{
+			Store: store,
+		},
+	}
+	resp, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(ctx)=_, %v; want nil error", err)
+	}
+	var versions []string
+	for _, c := range resp.Configs {
+		versions = append(versions, c.GetCmdDescriptor().GetSelector().GetVersion())
+		if got, want := c.GetTarget().GetAddr(), "rbe.example.com:443"; got != want {
+			t.Errorf("target addr=%q; want %q", got, want)
+		}
+	}
+	if len(versions) != 1 || versions[0] != "1" {
+		t.Errorf("loaded versions=%q; want [\"1\"]", versions)
+	}
+
+	_, err = loader.Load(ctx)
+	if err != ErrNoUpdate {
+		t.Errorf("Load(ctx) again=_, %v; want ErrNoUpdate", err)
+	}
+
+	writeDescriptor(t, filepath.Join(dir, "linux", "chrome", "descriptors", "hash3"), descriptor("3"))
+	err = ioutil.WriteFile(filepath.Join(dir, "linux", "seq"), []byte("2"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx)=%v; want nil error", err)
+	}
+	resp, err = loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(ctx) after update=_, %v; want nil error", err)
+	}
+	versions = nil
+	for _, c := range resp.Configs {
+		versions = append(versions, c.GetCmdDescriptor().GetSelector().GetVersion())
+	}
+	if len(versions) != 2 || versions[0] != "1" || versions[1] != "3" {
+		t.Errorf("loaded versions=%q; want [\"1\" \"3\"]", versions)
+	}
+}
+
+func TestConfigMapDirWatcherNewRuntime(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "configmap_dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := newConfigMapDirWatcher(dir)
+	if err != nil {
+		t.Fatalf("newConfigMapDirWatcher(%q)=_, %v; want nil error", dir, err)
+	}
+	defer w.Close()
+
+	// new runtime dir in top dir.
+	err = os.Mkdir(filepath.Join(dir, "linux"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx)=%v; want nil error", err)
+	}
+
+	// seq in new runtime dir.
+	err = ioutil.WriteFile(filepath.Join(dir, "linux", "seq"), []byte("1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx) for seq=%v; want nil error", err)
+	}
+
+	// drain remaining events for seq (e.g. WRITE after CREATE).
+	for {
+		dctx, dcancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		err := w.Next(dctx)
+		dcancel()
+		if err != nil {
+			break
+		}
+	}
+
+	// non-seq file in runtime dir is ignored.
+	err = ioutil.WriteFile(filepath.Join(dir, "linux", "README"), []byte("readme"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nctx, ncancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer ncancel()
+	err = w.Next(nctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Next(ctx) for README=%v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestConfigMapDirWatcherSymlinkSwap(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "configmap_dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data, err := ioutil.TempDir("", "configmap_dir_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(data)
+	for _, v := range []string{"v1", "v2"} {
+		err = os.Mkdir(filepath.Join(data, v), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink(filepath.Join(data, "v1"), filepath.Join(dir, "linux"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newConfigMapDirWatcher(dir)
+	if err != nil {
+		t.Fatalf("newConfigMapDirWatcher(%q)=_, %v; want nil error", dir, err)
+	}
+	defer w.Close()
+
+	drain := func() {
+		for {
+			dctx, dcancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			err := w.Next(dctx)
+			dcancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// atomic update of runtime dir by symlink swap.
+	tmp := filepath.Join(dir, ".linux.tmp")
+	err = os.Symlink(filepath.Join(data, "v2"), tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Rename(tmp, filepath.Join(dir, "linux"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx) for swap=%v; want nil error", err)
+	}
+	drain()
+
+	// seq in old target is no longer watched.
+	err = ioutil.WriteFile(filepath.Join(data, "v1", "seq"), []byte("1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nctx, ncancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer ncancel()
+	err = w.Next(nctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Next(ctx) for old seq=%v; want %v", err, context.DeadlineExceeded)
+	}
+
+	// seq in new target.
+	err = ioutil.WriteFile(filepath.Join(data, "v2", "seq"), []byte("2"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx) for new seq=%v; want nil error", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"go.chromium.org/goma/server/blobstore"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func TestConfigStoreRollout(t *testing.T) {
+	config := func(version string) *cmdpb.Config {
+		return &cmdpb.Config{
+			CmdDescriptor: &cmdpb.CmdDescriptor{
+				Selector: &cmdpb.Selector{
+					Name:    "clang",
+					Version: version,
+				},
+			},
+		}
+	}
+	rollout := &cmdpb.RolloutConfig{
+		CandidatePercent: 10,
+	}
+	type version struct {
+		Version   string
+		Seq       string
+		Candidate bool
+	}
+	versions := func(resp *cmdpb.ConfigResp) []version {
+		var vs []version
+		for _, c := range resp.Configs {
+			vs = append(vs, version{
+				Version:   c.GetCmdDescriptor().GetSelector().GetVersion(),
+				Seq:       c.GetRollout().GetSeq(),
+				Candidate: c.GetRollout().GetCandidate(),
+			})
+		}
+		return vs
+	}
+
+	var cs ConfigStore
+	cs.versionID = func() string { return "v" }
+
+	// no stable version. candidate becomes stable.
+	cs.SetCandidate("linux", "1", []*cmdpb.Config{config("1")}, rollout)
+	if diff := cmp.Diff([]version{{Version: "1"}}, versions(cs.ConfigResp())); diff != "" {
+		t.Errorf("SetCandidate without stable: diff -want +got:\n%s", diff)
+	}
+
+	cs.SetCandidate("linux", "2", []*cmdpb.Config{config("2")}, rollout)
+	if got, want := cs.Seq("linux"), "2"; got != want {
+		t.Errorf("Seq(linux)=%q; want %q", got, want)
+	}
+	if got, want := cs.StableSeq("linux"), "1"; got != want {
+		t.Errorf("StableSeq(linux)=%q; want %q", got, want)
+	}
+	want := []version{
+		{Version: "1", Seq: "1"},
+		{Version: "2", Seq: "2", Candidate: true},
+	}
+	if diff := cmp.Diff(want, versions(cs.ConfigResp())); diff != "" {
+		t.Errorf("SetCandidate: diff -want +got:\n%s", diff)
+	}
+
+	if cs.Rollback("linux", "3") {
+		t.Errorf("Rollback(linux, 3)=true; want false for unknown seq")
+	}
+	if !cs.Rollback("linux", "2") {
+		t.Errorf("Rollback(linux, 2)=false; want true")
+	}
+	// rolled back seq won't be loaded again.
+	if got, want := cs.Seq("linux"), "2"; got != want {
+		t.Errorf("Seq(linux) after rollback=%q; want %q", got, want)
+	}
+	if diff := cmp.Diff([]version{{Version: "1"}}, versions(cs.ConfigResp())); diff != "" {
+		t.Errorf("Rollback: diff -want +got:\n%s", diff)
+	}
+
+	cs.SetCandidate("linux", "3", []*cmdpb.Config{config("3")}, rollout)
+	if !cs.Promote("linux", "3") {
+		t.Errorf("Promote(linux, 3)=false; want true")
+	}
+	if got, want := cs.Seq("linux"), "3"; got != want {
+		t.Errorf("Seq(linux) after promote=%q; want %q", got, want)
+	}
+	if diff := cmp.Diff([]version{{Version: "3"}}, versions(cs.ConfigResp())); diff != "" {
+		t.Errorf("Promote: diff -want +got:\n%s", diff)
+	}
+	if cs.Promote("linux", "3") {
+		t.Errorf("Promote(linux, 3) again=true; want false")
+	}
+}
+
+func TestConfigMapBucket(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "configmap_bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := blobstore.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(name string, data []byte) {
+		t.Helper()
+		_, err := store.Put(ctx, name, data, blobstore.PutOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	descriptor := func(version string) []byte {
+		t.Helper()
+		b, err := proto.Marshal(&cmdpb.CmdDescriptor{
+			Selector: &cmdpb.Selector{
+				Name:       "clang",
+				Version:    version,
+				Target:     "x86_64-unknown-linux-gnu",
+				BinaryHash: "clang-" + version,
+			},
+			Setup: &cmdpb.CmdDescriptor_Setup{
+				PathType: cmdpb.CmdDescriptor_POSIX,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	put("linux/chrome/descriptors/hash1", descriptor("1"))
+	put("linux/seq", []byte("1"))
+
+	cm := ConfigMapBucket{
+		URI: "file://toolchain-config/",
+		ConfigMap: &cmdpb.ConfigMap{
+			Runtimes: []*cmdpb.RuntimeConfig{
+				{
+					Name: "linux",
+				},
+				{
+					Name: "windows",
+				},
+			},
+		},
+		Store:          store,
+		RemoteexecAddr: "rbe.example.com:443",
+	}
+	bucket, err := cm.Bucket(ctx)
+	if err != nil || bucket != "toolchain-config" {
+		t.Errorf("Bucket(ctx)=%q, %v; want toolchain-config, nil", bucket, err)
+	}
+	seqs, err := cm.Seqs(ctx)
+	if err != nil || len(seqs) != 1 || seqs["linux"] != "1" {
+		t.Errorf("Seqs(ctx)=%v, %v; want map[linux:1], nil", seqs, err)
+	}
+	w := cm.Watcher(ctx)
+	defer w.Close()
+	if _, ok := w.(configMapBucketWatcher); !ok {
+		t.Fatalf("Watcher=%T; want configMapBucketWatcher", w)
+	}
+
+	loader := &ConfigMapLoader{
+		ConfigMap: cm,
+		ConfigLoader: ConfigLoader{
+			Store: store,
+		},
+	}
+	resp, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(ctx)=_, %v; want nil error", err)
+	}
+	if len(resp.Configs) != 1 || resp.Configs[0].GetCmdDescriptor().GetSelector().GetVersion() != "1" {
+		t.Errorf("Load(ctx)=%v; want version 1", resp.Configs)
+	}
+
+	put("linux/chrome/descriptors/hash2", descriptor("2"))
+	put("linux/seq", []byte("2"))
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = w.Next(wctx)
+	if err != nil {
+		t.Fatalf("Next(ctx)=%v; want nil error", err)
+	}
+	resp, err = loader.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(ctx) after update=_, %v; want nil error", err)
+	}
+	if len(resp.Configs) != 2 {
+		t.Errorf("Load(ctx) after update=%v; want 2 configs", resp.Configs)
+	}
+}
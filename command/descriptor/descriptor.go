@@ -279,7 +279,7 @@ func (d *Descriptor) relocCmdSetup() error {
 	if err != nil {
 		return err
 	}
-	// ignore error, since fname may not be ELF executable.
+	// ignore error, since fname may not be ELF, Mach-O or PE executable.
 	_ = d.relocatableLibraries(fname)
 
 	if d.Setup.PathType == pb.CmdDescriptor_WINDOWS {
@@ -29,11 +29,11 @@ func removeAbsPaths(rpaths []string) []string {
 	return newRpaths
 }
 
-// relocateLibraries reads an elf binary to list dependent libraries.
+// relocatableELFLibraries reads an elf binary to list dependent libraries.
 // If a dependent library exists in rpath or runpath, it's considered as
 // relocatable. However, rpath or runpath is in absolute form, the dependent
 // library is considered as unrelocatable.
-func relocatableLibraries(fname string) ([]string, error) {
+func relocatableELFLibraries(fname string) ([]string, error) {
 	f, err := elf.Open(fname)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,53 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package descriptor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	elfMagic = []byte("\x7fELF")
+	peMagic  = []byte("MZ")
+
+	machoMagics = [][]byte{
+		{0xfe, 0xed, 0xfa, 0xce}, // 32-bit big endian
+		{0xce, 0xfa, 0xed, 0xfe}, // 32-bit little endian
+		{0xfe, 0xed, 0xfa, 0xcf}, // 64-bit big endian
+		{0xcf, 0xfa, 0xed, 0xfe}, // 64-bit little endian
+		{0xca, 0xfe, 0xba, 0xbe}, // fat (universal) binary
+	}
+)
+
+// relocatableLibraries lists dependent libraries of fname that are
+// considered as relocatable, i.e. found relative to fname.
+// It supports ELF, Mach-O and PE, selected by file format of fname.
+func relocatableLibraries(fname string) ([]string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(f, magic)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unknown file format: %v", fname, err)
+	}
+	switch {
+	case bytes.Equal(magic, elfMagic):
+		return relocatableELFLibraries(fname)
+	case bytes.HasPrefix(magic, peMagic):
+		return relocatablePELibraries(fname)
+	}
+	for _, m := range machoMagics {
+		if bytes.Equal(magic, m) {
+			return relocatableMachOLibraries(fname)
+		}
+	}
+	return nil, fmt.Errorf("%s: unknown file format: %x", fname, magic)
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package descriptor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Fixtures in testdata are minimal binaries that only have headers
+// needed to list dependent libraries.
+//
+// macho/bin/clang: 64-bit Mach-O with
+//   LC_LOAD_DYLIB @rpath/libLLVM.dylib
+//   LC_LOAD_WEAK_DYLIB @loader_path/../lib/libweak.dylib
+//   LC_LOAD_DYLIB @rpath/libmissing.dylib
+//   LC_LOAD_DYLIB /usr/lib/libSystem.B.dylib
+//   LC_RPATH /usr/local/lib
+//   LC_RPATH @executable_path/../lib
+//
+// pe/bin/clang-cl.exe: PE32+ importing
+//   KERNEL32.dll, LLVM-C.DLL, missing.dll, api-ms-win-crt-runtime-l1-1-0.dll
+func TestRelocatableLibraries(t *testing.T) {
+	for _, tc := range []struct {
+		fname string
+		want  []string
+	}{
+		{
+			fname: "testdata/macho/bin/clang",
+			want: []string{
+				"testdata/macho/lib/libLLVM.dylib",
+				"testdata/macho/lib/libweak.dylib",
+			},
+		},
+		{
+			fname: "testdata/pe/bin/clang-cl.exe",
+			want: []string{
+				"testdata/pe/bin/llvm-c.dll",
+			},
+		},
+	} {
+		got, err := relocatableLibraries(tc.fname)
+		if err != nil {
+			t.Errorf("relocatableLibraries(%q)=%q, %v; want nil error", tc.fname, got, err)
+			continue
+		}
+		var want []string
+		for _, w := range tc.want {
+			want = append(want, filepath.FromSlash(w))
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("relocatableLibraries(%q): diff -want +got:\n%s", tc.fname, diff)
+		}
+	}
+}
+
+func TestRelocatableLibrariesUnknownFormat(t *testing.T) {
+	fname := "testdata/macho/lib/libLLVM.dylib"
+	got, err := relocatableLibraries(fname)
+	if err == nil {
+		t.Errorf("relocatableLibraries(%q)=%q, nil; want error", fname, got)
+	}
+}
+
+func TestExpandMachOPath(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want string
+	}{
+		{
+			path: "@loader_path/../lib",
+			want: "/opt/clang/lib",
+		},
+		{
+			path: "@executable_path/libfoo.dylib",
+			want: "/opt/clang/bin/libfoo.dylib",
+		},
+		{
+			path: "@rpath/libfoo.dylib",
+			want: "@rpath/libfoo.dylib",
+		},
+		{
+			path: "/usr/lib/libSystem.B.dylib",
+			want: "/usr/lib/libSystem.B.dylib",
+		},
+	} {
+		got := expandMachOPath(tc.path, "/opt/clang/bin")
+		if got != filepath.FromSlash(tc.want) {
+			t.Errorf("expandMachOPath(%q, %q)=%q; want %q", tc.path, "/opt/clang/bin", got, tc.want)
+		}
+	}
+}
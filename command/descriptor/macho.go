@@ -0,0 +1,135 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package descriptor
+
+import (
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+)
+
+// load commands not defined in debug/macho.
+const (
+	machoLoadCmdLoadWeakDylib macho.LoadCmd = 0x80000018
+	machoLoadCmdRpath         macho.LoadCmd = 0x8000001c
+	machoLoadCmdReexportDylib macho.LoadCmd = 0x8000001f
+)
+
+// relocatableMachOLibraries reads a Mach-O binary to list dependent
+// libraries.
+// Libraries referred by "@loader_path/", "@executable_path/", or
+// "@rpath/" with LC_RPATH relative to those, are considered as
+// relocatable. Libraries in absolute path are considered as
+// unrelocatable, same as absolute rpath.
+// For fat binary, it lists libraries of all architectures.
+func relocatableMachOLibraries(fname string) ([]string, error) {
+	var files []*macho.File
+	f, err := macho.Open(fname)
+	switch err.(type) {
+	case nil:
+		defer f.Close()
+		files = append(files, f)
+	case *macho.FormatError:
+		ff, ferr := macho.OpenFat(fname)
+		if ferr != nil {
+			return nil, err
+		}
+		defer ff.Close()
+		for _, arch := range ff.Arches {
+			files = append(files, arch.File)
+		}
+	default:
+		return nil, err
+	}
+
+	loaderPath := filepath.Dir(fname)
+	seen := make(map[string]bool)
+	var libpaths []string
+	for _, f := range files {
+		libs, rpaths := machoDylibs(f)
+		rpaths = removeAbsPaths(rpaths)
+		for i, rpath := range rpaths {
+			rpaths[i] = expandMachOPath(rpath, loaderPath)
+		}
+		for _, lib := range libs {
+			var name string
+			var dirs []string
+			switch {
+			case strings.HasPrefix(lib, "@rpath/"):
+				name = strings.TrimPrefix(lib, "@rpath/")
+				dirs = rpaths
+			case strings.HasPrefix(lib, "@"):
+				lib = expandMachOPath(lib, loaderPath)
+				name = filepath.Base(lib)
+				dirs = []string{filepath.Dir(lib)}
+			default:
+				// absolute path, i.e. system library.
+				continue
+			}
+			path, err := lookpath(name, dirs)
+			if err != nil {
+				continue
+			}
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			libpaths = append(libpaths, path)
+		}
+	}
+	return libpaths, nil
+}
+
+// machoDylibs returns dylib names and rpaths in load commands of f.
+func machoDylibs(f *macho.File) ([]string, []string) {
+	var libs, rpaths []string
+	for _, l := range f.Loads {
+		raw := l.Raw()
+		if len(raw) < 12 {
+			continue
+		}
+		cmd := macho.LoadCmd(f.ByteOrder.Uint32(raw[0:4]))
+		switch cmd {
+		case macho.LoadCmdDylib, machoLoadCmdLoadWeakDylib, machoLoadCmdReexportDylib:
+			// dylib_command: cmd, cmdsize, dylib.name offset, ...
+			if name, ok := machoLoadCmdString(f.ByteOrder, raw, 8); ok {
+				libs = append(libs, name)
+			}
+		case machoLoadCmdRpath:
+			// rpath_command: cmd, cmdsize, path offset.
+			if path, ok := machoLoadCmdString(f.ByteOrder, raw, 8); ok {
+				rpaths = append(rpaths, path)
+			}
+		}
+	}
+	return libs, rpaths
+}
+
+// machoLoadCmdString returns NUL terminated string in load command raw,
+// whose offset is stored at off.
+func machoLoadCmdString(bo binary.ByteOrder, raw []byte, off int) (string, bool) {
+	o := int(bo.Uint32(raw[off : off+4]))
+	if o >= len(raw) {
+		return "", false
+	}
+	b := raw[o:]
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b), true
+}
+
+// expandMachOPath expands "@loader_path" and "@executable_path" in path.
+// It assumes loader is the executable.
+func expandMachOPath(path, loaderPath string) string {
+	for _, tok := range []string{"@loader_path", "@executable_path"} {
+		if strings.HasPrefix(path, tok) {
+			return filepath.Join(loaderPath, strings.TrimPrefix(path, tok))
+		}
+	}
+	return path
+}
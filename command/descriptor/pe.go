@@ -0,0 +1,71 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package descriptor
+
+import (
+	"debug/pe"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// relocatablePELibraries reads a PE binary to list dependent DLLs.
+// DLLs in the same directory as the binary (side-by-side DLLs) are
+// considered as relocatable, since the application directory is searched
+// first for DLLs. Other DLLs (e.g. system DLLs) are ignored.
+// DLL names are matched case-insensitively, as Windows does.
+func relocatablePELibraries(fname string) ([]string, error) {
+	f, err := pe.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// pe.File.ImportedLibraries is not implemented, so get DLL names
+	// from imported symbols, "<symbol>:<dll>".
+	syms, err := f.ImportedSymbols()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var dlls []string
+	for _, sym := range syms {
+		i := strings.LastIndex(sym, ":")
+		if i < 0 {
+			continue
+		}
+		dll := strings.ToLower(sym[i+1:])
+		if seen[dll] {
+			continue
+		}
+		seen[dll] = true
+		dlls = append(dlls, dll)
+	}
+	if len(dlls) == 0 {
+		return nil, nil
+	}
+
+	dir := filepath.Dir(fname)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		names[strings.ToLower(fi.Name())] = fi.Name()
+	}
+	var libpaths []string
+	for _, dll := range dlls {
+		name, ok := names[dll]
+		if !ok {
+			continue
+		}
+		libpaths = append(libpaths, filepath.Join(dir, name))
+	}
+	return libpaths, nil
+}
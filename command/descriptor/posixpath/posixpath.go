@@ -25,6 +25,8 @@ func (FilePath) Rel(basepath, targpath string) (string, error) {
 func (FilePath) Clean(path string) string       { return Clean(path) }
 func (FilePath) SplitElem(path string) []string { return SplitElem(path) }
 func (FilePath) PathSep() string                { return "/" }
+func (FilePath) EqualElem(a, b string) bool     { return EqualElem(a, b) }
+func (FilePath) IsRoot(path string) bool        { return IsRoot(path) }
 
 // IsAbs returns true if fname is absolute path.
 func IsAbs(fname string) bool {
@@ -137,6 +139,17 @@ func Clean(path string) string {
 	return joinElem(elems)
 }
 
+// EqualElem reports whether path elements a and b are the same.
+// It is case sensitive.
+func EqualElem(a, b string) bool {
+	return a == b
+}
+
+// IsRoot reports whether path is the root directory.
+func IsRoot(path string) bool {
+	return path != "" && Clean(path) == "/"
+}
+
 // SplitElem splits path into element, separated by "/".
 // If fname is absolute path, first element is "/".
 // If fname ends with "/" or "/.", last element is ".".
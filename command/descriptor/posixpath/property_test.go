@@ -0,0 +1,118 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package posixpath
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// genPath is a random posix path, generated from small set of
+// elements so that paths share elements often.
+type genPath string
+
+func (genPath) Generate(r *rand.Rand, size int) reflect.Value {
+	elems := []string{"a", "b", "Foo", "foo.cc", ".", "..", ""}
+	var sb strings.Builder
+	if r.Intn(2) == 0 {
+		sb.WriteString("/")
+	}
+	n := r.Intn(size%8 + 1)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString("/")
+		}
+		sb.WriteString(elems[r.Intn(len(elems))])
+	}
+	return reflect.ValueOf(genPath(sb.String()))
+}
+
+// genAbsPath is a random absolute posix path.
+type genAbsPath string
+
+func (genAbsPath) Generate(r *rand.Rand, size int) reflect.Value {
+	p := genPath("").Generate(r, size).Interface().(genPath)
+	return reflect.ValueOf(genAbsPath("/" + string(p)))
+}
+
+func TestPropertyCleanIdempotent(t *testing.T) {
+	f := func(p genPath) bool {
+		c := Clean(string(p))
+		return Clean(c) == c
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyCleanElems(t *testing.T) {
+	f := func(p genPath) bool {
+		c := Clean(string(p))
+		if c == "." || c == "/" {
+			return true
+		}
+		if IsAbs(c) != IsAbs(string(p)) {
+			return false
+		}
+		for i, e := range SplitElem(c) {
+			if i == 0 && e == "/" {
+				continue
+			}
+			if e == "" || e == "." || e == "/" {
+				return false
+			}
+		}
+		return !strings.HasSuffix(c, "/")
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyJoinSplitElem(t *testing.T) {
+	f := func(p genPath) bool {
+		return Clean(Join(SplitElem(string(p))...)) == Clean(string(p))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyRelJoin(t *testing.T) {
+	f := func(base, targ genAbsPath) bool {
+		rel, err := Rel(string(base), string(targ))
+		if err != nil {
+			return false
+		}
+		if IsAbs(rel) {
+			return false
+		}
+		return Clean(Join(string(base), rel)) == Clean(string(targ))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyIsRoot(t *testing.T) {
+	f := func(p genPath) bool {
+		return IsRoot(string(p)) == (IsAbs(string(p)) && Clean(string(p)) == "/")
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyEqualElem(t *testing.T) {
+	f := func(a, b string) bool {
+		return EqualElem(a, a) && EqualElem(a, b) == EqualElem(b, a) && EqualElem(a, b) == (a == b)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
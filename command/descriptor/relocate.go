@@ -44,6 +44,12 @@ type FilePath interface {
 
 	// PathSep returns the path separator.
 	PathSep() string
+
+	// EqualElem reports whether path elements are the same.
+	EqualElem(a, b string) bool
+
+	// IsRoot reports whether path is the root directory.
+	IsRoot(path string) bool
 }
 
 // FilePathOf returns FilePath of path type.
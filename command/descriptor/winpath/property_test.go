@@ -0,0 +1,124 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package winpath
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// genPath is a random windows path, generated from small set of
+// elements so that paths share elements often.
+// It may have drive letter, and may use `\` and "/" mixed.
+type genPath string
+
+func (genPath) Generate(r *rand.Rand, size int) reflect.Value {
+	elems := []string{"a", "B", "Foo", "foo.cc", ".", "..", ""}
+	seps := []string{`\`, "/"}
+	var sb strings.Builder
+	if r.Intn(2) == 0 {
+		sb.WriteString([]string{"c:", "C:", "d:"}[r.Intn(3)])
+	}
+	if r.Intn(2) == 0 {
+		sb.WriteString(seps[r.Intn(len(seps))])
+	}
+	n := r.Intn(size%8 + 1)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(seps[r.Intn(len(seps))])
+		}
+		sb.WriteString(elems[r.Intn(len(elems))])
+	}
+	return reflect.ValueOf(genPath(sb.String()))
+}
+
+// genAbsPath is a random absolute windows path on drive C.
+type genAbsPath string
+
+func (genAbsPath) Generate(r *rand.Rand, size int) reflect.Value {
+	p := genPath("").Generate(r, size).Interface().(genPath)
+	_, path := splitDrive(string(p))
+	return reflect.ValueOf(genAbsPath(`C:\` + path))
+}
+
+func TestPropertyCleanIdempotent(t *testing.T) {
+	f := func(p genPath) bool {
+		c := Clean(string(p))
+		return Clean(c) == c
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyCleanSeparator(t *testing.T) {
+	f := func(p genPath) bool {
+		c := Clean(string(p))
+		if strings.Contains(c, "/") {
+			return false
+		}
+		return IsAbs(c) == IsAbs(string(p))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyJoinSplitElem(t *testing.T) {
+	f := func(p genPath) bool {
+		return Clean(Join(SplitElem(string(p))...)) == Clean(string(p))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyRelJoin(t *testing.T) {
+	f := func(base, targ genAbsPath) bool {
+		rel, err := Rel(string(base), string(targ))
+		if err != nil {
+			return false
+		}
+		if IsAbs(rel) {
+			return false
+		}
+		return Clean(Join(string(base), rel)) == Clean(string(targ))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyIsRoot(t *testing.T) {
+	f := func(p genPath) bool {
+		elems := SplitElem(Clean(string(p)))
+		want := IsAbs(string(p)) && len(elems) == 1
+		return IsRoot(string(p)) == want
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyEqualElem(t *testing.T) {
+	f := func(a, b genPath) bool {
+		if !EqualElem(string(a), string(a)) {
+			return false
+		}
+		if EqualElem(string(a), string(b)) != EqualElem(string(b), string(a)) {
+			return false
+		}
+		if !EqualElem(string(a), strings.ToUpper(string(a))) {
+			return false
+		}
+		return EqualElem(string(a), strings.Replace(string(a), `\`, "/", -1))
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -33,6 +33,8 @@ func (FilePath) Rel(basepath, targpath string) (string, error) {
 func (FilePath) Clean(path string) string       { return Clean(path) }
 func (FilePath) SplitElem(path string) []string { return SplitElem(path) }
 func (FilePath) PathSep() string                { return `\` }
+func (FilePath) EqualElem(a, b string) bool     { return EqualElem(a, b) }
+func (FilePath) IsRoot(path string) bool        { return IsRoot(path) }
 
 // IsAbs returns true if fname is absolute path.
 func IsAbs(fname string) bool {
@@ -119,6 +121,20 @@ func Clean(path string) string {
 	return drive + fixPathSep(path, '/', '\\')
 }
 
+// EqualElem reports whether path elements a and b are the same.
+// It is case insensitive, and `\` and "/" are the same.
+func EqualElem(a, b string) bool {
+	a = fixPathSep(a, '/', '\\')
+	b = fixPathSep(b, '/', '\\')
+	return strings.EqualFold(a, b)
+}
+
+// IsRoot reports whether path is the root directory of a drive,
+// e.g. `C:\`.
+func IsRoot(path string) bool {
+	return IsAbs(path) && len(Clean(path)) == 3
+}
+
 // SplitElem splits path into element, separated by `\` or "/".
 // If fname is absolute path, first element is `\` or `<drive>:\`,
 // otherwise if fname has drive, first element is `<drive>:`.
@@ -5,6 +5,11 @@
 /*
 Package pathconv provides path converter between client and server.
 
+Default is for posix client and posix server with the same paths.
+PrefixMap is for posix client that has different directories from server,
+and Windows is for windows client on posix server.
+Use ForPathStyle to select converter for client's path style.
+
 TODO: Do we want to introduce ClientPath and ServerPath type so that
 we won't be confused the path is client path or server path?
 */
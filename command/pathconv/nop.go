@@ -37,21 +37,5 @@ func (nopPathConverter) IsSafeClient(path string) bool {
 	if !posixpath.IsAbs(path) {
 		return false
 	}
-	elems := strings.Split(path, "/")
-	depth := 0
-	for _, elem := range elems[1:] {
-		switch elem {
-		case "", ".":
-			continue
-		case "..":
-			depth--
-			if depth < 0 {
-				return false
-			}
-		default:
-			depth++
-		}
-	}
-
-	return true
+	return isSafeElems(strings.Split(path, "/")[1:])
 }
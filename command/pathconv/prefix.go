@@ -0,0 +1,122 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.chromium.org/goma/server/command/descriptor/posixpath"
+)
+
+// PrefixMapping maps client path prefix to server path prefix.
+type PrefixMapping struct {
+	Client string
+	Server string
+}
+
+// ParsePrefixMappings parses comma separated "<client>=<server>" pairs.
+func ParsePrefixMappings(s string) ([]PrefixMapping, error) {
+	var mappings []PrefixMapping
+	if s == "" {
+		return nil, nil
+	}
+	for _, m := range strings.Split(s, ",") {
+		i := strings.Index(m, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("bad prefix mapping %q: want <client>=<server>", m)
+		}
+		mappings = append(mappings, PrefixMapping{
+			Client: m[:i],
+			Server: m[i+1:],
+		})
+	}
+	return mappings, nil
+}
+
+// prefixPathConverter converts posix client path to posix server path
+// by replacing path prefix.
+type prefixPathConverter struct {
+	nopPathConverter
+	// sorted by Client.
+	mappings []PrefixMapping
+}
+
+// PrefixMap returns a path converter for posix client on posix server,
+// which have different directories, e.g. home directory or checkout root.
+// Path under mapping's Client is converted to path under its Server, and
+// vice versa. Path not under any mapping is not converted, but it is error
+// if such path is under other side's prefix, since it can't be converted
+// back.
+// Client and Server should be absolute path other than root, and client prefixes
+// (and server prefixes) should not be nested each other, so conversion
+// is reversible.
+func PrefixMap(mappings ...PrefixMapping) (PathConverter, error) {
+	c := prefixPathConverter{}
+	for _, m := range mappings {
+		if !posixpath.IsAbs(m.Client) || !posixpath.IsAbs(m.Server) {
+			return nil, fmt.Errorf("prefix mapping %s=%s: must be absolute path", m.Client, m.Server)
+		}
+		m = PrefixMapping{
+			Client: posixpath.Clean(m.Client),
+			Server: posixpath.Clean(m.Server),
+		}
+		if m.Client == "/" || m.Server == "/" {
+			return nil, fmt.Errorf("prefix mapping %s=%s: must not be root", m.Client, m.Server)
+		}
+		c.mappings = append(c.mappings, m)
+	}
+	for i, a := range c.mappings {
+		for _, b := range c.mappings[i+1:] {
+			if hasPrefixDir(a.Client, b.Client) || hasPrefixDir(b.Client, a.Client) {
+				return nil, fmt.Errorf("prefix mapping: nested client prefix %s and %s", a.Client, b.Client)
+			}
+			if hasPrefixDir(a.Server, b.Server) || hasPrefixDir(b.Server, a.Server) {
+				return nil, fmt.Errorf("prefix mapping: nested server prefix %s and %s", a.Server, b.Server)
+			}
+		}
+	}
+	sort.Slice(c.mappings, func(i, j int) bool {
+		return c.mappings[i].Client < c.mappings[j].Client
+	})
+	return c, nil
+}
+
+func (c prefixPathConverter) ToClientPath(path string) (string, error) {
+	for _, m := range c.mappings {
+		if hasPrefixDir(path, m.Server) {
+			return m.Client + strings.TrimPrefix(path, m.Server), nil
+		}
+	}
+	for _, m := range c.mappings {
+		if hasPrefixDir(path, m.Client) {
+			return "", fmt.Errorf("unmapped server path %q is under client prefix %s", path, m.Client)
+		}
+	}
+	return path, nil
+}
+
+func (c prefixPathConverter) ToServerPath(path string) (string, error) {
+	for _, m := range c.mappings {
+		if hasPrefixDir(path, m.Client) {
+			return m.Server + strings.TrimPrefix(path, m.Client), nil
+		}
+	}
+	for _, m := range c.mappings {
+		if hasPrefixDir(path, m.Server) {
+			return "", fmt.Errorf("unmapped client path %q is under server prefix %s", path, m.Server)
+		}
+	}
+	return path, nil
+}
+
+// hasPrefixDir reports whether p has prefix as a directory name.
+func hasPrefixDir(p, prefix string) bool {
+	if !strings.HasPrefix(p, prefix) {
+		return false
+	}
+	return len(p) == len(prefix) || p[len(prefix)] == '/'
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePrefixMappings(t *testing.T) {
+	got, err := ParsePrefixMappings("/home/alice=/b/home,/Users/bob/src=/b/src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PrefixMapping{
+		{Client: "/home/alice", Server: "/b/home"},
+		{Client: "/Users/bob/src", Server: "/b/src"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParsePrefixMappings: diff -want +got:\n%s", diff)
+	}
+
+	for _, s := range []string{"/home/alice", "/a=/b,"} {
+		got, err := ParsePrefixMappings(s)
+		if err == nil {
+			t.Errorf("ParsePrefixMappings(%q)=%v, nil; want error", s, got)
+		}
+	}
+}
+
+func TestPrefixPathConverter(t *testing.T) {
+	c, err := PrefixMap(
+		PrefixMapping{Client: "/home/alice/", Server: "/b/home"},
+		PrefixMapping{Client: "/Users/bob/src", Server: "/b/src"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		clientPath string
+		serverPath string
+	}{
+		{
+			clientPath: "/home/alice/src/foo.cc",
+			serverPath: "/b/home/src/foo.cc",
+		},
+		{
+			clientPath: "/home/alice",
+			serverPath: "/b/home",
+		},
+		{
+			clientPath: "/Users/bob/src/out/foo.o",
+			serverPath: "/b/src/out/foo.o",
+		},
+		{
+			clientPath: "/home/alicex/foo.cc",
+			serverPath: "/home/alicex/foo.cc",
+		},
+		{
+			clientPath: "/usr/include/stdio.h",
+			serverPath: "/usr/include/stdio.h",
+		},
+	} {
+		got, err := c.ToServerPath(tc.clientPath)
+		if err != nil || got != tc.serverPath {
+			t.Errorf("ToServerPath(%q)=%q, %v; want %q, nil", tc.clientPath, got, err, tc.serverPath)
+		}
+		got, err = c.ToClientPath(tc.serverPath)
+		if err != nil || got != tc.clientPath {
+			t.Errorf("ToClientPath(%q)=%q, %v; want %q, nil", tc.serverPath, got, err, tc.clientPath)
+		}
+	}
+}
+
+func TestPrefixPathConverterAmbiguous(t *testing.T) {
+	c, err := PrefixMap(PrefixMapping{Client: "/home/alice", Server: "/b/home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.ToServerPath("/b/home/foo.cc")
+	if err == nil {
+		t.Errorf("ToServerPath(%q)=%q, nil; want error", "/b/home/foo.cc", got)
+	}
+	got, err = c.ToClientPath("/home/alice/foo.cc")
+	if err == nil {
+		t.Errorf("ToClientPath(%q)=%q, nil; want error", "/home/alice/foo.cc", got)
+	}
+}
+
+func TestPrefixMapBadMappings(t *testing.T) {
+	for _, mappings := range [][]PrefixMapping{
+		{{Client: "home/alice", Server: "/b/home"}},
+		{{Client: "/home/alice", Server: "b/home"}},
+		{{Client: "/", Server: "/b"}},
+		{
+			{Client: "/home", Server: "/b/home"},
+			{Client: "/home/alice", Server: "/b/alice"},
+		},
+		{
+			{Client: "/home", Server: "/b"},
+			{Client: "/src", Server: "/b/src"},
+		},
+	} {
+		_, err := PrefixMap(mappings...)
+		if err == nil {
+			t.Errorf("PrefixMap(%v)=_, nil; want error", mappings)
+		}
+	}
+}
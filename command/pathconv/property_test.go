@@ -0,0 +1,88 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"go.chromium.org/goma/server/command/descriptor/posixpath"
+	"go.chromium.org/goma/server/command/descriptor/winpath"
+)
+
+// genElems is random path elements, which may include "." and "..".
+type genElems []string
+
+func (genElems) Generate(r *rand.Rand, size int) reflect.Value {
+	elems := []string{"a", "B", "Foo", "foo.cc", "src", "alice", ".", ".."}
+	n := r.Intn(size%8 + 1)
+	var e genElems
+	for i := 0; i < n; i++ {
+		e = append(e, elems[r.Intn(len(elems))])
+	}
+	return reflect.ValueOf(e)
+}
+
+func TestPropertyWindowsRoundTrip(t *testing.T) {
+	c, err := Windows("/mnt/win")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := func(drive byte, elems genElems, slash bool) bool {
+		drive = 'a' + drive%26
+		sep := `\`
+		if slash {
+			sep = "/"
+		}
+		client := string(drive) + `:` + sep + strings.Join(elems, sep)
+		server, err := c.ToServerPath(client)
+		if err != nil {
+			return false
+		}
+		if !posixpath.IsAbs(server) || strings.ToLower(server) != server {
+			return false
+		}
+		got, err := c.ToClientPath(server)
+		if err != nil {
+			return false
+		}
+		// client path is cleaned and case folded.
+		return winpath.EqualElem(got, winpath.Clean(client)) && winpath.Clean(got) == got
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyPrefixRoundTrip(t *testing.T) {
+	c, err := PrefixMap(
+		PrefixMapping{Client: "/home/alice", Server: "/b/home"},
+		PrefixMapping{Client: "/Users/alice/src", Server: "/b/src"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := []string{"/home/alice", "/Users/alice/src", "/usr", "/b", "/home"}
+	f := func(root byte, elems genElems) bool {
+		client := posixpath.Join(append([]string{roots[int(root)%len(roots)]}, elems...)...)
+		server, err := c.ToServerPath(client)
+		if err != nil {
+			// client path not mapped, but under server prefix.
+			return !hasPrefixDir(client, "/home/alice") && !hasPrefixDir(client, "/Users/alice/src") &&
+				(hasPrefixDir(client, "/b/home") || hasPrefixDir(client, "/b/src"))
+		}
+		got, err := c.ToClientPath(server)
+		if err != nil {
+			return false
+		}
+		return got == client
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
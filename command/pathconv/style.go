@@ -0,0 +1,39 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"errors"
+	"fmt"
+
+	gomapb "go.chromium.org/goma/server/proto/api"
+)
+
+// Options is options to select path converter.
+type Options struct {
+	// WindowsMountRoot is a server directory where windows client's
+	// drives are mounted. e.g. "/mnt/win" for `C:\` on "/mnt/win/c".
+	WindowsMountRoot string
+
+	// PrefixMappings are mappings of posix client path to server path.
+	PrefixMappings []PrefixMapping
+}
+
+// ForPathStyle returns a path converter for client's path style.
+func ForPathStyle(style gomapb.RequesterInfo_PathStyle, opts Options) (PathConverter, error) {
+	switch style {
+	case gomapb.RequesterInfo_WINDOWS_STYLE:
+		if opts.WindowsMountRoot == "" {
+			return nil, errors.New("no windows mount root for windows client")
+		}
+		return Windows(opts.WindowsMountRoot)
+	case gomapb.RequesterInfo_UNKNOWN_STYLE, gomapb.RequesterInfo_POSIX_STYLE:
+		if len(opts.PrefixMappings) == 0 {
+			return Default(), nil
+		}
+		return PrefixMap(opts.PrefixMappings...)
+	}
+	return nil, fmt.Errorf("unknown path style: %v", style)
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"testing"
+
+	gomapb "go.chromium.org/goma/server/proto/api"
+)
+
+func TestForPathStyle(t *testing.T) {
+	opts := Options{
+		WindowsMountRoot: "/mnt/win",
+		PrefixMappings: []PrefixMapping{
+			{Client: "/home/alice", Server: "/b/home"},
+		},
+	}
+	for _, tc := range []struct {
+		style      gomapb.RequesterInfo_PathStyle
+		opts       Options
+		clientPath string
+		want       string
+	}{
+		{
+			style:      gomapb.RequesterInfo_WINDOWS_STYLE,
+			opts:       opts,
+			clientPath: `C:\src\foo.cc`,
+			want:       "/mnt/win/c/src/foo.cc",
+		},
+		{
+			style:      gomapb.RequesterInfo_POSIX_STYLE,
+			opts:       opts,
+			clientPath: "/home/alice/foo.cc",
+			want:       "/b/home/foo.cc",
+		},
+		{
+			style:      gomapb.RequesterInfo_UNKNOWN_STYLE,
+			clientPath: "/home/alice/foo.cc",
+			want:       "/home/alice/foo.cc",
+		},
+	} {
+		c, err := ForPathStyle(tc.style, tc.opts)
+		if err != nil {
+			t.Errorf("ForPathStyle(%v, %v)=_, %v; want nil error", tc.style, tc.opts, err)
+			continue
+		}
+		got, err := c.ToServerPath(tc.clientPath)
+		if err != nil || got != tc.want {
+			t.Errorf("ForPathStyle(%v).ToServerPath(%q)=%q, %v; want %q, nil", tc.style, tc.clientPath, got, err, tc.want)
+		}
+	}
+
+	_, err := ForPathStyle(gomapb.RequesterInfo_WINDOWS_STYLE, Options{})
+	if err == nil {
+		t.Errorf("ForPathStyle(WINDOWS_STYLE, {})=_, nil; want error")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"fmt"
+	"strings"
+
+	"go.chromium.org/goma/server/command/descriptor/posixpath"
+	"go.chromium.org/goma/server/command/descriptor/winpath"
+)
+
+// windowsPathConverter converts windows client path to posix server path
+// under mount root.
+// e.g. `C:\src\Foo.cc` <-> "<mountRoot>/c/src/foo.cc".
+type windowsPathConverter struct {
+	mountRoot string
+}
+
+// Windows returns a path converter for windows client on posix server.
+// Drive letters are mapped to directories under mountRoot, and paths are
+// case folded to lower case since windows path is case insensitive.
+// Both `\` and "/" are accepted as path separator of client path.
+func Windows(mountRoot string) (PathConverter, error) {
+	if !posixpath.IsAbs(mountRoot) {
+		return nil, fmt.Errorf("mount root must be absolute: %q", mountRoot)
+	}
+	return windowsPathConverter{
+		mountRoot: posixpath.Clean(mountRoot),
+	}, nil
+}
+
+// ToClientPath converts server path under mount root to windows path.
+// Relative path (e.g. files of relocatable toolchain) is converted to
+// relative windows path.
+func (c windowsPathConverter) ToClientPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	if !posixpath.IsAbs(path) {
+		return strings.Join(posixpath.SplitElem(posixpath.Clean(path)), `\`), nil
+	}
+	rel, err := posixpath.Rel(c.mountRoot, posixpath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	elems := posixpath.SplitElem(rel)
+	if len(elems) == 0 || len(elems[0]) != 1 || !isDriveLetter(elems[0][0]) {
+		return "", fmt.Errorf("not under drive in %s: %q", c.mountRoot, path)
+	}
+	drive := strings.ToUpper(elems[0]) + `:\`
+	return drive + strings.Join(elems[1:], `\`), nil
+}
+
+// ToServerPath converts absolute windows path to server path.
+func (c windowsPathConverter) ToServerPath(path string) (string, error) {
+	if !winpath.IsAbs(path) {
+		return "", fmt.Errorf("not absolute windows path: %q", path)
+	}
+	elems := winpath.SplitElem(strings.ToLower(winpath.Clean(path)))
+	elems = append([]string{c.mountRoot, elems[0][:1]}, elems[1:]...)
+	return posixpath.Join(elems...), nil
+}
+
+func (windowsPathConverter) IsAbsClient(path string) bool {
+	return winpath.IsAbs(path)
+}
+
+func (windowsPathConverter) JoinClient(elem ...string) string {
+	return winpath.Join(elem...)
+}
+
+func (windowsPathConverter) IsSafeClient(path string) bool {
+	if !winpath.IsAbs(path) {
+		return false
+	}
+	return isSafeElems(winpath.SplitElem(path)[1:])
+}
+
+func isDriveLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// isSafeElems reports whether elems won't go out from root directory.
+func isSafeElems(elems []string) bool {
+	depth := 0
+	for _, elem := range elems {
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		default:
+			depth++
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package pathconv
+
+import (
+	"testing"
+)
+
+func TestWindowsPathConverter(t *testing.T) {
+	c, err := Windows("/mnt/win/")
+	if err != nil {
+		t.Fatalf("Windows(%q)=_, %v; want nil error", "/mnt/win/", err)
+	}
+
+	for _, tc := range []struct {
+		clientPath string
+		serverPath string
+		// clientPath converted back from serverPath.
+		want string
+	}{
+		{
+			clientPath: `C:\src\Foo.cc`,
+			serverPath: "/mnt/win/c/src/foo.cc",
+			want:       `C:\src\foo.cc`,
+		},
+		{
+			clientPath: `d:/b/../out/Release/obj\base.obj`,
+			serverPath: "/mnt/win/d/out/release/obj/base.obj",
+			want:       `D:\out\release\obj\base.obj`,
+		},
+		{
+			clientPath: `C:\`,
+			serverPath: "/mnt/win/c",
+			want:       `C:\`,
+		},
+	} {
+		got, err := c.ToServerPath(tc.clientPath)
+		if err != nil || got != tc.serverPath {
+			t.Errorf("ToServerPath(%q)=%q, %v; want %q, nil", tc.clientPath, got, err, tc.serverPath)
+		}
+		got, err = c.ToClientPath(tc.serverPath)
+		if err != nil || got != tc.want {
+			t.Errorf("ToClientPath(%q)=%q, %v; want %q, nil", tc.serverPath, got, err, tc.want)
+		}
+	}
+
+	for _, p := range []string{`src\foo.cc`, "/src/foo.cc", ""} {
+		got, err := c.ToServerPath(p)
+		if err == nil {
+			t.Errorf("ToServerPath(%q)=%q, nil; want error", p, got)
+		}
+	}
+	for _, tc := range []struct {
+		serverPath string
+		want       string
+	}{
+		{
+			serverPath: "../../third_party/llvm-build/Release+Asserts/bin/clang-cl.exe",
+			want:       `..\..\third_party\llvm-build\Release+Asserts\bin\clang-cl.exe`,
+		},
+		{
+			serverPath: "./bin/../lib/Foo.dll",
+			want:       `lib\Foo.dll`,
+		},
+	} {
+		got, err := c.ToClientPath(tc.serverPath)
+		if err != nil || got != tc.want {
+			t.Errorf("ToClientPath(%q)=%q, %v; want %q, nil", tc.serverPath, got, err, tc.want)
+		}
+	}
+
+	for _, p := range []string{"/mnt/win", "/mnt/win/cc/foo", "/tmp/foo", "/mnt/winx/c/foo", ""} {
+		got, err := c.ToClientPath(p)
+		if err == nil {
+			t.Errorf("ToClientPath(%q)=%q, nil; want error", p, got)
+		}
+	}
+}
+
+func TestWindowsIsSafeClient(t *testing.T) {
+	c, err := Windows("/mnt/win")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		path string
+		safe bool
+	}{
+		{path: `C:\`, safe: true},
+		{path: `C:\a\b`, safe: true},
+		{path: `C:\a\..\b`, safe: true},
+		{path: `C:/a/b`, safe: true},
+		{path: `a\b`, safe: false},
+		{path: `C:\..\a`, safe: false},
+		{path: `C:\a\..\..`, safe: false},
+	} {
+		got := c.IsSafeClient(tc.path)
+		if got != tc.safe {
+			t.Errorf("IsSafeClient(%q)=%t; want %t", tc.path, got, tc.safe)
+		}
+	}
+}
+
+func TestWindowsBadMountRoot(t *testing.T) {
+	for _, root := range []string{"", "mnt/win", `C:\mnt`} {
+		_, err := Windows(root)
+		if err == nil {
+			t.Errorf("Windows(%q)=_, nil; want error", root)
+		}
+	}
+}
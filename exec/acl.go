@@ -0,0 +1,230 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// accessDeniedError is an error when requester is not allowed to use
+// the toolchain by ACL.
+type accessDeniedError struct {
+	err error
+}
+
+func (e accessDeniedError) Error() string {
+	return fmt.Sprintf("access denied: %v", e.err)
+}
+
+func isAccessDenied(err error) bool {
+	_, ok := err.(accessDeniedError)
+	return ok
+}
+
+// checkACL checks requester in ctx and ri is allowed to use by acl at now.
+func checkACL(ctx context.Context, acl *cmdpb.ACL, ri *gomapb.RequesterInfo, now time.Time) error {
+	if acl == nil {
+		return nil
+	}
+	eu, ok := enduser.FromContext(ctx)
+	if len(acl.DisallowedGroups) > 0 {
+		if !ok {
+			return errors.New("no enduser group in context")
+		}
+		for _, g := range acl.DisallowedGroups {
+			if g == eu.Group {
+				return fmt.Errorf("enduser group %q not allowed (in disallowed groups)", eu.Group)
+			}
+		}
+	}
+	if len(acl.AllowedGroups) > 0 {
+		if !ok {
+			return errors.New("no enduser group in context")
+		}
+		allowed := false
+		for _, g := range acl.AllowedGroups {
+			if g == eu.Group {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("enduser group %q not allowed (not in allowed groups)", eu.Group)
+		}
+	}
+	if !ok {
+		eu = nil
+	}
+	for i, rule := range acl.Rules {
+		if !matchACLRule(rule, eu, ri, now) {
+			continue
+		}
+		switch rule.Action {
+		case cmdpb.ACLRule_ALLOW:
+			return nil
+		case cmdpb.ACLRule_DENY:
+			return fmt.Errorf("denied by rule[%d]", i)
+		}
+	}
+	return nil
+}
+
+// checkACLConfig checks acl is valid.
+func checkACLConfig(acl *cmdpb.ACL) error {
+	for i, rule := range acl.GetRules() {
+		if err := checkACLRule(rule); err != nil {
+			return &ConfigError{
+				Message: fmt.Sprintf("bad acl rule[%d]: %v", i, err),
+				Fix:     "set action to ALLOW or DENY, and valid timestamps in acl rule",
+			}
+		}
+	}
+	return nil
+}
+
+// checkACLRule checks rule is valid.
+func checkACLRule(rule *cmdpb.ACLRule) error {
+	if rule.Action == cmdpb.ACLRule_UNSPECIFIED_ACTION {
+		return errors.New("no action")
+	}
+	for _, ts := range []*tspb.Timestamp{rule.MinGomaRevisionTime, rule.NotBefore, rule.NotAfter} {
+		if ts == nil {
+			continue
+		}
+		if _, err := ptypes.Timestamp(ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchACLRule reports whether all conditions in rule match with
+// enduser eu (may be nil) and requester info ri at now.
+func matchACLRule(rule *cmdpb.ACLRule, eu *enduser.EndUser, ri *gomapb.RequesterInfo, now time.Time) bool {
+	var group, email string
+	if eu != nil {
+		group = eu.Group
+		email = string(eu.Email)
+	}
+	if len(rule.Groups) > 0 && !containsString(rule.Groups, group) {
+		return false
+	}
+	if len(rule.Emails) > 0 && !containsString(rule.Emails, email) {
+		return false
+	}
+	if len(rule.EmailDomains) > 0 {
+		i := strings.LastIndex(email, "@")
+		if i < 0 {
+			return false
+		}
+		domain := strings.ToLower(email[i+1:])
+		found := false
+		for _, d := range rule.EmailDomains {
+			if strings.ToLower(d) == domain {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(rule.BuildIdPrefixes) > 0 {
+		found := false
+		for _, p := range rule.BuildIdPrefixes {
+			if strings.HasPrefix(ri.GetBuildId(), p) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(rule.PathTypes) > 0 {
+		pt := pathTypeFromPathStyle(ri.GetPathStyle())
+		found := false
+		for _, t := range rule.PathTypes {
+			if t == pt {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.MinGomaRevisionTime != nil || len(rule.GomaRevisionPrefixes) > 0 {
+		hash, rt, ok := parseGomaRevision(ri.GetGomaRevision())
+		if !ok {
+			return false
+		}
+		if rule.MinGomaRevisionTime != nil {
+			min, err := ptypes.Timestamp(rule.MinGomaRevisionTime)
+			if err != nil || rt.Before(min) {
+				return false
+			}
+		}
+		if len(rule.GomaRevisionPrefixes) > 0 {
+			found := false
+			for _, p := range rule.GomaRevisionPrefixes {
+				if strings.HasPrefix(hash, p) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	if rule.NotBefore != nil {
+		t, err := ptypes.Timestamp(rule.NotBefore)
+		if err != nil || now.Before(t) {
+			return false
+		}
+	}
+	if rule.NotAfter != nil {
+		t, err := ptypes.Timestamp(rule.NotAfter)
+		if err != nil || now.After(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseGomaRevision parses goma client revision "<hash>@<unix time>".
+func parseGomaRevision(rev string) (string, time.Time, bool) {
+	i := strings.LastIndex(rev, "@")
+	if i < 0 {
+		return "", time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(rev[i+1:], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return rev[:i], time.Unix(sec, 0), true
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,349 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func TestCheckACL(t *testing.T) {
+	now := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+	ts := func(t time.Time) *tspb.Timestamp {
+		return &tspb.Timestamp{Seconds: t.Unix()}
+	}
+	user := enduser.New("someone@example.com", "users", nil)
+	ri := &gomapb.RequesterInfo{
+		BuildId:      proto.String("ci-linux-1234"),
+		PathStyle:    gomapb.RequesterInfo_POSIX_STYLE.Enum(),
+		GomaRevision: proto.String("abcdef0123@1617000000"),
+	}
+	denyAll := &cmdpb.ACLRule{Action: cmdpb.ACLRule_DENY}
+
+	for _, tc := range []struct {
+		desc    string
+		user    *enduser.EndUser
+		ri      *gomapb.RequesterInfo
+		acl     *cmdpb.ACL
+		allowed bool
+	}{
+		{
+			desc:    "no acl",
+			user:    user,
+			allowed: true,
+		},
+		{
+			desc:    "default allow",
+			user:    user,
+			ri:      ri,
+			acl:     &cmdpb.ACL{},
+			allowed: true,
+		},
+		{
+			desc: "allowed group",
+			user: user,
+			acl: &cmdpb.ACL{
+				AllowedGroups: []string{"admins", "users"},
+			},
+			allowed: true,
+		},
+		{
+			desc: "not in allowed groups",
+			user: user,
+			acl: &cmdpb.ACL{
+				AllowedGroups: []string{"admins"},
+			},
+		},
+		{
+			desc: "disallowed group",
+			user: user,
+			acl: &cmdpb.ACL{
+				DisallowedGroups: []string{"users"},
+			},
+		},
+		{
+			desc: "no enduser for group acl",
+			acl: &cmdpb.ACL{
+				AllowedGroups: []string{"users"},
+			},
+		},
+		{
+			desc: "deny rule",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{denyAll},
+			},
+		},
+		{
+			desc: "allow rule before deny rule",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:       cmdpb.ACLRule_ALLOW,
+						EmailDomains: []string{"EXAMPLE.com"},
+					},
+					denyAll,
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "allow by email",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action: cmdpb.ACLRule_ALLOW,
+						Emails: []string{"someone@example.com"},
+					},
+					denyAll,
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "allow by build id and path type",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:          cmdpb.ACLRule_ALLOW,
+						BuildIdPrefixes: []string{"ci-"},
+						PathTypes:       []cmdpb.CmdDescriptor_PathType{cmdpb.CmdDescriptor_POSIX},
+					},
+					denyAll,
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "path type mismatch",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:    cmdpb.ACLRule_ALLOW,
+						PathTypes: []cmdpb.CmdDescriptor_PathType{cmdpb.CmdDescriptor_WINDOWS},
+					},
+					denyAll,
+				},
+			},
+		},
+		{
+			desc: "allow new goma revision",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:              cmdpb.ACLRule_ALLOW,
+						MinGomaRevisionTime: ts(time.Unix(1617000000, 0)),
+						GomaRevisionPrefixes: []string{
+							"abcdef",
+						},
+					},
+					denyAll,
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "goma revision too old",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:              cmdpb.ACLRule_ALLOW,
+						MinGomaRevisionTime: ts(time.Unix(1617000001, 0)),
+					},
+					denyAll,
+				},
+			},
+		},
+		{
+			desc: "malformed goma revision",
+			user: user,
+			ri: &gomapb.RequesterInfo{
+				GomaRevision: proto.String("abcdef0123"),
+			},
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:               cmdpb.ACLRule_DENY,
+						GomaRevisionPrefixes: []string{"abcdef"},
+					},
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "deny in period",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:    cmdpb.ACLRule_DENY,
+						NotBefore: ts(now.Add(-time.Hour)),
+						NotAfter:  ts(now.Add(time.Hour)),
+					},
+				},
+			},
+		},
+		{
+			desc: "deny out of period",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:    cmdpb.ACLRule_DENY,
+						NotBefore: ts(now.Add(time.Hour)),
+					},
+					{
+						Action:   cmdpb.ACLRule_DENY,
+						NotAfter: ts(now.Add(-time.Hour)),
+					},
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "malformed timestamp",
+			user: user,
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:    cmdpb.ACLRule_DENY,
+						NotBefore: &tspb.Timestamp{Nanos: -1},
+					},
+				},
+			},
+			allowed: true,
+		},
+		{
+			desc: "no enduser for email rule",
+			ri:   ri,
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:       cmdpb.ACLRule_DENY,
+						EmailDomains: []string{"example.com"},
+					},
+				},
+			},
+			allowed: true,
+		},
+	} {
+		ctx := context.Background()
+		if tc.user != nil {
+			ctx = enduser.NewContext(ctx, tc.user)
+		}
+		err := checkACL(ctx, tc.acl, tc.ri, now)
+		if tc.allowed && err != nil {
+			t.Errorf("%s: checkACL()=%v; want nil", tc.desc, err)
+		}
+		if !tc.allowed && err == nil {
+			t.Errorf("%s: checkACL()=nil; want error", tc.desc)
+		}
+	}
+}
+
+func TestCheckACLConfig(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		acl     *cmdpb.ACL
+		wantErr bool
+	}{
+		{
+			desc: "nil",
+		},
+		{
+			desc: "valid",
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:    cmdpb.ACLRule_DENY,
+						NotBefore: &tspb.Timestamp{Seconds: 1617000000},
+					},
+				},
+			},
+		},
+		{
+			desc: "no action",
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Groups: []string{"users"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "malformed timestamp",
+			acl: &cmdpb.ACL{
+				Rules: []*cmdpb.ACLRule{
+					{
+						Action:   cmdpb.ACLRule_ALLOW,
+						NotAfter: &tspb.Timestamp{Nanos: -1},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	} {
+		err := checkACLConfig(tc.acl)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: checkACLConfig()=%v; want error %t", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseGomaRevision(t *testing.T) {
+	for _, tc := range []struct {
+		rev      string
+		wantHash string
+		wantTime time.Time
+		wantOK   bool
+	}{
+		{
+			rev:      "abcdef0123@1617000000",
+			wantHash: "abcdef0123",
+			wantTime: time.Unix(1617000000, 0),
+			wantOK:   true,
+		},
+		{
+			rev: "abcdef0123",
+		},
+		{
+			rev: "abcdef0123@yesterday",
+		},
+		{
+			rev: "",
+		},
+	} {
+		hash, rt, ok := parseGomaRevision(tc.rev)
+		if hash != tc.wantHash || !rt.Equal(tc.wantTime) || ok != tc.wantOK {
+			t.Errorf("parseGomaRevision(%q)=%q, %v, %t; want %q, %v, %t", tc.rev, hash, rt, ok, tc.wantHash, tc.wantTime, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// dimensionOp is an operator of dimension expression.
+type dimensionOp string
+
+const (
+	// opMatch matches value with glob pattern. e.g. "os:linux", "cpu:x86*".
+	opMatch dimensionOp = ":"
+	opGE    dimensionOp = ">="
+	opLE    dimensionOp = "<="
+	opGT    dimensionOp = ">"
+	opLT    dimensionOp = "<"
+	opNE    dimensionOp = "!="
+)
+
+// dimensionOps is a list of operators in the order of parsing,
+// i.e. longer operator first.
+var dimensionOps = []dimensionOp{opGE, opLE, opNE, opMatch, opGT, opLT}
+
+// dimensionExpr is an expression of platform config's dimension,
+// enabled by config's dimension_expressions.
+//
+//  "os:linux"     requester dimension "os:linux".
+//  "os:*"         any requester dimension with key "os".
+//  "glibc>=2.27"  requester dimension "glibc:<version>" with version >= 2.27.
+//  "!os:windows"  platform config is rejected if requester has "os:windows".
+//
+// A dimension without operator matches with the same requester dimension.
+type dimensionExpr struct {
+	raw    string
+	negate bool
+	key    string
+	op     dimensionOp
+	value  string
+}
+
+func (e dimensionExpr) String() string { return e.raw }
+
+// exact reports whether e matches only one value.
+func (e dimensionExpr) exact() bool {
+	switch e.op {
+	case "":
+		return true
+	case opMatch:
+		return !strings.ContainsAny(e.value, `*?[\`)
+	}
+	return false
+}
+
+// parseDimensionExpr parses platform config's dimension s.
+func parseDimensionExpr(s string) (dimensionExpr, error) {
+	e := dimensionExpr{raw: s}
+	if strings.HasPrefix(s, "!") && !strings.HasPrefix(s, "!=") {
+		e.negate = true
+		s = s[1:]
+	}
+	if s == "" {
+		return e, fmt.Errorf("dimension %q: empty", e.raw)
+	}
+	i := strings.IndexAny(s, ":<>!")
+	if i < 0 {
+		e.value = s
+		return e, nil
+	}
+	for _, op := range dimensionOps {
+		if strings.HasPrefix(s[i:], string(op)) {
+			e.key = s[:i]
+			e.op = op
+			e.value = s[i+len(op):]
+			break
+		}
+	}
+	switch {
+	case e.op == "":
+		return e, fmt.Errorf("dimension %q: unknown operator", e.raw)
+	case e.key == "":
+		return e, fmt.Errorf("dimension %q: empty key", e.raw)
+	case e.value == "":
+		return e, fmt.Errorf("dimension %q: empty value", e.raw)
+	case e.op == opMatch:
+		if _, err := path.Match(e.value, ""); err != nil {
+			return e, fmt.Errorf("dimension %q: bad pattern: %v", e.raw, err)
+		}
+	}
+	return e, nil
+}
+
+// parseDimensionExprs parses platform config's dimensions.
+// If expressions is false, each dimension matches only the same
+// requester dimension, even if it contains operators.
+func parseDimensionExprs(dimensions []string, expressions bool) ([]dimensionExpr, error) {
+	var exprs []dimensionExpr
+	for _, d := range dimensions {
+		if !expressions {
+			exprs = append(exprs, dimensionExpr{raw: d, value: d})
+			continue
+		}
+		e, err := parseDimensionExpr(d)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+	return exprs, nil
+}
+
+// match reports whether requester dimension d matches with e,
+// ignoring e.negate.
+func (e dimensionExpr) match(d string) bool {
+	if e.op == "" {
+		return d == e.value
+	}
+	i := strings.Index(d, ":")
+	if i < 0 || d[:i] != e.key {
+		return false
+	}
+	v := d[i+1:]
+	switch e.op {
+	case opMatch:
+		ok, _ := path.Match(e.value, v)
+		return ok
+	case opNE:
+		return v != e.value
+	}
+	c := compareVersion(v, e.value)
+	switch e.op {
+	case opGE:
+		return c >= 0
+	case opLE:
+		return c <= 0
+	case opGT:
+		return c > 0
+	case opLT:
+		return c < 0
+	}
+	return false
+}
+
+// compareVersion compares version a and b component-wise, split by
+// "." or "-". Numeric components are compared numerically, others are
+// compared lexically. It returns -1 if a < b, 1 if a > b, and 0 if equal.
+func compareVersion(a, b string) int {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool {
+			return r == '.' || r == '-'
+		})
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.ParseInt(x, 10, 64)
+		yn, yerr := strconv.ParseInt(y, 10, 64)
+		if x == "" {
+			xn, xerr = 0, yerr
+		}
+		if y == "" {
+			yn, yerr = 0, xerr
+		}
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// dimensionMatch is a result of matching requester dimensions with
+// platform config's dimension expressions.
+type dimensionMatch struct {
+	// ok is true if all requester dimensions are accepted and no
+	// dimension is rejected by negated expression.
+	ok bool
+	// exact is the number of requester dimensions matched with
+	// exact expressions. Larger is more specific.
+	exact int
+	// reason describes why it was not matched.
+	reason string
+}
+
+// matchDimensions matches requester's dimensions with platform config's
+// dimension expressions.
+// All requester's dimensions should be accepted by some non negated
+// expression, and no requester's dimension should match with negated
+// expression.
+func matchDimensions(dimensions []string, exprs []dimensionExpr) dimensionMatch {
+	var m dimensionMatch
+	for _, d := range dimensions {
+		accepted := false
+		exact := false
+		for _, e := range exprs {
+			if !e.match(d) {
+				continue
+			}
+			if e.negate {
+				m.reason = fmt.Sprintf("%q rejected by %q", d, e)
+				return m
+			}
+			accepted = true
+			if e.exact() {
+				exact = true
+			}
+		}
+		if !accepted {
+			m.reason = fmt.Sprintf("%q not accepted", d)
+			return m
+		}
+		if exact {
+			m.exact++
+		}
+	}
+	// everything matched.
+	m.ok = true
+	return m
+}
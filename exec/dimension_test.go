@@ -0,0 +1,251 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"testing"
+)
+
+func TestParseDimensionExpr(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		want    dimensionExpr
+		wantErr bool
+	}{
+		{
+			input: "os:linux",
+			want:  dimensionExpr{raw: "os:linux", key: "os", op: opMatch, value: "linux"},
+		},
+		{
+			input: "cpu:x86*",
+			want:  dimensionExpr{raw: "cpu:x86*", key: "cpu", op: opMatch, value: "x86*"},
+		},
+		{
+			input: "glibc>=2.27",
+			want:  dimensionExpr{raw: "glibc>=2.27", key: "glibc", op: opGE, value: "2.27"},
+		},
+		{
+			input: "glibc<=2.27",
+			want:  dimensionExpr{raw: "glibc<=2.27", key: "glibc", op: opLE, value: "2.27"},
+		},
+		{
+			input: "glibc>2.27",
+			want:  dimensionExpr{raw: "glibc>2.27", key: "glibc", op: opGT, value: "2.27"},
+		},
+		{
+			input: "glibc<2.27",
+			want:  dimensionExpr{raw: "glibc<2.27", key: "glibc", op: opLT, value: "2.27"},
+		},
+		{
+			input: "os!=windows",
+			want:  dimensionExpr{raw: "os!=windows", key: "os", op: opNE, value: "windows"},
+		},
+		{
+			input: "!os:windows",
+			want:  dimensionExpr{raw: "!os:windows", negate: true, key: "os", op: opMatch, value: "windows"},
+		},
+		{
+			input: "linux",
+			want:  dimensionExpr{raw: "linux", value: "linux"},
+		},
+		{
+			input:   "",
+			wantErr: true,
+		},
+		{
+			input:   "!",
+			wantErr: true,
+		},
+		{
+			input:   "os!linux",
+			wantErr: true,
+		},
+		{
+			input:   "!=linux",
+			wantErr: true,
+		},
+		{
+			input:   ":linux",
+			wantErr: true,
+		},
+		{
+			input:   "os:",
+			wantErr: true,
+		},
+		{
+			input:   "glibc>=",
+			wantErr: true,
+		},
+		{
+			input:   "cpu:x86[",
+			wantErr: true,
+		},
+	} {
+		got, err := parseDimensionExpr(tc.input)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseDimensionExpr(%q)=%#v, nil; want error", tc.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("parseDimensionExpr(%q)=%#v, %v; want %#v, nil", tc.input, got, err, tc.want)
+		}
+	}
+}
+
+func TestParseDimensionExprsWithoutExpressions(t *testing.T) {
+	dimensions := []string{"os:linux", "glibc>=2.27", "!os:windows", "os!linux"}
+	exprs, err := parseDimensionExprs(dimensions, false)
+	if err != nil {
+		t.Fatalf("parseDimensionExprs(%q, false)=_, %v; want nil error", dimensions, err)
+	}
+	for i, e := range exprs {
+		want := dimensionExpr{raw: dimensions[i], value: dimensions[i]}
+		if e != want {
+			t.Errorf("parseDimensionExprs(%q, false)[%d]=%#v; want %#v", dimensions, i, e, want)
+		}
+	}
+	if _, err := parseDimensionExprs(dimensions, true); err == nil {
+		t.Errorf("parseDimensionExprs(%q, true)=_, nil; want error", dimensions)
+	}
+}
+
+func TestCompareVersion(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{a: "2.27", b: "2.27", want: 0},
+		{a: "2.27", b: "2.3", want: 1},
+		{a: "2.3", b: "2.27", want: -1},
+		{a: "10", b: "9", want: 1},
+		// differing component counts.
+		{a: "2.27", b: "2.27.0", want: 0},
+		{a: "2", b: "2.0.0", want: 0},
+		{a: "2.27.1", b: "2.27", want: 1},
+		{a: "1.0", b: "1.0.1", want: -1},
+		{a: "", b: "0", want: 0},
+		// non numeric components.
+		{a: "1.0-rc1", b: "1.0-rc2", want: -1},
+		{a: "1.0-beta", b: "1.0-alpha", want: 1},
+		{a: "1.a", b: "1.1", want: 1},
+	} {
+		if got := compareVersion(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersion(%q, %q)=%d; want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMatchDimensions(t *testing.T) {
+	for _, tc := range []struct {
+		dimensions  []string
+		exprs       []string
+		expressions bool
+		want        dimensionMatch
+	}{
+		{
+			dimensions:  []string{"os:linux"},
+			exprs:       []string{"os:linux"},
+			expressions: true,
+			want:        dimensionMatch{ok: true, exact: 1},
+		},
+		{
+			dimensions:  []string{"os:linux", "cpu:x86_64"},
+			exprs:       []string{"os:linux", "cpu:x86*"},
+			expressions: true,
+			want:        dimensionMatch{ok: true, exact: 1},
+		},
+		{
+			dimensions:  []string{"os:linux", "glibc:2.31"},
+			exprs:       []string{"os:*", "glibc>=2.27"},
+			expressions: true,
+			want:        dimensionMatch{ok: true},
+		},
+		{
+			dimensions:  []string{"glibc:2.27.0"},
+			exprs:       []string{"glibc>=2.27"},
+			expressions: true,
+			want:        dimensionMatch{ok: true},
+		},
+		{
+			dimensions:  []string{"glibc:2.27"},
+			exprs:       []string{"glibc>2.27"},
+			expressions: true,
+			want:        dimensionMatch{reason: `"glibc:2.27" not accepted`},
+		},
+		{
+			dimensions:  []string{"glibc:2.17"},
+			exprs:       []string{"glibc>=2.27"},
+			expressions: true,
+			want:        dimensionMatch{reason: `"glibc:2.17" not accepted`},
+		},
+		{
+			dimensions:  []string{"os:mac"},
+			exprs:       []string{"os!=linux"},
+			expressions: true,
+			want:        dimensionMatch{ok: true},
+		},
+		{
+			dimensions:  []string{"os:linux"},
+			exprs:       []string{"os!=linux"},
+			expressions: true,
+			want:        dimensionMatch{reason: `"os:linux" not accepted`},
+		},
+		{
+			dimensions:  []string{"os:linux"},
+			exprs:       []string{"os:*", "!os:windows"},
+			expressions: true,
+			want:        dimensionMatch{ok: true},
+		},
+		{
+			dimensions:  []string{"os:windows"},
+			exprs:       []string{"os:*", "!os:windows"},
+			expressions: true,
+			want:        dimensionMatch{reason: `"os:windows" rejected by "!os:windows"`},
+		},
+		{
+			dimensions:  []string{"os:linux", "gpu:none"},
+			exprs:       []string{"os:linux"},
+			expressions: true,
+			want:        dimensionMatch{exact: 1, reason: `"gpu:none" not accepted`},
+		},
+		{
+			exprs:       []string{"os:linux"},
+			expressions: true,
+			want:        dimensionMatch{ok: true},
+		},
+		{
+			// without expressions, dimensions match literally.
+			dimensions: []string{"os:linux"},
+			exprs:      []string{"os:linux"},
+			want:       dimensionMatch{ok: true, exact: 1},
+		},
+		{
+			dimensions: []string{"glibc:2.31"},
+			exprs:      []string{"glibc>=2.27"},
+			want:       dimensionMatch{reason: `"glibc:2.31" not accepted`},
+		},
+		{
+			dimensions: []string{"cpu:x86*"},
+			exprs:      []string{"cpu:x86*"},
+			want:       dimensionMatch{ok: true, exact: 1},
+		},
+		{
+			dimensions: []string{"cpu:x86_64"},
+			exprs:      []string{"cpu:x86*"},
+			want:       dimensionMatch{reason: `"cpu:x86_64" not accepted`},
+		},
+	} {
+		exprs, err := parseDimensionExprs(tc.exprs, tc.expressions)
+		if err != nil {
+			t.Errorf("parseDimensionExprs(%q, %t)=_, %v; want nil error", tc.exprs, tc.expressions, err)
+			continue
+		}
+		if got := matchDimensions(tc.dimensions, exprs); got != tc.want {
+			t.Errorf("matchDimensions(%q, %q (expressions=%t))=%#v; want %#v", tc.dimensions, tc.exprs, tc.expressions, got, tc.want)
+		}
+	}
+}
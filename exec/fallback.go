@@ -0,0 +1,132 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.chromium.org/goma/server/command/normalizer"
+	"go.chromium.org/goma/server/log"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+var (
+	toolchainSubstitutions = stats.Int64(
+		"go.chromium.org/goma/server/exec.toolchain-substitutions",
+		"Toolchain substitution by fallback policy",
+		stats.UnitDimensionless)
+
+	substitutionReasonKey = tag.MustNewKey("reason")
+
+	// DefaultFallbackViews are the default views of toolchain fallback.
+	// You need to register the view for data to actually be collected.
+	DefaultFallbackViews = []*view.View{
+		{
+			Description: `counts toolchain substitution by fallback policy. selector is requested selector. reason is "BINARY_HASH_MISMATCH" or "COMPATIBLE_VERSION"`,
+			TagKeys: []tag.Key{
+				selectorKey,
+				substitutionReasonKey,
+			},
+			Measure:     toolchainSubstitutions,
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+// fallbackSelectors returns selectors in addrsMap that can be used
+// for cmdSel by fallback policy of their configs.
+// Selectors with the same version (i.e. binary hash mismatch) are
+// preferred over selectors with compatible versions.
+func fallbackSelectors(cmdSel selector, addrsMap map[selector][]string, configsMap map[string]map[selector]*cmdpb.Config) []selector {
+	var sameVersion, compatible []selector
+	for sel, addrs := range addrsMap {
+		if sel.Name != cmdSel.Name || sel.Target != cmdSel.Target || len(addrs) == 0 {
+			continue
+		}
+		// fallback policy is per runtime, so it should be the same
+		// in configs of sel.
+		policy := configsMap[addrs[0]][sel].GetFallback()
+		if policy == nil {
+			continue
+		}
+		switch substitutionReason(cmdSel, sel, policy) {
+		case gomapb.CommandSubstitution_BINARY_HASH_MISMATCH:
+			sameVersion = append(sameVersion, sel)
+		case gomapb.CommandSubstitution_COMPATIBLE_VERSION:
+			compatible = append(compatible, sel)
+		}
+	}
+	sels := sameVersion
+	if len(sels) == 0 {
+		sels = compatible
+	}
+	sort.Sort(byName(sels))
+	return sels
+}
+
+// substitutionReason returns a reason to use sel for requested selector
+// by policy, or UNKNOWN if policy doesn't allow it.
+func substitutionReason(requested, sel selector, policy *cmdpb.FallbackPolicy) gomapb.CommandSubstitution_Reason {
+	if requested.Name != sel.Name || requested.Target != sel.Target {
+		return gomapb.CommandSubstitution_UNKNOWN
+	}
+	if requested.Version == sel.Version {
+		if policy.GetAllowBinaryHashMismatch() {
+			return gomapb.CommandSubstitution_BINARY_HASH_MISMATCH
+		}
+		return gomapb.CommandSubstitution_UNKNOWN
+	}
+	for _, cv := range policy.GetCompatibleVersions() {
+		if cv.Name != "" && cv.Name != requested.Name {
+			continue
+		}
+		if cv.Version == requested.Version && cv.CompatibleVersion == sel.Version {
+			return gomapb.CommandSubstitution_COMPATIBLE_VERSION
+		}
+	}
+	return gomapb.CommandSubstitution_UNKNOWN
+}
+
+// setSubstitution sets command_substitution in result if cfg's command
+// is not requested command, and records it in metrics.
+func setSubstitution(ctx context.Context, result *gomapb.ExecResult, spec *gomapb.CommandSpec, cmdSel selector, cfg *cmdpb.Config) {
+	logger := log.FromContext(ctx)
+	selpb, err := normalizer.Selector(cfg.GetCmdDescriptor().GetSelector())
+	if err != nil {
+		logger.Errorf("failed to normalize selector %s: %v", cfg.GetCmdDescriptor().GetSelector(), err)
+		return
+	}
+	sel := fromSelectorProto(selpb)
+	if sel == cmdSel {
+		return
+	}
+	reason := substitutionReason(cmdSel, sel, cfg.GetFallback())
+	logger.Warnf("command substitution %s: requested=%s used=%s", reason, cmdSel, sel)
+	result.CommandSubstitution = &gomapb.CommandSubstitution{
+		Reason: reason.Enum(),
+		Requested: &gomapb.CommandSpec{
+			Name:       proto.String(spec.GetName()),
+			Version:    proto.String(spec.GetVersion()),
+			Target:     proto.String(spec.GetTarget()),
+			BinaryHash: spec.GetBinaryHash(),
+		},
+	}
+	result.CommandSpec.Version = proto.String(cfg.GetCmdDescriptor().GetSelector().GetVersion())
+
+	err = stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(selectorKey, selectorTagValue(cmdSel)),
+		tag.Upsert(substitutionReasonKey, reason.String()),
+	}, toolchainSubstitutions.M(1))
+	if err != nil {
+		logger.Errorf("failed to record stats: %v", err)
+	}
+}
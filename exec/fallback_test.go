@@ -0,0 +1,182 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+func TestSubstitutionReason(t *testing.T) {
+	requested := selector{
+		Name:       "clang",
+		Version:    "12.0",
+		Target:     "x86_64-unknown-linux-gnu",
+		BinaryHash: "hash",
+	}
+	policy := &cmdpb.FallbackPolicy{
+		AllowBinaryHashMismatch: true,
+		CompatibleVersions: []*cmdpb.FallbackPolicy_CompatibleVersion{
+			{
+				Name:              "clang",
+				Version:           "12.0",
+				CompatibleVersion: "12.1",
+			},
+			{
+				// empty name matches any name.
+				Version:           "12.0",
+				CompatibleVersion: "12.2",
+			},
+			{
+				Name:              "gcc",
+				Version:           "12.0",
+				CompatibleVersion: "12.3",
+			},
+		},
+	}
+	sel := func(f func(*selector)) selector {
+		s := requested
+		s.BinaryHash = "other"
+		f(&s)
+		return s
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		sel    selector
+		policy *cmdpb.FallbackPolicy
+		want   gomapb.CommandSubstitution_Reason
+	}{
+		{
+			desc:   "binary hash mismatch",
+			sel:    sel(func(*selector) {}),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_BINARY_HASH_MISMATCH,
+		},
+		{
+			desc:   "binary hash mismatch not allowed",
+			sel:    sel(func(*selector) {}),
+			policy: &cmdpb.FallbackPolicy{CompatibleVersions: policy.CompatibleVersions},
+			want:   gomapb.CommandSubstitution_UNKNOWN,
+		},
+		{
+			desc:   "compatible version",
+			sel:    sel(func(s *selector) { s.Version = "12.1" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_COMPATIBLE_VERSION,
+		},
+		{
+			desc:   "compatible version for any name",
+			sel:    sel(func(s *selector) { s.Version = "12.2" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_COMPATIBLE_VERSION,
+		},
+		{
+			desc:   "compatible version for other name",
+			sel:    sel(func(s *selector) { s.Version = "12.3" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_UNKNOWN,
+		},
+		{
+			desc:   "incompatible version",
+			sel:    sel(func(s *selector) { s.Version = "13.0" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_UNKNOWN,
+		},
+		{
+			desc:   "different name",
+			sel:    sel(func(s *selector) { s.Name = "clang++" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_UNKNOWN,
+		},
+		{
+			desc:   "different target",
+			sel:    sel(func(s *selector) { s.Target = "x86_64-apple-darwin" }),
+			policy: policy,
+			want:   gomapb.CommandSubstitution_UNKNOWN,
+		},
+		{
+			desc: "no policy",
+			sel:  sel(func(*selector) {}),
+			want: gomapb.CommandSubstitution_UNKNOWN,
+		},
+	} {
+		if got := substitutionReason(requested, tc.sel, tc.policy); got != tc.want {
+			t.Errorf("%s: substitutionReason(%v, %v)=%v; want %v", tc.desc, requested, tc.sel, got, tc.want)
+		}
+	}
+}
+
+func TestFallbackSelectors(t *testing.T) {
+	const addr = "rbe.example.com:443"
+	requested := selector{
+		Name:       "clang",
+		Version:    "12.0",
+		Target:     "x86_64-unknown-linux-gnu",
+		BinaryHash: "hash",
+	}
+	policy := &cmdpb.FallbackPolicy{
+		AllowBinaryHashMismatch: true,
+		CompatibleVersions: []*cmdpb.FallbackPolicy_CompatibleVersion{
+			{
+				Version:           "12.0",
+				CompatibleVersion: "12.1",
+			},
+		},
+	}
+	sameVersion := selector{Name: "clang", Version: "12.0", Target: requested.Target, BinaryHash: "same-version"}
+	sameVersion2 := selector{Name: "clang", Version: "12.0", Target: requested.Target, BinaryHash: "same-version-2"}
+	compatible := selector{Name: "clang", Version: "12.1", Target: requested.Target, BinaryHash: "compatible"}
+	incompatible := selector{Name: "clang", Version: "13.0", Target: requested.Target, BinaryHash: "incompatible"}
+	noPolicy := selector{Name: "clang", Version: "12.0", Target: requested.Target, BinaryHash: "no-policy"}
+	otherTarget := selector{Name: "clang", Version: "12.0", Target: "x86_64-apple-darwin", BinaryHash: "other-target"}
+	noAddr := selector{Name: "clang", Version: "12.0", Target: requested.Target, BinaryHash: "no-addr"}
+
+	configsMap := map[string]map[selector]*cmdpb.Config{
+		addr: {
+			sameVersion:  {Fallback: policy},
+			sameVersion2: {Fallback: policy},
+			compatible:   {Fallback: policy},
+			incompatible: {Fallback: policy},
+			noPolicy:     {},
+			otherTarget:  {Fallback: policy},
+			noAddr:       {Fallback: policy},
+		},
+	}
+	addrsMap := map[selector][]string{
+		sameVersion:  {addr},
+		sameVersion2: {addr},
+		compatible:   {addr},
+		incompatible: {addr},
+		noPolicy:     {addr},
+		otherTarget:  {addr},
+		noAddr:       nil,
+	}
+
+	got := fallbackSelectors(requested, addrsMap, configsMap)
+	want := []selector{sameVersion, sameVersion2}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fallbackSelectors(%v) diff -want +got:\n%s", requested, diff)
+	}
+
+	// compatible versions are used only if no selectors with the same version.
+	delete(addrsMap, sameVersion)
+	delete(addrsMap, sameVersion2)
+	got = fallbackSelectors(requested, addrsMap, configsMap)
+	want = []selector{compatible}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fallbackSelectors(%v) without same version diff -want +got:\n%s", requested, diff)
+	}
+
+	delete(addrsMap, compatible)
+	got = fallbackSelectors(requested, addrsMap, configsMap)
+	if len(got) != 0 {
+		t.Errorf("fallbackSelectors(%v)=%v; want none", requested, got)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -20,7 +19,6 @@ import (
 	"go.opencensus.io/tag"
 	"go.opencensus.io/trace"
 
-	"go.chromium.org/goma/server/auth/enduser"
 	"go.chromium.org/goma/server/command/descriptor"
 	"go.chromium.org/goma/server/command/normalizer"
 	"go.chromium.org/goma/server/log"
@@ -41,7 +39,7 @@ var (
 	// You need to register the view for data to actually be collected.
 	DefaultToolchainViews = []*view.View{
 		{
-			Description: `counts toolchain selection. result is "used", "found", "requested" or "missed"`,
+			Description: `counts toolchain selection. result is "used", "found", "requested", "missed", "substituted" or "denied"`,
 			TagKeys: []tag.Key{
 				selectorKey,
 				resultKey,
@@ -75,24 +73,34 @@ const (
 
 	// toolchain is requested and used.
 	resultUsed resultValue = "used"
+
+	// toolchain is requested, but not registered, and other
+	// toolchain is used by fallback policy.
+	resultSubstituted resultValue = "substituted"
+
+	// toolchain is requested and registered, but requester is not
+	// allowed to use it by ACL.
+	resultDenied resultValue = "denied"
 )
 
-func recordToolchainSelect(ctx context.Context, s selector, result resultValue) error {
-	// tag string cannot be over 255 or containing non-printable ascii characters.
-	// https://github.com/census-instrumentation/opencensus-go/blob/264a2a48d94c062252389fffbc308ba555e35166/tag/validate.go
-	tagNormalizer := func(tag string) string {
-		if len(tag) > maxKeyLength {
-			tag = tag[:maxKeyLength]
+// tagValue normalizes v for tag value.
+// tag string cannot be over 255 or containing non-printable ascii characters.
+// https://github.com/census-instrumentation/opencensus-go/blob/264a2a48d94c062252389fffbc308ba555e35166/tag/validate.go
+func tagValue(v string) string {
+	if len(v) > maxKeyLength {
+		v = v[:maxKeyLength]
+	}
+	buf := []rune(v)
+	for i, r := range buf {
+		if validKeyValueMin > r || r > validKeyValueMax {
+			buf[i] = '_'
 		}
-		buf := []rune(tag)
-		for i, v := range buf {
-			if validKeyValueMin > v || v > validKeyValueMax {
-				buf[i] = '_'
-			}
-		}
-		return string(buf)
 	}
+	return string(buf)
+}
 
+// selectorTagValue returns tag value for selector.
+func selectorTagValue(s selector) string {
 	// selector string can be too long, more than tag value limit
 	// (255 ASCII characters).
 	// http://b/115441117
@@ -101,10 +109,13 @@ func recordToolchainSelect(ctx context.Context, s selector, result resultValue)
 	fmt.Fprintf(&buf, " t:%s", s.Target)
 	fmt.Fprintf(&buf, " b:%s", s.BinaryHash)
 	fmt.Fprintf(&buf, " v:%s", s.Version)
+	return tagValue(buf.String())
+}
 
+func recordToolchainSelect(ctx context.Context, s selector, result resultValue) error {
 	ctx, err := tag.New(ctx,
-		tag.Upsert(selectorKey, tagNormalizer(buf.String())),
-		tag.Upsert(resultKey, tagNormalizer(string(result))))
+		tag.Upsert(selectorKey, selectorTagValue(s)),
+		tag.Upsert(resultKey, tagValue(string(result))))
 	if err != nil {
 		return err
 	}
@@ -122,6 +133,14 @@ type Inventory struct {
 	configs map[string]map[selector]*cmdpb.Config
 	// config for arbitrary toolchain support.
 	platformConfigs []*platformConfig
+
+	// candidates in staged rollout, sorted by runtime name.
+	candidates []*candidateConfigs
+	// stable seqs of runtimes in staged rollout. key: runtime name.
+	stableSeqs map[string]string
+	rollout    rolloutStats
+
+	usage usageTracker
 }
 
 type selector struct {
@@ -163,7 +182,8 @@ func (s byName) Less(i, j int) bool {
 
 // platformConfig is for arbitrary toolchain support.
 type platformConfig struct {
-	dimensionSet       map[string]bool
+	config             *cmdpb.Config
+	dimensions         []dimensionExpr
 	remoteexecPlatform *cmdpb.RemoteexecPlatform
 	acl                *cmdpb.ACL
 }
@@ -173,20 +193,29 @@ func (in *Inventory) Configure(ctx context.Context, cfgs *cmdpb.ConfigResp) erro
 	ctx, span := trace.StartSpan(ctx, "go.chromium.org/goma/server/exec.Service.Configure")
 	defer span.End()
 	logger := log.FromContext(ctx)
-	newAddrs := make(map[selector][]string)
-	newConfigs := make(map[string]map[selector]*cmdpb.Config)
+	type selConfig struct {
+		sel selector
+		cfg *cmdpb.Config
+	}
+	var selConfigs []selConfig
 	var newPlatformConfigs []*platformConfig
 	for _, cfg := range cfgs.Configs {
 		// If RemoteexecPlatform exists but CmdDescriptor does not exists,
 		// this config is for arbitrary toolchain support.
 		// TODO: Split this from ConfigResp.Configs?
 		if cfg.CmdDescriptor == nil && cfg.RemoteexecPlatform != nil {
-			dimensionSet := make(map[string]bool)
-			for _, d := range cfg.GetDimensions() {
-				dimensionSet[d] = true
+			dimensions, err := parseDimensionExprs(cfg.GetDimensions(), cfg.GetDimensionExpressions())
+			if err != nil {
+				logger.Warnf("%v in %s", err, cfg)
+				continue
+			}
+			if err := checkACLConfig(cfg.GetAcl()); err != nil {
+				logger.Warnf("%v in %s", err, cfg)
+				continue
 			}
 			newPlatformConfigs = append(newPlatformConfigs, &platformConfig{
-				dimensionSet:       dimensionSet,
+				config:             cfg,
+				dimensions:         dimensions,
 				remoteexecPlatform: cfg.GetRemoteexecPlatform(),
 				acl:                cfg.GetAcl(),
 			})
@@ -194,109 +223,168 @@ func (in *Inventory) Configure(ctx context.Context, cfgs *cmdpb.ConfigResp) erro
 			continue
 		}
 
-		if cfg.Target == nil {
-			logger.Warnf("no target in %s", cfg)
-			continue
-		}
-		if cfg.Target.Addr == "" {
-			logger.Warnf("no target address in %s", cfg)
+		sel, err := checkConfig(cfg)
+		if err != nil {
+			logger.Warnf("%v in %s", err, cfg)
 			continue
 		}
-		if cfg.CmdDescriptor == nil || cfg.CmdDescriptor.Selector == nil {
-			logger.Warnf("no cmd descriptor in %s", cfg)
-			continue
+		selConfigs = append(selConfigs, selConfig{sel: sel, cfg: cfg})
+		logger.Infof("configure %s: %s %s", sel, cfg.Target.Addr, rolloutVersionString(cfg))
+	}
+
+	// stable configs are used unless request is routed to candidate.
+	newAddrs := make(map[selector][]string)
+	newConfigs := make(map[string]map[selector]*cmdpb.Config)
+	newCandidates := make(map[string]*candidateConfigs)
+	newStableSeqs := make(map[string]string)
+	versions := make(map[rolloutVersion]bool)
+	for _, sc := range selConfigs {
+		rollout := sc.cfg.GetRollout()
+		if rollout != nil {
+			versions[rolloutVersion{
+				runtime:   rollout.Runtime,
+				seq:       rollout.Seq,
+				candidate: rollout.Candidate,
+			}] = true
 		}
-		selpb, err := normalizer.Selector(cfg.CmdDescriptor.Selector)
-		if err != nil {
-			logger.Errorf("failed to normalize selector in %s", cfg)
+		if rollout.GetCandidate() {
+			if _, ok := newCandidates[rollout.Runtime]; !ok {
+				newCandidates[rollout.Runtime] = &candidateConfigs{
+					rollout: rollout,
+					addrs:   make(map[selector][]string),
+					configs: make(map[string]map[selector]*cmdpb.Config),
+				}
+			}
 			continue
 		}
-		sel := fromSelectorProto(selpb)
-		if cfg.CmdDescriptor.GetSetup().GetPathType() == cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE {
-			logger.Errorf("unknown path type in %s %s", sel, cfg)
-			continue
+		if rollout != nil {
+			newStableSeqs[rollout.Runtime] = rollout.Seq
 		}
-		addr := cfg.Target.Addr
-		newAddrs[sel] = append(newAddrs[sel], addr)
-		m, ok := newConfigs[addr]
-		if !ok {
-			newConfigs[addr] = make(map[selector]*cmdpb.Config)
-			m = newConfigs[addr]
+		addConfig(newAddrs, newConfigs, sc.sel, sc.cfg)
+	}
+	// candidate configs are the same as stable configs, except
+	// its runtime uses candidate version.
+	for runtime, cand := range newCandidates {
+		for _, sc := range selConfigs {
+			rollout := sc.cfg.GetRollout()
+			if rollout.GetRuntime() == runtime {
+				if !rollout.GetCandidate() {
+					continue
+				}
+			} else if rollout.GetCandidate() {
+				continue
+			}
+			addConfig(cand.addrs, cand.configs, sc.sel, sc.cfg)
 		}
-		m[sel] = cfg
-		logger.Infof("configure %s: %s", sel, addr)
 	}
+	var newCandidateList []*candidateConfigs
+	for _, cand := range newCandidates {
+		newCandidateList = append(newCandidateList, cand)
+	}
+	sort.Slice(newCandidateList, func(i, j int) bool {
+		return newCandidateList[i].rollout.Runtime < newCandidateList[j].rollout.Runtime
+	})
+	in.rollout.retain(versions)
+	in.usage.start(time.Now())
+
 	in.mu.Lock()
 	defer in.mu.Unlock()
 	in.versionID = cfgs.VersionId
 	in.addrs = newAddrs
 	in.configs = newConfigs
 	in.platformConfigs = newPlatformConfigs
+	in.candidates = newCandidateList
+	in.stableSeqs = newStableSeqs
 	if len(in.configs) == 0 && len(in.platformConfigs) == 0 {
 		return fmt.Errorf("no available config in %s", cfgs.VersionId)
 	}
 	return nil
 }
 
-func (in *Inventory) VersionID() string {
-	in.mu.RLock()
-	defer in.mu.RUnlock()
-	return in.versionID
+func addConfig(addrs map[selector][]string, configs map[string]map[selector]*cmdpb.Config, sel selector, cfg *cmdpb.Config) {
+	addr := cfg.Target.Addr
+	addrs[sel] = append(addrs[sel], addr)
+	m, ok := configs[addr]
+	if !ok {
+		m = make(map[selector]*cmdpb.Config)
+		configs[addr] = m
+	}
+	m[sel] = cfg
 }
 
-func (in *Inventory) status() (string, []*cmdpb.Config) {
-	in.mu.RLock()
-	defer in.mu.RUnlock()
-	// sort by addr
-	var addrs []string
-	for a := range in.configs {
-		addrs = append(addrs, a)
-	}
-	sort.Strings(addrs)
-
-	var resp []*cmdpb.Config
-	// sort by selector
-	for _, a := range addrs {
-		var sels []selector
-		m := in.configs[a]
-		for sel := range m {
-			sels = append(sels, sel)
-		}
-		sort.Sort(byName(sels))
-		for _, sel := range sels {
-			resp = append(resp, proto.Clone(m[sel]).(*cmdpb.Config))
+// ConfigError is an error of a config in ConfigResp.
+type ConfigError struct {
+	// Message describes the problem.
+	Message string
+	// Fix suggests how to fix the problem.
+	Fix string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Message
+}
+
+// CheckConfig checks cfg is valid for Inventory.
+// Configure ignores configs that CheckConfig reports an error.
+func CheckConfig(cfg *cmdpb.Config) error {
+	if cfg.CmdDescriptor == nil && cfg.RemoteexecPlatform != nil {
+		// platform config for arbitrary toolchain support.
+		_, err := parseDimensionExprs(cfg.GetDimensions(), cfg.GetDimensionExpressions())
+		if err != nil {
+			return &ConfigError{
+				Message: err.Error(),
+				Fix:     `use "<key>:<pattern>", "<key><op><version>" (op is one of >=, <=, >, <, !=) or "!" prefix for negation`,
+			}
 		}
+		return checkACLConfig(cfg.GetAcl())
 	}
-	return in.versionID, resp
+	_, err := checkConfig(cfg)
+	return err
 }
 
-func checkACL(ctx context.Context, acl *cmdpb.ACL) error {
-	if acl == nil {
-		return nil
+func checkConfig(cfg *cmdpb.Config) (selector, error) {
+	if cfg.Target == nil {
+		return selector{}, &ConfigError{
+			Message: "no target",
+			Fix:     "set service_addr in runtime config, or target in config",
+		}
 	}
-	eu, ok := enduser.FromContext(ctx)
-	if len(acl.DisallowedGroups) > 0 {
-		if !ok {
-			return errors.New("no enduser group in context")
+	if cfg.Target.Addr == "" {
+		return selector{}, &ConfigError{
+			Message: "no target address",
+			Fix:     "set service_addr in runtime config, or target.addr in config",
 		}
-		for _, g := range acl.DisallowedGroups {
-			if g == eu.Group {
-				return fmt.Errorf("enduser group %q not allowed (in disallowed groups)", eu.Group)
-			}
+	}
+	if cfg.CmdDescriptor == nil || cfg.CmdDescriptor.Selector == nil {
+		return selector{}, &ConfigError{
+			Message: "no cmd descriptor",
+			Fix:     "set cmd_descriptor with selector",
 		}
 	}
-	if len(acl.AllowedGroups) > 0 {
-		if !ok {
-			return errors.New("no enduser group in context")
+	selpb, err := normalizer.Selector(cfg.CmdDescriptor.Selector)
+	if err != nil {
+		return selector{}, &ConfigError{
+			Message: fmt.Sprintf("failed to normalize selector: %v", err),
+			Fix:     "set valid target triple (e.g. x86_64-unknown-linux-gnu) in selector",
 		}
-		for _, g := range acl.AllowedGroups {
-			if g == eu.Group {
-				return nil
-			}
+	}
+	sel := fromSelectorProto(selpb)
+	if cfg.CmdDescriptor.GetSetup().GetPathType() == cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE {
+		return sel, &ConfigError{
+			Message: fmt.Sprintf("unknown path type for %s", sel),
+			Fix:     "set setup.path_type to POSIX or WINDOWS",
 		}
-		return fmt.Errorf("enduser group %q not allowed (not in allowed groups)", eu.Group)
 	}
-	return nil
+	if err := checkACLConfig(cfg.GetAcl()); err != nil {
+		return sel, err
+	}
+	return sel, nil
+}
+
+func (in *Inventory) VersionID() string {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return in.versionID
 }
 
 // pickCmd takes selectors of compiler and subprograms, and returns configs of
@@ -304,11 +392,23 @@ func checkACL(ctx context.Context, acl *cmdpb.ACL) error {
 // First, it find out cmd_server that has both selectors of compiler and
 // subprograms. (Step 1. and Step 2.)
 // Then, it picks cmd_server whose compiler's build time is latest. (Step 3.)
-func (in *Inventory) pickCmd(ctx context.Context, cmdSel selector, subprogSels []selector) (*cmdpb.Config, map[selector]*cmdpb.Config, error) {
+// If cmdSel is in staged rollout, it uses candidate configs for
+// requests routed to candidate.
+// If cmdSel is not registered and req is not hermetic, it uses compiler
+// allowed by fallback policy instead.
+// If requester is not allowed to use any matching configs by ACL,
+// it returns accessDeniedError.
+func (in *Inventory) pickCmd(ctx context.Context, cmdSel selector, subprogSels []selector, req *gomapb.ExecReq) (*cmdpb.Config, map[selector]*cmdpb.Config, error) {
 	logger := log.FromContext(ctx)
 	in.mu.RLock()
 	defer in.mu.RUnlock()
 
+	addrsMap, configsMap := in.addrs, in.configs
+	if cand := in.pickCandidate(cmdSel, newRolloutKeys(ctx, req)); cand != nil {
+		logger.Infof("use candidate %s seq=%s for %s", cand.rollout.Runtime, cand.rollout.Seq, cmdSel)
+		addrsMap, configsMap = cand.addrs, cand.configs
+	}
+
 	record := func(ctx context.Context, s selector, result resultValue) {
 		err := recordToolchainSelect(ctx, s, result)
 		if err != nil {
@@ -316,76 +416,111 @@ func (in *Inventory) pickCmd(ctx context.Context, cmdSel selector, subprogSels [
 		}
 	}
 
+	now := time.Now()
+
 	// 1. command spec selector -> addresses
-	addrs, ok := in.addrs[cmdSel]
-	if !ok {
-		record(ctx, cmdSel, resultMissed)
-		for _, s := range subprogSels {
-			record(ctx, s, resultRequested)
+	cmdSels := []selector{cmdSel}
+	if _, ok := addrsMap[cmdSel]; !ok {
+		cmdSels = nil
+		if !req.GetHermeticMode() {
+			cmdSels = fallbackSelectors(cmdSel, addrsMap, configsMap)
 		}
-		return nil, nil, fmt.Errorf("no compiler for %v", cmdSel)
+		if len(cmdSels) == 0 {
+			record(ctx, cmdSel, resultMissed)
+			in.usage.miss(cmdSel, now)
+			for _, s := range subprogSels {
+				record(ctx, s, resultRequested)
+			}
+			return nil, nil, fmt.Errorf("no compiler for %v", cmdSel)
+		}
+		logger.Infof("no compiler for %v. fallback to %v", cmdSel, cmdSels)
 	}
 
 	// 2. choose configs that has all subprograms
-	var ccfgs []*cmdpb.Config
+	type selConfig struct {
+		sel selector
+		cfg *cmdpb.Config
+	}
+	var ccfgs []selConfig
+	var denied error
 	subprogResult := make(map[selector]resultValue)
 	for _, s := range subprogSels {
 		subprogResult[s] = resultMissed
 	}
-Loop:
-	for _, a := range addrs {
-		m, ok := in.configs[a]
-		if !ok {
-			logger.Errorf("unknown address (%s) is given.", a)
-			continue
-		}
-		for _, s := range subprogSels {
-			if _, ok := m[s]; !ok {
-				logger.Infof("cfg for %v is not registered in %s.", s, a)
-				continue Loop
+	for _, sel := range cmdSels {
+	Loop:
+		for _, a := range addrsMap[sel] {
+			m, ok := configsMap[a]
+			if !ok {
+				logger.Errorf("unknown address (%s) is given.", a)
+				continue
 			}
-			subprogResult[s] = resultFound
-		}
-		cfg, ok := m[cmdSel]
-		if !ok {
-			logger.Errorf("cfg for %v is not registered. possibly configs broken.", cmdSel)
-			continue
+			for _, s := range subprogSels {
+				if _, ok := m[s]; !ok {
+					logger.Infof("cfg for %v is not registered in %s.", s, a)
+					continue Loop
+				}
+				subprogResult[s] = resultFound
+			}
+			cfg, ok := m[sel]
+			if !ok {
+				logger.Errorf("cfg for %v is not registered. possibly configs broken.", sel)
+				continue
+			}
+			if err := checkACL(ctx, cfg.Acl, req.GetRequesterInfo(), now); err != nil {
+				logger.Errorf("cfg for %v; access denied: %v", sel, err)
+				denied = err
+				continue
+			}
+			ccfgs = append(ccfgs, selConfig{sel: sel, cfg: cfg})
 		}
-		if err := checkACL(ctx, cfg.Acl); err != nil {
-			logger.Errorf("cfg for %v; access denied: %v", cmdSel, err)
-			continue
+	}
+	if len(ccfgs) == 0 && denied != nil {
+		record(ctx, cmdSel, resultDenied)
+		for _, s := range subprogSels {
+			record(ctx, s, resultFound)
 		}
-		ccfgs = append(ccfgs, cfg)
+		return nil, nil, accessDeniedError{err: fmt.Errorf("%v: %v", cmdSel, denied)}
 	}
 	if len(ccfgs) == 0 {
 		record(ctx, cmdSel, resultFound)
 		for s, r := range subprogResult {
 			record(ctx, s, r)
+			if r == resultMissed {
+				in.usage.miss(s, now)
+			}
 		}
 		return nil, nil, fmt.Errorf("no matching backend found for %v", cmdSel)
 	}
 
 	// 3. choose the latest compiler config.
 	sort.Slice(ccfgs, func(i, j int) bool {
-		ti, err := ptypes.Timestamp(ccfgs[i].GetBuildInfo().GetTimestamp())
+		ti, err := ptypes.Timestamp(ccfgs[i].cfg.GetBuildInfo().GetTimestamp())
 		if err != nil {
 			logger.Warnf("strange timestamp: %v", err)
 			ti = time.Unix(0, 0)
 		}
-		tj, err := ptypes.Timestamp(ccfgs[j].GetBuildInfo().GetTimestamp())
+		tj, err := ptypes.Timestamp(ccfgs[j].cfg.GetBuildInfo().GetTimestamp())
 		if err != nil {
 			logger.Warnf("strange timestamp: %v", err)
 			tj = time.Unix(0, 0)
 		}
 		return ti.Before(tj)
 	})
-	ccfg := ccfgs[len(ccfgs)-1]
+	picked := ccfgs[len(ccfgs)-1]
+	ccfg := picked.cfg
 
-	record(ctx, cmdSel, resultUsed)
+	if picked.sel != cmdSel {
+		record(ctx, cmdSel, resultSubstituted)
+		in.usage.miss(cmdSel, now)
+	}
+	record(ctx, picked.sel, resultUsed)
+	in.usage.pick(picked.sel, ccfg.Target.Addr, now)
 	for _, s := range subprogSels {
 		record(ctx, s, resultUsed)
+		in.usage.pick(s, ccfg.Target.Addr, now)
 	}
-	return ccfg, in.configs[ccfg.Target.Addr], nil
+	return ccfg, configsMap[ccfg.Target.Addr], nil
 }
 
 // Pick picks command and subprograms requested in req, and
@@ -426,9 +561,14 @@ func (in *Inventory) Pick(ctx context.Context, req *gomapb.ExecReq, resp *gomapb
 	}
 
 	resp.Result = initResult(req)
-	cfg, sels, err := in.pickCmd(ctx, cmdSel, sSels)
+	cfg, sels, err := in.pickCmd(ctx, cmdSel, sSels, req)
 	if err != nil {
 		resp.Error = gomapb.ExecResp_BAD_REQUEST.Enum()
+		if isAccessDenied(err) {
+			resp.BadRequestReasonCode = gomapb.ExecResp_ACCESS_DENIED.Enum()
+			resp.ErrorMessage = append(resp.ErrorMessage, err.Error())
+			return nil, nil, err
+		}
 		return nil, nil, fmt.Errorf("pick %v: %v", cmdSel, err)
 	}
 	logger.Infof("pick command %s => %s", cmdPath, cfg.GetCmdDescriptor().GetSelector())
@@ -439,6 +579,7 @@ func (in *Inventory) Pick(ctx context.Context, req *gomapb.ExecReq, resp *gomapb
 		subprogSetups[path] = scfg.CmdDescriptor.Setup
 	}
 	setPicked(resp.Result, cfg, path2sel)
+	setSubstitution(ctx, resp.Result, req.GetCommandSpec(), cmdSel, cfg)
 
 	cmdFiles, err := descriptor.RelocateCmd(cmdPath, cfg.CmdDescriptor.Setup, subprogSetups)
 	if err != nil {
@@ -462,18 +603,6 @@ func pathTypeFromPathStyle(pathStyle gomapb.RequesterInfo_PathStyle) cmdpb.CmdDe
 	return cmdpb.CmdDescriptor_UNKNOWN_PATH_TYPE
 }
 
-// Returns true if all `dimensions` exist in `platformDimensions`.
-func matchDimensions(dimensions []string, platformDimensions map[string]bool) bool {
-	for _, d := range dimensions {
-		if !platformDimensions[d] {
-			return false
-		}
-	}
-
-	// everything matched.
-	return true
-}
-
 // toolchainSpecToFileSpec converts ToolchainSpec to FileSpec.
 func toolchainSpecToFileSpec(ts *gomapb.ToolchainSpec) *cmdpb.FileSpec {
 	if ts.GetSymlinkPath() != "" {
@@ -534,21 +663,41 @@ func (in *Inventory) pickFromExecReq(ctx context.Context, req *gomapb.ExecReq, r
 	}
 
 	// Select the best possible platform. If there is multiple possible platforms,
-	// select the first one.
+	// select the one that matches the most dimensions exactly,
+	// or the first one if tied.
 	var matchedConfig *platformConfig
-	for _, pCfg := range in.platformConfigs {
-		if err := checkACL(ctx, pCfg.acl); err != nil {
+	var matched dimensionMatch
+	var rejects []string
+	var denied error
+	now := time.Now()
+	for i, pCfg := range in.platformConfigs {
+		m := matchDimensions(dimensions, pCfg.dimensions)
+		if !m.ok {
+			rejects = append(rejects, fmt.Sprintf("platform[%d] %q: %s", i, pCfg.config.GetDimensions(), m.reason))
+			continue
+		}
+		if err := checkACL(ctx, pCfg.acl, req.GetRequesterInfo(), now); err != nil {
 			logger.Errorf("pcfg %v; access denied: %v", pCfg, err)
+			rejects = append(rejects, fmt.Sprintf("platform[%d]: access denied", i))
+			denied = err
 			continue
 		}
-		if matchDimensions(dimensions, pCfg.dimensionSet) {
+		if matchedConfig == nil || m.exact > matched.exact {
 			matchedConfig = pCfg
-			break
+			matched = m
 		}
 	}
 
 	if matchedConfig == nil {
+		// rejects may reveal platform configs, so log it only in server.
+		logger.Infof("no platform for dimensions=%v: %s", dimensions, strings.Join(rejects, "; "))
 		resp.Error = gomapb.ExecResp_BAD_REQUEST.Enum()
+		if denied != nil {
+			err := accessDeniedError{err: fmt.Errorf("dimensions=%v: %v", dimensions, denied)}
+			resp.BadRequestReasonCode = gomapb.ExecResp_ACCESS_DENIED.Enum()
+			resp.ErrorMessage = append(resp.ErrorMessage, err.Error())
+			return nil, nil, err
+		}
 		resp.ErrorMessage = append(resp.ErrorMessage, fmt.Sprintf("Could not matching runtime config with dimensions=%v", dimensions))
 		return nil, nil, fmt.Errorf("possible platform not found in inventory: dimensions=%v", dimensions)
 	}
@@ -628,13 +777,3 @@ func fromSubprogramSpec(spec *gomapb.SubprogramSpec) selector {
 		BinaryHash: string(spec.GetBinaryHash()),
 	}
 }
-
-func (in *Inventory) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	versionID, resp := in.status()
-	w.Header().Set("Content-Type", "text/plain")
-	fmt.Fprintf(w, "version-id: %s\n", versionID)
-	fmt.Fprintln(w)
-	for _, cfg := range resp {
-		fmt.Fprintf(w, "%v\n", cfg)
-	}
-}
@@ -0,0 +1,622 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	tspb "github.com/golang/protobuf/ptypes/timestamp"
+
+	"go.chromium.org/goma/server/command/normalizer"
+	"go.chromium.org/goma/server/log"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// inventoryFilter is a compiled cmdpb.InventoryFilter.
+type inventoryFilter struct {
+	name       string
+	version    string
+	target     string
+	binaryHash string
+	dimensions []string
+}
+
+func newInventoryFilter(f *cmdpb.InventoryFilter) inventoryFilter {
+	target := f.GetTarget()
+	if target != "" {
+		// match with normalized target in inventory.
+		// use target as is, if it is not normalizable (e.g. "java").
+		t, err := normalizer.Target(target)
+		if err == nil {
+			target = t
+		}
+	}
+	return inventoryFilter{
+		name:       f.GetName(),
+		version:    f.GetVersion(),
+		target:     target,
+		binaryHash: f.GetBinaryHash(),
+		dimensions: f.GetDimensions(),
+	}
+}
+
+func (f inventoryFilter) matchSelector(sel selector) bool {
+	switch {
+	case f.name != "" && f.name != sel.Name:
+		return false
+	case f.version != "" && f.version != sel.Version:
+		return false
+	case f.target != "" && f.target != sel.Target:
+		return false
+	case f.binaryHash != "" && f.binaryHash != sel.BinaryHash:
+		return false
+	}
+	return true
+}
+
+func (f inventoryFilter) matchDimensions(cfg *cmdpb.Config) bool {
+	if len(f.dimensions) == 0 {
+		return true
+	}
+	exprs, err := parseDimensionExprs(cfg.GetDimensions(), cfg.GetDimensionExpressions())
+	if err != nil {
+		return false
+	}
+	return matchDimensions(f.dimensions, exprs).ok
+}
+
+// appendConfigs appends configs in configs matched with f, sorted by
+// address and selector, and their usages to resp.
+// If keep is not nil, it only appends config for which keep returns true.
+func (in *Inventory) appendConfigs(resp *cmdpb.InventoryResp, f inventoryFilter, configs map[string]map[selector]*cmdpb.Config, keep func(*cmdpb.Config) bool, now time.Time) {
+	var addrs []string
+	for a := range configs {
+		addrs = append(addrs, a)
+	}
+	sort.Strings(addrs)
+	for _, a := range addrs {
+		var sels []selector
+		m := configs[a]
+		for sel, cfg := range m {
+			if keep != nil && !keep(cfg) {
+				continue
+			}
+			if !f.matchSelector(sel) || !f.matchDimensions(cfg) {
+				continue
+			}
+			sels = append(sels, sel)
+		}
+		sort.Sort(byName(sels))
+		for _, sel := range sels {
+			resp.Configs = append(resp.Configs, proto.Clone(m[sel]).(*cmdpb.Config))
+			resp.Usages = append(resp.Usages, in.usage.usage(sel, a, now))
+		}
+	}
+}
+
+// Query returns configs in the inventory matched with filter,
+// with usages of the configs and misses of toolchains.
+// It includes configs of candidate versions in staged rollout.
+func (in *Inventory) Query(filter *cmdpb.InventoryFilter) *cmdpb.InventoryResp {
+	f := newInventoryFilter(filter)
+	now := time.Now()
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	resp := &cmdpb.InventoryResp{
+		VersionId: in.versionID,
+	}
+	in.appendConfigs(resp, f, in.configs, nil, now)
+
+	for _, cand := range in.candidates {
+		runtime := cand.rollout.Runtime
+		in.appendConfigs(resp, f, cand.configs, func(cfg *cmdpb.Config) bool {
+			rollout := cfg.GetRollout()
+			return rollout.GetCandidate() && rollout.GetRuntime() == runtime
+		}, now)
+	}
+
+	for _, pc := range in.platformConfigs {
+		if !f.matchDimensions(pc.config) {
+			continue
+		}
+		resp.PlatformConfigs = append(resp.PlatformConfigs, proto.Clone(pc.config).(*cmdpb.Config))
+	}
+	resp.Misses = in.usage.missList(f, now)
+	return resp
+}
+
+// Report returns toolchains not picked in unusedFor, and toolchains
+// missed at least minMisses times in the last week.
+// Usage is tracked in memory, so toolchains may be reported as unused
+// if exec_server started less than unusedFor ago. Check tracking_since.
+func (in *Inventory) Report(unusedFor time.Duration, minMisses int64) *cmdpb.ToolchainReport {
+	now := time.Now()
+	resp := in.Query(&cmdpb.InventoryFilter{})
+	report := &cmdpb.ToolchainReport{
+		VersionId: resp.VersionId,
+	}
+	in.usage.mu.RLock()
+	since := in.usage.since
+	in.usage.mu.RUnlock()
+	if !since.IsZero() {
+		report.TrackingSince, _ = ptypes.TimestampProto(since)
+	}
+	for _, u := range resp.Usages {
+		if u.LastPicked != nil {
+			t, err := ptypes.Timestamp(u.LastPicked)
+			if err == nil && now.Sub(t) < unusedFor {
+				continue
+			}
+		}
+		report.Unused = append(report.Unused, u)
+	}
+	for _, m := range resp.Misses {
+		if m.Misses_7D < minMisses {
+			continue
+		}
+		report.Missing = append(report.Missing, m)
+	}
+	return report
+}
+
+// filterFromQuery returns filter from URL query parameters
+// "name", "version", "target", "binary_hash" and "dimension".
+// "dimension" may be specified multiple times, or comma separated.
+func filterFromQuery(q url.Values) *cmdpb.InventoryFilter {
+	var dimensions []string
+	for _, v := range q["dimension"] {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			dimensions = append(dimensions, d)
+		}
+	}
+	return &cmdpb.InventoryFilter{
+		Name:       q.Get("name"),
+		Version:    q.Get("version"),
+		Target:     q.Get("target"),
+		BinaryHash: q.Get("binary_hash"),
+		Dimensions: dimensions,
+	}
+}
+
+// ServeHTTP serves configs in the inventory, or toolchain report.
+//
+// "view" query parameter selects contents:
+//  (empty): configs filtered by query parameters (see filterFromQuery),
+//           with their usages and misses of toolchains.
+//  report: toolchains not picked in "unused_days" days (default 7),
+//          and toolchains missed at least "min_misses" times
+//          (default 1) in the last week.
+//
+// Output format is specified by "format" query parameter:
+//  json: protojson of cmdpb.InventoryResp or cmdpb.ToolchainReport.
+//  html: html page.
+//  text (or empty): text format.
+func (in *Inventory) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	format := q.Get("format")
+	switch format {
+	case "json", "html", "text", "":
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		return
+	}
+	switch view := q.Get("view"); view {
+	case "":
+		in.serveInventory(w, req, format)
+	case "report":
+		in.serveReport(w, req, format)
+	default:
+		http.Error(w, fmt.Sprintf("unknown view %q", view), http.StatusBadRequest)
+	}
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, m proto.Message) {
+	w.Header().Set("Content-Type", "application/json")
+	marshaler := &jsonpb.Marshaler{Indent: "  "}
+	err := marshaler.Marshal(w, m)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Errorf("failed to marshal %T: %v", m, err)
+	}
+}
+
+func writeHTML(ctx context.Context, w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := inventoryTmpl.ExecuteTemplate(w, name, data)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Errorf("failed to execute template %s: %v", name, err)
+	}
+}
+
+func (in *Inventory) serveInventory(w http.ResponseWriter, req *http.Request, format string) {
+	ctx := req.Context()
+	filter := filterFromQuery(req.URL.Query())
+	resp := in.Query(filter)
+	switch format {
+	case "json":
+		writeJSON(ctx, w, resp)
+
+	case "html":
+		writeHTML(ctx, w, "Page", newInventoryPage(ctx, req.URL.Path, filter, resp))
+
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "version-id: %s\n", resp.VersionId)
+		fmt.Fprintln(w)
+		for _, cfg := range resp.Configs {
+			fmt.Fprintf(w, "%v\n", cfg)
+		}
+		if len(resp.PlatformConfigs) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "platform configs:")
+			for _, cfg := range resp.PlatformConfigs {
+				fmt.Fprintf(w, "%v\n", cfg)
+			}
+		}
+		if len(resp.Usages) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "usages:")
+			for _, u := range resp.Usages {
+				fmt.Fprintf(w, "%v\n", u)
+			}
+		}
+		if len(resp.Misses) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "misses:")
+			for _, m := range resp.Misses {
+				fmt.Fprintf(w, "%v\n", m)
+			}
+		}
+	}
+}
+
+func (in *Inventory) serveReport(w http.ResponseWriter, req *http.Request, format string) {
+	ctx := req.Context()
+	q := req.URL.Query()
+	unusedDays := 7
+	if v := q.Get("unused_days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("bad unused_days %q", v), http.StatusBadRequest)
+			return
+		}
+		unusedDays = n
+	}
+	minMisses := int64(1)
+	if v := q.Get("min_misses"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("bad min_misses %q", v), http.StatusBadRequest)
+			return
+		}
+		minMisses = n
+	}
+	report := in.Report(time.Duration(unusedDays)*24*time.Hour, minMisses)
+	switch format {
+	case "json":
+		writeJSON(ctx, w, report)
+
+	case "html":
+		writeHTML(ctx, w, "Report", reportPage{
+			Path:          req.URL.Path,
+			UnusedDays:    unusedDays,
+			MinMisses:     minMisses,
+			VersionID:     report.VersionId,
+			TrackingSince: timestampString(ctx, report.TrackingSince),
+			Unused:        newUsageRows(ctx, report.Unused),
+			Missing:       newMissRows(ctx, report.Missing),
+		})
+
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "version-id: %s\n", report.VersionId)
+		fmt.Fprintf(w, "tracking-since: %s\n", timestampString(ctx, report.TrackingSince))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "unused in %d days:\n", unusedDays)
+		for _, u := range report.Unused {
+			fmt.Fprintf(w, "%v\n", u)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "missing at least %d times in the last week:\n", minMisses)
+		for _, m := range report.Missing {
+			fmt.Fprintf(w, "%v\n", m)
+		}
+	}
+}
+
+func timestampString(ctx context.Context, ts *tspb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Warnf("strange timestamp: %v", err)
+		return ""
+	}
+	return t.String()
+}
+
+type inventoryPage struct {
+	Path      string
+	Filter    *cmdpb.InventoryFilter
+	Dimension string
+	VersionID string
+	Configs   []inventoryPageConfig
+	Platforms []inventoryPagePlatform
+	Misses    []missRow
+}
+
+type inventoryPageConfig struct {
+	Addr       string
+	Selector   *cmdpb.Selector
+	Dimensions string
+	BuildTime  string
+	Rollout    string
+	Usage      usageRow
+}
+
+type inventoryPagePlatform struct {
+	Dimensions string
+	Properties string
+	HasNsjail  bool
+}
+
+type usageRow struct {
+	Addr       string
+	Selector   *cmdpb.Selector
+	LastPicked string
+	Picks1h    int64
+	Picks1d    int64
+	Picks7d    int64
+}
+
+func newUsageRows(ctx context.Context, usages []*cmdpb.ToolchainUsage) []usageRow {
+	var rows []usageRow
+	for _, u := range usages {
+		rows = append(rows, usageRow{
+			Addr:       u.Addr,
+			Selector:   u.Selector,
+			LastPicked: timestampString(ctx, u.LastPicked),
+			Picks1h:    u.Picks_1H,
+			Picks1d:    u.Picks_1D,
+			Picks7d:    u.Picks_7D,
+		})
+	}
+	return rows
+}
+
+type missRow struct {
+	Selector   *cmdpb.Selector
+	LastMissed string
+	Misses1h   int64
+	Misses1d   int64
+	Misses7d   int64
+}
+
+func newMissRows(ctx context.Context, misses []*cmdpb.ToolchainMiss) []missRow {
+	var rows []missRow
+	for _, m := range misses {
+		rows = append(rows, missRow{
+			Selector:   m.Selector,
+			LastMissed: timestampString(ctx, m.LastMissed),
+			Misses1h:   m.Misses_1H,
+			Misses1d:   m.Misses_1D,
+			Misses7d:   m.Misses_7D,
+		})
+	}
+	return rows
+}
+
+type reportPage struct {
+	Path          string
+	UnusedDays    int
+	MinMisses     int64
+	VersionID     string
+	TrackingSince string
+	Unused        []usageRow
+	Missing       []missRow
+}
+
+func newInventoryPage(ctx context.Context, path string, filter *cmdpb.InventoryFilter, resp *cmdpb.InventoryResp) inventoryPage {
+	page := inventoryPage{
+		Path:      path,
+		Filter:    filter,
+		Dimension: strings.Join(filter.Dimensions, ","),
+		VersionID: resp.VersionId,
+		Misses:    newMissRows(ctx, resp.Misses),
+	}
+	// resp.Usages[i] is usage of resp.Configs[i].
+	usages := newUsageRows(ctx, resp.Usages)
+	for i, cfg := range resp.Configs {
+		c := inventoryPageConfig{
+			Addr:       cfg.GetTarget().GetAddr(),
+			Selector:   cfg.GetCmdDescriptor().GetSelector(),
+			Dimensions: strings.Join(cfg.GetDimensions(), ", "),
+			BuildTime:  timestampString(ctx, cfg.GetBuildInfo().GetTimestamp()),
+			Rollout:    rolloutVersionString(cfg),
+		}
+		if i < len(usages) {
+			c.Usage = usages[i]
+		}
+		page.Configs = append(page.Configs, c)
+	}
+	for _, cfg := range resp.PlatformConfigs {
+		var props []string
+		for _, p := range cfg.GetRemoteexecPlatform().GetProperties() {
+			props = append(props, p.Name+"="+p.Value)
+		}
+		page.Platforms = append(page.Platforms, inventoryPagePlatform{
+			Dimensions: strings.Join(cfg.GetDimensions(), ", "),
+			Properties: strings.Join(props, ", "),
+			HasNsjail:  cfg.GetRemoteexecPlatform().GetHasNsjail(),
+		})
+	}
+	return page
+}
+
+var inventoryTmpl = template.Must(template.New("Page").Parse(inventoryHTML))
+
+const inventoryHTML = `
+{{define "Page"}}
+<html>
+ <head>
+  <title>inventory</title>
+ </head>
+ <body>
+<h1>inventory</h1>
+<p>version-id: {{.VersionID}}
+ (<a href="{{.Path}}?format=json">json</a>
+ <a href="{{.Path}}?format=text">text</a>
+ <a href="{{.Path}}?view=report&format=html">report</a>)
+
+<form action="{{.Path}}" method="get">
+ <input type="hidden" name="format" value="html">
+ name: <input type="text" name="name" value="{{.Filter.Name}}">
+ version: <input type="text" name="version" value="{{.Filter.Version}}">
+ target: <input type="text" name="target" value="{{.Filter.Target}}">
+ binary_hash: <input type="text" name="binary_hash" value="{{.Filter.BinaryHash}}">
+ dimension: <input type="text" name="dimension" value="{{.Dimension}}">
+ <input type="submit" value="filter">
+</form>
+
+<h2>configs</h2>
+<table border="1">
+ <tr>
+  <th>addr
+  <th>name
+  <th>version
+  <th>target
+  <th>binary_hash
+  <th>dimensions
+  <th>build time
+  <th>rollout
+  <th>last picked
+  <th>picks 1h
+  <th>picks 1d
+  <th>picks 7d
+ {{range $c := .Configs}}
+ <tr>
+  <td>{{$c.Addr}}
+  <td>{{$c.Selector.Name}}
+  <td>{{$c.Selector.Version}}
+  <td>{{$c.Selector.Target}}
+  <td>{{$c.Selector.BinaryHash}}
+  <td>{{$c.Dimensions}}
+  <td>{{$c.BuildTime}}
+  <td>{{$c.Rollout}}
+  <td>{{$c.Usage.LastPicked}}
+  <td>{{$c.Usage.Picks1h}}
+  <td>{{$c.Usage.Picks1d}}
+  <td>{{$c.Usage.Picks7d}}
+ {{end}}
+</table>
+
+<h2>platform configs</h2>
+<table border="1">
+ <tr>
+  <th>dimensions
+  <th>properties
+  <th>has nsjail
+ {{range $p := .Platforms}}
+ <tr>
+  <td>{{$p.Dimensions}}
+  <td>{{$p.Properties}}
+  <td>{{$p.HasNsjail}}
+ {{end}}
+</table>
+
+<h2>misses</h2>
+{{template "Misses" .Misses}}
+ </body>
+</html>
+{{end}}
+
+{{define "Misses"}}
+<table border="1">
+ <tr>
+  <th>name
+  <th>version
+  <th>target
+  <th>binary_hash
+  <th>last missed
+  <th>misses 1h
+  <th>misses 1d
+  <th>misses 7d
+ {{range $m := .}}
+ <tr>
+  <td>{{$m.Selector.Name}}
+  <td>{{$m.Selector.Version}}
+  <td>{{$m.Selector.Target}}
+  <td>{{$m.Selector.BinaryHash}}
+  <td>{{$m.LastMissed}}
+  <td>{{$m.Misses1h}}
+  <td>{{$m.Misses1d}}
+  <td>{{$m.Misses7d}}
+ {{end}}
+</table>
+{{end}}
+
+{{define "Report"}}
+<html>
+ <head>
+  <title>toolchain report</title>
+ </head>
+ <body>
+<h1>toolchain report</h1>
+<p>version-id: {{.VersionID}}
+<p>tracking since: {{.TrackingSince}}
+ (<a href="{{.Path}}?format=html">inventory</a>)
+
+<form action="{{.Path}}" method="get">
+ <input type="hidden" name="view" value="report">
+ <input type="hidden" name="format" value="html">
+ unused days: <input type="text" name="unused_days" value="{{.UnusedDays}}">
+ min misses: <input type="text" name="min_misses" value="{{.MinMisses}}">
+ <input type="submit" value="report">
+</form>
+
+<h2>unused in {{.UnusedDays}} days</h2>
+<table border="1">
+ <tr>
+  <th>addr
+  <th>name
+  <th>version
+  <th>target
+  <th>binary_hash
+  <th>last picked
+ {{range $u := .Unused}}
+ <tr>
+  <td>{{$u.Addr}}
+  <td>{{$u.Selector.Name}}
+  <td>{{$u.Selector.Version}}
+  <td>{{$u.Selector.Target}}
+  <td>{{$u.Selector.BinaryHash}}
+  <td>{{$u.LastPicked}}
+ {{end}}
+</table>
+
+<h2>missing at least {{.MinMisses}} times in the last week</h2>
+{{template "Misses" .Missing}}
+ </body>
+</html>
+{{end}}
+`
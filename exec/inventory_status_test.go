@@ -0,0 +1,224 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/log"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// newTestStatusInventory returns inventory with stable and candidate
+// configs of linux, mac config and platform configs.
+// linux-1 is picked twice, and "missing" is missed once.
+func newTestStatusInventory(t *testing.T) *Inventory {
+	t.Helper()
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	in := &Inventory{}
+	err := in.Configure(ctx, &cmdpb.ConfigResp{
+		VersionId: "v1",
+		Configs: []*cmdpb.Config{
+			testConfig("linux-1", testRollout("linux", "1", false, 10)),
+			testConfig("linux-1", testRollout("linux", "2", true, 10)),
+			testConfig("mac-1", nil),
+			{
+				RemoteexecPlatform: &cmdpb.RemoteexecPlatform{},
+				Dimensions:         []string{"os:linux"},
+			},
+			{
+				RemoteexecPlatform: &cmdpb.RemoteexecPlatform{},
+				Dimensions:         []string{"os:win"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure()=%v; want nil error", err)
+	}
+	now := time.Now()
+	in.usage.pick(testSelector("linux-1"), testAddr, now)
+	in.usage.pick(testSelector("linux-1"), testAddr, now)
+	in.usage.miss(testSelector("missing"), now)
+	return in
+}
+
+func TestInventoryQuery(t *testing.T) {
+	in := newTestStatusInventory(t)
+
+	resp := in.Query(&cmdpb.InventoryFilter{})
+	if resp.VersionId != "v1" {
+		t.Errorf("VersionId=%q; want v1", resp.VersionId)
+	}
+	type config struct {
+		hash      string
+		seq       string
+		candidate bool
+		picks     int64
+	}
+	var got []config
+	for i, cfg := range resp.Configs {
+		got = append(got, config{
+			hash:      cfg.GetCmdDescriptor().GetSelector().GetBinaryHash(),
+			seq:       cfg.GetRollout().GetSeq(),
+			candidate: cfg.GetRollout().GetCandidate(),
+			picks:     resp.Usages[i].Picks_1H,
+		})
+	}
+	// stable configs first, then candidate configs.
+	want := []config{
+		{hash: "linux-1", seq: "1", picks: 2},
+		{hash: "mac-1"},
+		{hash: "linux-1", seq: "2", candidate: true, picks: 2},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(config{})); diff != "" {
+		t.Errorf("Query() configs diff -want +got:\n%s", diff)
+	}
+	if len(resp.PlatformConfigs) != 2 {
+		t.Errorf("Query() platform configs=%v; want 2 configs", resp.PlatformConfigs)
+	}
+	if len(resp.Misses) != 1 || resp.Misses[0].Selector.BinaryHash != "missing" || resp.Misses[0].Misses_7D != 1 {
+		t.Errorf("Query() misses=%v; want 1 miss of missing", resp.Misses)
+	}
+
+	for _, tc := range []struct {
+		filter    *cmdpb.InventoryFilter
+		configs   int
+		platforms int
+		misses    int
+	}{
+		{
+			filter:    &cmdpb.InventoryFilter{BinaryHash: "mac-1"},
+			configs:   1,
+			platforms: 2,
+		},
+		{
+			// target is normalized.
+			filter:    &cmdpb.InventoryFilter{Target: "x86_64-unknown-linux-gnu"},
+			configs:   3,
+			platforms: 2,
+			misses:    1,
+		},
+		{
+			filter:    &cmdpb.InventoryFilter{Name: "gcc"},
+			platforms: 2,
+		},
+		{
+			// configs without dimensions don't match.
+			filter:    &cmdpb.InventoryFilter{Dimensions: []string{"os:linux"}},
+			platforms: 1,
+			misses:    1,
+		},
+	} {
+		resp := in.Query(tc.filter)
+		if len(resp.Configs) != tc.configs || len(resp.Usages) != tc.configs || len(resp.PlatformConfigs) != tc.platforms || len(resp.Misses) != tc.misses {
+			t.Errorf("Query(%v)=%d configs %d usages %d platforms %d misses; want %d configs %d platforms %d misses", tc.filter, len(resp.Configs), len(resp.Usages), len(resp.PlatformConfigs), len(resp.Misses), tc.configs, tc.platforms, tc.misses)
+		}
+	}
+}
+
+func TestInventoryReport(t *testing.T) {
+	in := newTestStatusInventory(t)
+
+	report := in.Report(time.Hour, 1)
+	if report.VersionId != "v1" || report.TrackingSince == nil {
+		t.Errorf("Report() version=%q tracking_since=%v; want v1 with tracking_since", report.VersionId, report.TrackingSince)
+	}
+	if len(report.Unused) != 1 || report.Unused[0].Selector.BinaryHash != "mac-1" {
+		t.Errorf("Report() unused=%v; want mac-1", report.Unused)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Selector.BinaryHash != "missing" {
+		t.Errorf("Report() missing=%v; want missing", report.Missing)
+	}
+
+	report = in.Report(time.Hour, 2)
+	if len(report.Missing) != 0 {
+		t.Errorf("Report(min_misses=2) missing=%v; want none", report.Missing)
+	}
+}
+
+func TestFilterFromQuery(t *testing.T) {
+	q := url.Values{
+		"name":        {"clang"},
+		"target":      {"x86_64-linux-gnu"},
+		"binary_hash": {"hash"},
+		"dimension":   {"os:linux, glibc>=2.27", "", "arch:x86_64"},
+	}
+	got := filterFromQuery(q)
+	want := &cmdpb.InventoryFilter{
+		Name:       "clang",
+		Target:     "x86_64-linux-gnu",
+		BinaryHash: "hash",
+		Dimensions: []string{"os:linux", "glibc>=2.27", "arch:x86_64"},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("filterFromQuery(%v)=%v; want %v", q, got, want)
+	}
+}
+
+func TestInventoryServeHTTP(t *testing.T) {
+	in := newTestStatusInventory(t)
+
+	serve := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/inventory?"+query, nil)
+		w := httptest.NewRecorder()
+		in.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("format=json&binary_hash=mac-1")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("json: code=%d content-type=%q; want %d application/json", w.Code, w.Header().Get("Content-Type"), http.StatusOK)
+	}
+	resp := &cmdpb.InventoryResp{}
+	if err := jsonpb.Unmarshal(w.Body, resp); err != nil || resp.VersionId != "v1" || len(resp.Configs) != 1 {
+		t.Errorf("json: %v, %v; want v1 with 1 config", resp, err)
+	}
+
+	w = serve("view=report&format=json&unused_days=0&min_misses=1")
+	report := &cmdpb.ToolchainReport{}
+	if err := jsonpb.Unmarshal(w.Body, report); err != nil || len(report.Unused) != 3 || len(report.Missing) != 1 {
+		t.Errorf("report json: %v, %v; want 3 unused, 1 missing", report, err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: "version-id: v1\n"},
+		{query: "format=text", want: "platform configs:"},
+		{query: "format=html", want: "<h1>inventory</h1>"},
+		{query: "view=report", want: "missing at least 1 times in the last week:"},
+		{query: "view=report&format=html", want: "<h1>toolchain report</h1>"},
+	} {
+		w := serve(tc.query)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tc.want) {
+			t.Errorf("%q: code=%d body=%q; want %d with %q", tc.query, w.Code, w.Body.String(), http.StatusOK, tc.want)
+		}
+	}
+
+	for _, query := range []string{
+		"format=xml",
+		"view=unknown",
+		"view=report&unused_days=-1",
+		"view=report&min_misses=x",
+	} {
+		w := serve(query)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: code=%d; want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	"go.chromium.org/goma/server/log"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+var (
+	rolloutRequests = stats.Int64(
+		"go.chromium.org/goma/server/exec.rollout-requests",
+		"requests to toolchain config in staged rollout",
+		stats.UnitDimensionless)
+	rolloutDecisions = stats.Int64(
+		"go.chromium.org/goma/server/exec.rollout-decisions",
+		"decisions of staged rollout",
+		stats.UnitDimensionless)
+
+	runtimeKey  = tag.MustNewKey("runtime")
+	versionKey  = tag.MustNewKey("version")
+	decisionKey = tag.MustNewKey("decision")
+
+	// DefaultRolloutViews are the default views of staged rollout.
+	// You need to register the view for data to actually be collected.
+	DefaultRolloutViews = []*view.View{
+		{
+			Description: `counts requests in staged rollout. version is "stable" or "candidate". result is "ok" or "error"`,
+			TagKeys: []tag.Key{
+				runtimeKey,
+				versionKey,
+				resultKey,
+			},
+			Measure:     rolloutRequests,
+			Aggregation: view.Count(),
+		},
+		{
+			Description: `counts decisions of staged rollout. decision is "promote" or "rollback"`,
+			TagKeys: []tag.Key{
+				runtimeKey,
+				decisionKey,
+			},
+			Measure:     rolloutDecisions,
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+// rolloutKeys are values used as hash key of staged rollout.
+type rolloutKeys struct {
+	user            string
+	compilerProxyID string
+	group           string
+}
+
+func newRolloutKeys(ctx context.Context, req *gomapb.ExecReq) rolloutKeys {
+	var rk rolloutKeys
+	if eu, ok := enduser.FromContext(ctx); ok {
+		rk.user = string(eu.Email)
+		rk.group = eu.Group
+	}
+	if rk.user == "" {
+		rk.user = req.GetRequesterInfo().GetUsername()
+	}
+	// compiler_proxy_id is "<id>/<request sequence>".
+	id := req.GetRequesterInfo().GetCompilerProxyId()
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[:i]
+	}
+	rk.compilerProxyID = id
+	return rk
+}
+
+func (rk rolloutKeys) value(k cmdpb.RolloutConfig_HashKey) string {
+	switch k {
+	case cmdpb.RolloutConfig_USER:
+		return rk.user
+	case cmdpb.RolloutConfig_COMPILER_PROXY_ID:
+		return rk.compilerProxyID
+	case cmdpb.RolloutConfig_GROUP:
+		return rk.group
+	}
+	return ""
+}
+
+// inCandidate reports whether the request identified by rk should be
+// routed to candidate version of the runtime in rollout.
+// Requests without hash key are routed to stable version.
+func (rk rolloutKeys) inCandidate(rollout *cmdpb.ConfigRollout) bool {
+	key := rk.value(rollout.GetConfig().GetHashKey())
+	if key == "" {
+		return false
+	}
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s\x00%s", rollout.Runtime, key)
+	return int32(h.Sum32()%100) < rollout.GetConfig().GetCandidatePercent()
+}
+
+// rolloutVersion identifies a version of runtime in rollout.
+type rolloutVersion struct {
+	runtime   string
+	seq       string
+	candidate bool
+}
+
+func (v rolloutVersion) String() string {
+	if v.candidate {
+		return "candidate"
+	}
+	return "stable"
+}
+
+type rolloutCount struct {
+	requests int64
+	errors   int64
+}
+
+func (c rolloutCount) errorRate() float64 {
+	if c.requests == 0 {
+		return 0
+	}
+	return float64(c.errors) / float64(c.requests)
+}
+
+// rolloutStats counts results per version in rollout.
+type rolloutStats struct {
+	mu     sync.Mutex
+	counts map[rolloutVersion]rolloutCount
+}
+
+func (s *rolloutStats) record(v rolloutVersion, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts == nil {
+		s.counts = make(map[rolloutVersion]rolloutCount)
+	}
+	c := s.counts[v]
+	c.requests++
+	if failed {
+		c.errors++
+	}
+	s.counts[v] = c
+}
+
+func (s *rolloutStats) get(v rolloutVersion) rolloutCount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[v]
+}
+
+// retain drops counts of versions not in vs.
+func (s *rolloutStats) retain(vs map[rolloutVersion]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for v := range s.counts {
+		if !vs[v] {
+			delete(s.counts, v)
+		}
+	}
+}
+
+// RecordResult records result of the request using cfg.
+// It is used to evaluate candidate in staged rollout, so
+// failed should be true only if the request failed on server side
+// due to possibly broken config, e.g. api error or compiler crash,
+// and should be false for bad requests.
+func (in *Inventory) RecordResult(ctx context.Context, cfg *cmdpb.Config, failed bool) {
+	rollout := cfg.GetRollout()
+	if rollout == nil {
+		return
+	}
+	v := rolloutVersion{
+		runtime:   rollout.Runtime,
+		seq:       rollout.Seq,
+		candidate: rollout.Candidate,
+	}
+	in.rollout.record(v, failed)
+	result := "ok"
+	if failed {
+		result = "error"
+	}
+	err := stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(runtimeKey, v.runtime),
+		tag.Upsert(versionKey, v.String()),
+		tag.Upsert(resultKey, result),
+	}, rolloutRequests.M(1))
+	if err != nil {
+		log.FromContext(ctx).Errorf("failed to record stats: %v", err)
+	}
+}
+
+// RolloutDecision is a decision of staged rollout.
+type RolloutDecision struct {
+	// Runtime is the runtime name.
+	Runtime string
+	// Seq is the seq of the candidate.
+	Seq string
+	// Promote is true if the candidate should be promoted to stable.
+	// Otherwise, the candidate should be rolled back.
+	Promote bool
+	// Reason describes why it is decided.
+	Reason string
+}
+
+func (d RolloutDecision) String() string {
+	action := "rollback"
+	if d.Promote {
+		action = "promote"
+	}
+	return fmt.Sprintf("%s %s seq=%s: %s", action, d.Runtime, d.Seq, d.Reason)
+}
+
+// EvaluateRollouts compares error rates of candidate and stable
+// versions of runtimes in rollout, and returns decisions for
+// candidates that have enough requests.
+// Caller should apply decisions to config, and configure inventory again.
+func (in *Inventory) EvaluateRollouts(ctx context.Context) []RolloutDecision {
+	logger := log.FromContext(ctx)
+	in.mu.RLock()
+	cands := in.candidates
+	stableSeqs := make(map[string]string)
+	for _, cand := range cands {
+		stableSeqs[cand.rollout.Runtime] = in.stableSeqs[cand.rollout.Runtime]
+	}
+	in.mu.RUnlock()
+
+	var decisions []RolloutDecision
+	for _, cand := range cands {
+		runtime := cand.rollout.Runtime
+		cc := in.rollout.get(rolloutVersion{
+			runtime:   runtime,
+			seq:       cand.rollout.Seq,
+			candidate: true,
+		})
+		sc := in.rollout.get(rolloutVersion{
+			runtime: runtime,
+			seq:     stableSeqs[runtime],
+		})
+		cfg := cand.rollout.GetConfig()
+		if cc.requests < cfg.GetMinRequests() || cc.requests == 0 {
+			logger.Infof("rollout %s seq=%s: %d requests < %d", runtime, cand.rollout.Seq, cc.requests, cfg.GetMinRequests())
+			continue
+		}
+		d := RolloutDecision{
+			Runtime: runtime,
+			Seq:     cand.rollout.Seq,
+		}
+		increase := cc.errorRate() - sc.errorRate()
+		d.Promote = increase <= cfg.GetMaxErrorRateIncrease()
+		d.Reason = fmt.Sprintf("error rate candidate=%d/%d stable=%d/%d increase=%.4f max=%.4f",
+			cc.errors, cc.requests, sc.errors, sc.requests, increase, cfg.GetMaxErrorRateIncrease())
+		logger.Infof("rollout decision: %s", d)
+		decision := "rollback"
+		if d.Promote {
+			decision = "promote"
+		}
+		err := stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(runtimeKey, runtime),
+			tag.Upsert(decisionKey, decision),
+		}, rolloutDecisions.M(1))
+		if err != nil {
+			logger.Errorf("failed to record stats: %v", err)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// candidateConfigs holds configs used for requests routed to candidate.
+// addrs and configs are the same as Inventory's, except the runtime
+// uses candidate version instead of stable version.
+type candidateConfigs struct {
+	rollout *cmdpb.ConfigRollout
+	addrs   map[selector][]string
+	configs map[string]map[selector]*cmdpb.Config
+}
+
+// pickCandidate returns candidate configs for cmdSel if the request
+// should be routed to candidate version.
+// It returns nil if the request should use stable version.
+func (in *Inventory) pickCandidate(cmdSel selector, rk rolloutKeys) *candidateConfigs {
+	// candidates are sorted by runtime when configured.
+	for _, cand := range in.candidates {
+		if _, ok := cand.addrs[cmdSel]; !ok {
+			continue
+		}
+		if rk.inCandidate(cand.rollout) {
+			return cand
+		}
+	}
+	return nil
+}
+
+// rolloutVersionString returns version string of cfg for logging.
+func rolloutVersionString(cfg *cmdpb.Config) string {
+	rollout := cfg.GetRollout()
+	if rollout == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s seq=%s candidate=%t", rollout.Runtime, rollout.Seq, rollout.Candidate)
+}
@@ -0,0 +1,341 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+
+	"go.chromium.org/goma/server/auth/enduser"
+	"go.chromium.org/goma/server/log"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+const testAddr = "rbe.example.com:443"
+
+func testConfig(binaryHash string, rollout *cmdpb.ConfigRollout) *cmdpb.Config {
+	return &cmdpb.Config{
+		Target: &cmdpb.Target{
+			Addr: testAddr,
+		},
+		BuildInfo: &cmdpb.BuildInfo{
+			Timestamp: ptypes.TimestampNow(),
+		},
+		CmdDescriptor: &cmdpb.CmdDescriptor{
+			Selector: &cmdpb.Selector{
+				Name:       "clang",
+				Version:    "1",
+				Target:     "x86_64-unknown-linux-gnu",
+				BinaryHash: binaryHash,
+			},
+			Setup: &cmdpb.CmdDescriptor_Setup{
+				PathType: cmdpb.CmdDescriptor_POSIX,
+			},
+		},
+		Rollout: rollout,
+	}
+}
+
+func testRollout(runtime, seq string, candidate bool, percent int32) *cmdpb.ConfigRollout {
+	return &cmdpb.ConfigRollout{
+		Runtime:   runtime,
+		Seq:       seq,
+		Candidate: candidate,
+		Config: &cmdpb.RolloutConfig{
+			CandidatePercent:     percent,
+			MinRequests:          10,
+			MaxErrorRateIncrease: 0.1,
+		},
+	}
+}
+
+// testSelector returns normalized selector of testConfig.
+func testSelector(binaryHash string) selector {
+	sel, _, err := fromCommandSpec(testExecReq(binaryHash, "").CommandSpec)
+	if err != nil {
+		panic(err)
+	}
+	return sel
+}
+
+func testExecReq(binaryHash, user string) *gomapb.ExecReq {
+	return &gomapb.ExecReq{
+		CommandSpec: &gomapb.CommandSpec{
+			Name:       proto.String("clang"),
+			Version:    proto.String("1"),
+			Target:     proto.String("x86_64-unknown-linux-gnu"),
+			BinaryHash: []byte(binaryHash),
+		},
+		RequesterInfo: &gomapb.RequesterInfo{
+			Username: proto.String(user),
+		},
+	}
+}
+
+func TestRolloutKeysInCandidate(t *testing.T) {
+	rollout := func(key cmdpb.RolloutConfig_HashKey, percent int32) *cmdpb.ConfigRollout {
+		return &cmdpb.ConfigRollout{
+			Runtime:   "linux",
+			Seq:       "2",
+			Candidate: true,
+			Config: &cmdpb.RolloutConfig{
+				CandidatePercent: percent,
+				HashKey:          key,
+			},
+		}
+	}
+	for _, tc := range []struct {
+		desc    string
+		rk      rolloutKeys
+		rollout *cmdpb.ConfigRollout
+		want    bool
+	}{
+		{
+			desc:    "zero percent",
+			rk:      rolloutKeys{user: "alice@example.com"},
+			rollout: rollout(cmdpb.RolloutConfig_USER, 0),
+			want:    false,
+		},
+		{
+			desc:    "all",
+			rk:      rolloutKeys{user: "alice@example.com"},
+			rollout: rollout(cmdpb.RolloutConfig_USER, 100),
+			want:    true,
+		},
+		{
+			desc:    "no hash key",
+			rk:      rolloutKeys{compilerProxyID: "abc"},
+			rollout: rollout(cmdpb.RolloutConfig_USER, 100),
+			want:    false,
+		},
+		{
+			desc:    "compiler proxy id",
+			rk:      rolloutKeys{compilerProxyID: "abc"},
+			rollout: rollout(cmdpb.RolloutConfig_COMPILER_PROXY_ID, 100),
+			want:    true,
+		},
+		{
+			desc:    "no group",
+			rk:      rolloutKeys{user: "alice@example.com"},
+			rollout: rollout(cmdpb.RolloutConfig_GROUP, 100),
+			want:    false,
+		},
+	} {
+		if got := tc.rk.inCandidate(tc.rollout); got != tc.want {
+			t.Errorf("%s: inCandidate(%v)=%t; want %t", tc.desc, tc.rollout, got, tc.want)
+		}
+	}
+
+	// requests are routed to candidate by percent, and users in
+	// candidate stay in candidate when percent increases.
+	n := 0
+	for i := 0; i < 1000; i++ {
+		rk := rolloutKeys{user: fmt.Sprintf("user%d@example.com", i)}
+		in10 := rk.inCandidate(rollout(cmdpb.RolloutConfig_USER, 10))
+		in50 := rk.inCandidate(rollout(cmdpb.RolloutConfig_USER, 50))
+		if in10 && !in50 {
+			t.Errorf("%s: in candidate at 10%%, but not at 50%%", rk.user)
+		}
+		if in50 {
+			n++
+		}
+	}
+	if n < 400 || n > 600 {
+		t.Errorf("%d/1000 users in candidate at 50%%; want about 500", n)
+	}
+}
+
+func TestNewRolloutKeys(t *testing.T) {
+	ctx := context.Background()
+	req := &gomapb.ExecReq{
+		RequesterInfo: &gomapb.RequesterInfo{
+			Username:        proto.String("alice"),
+			CompilerProxyId: proto.String("abc/1"),
+		},
+	}
+	rk := newRolloutKeys(ctx, req)
+	if want := (rolloutKeys{user: "alice", compilerProxyID: "abc"}); rk != want {
+		t.Errorf("newRolloutKeys(%v)=%#v; want %#v", req, rk, want)
+	}
+
+	ctx = enduser.NewContext(ctx, enduser.New("alice@example.com", "goma-users", nil))
+	req.RequesterInfo.CompilerProxyId = proto.String("abc/2")
+	rk = newRolloutKeys(ctx, req)
+	if want := (rolloutKeys{user: "alice@example.com", compilerProxyID: "abc", group: "goma-users"}); rk != want {
+		t.Errorf("newRolloutKeys(%v) with enduser=%#v; want %#v", req, rk, want)
+	}
+}
+
+func TestInventoryConfigureRollout(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	linuxStable := testConfig("linux-1", testRollout("linux", "1", false, 100))
+	linuxCandidate := testConfig("linux-1", testRollout("linux", "2", true, 100))
+	winStable := testConfig("win-1", testRollout("win", "1", false, 100))
+	winCandidate := testConfig("win-2", testRollout("win", "2", true, 100))
+	mac := testConfig("mac-1", nil)
+
+	in := &Inventory{}
+	err := in.Configure(ctx, &cmdpb.ConfigResp{
+		VersionId: "v1",
+		Configs: []*cmdpb.Config{
+			winCandidate,
+			linuxCandidate,
+			mac,
+			winStable,
+			linuxStable,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure()=%v; want nil error", err)
+	}
+
+	// stable configs.
+	for sel, want := range map[selector]*cmdpb.Config{
+		testSelector("linux-1"): linuxStable,
+		testSelector("win-1"):   winStable,
+		testSelector("mac-1"):   mac,
+	} {
+		if got := in.configs[testAddr][sel]; got != want {
+			t.Errorf("stable config for %s=%v; want %v", sel, got, want)
+		}
+	}
+	if _, ok := in.addrs[testSelector("win-2")]; ok {
+		t.Errorf("stable addrs has candidate only selector win-2")
+	}
+	if got, want := in.stableSeqs, map[string]string{"linux": "1", "win": "1"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("stableSeqs=%v; want %v", got, want)
+	}
+
+	// candidate configs, sorted by runtime.
+	if len(in.candidates) != 2 || in.candidates[0].rollout.Runtime != "linux" || in.candidates[1].rollout.Runtime != "win" {
+		t.Fatalf("candidates=%v; want linux, win", in.candidates)
+	}
+	for i, want := range []map[selector]*cmdpb.Config{
+		{
+			// linux uses candidate, others use stable.
+			testSelector("linux-1"): linuxCandidate,
+			testSelector("win-1"):   winStable,
+			testSelector("mac-1"):   mac,
+		},
+		{
+			// win uses candidate, others use stable.
+			testSelector("linux-1"): linuxStable,
+			testSelector("win-2"):   winCandidate,
+			testSelector("mac-1"):   mac,
+		},
+	} {
+		cand := in.candidates[i]
+		if len(cand.configs[testAddr]) != len(want) || len(cand.addrs) != len(want) {
+			t.Errorf("candidate %s: configs=%v addrs=%v; want %v", cand.rollout.Runtime, cand.configs[testAddr], cand.addrs, want)
+		}
+		for sel, cfg := range want {
+			if got := cand.configs[testAddr][sel]; got != cfg {
+				t.Errorf("candidate %s: config for %s=%v; want %v", cand.rollout.Runtime, sel, got, cfg)
+			}
+		}
+	}
+
+	// all requests are routed to candidate at 100%.
+	for _, tc := range []struct {
+		hash string
+		want *cmdpb.Config
+	}{
+		{hash: "linux-1", want: linuxCandidate},
+		{hash: "win-1", want: winStable},
+		{hash: "win-2", want: winCandidate},
+		{hash: "mac-1", want: mac},
+	} {
+		cfg, _, err := in.pickCmd(ctx, testSelector(tc.hash), nil, testExecReq(tc.hash, "alice"))
+		if err != nil || cfg != tc.want {
+			t.Errorf("pickCmd(%s)=%v, _, %v; want %v, nil", tc.hash, cfg, err, tc.want)
+		}
+	}
+	// requests without hash key use stable.
+	cfg, _, err := in.pickCmd(ctx, testSelector("linux-1"), nil, testExecReq("linux-1", ""))
+	if err != nil || cfg != linuxStable {
+		t.Errorf("pickCmd(linux-1) without user=%v, _, %v; want %v, nil", cfg, err, linuxStable)
+	}
+}
+
+func TestEvaluateRollouts(t *testing.T) {
+	ctx := log.NewContext(context.Background(), zap.NewNop().Sugar())
+	linuxStable := testConfig("linux-1", testRollout("linux", "1", false, 50))
+	linuxCandidate := testConfig("linux-1", testRollout("linux", "2", true, 50))
+	winStable := testConfig("win-1", testRollout("win", "1", false, 50))
+	winCandidate := testConfig("win-1", testRollout("win", "2", true, 50))
+
+	in := &Inventory{}
+	err := in.Configure(ctx, &cmdpb.ConfigResp{
+		VersionId: "v1",
+		Configs:   []*cmdpb.Config{linuxStable, linuxCandidate, winStable, winCandidate},
+	})
+	if err != nil {
+		t.Fatalf("Configure()=%v; want nil error", err)
+	}
+
+	record := func(cfg *cmdpb.Config, requests, errors int) {
+		for i := 0; i < requests; i++ {
+			in.RecordResult(ctx, cfg, i < errors)
+		}
+	}
+	// not used in rollout.
+	in.RecordResult(ctx, testConfig("mac-1", nil), true)
+
+	// linux: same error rate as stable.
+	record(linuxStable, 20, 2)
+	record(linuxCandidate, 5, 1)
+	// win: error rate increases.
+	record(winStable, 20, 0)
+	record(winCandidate, 5, 2)
+
+	if got := in.EvaluateRollouts(ctx); len(got) != 0 {
+		t.Errorf("EvaluateRollouts() with less than min requests=%v; want no decision", got)
+	}
+
+	record(linuxCandidate, 5, 0)
+	record(winCandidate, 5, 0)
+	got := in.EvaluateRollouts(ctx)
+	if len(got) != 2 {
+		t.Fatalf("EvaluateRollouts()=%v; want 2 decisions", got)
+	}
+	if got[0].Runtime != "linux" || got[0].Seq != "2" || !got[0].Promote {
+		t.Errorf("EvaluateRollouts()[0]=%v; want promote linux seq=2", got[0])
+	}
+	if got[1].Runtime != "win" || got[1].Seq != "2" || got[1].Promote {
+		t.Errorf("EvaluateRollouts()[1]=%v; want rollback win seq=2", got[1])
+	}
+
+	// counts of versions not in config are dropped by Configure.
+	err = in.Configure(ctx, &cmdpb.ConfigResp{
+		VersionId: "v2",
+		Configs: []*cmdpb.Config{
+			// linux seq=2 is promoted.
+			testConfig("linux-1", testRollout("linux", "2", false, 50)),
+			winStable,
+			testConfig("win-1", testRollout("win", "3", true, 50)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Configure()=%v; want nil error", err)
+	}
+	if c := in.rollout.get(rolloutVersion{runtime: "linux", seq: "1"}); c.requests != 0 {
+		t.Errorf("linux seq=1 counts=%v; want dropped", c)
+	}
+	if c := in.rollout.get(rolloutVersion{runtime: "linux", seq: "2", candidate: true}); c.requests != 0 {
+		t.Errorf("linux seq=2 candidate counts=%v; want dropped", c)
+	}
+	if c := in.rollout.get(rolloutVersion{runtime: "win", seq: "1"}); c.requests != 20 {
+		t.Errorf("win seq=1 counts=%v; want 20 requests", c)
+	}
+	if got := in.EvaluateRollouts(ctx); len(got) != 0 {
+		t.Errorf("EvaluateRollouts() for new candidate=%v; want no decision", got)
+	}
+}
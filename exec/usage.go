@@ -0,0 +1,208 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+const (
+	// hourlyBuckets is a number of hourly buckets in rolling counters.
+	hourlyBuckets = 24
+	// dailyBuckets is a number of daily buckets in rolling counters,
+	// i.e. counters hold counts in the last week.
+	dailyBuckets = 7
+	// usageWindow is a period rolling counters hold counts for.
+	usageWindow = dailyBuckets * 24 * time.Hour
+
+	// maxUsageEntries is the maximum number of entries for picks or
+	// misses. misses might be requested with arbitrary selectors.
+	maxUsageEntries = 10000
+)
+
+// countBucket holds count in bucket number (unix time / bucket size).
+type countBucket struct {
+	bucket int64
+	count  int64
+}
+
+// rollingCount counts events in the last day per hour, and
+// in the last week per day. It takes ~530 bytes per counter.
+type rollingCount struct {
+	mu     sync.Mutex
+	last   time.Time
+	hourly [hourlyBuckets]countBucket
+	daily  [dailyBuckets]countBucket
+}
+
+func incBucket(buckets []countBucket, b int64) {
+	c := &buckets[b%int64(len(buckets))]
+	if c.bucket != b {
+		c.bucket = b
+		c.count = 0
+	}
+	c.count++
+}
+
+func sumBuckets(buckets []countBucket, b, n int64) int64 {
+	var s int64
+	for _, c := range buckets {
+		if c.bucket > b-n && c.bucket <= b {
+			s += c.count
+		}
+	}
+	return s
+}
+
+func (c *rollingCount) inc(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	incBucket(c.hourly[:], now.Unix()/int64(time.Hour/time.Second))
+	incBucket(c.daily[:], now.Unix()/int64(24*time.Hour/time.Second))
+	c.last = now
+}
+
+// lastTime returns the time of the last event.
+func (c *rollingCount) lastTime() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// sum returns counts in the last d, in hour granularity up to a day,
+// or in day granularity up to a week.
+func (c *rollingCount) sum(now time.Time, d time.Duration) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d <= hourlyBuckets*time.Hour {
+		return sumBuckets(c.hourly[:], now.Unix()/int64(time.Hour/time.Second), int64(d/time.Hour))
+	}
+	return sumBuckets(c.daily[:], now.Unix()/int64(24*time.Hour/time.Second), int64(d/(24*time.Hour)))
+}
+
+type usageKey struct {
+	sel  selector
+	addr string
+}
+
+// usageTracker tracks picks and misses of toolchains.
+// mu guards maps only. Each rollingCount has its own lock, so
+// counting on existing entries only takes read lock of mu.
+type usageTracker struct {
+	mu    sync.RWMutex
+	since time.Time
+	picks map[usageKey]*rollingCount
+	// key's addr is empty for misses.
+	misses map[usageKey]*rollingCount
+}
+
+func (u *usageTracker) start(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.since.IsZero() {
+		u.since = now
+	}
+}
+
+func (u *usageTracker) pick(sel selector, addr string, now time.Time) {
+	u.count(&u.picks, usageKey{sel: sel, addr: addr}, now)
+}
+
+func (u *usageTracker) miss(sel selector, now time.Time) {
+	u.count(&u.misses, usageKey{sel: sel}, now)
+}
+
+// count counts an event of k in *m.
+// If *m is full, it evicts entries not updated in usageWindow,
+// or drops the event if no entry is evicted.
+func (u *usageTracker) count(m *map[usageKey]*rollingCount, k usageKey, now time.Time) {
+	u.mu.RLock()
+	c, ok := (*m)[k]
+	u.mu.RUnlock()
+	if ok {
+		c.inc(now)
+		return
+	}
+	u.mu.Lock()
+	if *m == nil {
+		*m = make(map[usageKey]*rollingCount)
+	}
+	c, ok = (*m)[k]
+	if !ok {
+		if len(*m) >= maxUsageEntries {
+			for k, c := range *m {
+				if now.Sub(c.lastTime()) > usageWindow {
+					delete(*m, k)
+				}
+			}
+		}
+		if len(*m) < maxUsageEntries {
+			c = &rollingCount{}
+			(*m)[k] = c
+		}
+	}
+	u.mu.Unlock()
+	if c == nil {
+		return
+	}
+	c.inc(now)
+}
+
+// usage returns usage of sel on addr.
+func (u *usageTracker) usage(sel selector, addr string, now time.Time) *cmdpb.ToolchainUsage {
+	u.mu.RLock()
+	c, ok := u.picks[usageKey{sel: sel, addr: addr}]
+	u.mu.RUnlock()
+	r := &cmdpb.ToolchainUsage{
+		Selector: sel.Proto(),
+		Addr:     addr,
+	}
+	if !ok {
+		return r
+	}
+	r.LastPicked, _ = ptypes.TimestampProto(c.lastTime())
+	r.Picks_1H = c.sum(now, time.Hour)
+	r.Picks_1D = c.sum(now, 24*time.Hour)
+	r.Picks_7D = c.sum(now, usageWindow)
+	return r
+}
+
+// missList returns misses in the last week that match with f,
+// sorted by number of misses in the last week.
+func (u *usageTracker) missList(f inventoryFilter, now time.Time) []*cmdpb.ToolchainMiss {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	var r []*cmdpb.ToolchainMiss
+	for k, c := range u.misses {
+		sel := k.sel
+		if !f.matchSelector(sel) {
+			continue
+		}
+		m := &cmdpb.ToolchainMiss{
+			Selector:  sel.Proto(),
+			Misses_1H: c.sum(now, time.Hour),
+			Misses_1D: c.sum(now, 24*time.Hour),
+			Misses_7D: c.sum(now, usageWindow),
+		}
+		if m.Misses_7D == 0 {
+			continue
+		}
+		m.LastMissed, _ = ptypes.TimestampProto(c.lastTime())
+		r = append(r, m)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Misses_7D != r[j].Misses_7D {
+			return r[i].Misses_7D > r[j].Misses_7D
+		}
+		return r[i].Selector.String() < r[j].Selector.String()
+	})
+	return r
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package exec
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestRollingCount(t *testing.T) {
+	now := time.Date(2021, 4, 1, 12, 30, 0, 0, time.UTC)
+	c := &rollingCount{}
+	for _, d := range []time.Duration{
+		8 * 24 * time.Hour,
+		6 * 24 * time.Hour,
+		3 * 24 * time.Hour,
+		20 * time.Hour,
+		2 * time.Hour,
+		10 * time.Minute,
+		0,
+		0,
+	} {
+		c.inc(now.Add(-d))
+	}
+	if got := c.lastTime(); !got.Equal(now) {
+		t.Errorf("lastTime()=%v; want %v", got, now)
+	}
+
+	for _, tc := range []struct {
+		d    time.Duration
+		want int64
+	}{
+		{d: time.Hour, want: 3},
+		{d: 3 * time.Hour, want: 4},
+		{d: 24 * time.Hour, want: 5},
+		{d: 4 * 24 * time.Hour, want: 6},
+		{d: usageWindow, want: 7},
+	} {
+		if got := c.sum(now, tc.d); got != tc.want {
+			t.Errorf("sum(now, %v)=%d; want %d", tc.d, got, tc.want)
+		}
+	}
+
+	// old buckets are reused.
+	later := now.Add(usageWindow)
+	c.inc(later)
+	if got := c.sum(later, usageWindow); got != 1 {
+		t.Errorf("sum(later, %v)=%d; want 1", usageWindow, got)
+	}
+}
+
+func TestRollingCountSize(t *testing.T) {
+	const maxSize = 1024
+	if size := unsafe.Sizeof(rollingCount{}); size > maxSize {
+		t.Errorf("sizeof(rollingCount)=%d; want <= %d", size, maxSize)
+	}
+}
+
+func TestUsageTracker(t *testing.T) {
+	now := time.Now()
+	u := &usageTracker{}
+	u.start(now)
+	u.start(now.Add(time.Hour))
+	if !u.since.Equal(now) {
+		t.Errorf("since=%v; want %v", u.since, now)
+	}
+
+	sel := testSelector("linux-1")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u.pick(sel, testAddr, now)
+		}()
+	}
+	wg.Wait()
+	r := u.usage(sel, testAddr, now)
+	if r.Picks_1H != 10 || r.Picks_1D != 10 || r.Picks_7D != 10 || r.LastPicked == nil {
+		t.Errorf("usage(linux-1)=%v; want 10 picks", r)
+	}
+	r = u.usage(sel, "other", now)
+	if r.Picks_7D != 0 || r.LastPicked != nil {
+		t.Errorf("usage(linux-1, other)=%v; want no picks", r)
+	}
+
+	u.miss(testSelector("miss-1"), now)
+	u.miss(testSelector("miss-2"), now)
+	u.miss(testSelector("miss-2"), now)
+	u.miss(testSelector("miss-3"), now.Add(-2*usageWindow))
+	misses := u.missList(inventoryFilter{}, now)
+	if len(misses) != 2 || misses[0].Selector.BinaryHash != "miss-2" || misses[0].Misses_7D != 2 || misses[1].Selector.BinaryHash != "miss-1" {
+		t.Errorf("missList()=%v; want miss-2, miss-1", misses)
+	}
+	misses = u.missList(inventoryFilter{binaryHash: "miss-1"}, now)
+	if len(misses) != 1 || misses[0].Selector.BinaryHash != "miss-1" {
+		t.Errorf("missList(miss-1)=%v; want miss-1", misses)
+	}
+}
+
+func TestUsageTrackerMaxEntries(t *testing.T) {
+	now := time.Now()
+	u := &usageTracker{}
+	u.misses = make(map[usageKey]*rollingCount)
+	for i := 0; i < maxUsageEntries; i++ {
+		c := &rollingCount{}
+		c.inc(now)
+		u.misses[usageKey{sel: selector{BinaryHash: fmt.Sprint(i)}}] = c
+	}
+	sel := testSelector("new")
+	u.miss(sel, now)
+	if _, ok := u.misses[usageKey{sel: sel}]; ok {
+		t.Errorf("miss(new) added when full of recent entries")
+	}
+
+	// stale entries are evicted when full.
+	stale := usageKey{sel: selector{BinaryHash: "stale"}}
+	delete(u.misses, usageKey{sel: selector{BinaryHash: "0"}})
+	u.misses[stale] = &rollingCount{last: now.Add(-2 * usageWindow)}
+	u.miss(sel, now)
+	if _, ok := u.misses[stale]; ok {
+		t.Errorf("stale entry is not evicted")
+	}
+	if _, ok := u.misses[usageKey{sel: sel}]; !ok {
+		t.Errorf("miss(new) not added after eviction")
+	}
+}
@@ -122,6 +122,36 @@ func (s *Service) LookupFile(ctx context.Context, req *gomapb.LookupFileReq) (*g
 	resp := &gomapb.LookupFileResp{
 		Blob: make([]*gomapb.FileBlob, len(req.GetHashKey())),
 	}
+	for i := range resp.Blob {
+		resp.Blob[i] = &gomapb.FileBlob{
+			BlobType: gomapb.FileBlob_FILE_UNSPECIFIED.Enum(),
+		}
+	}
+
+	t := time.Now()
+	r, err := s.Cache.BatchGet(ctx, &cachepb.BatchGetReq{
+		Keys: req.GetHashKey(),
+	})
+	getTime := time.Since(t)
+	switch status.Code(err) {
+	case codes.OK:
+		for i, hashKey := range req.GetHashKey() {
+			var kv *cachepb.KV
+			if i < len(r.Resps) {
+				kv = r.Resps[i].GetKv()
+			}
+			s.unmarshalBlob(ctx, i, hashKey, kv, resp.Blob[i])
+		}
+		logger.Infof("cache.BatchGet %d blobs: get:%s total:%s", len(req.GetHashKey()), getTime, time.Since(start))
+		return resp, nil
+	case codes.Unimplemented:
+		// cache server is older. fallback to Get.
+		logger.Warnf("cache.BatchGet: %v", err)
+	default:
+		// fallback to Get, which may succeed for some keys.
+		span.Annotatef(nil, "cache.BatchGet: %v", err)
+		logger.Warnf("cache.BatchGet %d blobs: %v", len(req.GetHashKey()), err)
+	}
 
 	var wg sync.WaitGroup
 
@@ -131,32 +161,17 @@ func (s *Service) LookupFile(ctx context.Context, req *gomapb.LookupFileReq) (*g
 		go func(i int, hashKey string) {
 			defer wg.Done()
 			t := time.Now()
-			resp.Blob[i] = &gomapb.FileBlob{
-				BlobType: gomapb.FileBlob_FILE_UNSPECIFIED.Enum(),
-			}
 			r, err := s.Cache.Get(ctx, &cachepb.GetReq{
 				Key: hashKey,
 			})
 			getTime := time.Since(t)
-			t = time.Now()
 			if err != nil {
 				span.Annotatef(nil, "%d: hashKey=%s: %v", i, hashKey, err)
 				logger.Warnf("%d: cache.Get %s: %v", i, hashKey, err)
 				return
 			}
-			if len(r.Kv.Value) == 0 {
-				span.Annotatef(nil, "%d: hashKey=%s not found", i, hashKey)
-				logger.Errorf("%d: cache.Get %s: no value", i, hashKey)
-				return
-			}
-			err = proto.Unmarshal(r.Kv.Value, resp.Blob[i])
-			unmarshalTime := time.Since(t)
-			if err != nil {
-				span.Annotatef(nil, "%d: hashKey=%s: proto.Unmarshal %v", i, hashKey, err)
-				logger.Errorf("%d: proto.Unmarshal %s: %v", i, hashKey, err)
-				return
-			}
-			logger.Infof("%d: cache.Get %s: get:%s unmarshal:%s", i, hashKey, getTime, unmarshalTime)
+			s.unmarshalBlob(ctx, i, hashKey, r.Kv, resp.Blob[i])
+			logger.Infof("%d: cache.Get %s: get:%s", i, hashKey, getTime)
 		}(i, hashKey)
 	}
 	logger.Debugf("waiting lookup %d blobs", len(req.GetHashKey()))
@@ -165,3 +180,28 @@ func (s *Service) LookupFile(ctx context.Context, req *gomapb.LookupFileReq) (*g
 
 	return resp, nil
 }
+
+// unmarshalBlob unmarshals kv's value for i-th hashKey into blob.
+// It leaves blob as is if kv is nil (i.e. not found) or has no value.
+func (s *Service) unmarshalBlob(ctx context.Context, i int, hashKey string, kv *cachepb.KV, blob *gomapb.FileBlob) {
+	span := trace.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	if kv == nil {
+		span.Annotatef(nil, "%d: hashKey=%s not found", i, hashKey)
+		logger.Warnf("%d: cache.Get %s: not found", i, hashKey)
+		return
+	}
+	if len(kv.Value) == 0 {
+		span.Annotatef(nil, "%d: hashKey=%s not found", i, hashKey)
+		logger.Errorf("%d: cache.Get %s: no value", i, hashKey)
+		return
+	}
+	t := time.Now()
+	err := proto.Unmarshal(kv.Value, blob)
+	if err != nil {
+		span.Annotatef(nil, "%d: hashKey=%s: proto.Unmarshal %v", i, hashKey, err)
+		logger.Errorf("%d: proto.Unmarshal %s: %v", i, hashKey, err)
+		return
+	}
+	logger.Debugf("%d: unmarshal %s: %s", i, hashKey, time.Since(t))
+}
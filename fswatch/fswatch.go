@@ -67,9 +67,17 @@ func (w *Watcher) loop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case event := <-w.w.Events:
-			w.ch <- resp{Event: event}
+			select {
+			case w.ch <- resp{Event: event}:
+			case <-ctx.Done():
+				return
+			}
 		case err := <-w.w.Errors:
-			w.ch <- resp{Err: err}
+			select {
+			case w.ch <- resp{Err: err}:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
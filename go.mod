@@ -7,6 +7,7 @@ require (
 	cloud.google.com/go/pubsub v1.10.0
 	cloud.google.com/go/storage v1.13.0
 	contrib.go.opencensus.io/exporter/stackdriver v0.13.5
+	github.com/aws/aws-sdk-go v1.23.20
 	github.com/bazelbuild/remote-apis v0.0.0-20200904140912-1aeb39973178
 	github.com/bazelbuild/remote-apis-sdks v0.0.0-20201118210229-b732553f9d45
 	github.com/fsnotify/fsnotify v1.4.9
@@ -16,8 +17,8 @@ require (
 	github.com/google/go-cmp v0.5.4
 	github.com/google/uuid v1.2.0
 	github.com/googleapis/gax-go/v2 v2.0.5
-	github.com/googleapis/google-cloud-go-testing v0.0.0-20190904031503-2d24dde44ba5
 	github.com/grpc-ecosystem/go-grpc-middleware v1.2.2
+	github.com/klauspost/compress v1.9.7
 	go.opencensus.io v0.23.0
 	go.uber.org/zap v1.16.0
 	golang.org/x/build v0.0.0-20191031202223-0706ea4fce0c
@@ -14,6 +14,7 @@ import (
 	proto "github.com/golang/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -201,6 +202,9 @@ type PutReq struct {
 
 	Kv        *KV  `protobuf:"bytes,1,opt,name=kv,proto3" json:"kv,omitempty"`
 	WriteBack bool `protobuf:"varint,2,opt,name=write_back,json=writeBack,proto3" json:"write_back,omitempty"`
+	// ttl is time to live of the key-value pair.
+	// if not set, key-value pair won't expire, but may be evicted.
+	Ttl *durationpb.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
 }
 
 func (x *PutReq) Reset() {
@@ -249,6 +253,13 @@ func (x *PutReq) GetWriteBack() bool {
 	return false
 }
 
+func (x *PutReq) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
 type PutResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -287,11 +298,287 @@ func (*PutResp) Descriptor() ([]byte, []int) {
 	return file_cache_cache_proto_rawDescGZIP(), []int{4}
 }
 
+type BatchGetReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	Fast bool     `protobuf:"varint,2,opt,name=fast,proto3" json:"fast,omitempty"`
+}
+
+func (x *BatchGetReq) Reset() {
+	*x = BatchGetReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetReq) ProtoMessage() {}
+
+func (x *BatchGetReq) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetReq.ProtoReflect.Descriptor instead.
+func (*BatchGetReq) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetReq) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *BatchGetReq) GetFast() bool {
+	if x != nil {
+		return x.Fast
+	}
+	return false
+}
+
+type BatchGetResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// resps are responses for keys in BatchGetReq, in the same order.
+	// kv is not set if key is not found.
+	Resps []*GetResp `protobuf:"bytes,1,rep,name=resps,proto3" json:"resps,omitempty"`
+}
+
+func (x *BatchGetResp) Reset() {
+	*x = BatchGetResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResp) ProtoMessage() {}
+
+func (x *BatchGetResp) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResp.ProtoReflect.Descriptor instead.
+func (*BatchGetResp) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchGetResp) GetResps() []*GetResp {
+	if x != nil {
+		return x.Resps
+	}
+	return nil
+}
+
+type BatchPutReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reqs []*PutReq `protobuf:"bytes,1,rep,name=reqs,proto3" json:"reqs,omitempty"`
+}
+
+func (x *BatchPutReq) Reset() {
+	*x = BatchPutReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchPutReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPutReq) ProtoMessage() {}
+
+func (x *BatchPutReq) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPutReq.ProtoReflect.Descriptor instead.
+func (*BatchPutReq) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchPutReq) GetReqs() []*PutReq {
+	if x != nil {
+		return x.Reqs
+	}
+	return nil
+}
+
+type BatchPutResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *BatchPutResp) Reset() {
+	*x = BatchPutResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchPutResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPutResp) ProtoMessage() {}
+
+func (x *BatchPutResp) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPutResp.ProtoReflect.Descriptor instead.
+func (*BatchPutResp) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{8}
+}
+
+type DeleteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *DeleteReq) Reset() {
+	*x = DeleteReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteReq) ProtoMessage() {}
+
+func (x *DeleteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteReq.ProtoReflect.Descriptor instead.
+func (*DeleteReq) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteReq) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type DeleteResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteResp) Reset() {
+	*x = DeleteResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cache_cache_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResp) ProtoMessage() {}
+
+func (x *DeleteResp) ProtoReflect() protoreflect.Message {
+	mi := &file_cache_cache_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResp.ProtoReflect.Descriptor instead.
+func (*DeleteResp) Descriptor() ([]byte, []int) {
+	return file_cache_cache_proto_rawDescGZIP(), []int{10}
+}
+
 var File_cache_cache_proto protoreflect.FileDescriptor
 
 var file_cache_cache_proto_rawDesc = []byte{
 	0x0a, 0x11, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x22, 0x2c, 0x0a, 0x02, 0x4b, 0x56,
+	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2c, 0x0a, 0x02, 0x4b, 0x56,
 	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
 	0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
 	0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x2e, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x52,
@@ -301,15 +588,32 @@ var file_cache_cache_proto_rawDesc = []byte{
 	0x65, 0x73, 0x70, 0x12, 0x19, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
 	0x09, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x4b, 0x56, 0x52, 0x02, 0x6b, 0x76, 0x12, 0x1b,
 	0x0a, 0x09, 0x69, 0x6e, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x08, 0x69, 0x6e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x22, 0x42, 0x0a, 0x06, 0x50,
+	0x08, 0x52, 0x08, 0x69, 0x6e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x22, 0x6f, 0x0a, 0x06, 0x50,
 	0x75, 0x74, 0x52, 0x65, 0x71, 0x12, 0x19, 0x0a, 0x02, 0x6b, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x0b, 0x32, 0x09, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x4b, 0x56, 0x52, 0x02, 0x6b, 0x76,
 	0x12, 0x1d, 0x0a, 0x0a, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x77, 0x72, 0x69, 0x74, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x22,
-	0x09, 0x0a, 0x07, 0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x6f,
-	0x2e, 0x63, 0x68, 0x72, 0x6f, 0x6d, 0x69, 0x75, 0x6d, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f,
-	0x6d, 0x61, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
-	0x63, 0x61, 0x63, 0x68, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x77, 0x72, 0x69, 0x74, 0x65, 0x42, 0x61, 0x63, 0x6b, 0x12,
+	0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x22, 0x09, 0x0a, 0x07,
+	0x50, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x22, 0x35, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x61,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x66, 0x61, 0x73, 0x74, 0x22, 0x34,
+	0x0a, 0x0c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x12, 0x24,
+	0x0a, 0x05, 0x72, 0x65, 0x73, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e,
+	0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x52, 0x05, 0x72,
+	0x65, 0x73, 0x70, 0x73, 0x22, 0x30, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x50, 0x75, 0x74,
+	0x52, 0x65, 0x71, 0x12, 0x21, 0x0a, 0x04, 0x72, 0x65, 0x71, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71,
+	0x52, 0x04, 0x72, 0x65, 0x71, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x42, 0x61, 0x74, 0x63, 0x68, 0x50,
+	0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x22, 0x1d, 0x0a, 0x09, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x0c, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x6f, 0x2e, 0x63, 0x68, 0x72, 0x6f, 0x6d, 0x69,
+	0x75, 0x6d, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f, 0x6d, 0x61, 0x2f, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -324,22 +628,32 @@ func file_cache_cache_proto_rawDescGZIP() []byte {
 	return file_cache_cache_proto_rawDescData
 }
 
-var file_cache_cache_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_cache_cache_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_cache_cache_proto_goTypes = []interface{}{
-	(*KV)(nil),      // 0: cache.KV
-	(*GetReq)(nil),  // 1: cache.GetReq
-	(*GetResp)(nil), // 2: cache.GetResp
-	(*PutReq)(nil),  // 3: cache.PutReq
-	(*PutResp)(nil), // 4: cache.PutResp
+	(*KV)(nil),                  // 0: cache.KV
+	(*GetReq)(nil),              // 1: cache.GetReq
+	(*GetResp)(nil),             // 2: cache.GetResp
+	(*PutReq)(nil),              // 3: cache.PutReq
+	(*PutResp)(nil),             // 4: cache.PutResp
+	(*BatchGetReq)(nil),         // 5: cache.BatchGetReq
+	(*BatchGetResp)(nil),        // 6: cache.BatchGetResp
+	(*BatchPutReq)(nil),         // 7: cache.BatchPutReq
+	(*BatchPutResp)(nil),        // 8: cache.BatchPutResp
+	(*DeleteReq)(nil),           // 9: cache.DeleteReq
+	(*DeleteResp)(nil),          // 10: cache.DeleteResp
+	(*durationpb.Duration)(nil), // 11: google.protobuf.Duration
 }
 var file_cache_cache_proto_depIdxs = []int32{
-	0, // 0: cache.GetResp.kv:type_name -> cache.KV
-	0, // 1: cache.PutReq.kv:type_name -> cache.KV
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	0,  // 0: cache.GetResp.kv:type_name -> cache.KV
+	0,  // 1: cache.PutReq.kv:type_name -> cache.KV
+	11, // 2: cache.PutReq.ttl:type_name -> google.protobuf.Duration
+	2,  // 3: cache.BatchGetResp.resps:type_name -> cache.GetResp
+	3,  // 4: cache.BatchPutReq.reqs:type_name -> cache.PutReq
+	5,  // [5:5] is the sub-list for method output_type
+	5,  // [5:5] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_cache_cache_proto_init() }
@@ -408,6 +722,78 @@ func file_cache_cache_proto_init() {
 				return nil
 			}
 		}
+		file_cache_cache_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cache_cache_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchGetResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cache_cache_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchPutReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cache_cache_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchPutResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cache_cache_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cache_cache_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -415,7 +801,7 @@ func file_cache_cache_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_cache_cache_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -38,12 +38,22 @@ var file_cache_cache_service_proto_rawDesc = []byte{
 	0x0a, 0x19, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x73, 0x65,
 	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x61, 0x63,
 	0x68, 0x65, 0x1a, 0x11, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x5e, 0x0a, 0x0c, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0d, 0x2e, 0x63,
-	0x61, 0x63, 0x68, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x63, 0x61,
-	0x63, 0x68, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x26, 0x0a,
-	0x03, 0x50, 0x75, 0x74, 0x12, 0x0d, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x50, 0x75, 0x74,
-	0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x50, 0x75, 0x74, 0x52,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xfd, 0x01, 0x0a, 0x0c, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x26, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x0d, 0x2e,
+	0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x63,
+	0x61, 0x63, 0x68, 0x65, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x26,
+	0x0a, 0x03, 0x50, 0x75, 0x74, 0x12, 0x0d, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x50, 0x75,
+	0x74, 0x52, 0x65, 0x71, 0x1a, 0x0e, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x50, 0x75, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x35, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47,
+	0x65, 0x74, 0x12, 0x12, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x22, 0x00, 0x12, 0x35, 0x0a,
+	0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x50, 0x75, 0x74, 0x12, 0x12, 0x2e, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x50, 0x75, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e,
+	0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x50, 0x75, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x22, 0x00, 0x12, 0x2f, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x10,
+	0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x1a, 0x11, 0x2e, 0x63, 0x61, 0x63, 0x68, 0x65, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52,
 	0x65, 0x73, 0x70, 0x22, 0x00, 0x42, 0x29, 0x5a, 0x27, 0x67, 0x6f, 0x2e, 0x63, 0x68, 0x72, 0x6f,
 	0x6d, 0x69, 0x75, 0x6d, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f, 0x6d, 0x61, 0x2f, 0x73, 0x65,
 	0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x61, 0x63, 0x68, 0x65,
@@ -51,18 +61,30 @@ var file_cache_cache_service_proto_rawDesc = []byte{
 }
 
 var file_cache_cache_service_proto_goTypes = []interface{}{
-	(*GetReq)(nil),  // 0: cache.GetReq
-	(*PutReq)(nil),  // 1: cache.PutReq
-	(*GetResp)(nil), // 2: cache.GetResp
-	(*PutResp)(nil), // 3: cache.PutResp
+	(*GetReq)(nil),       // 0: cache.GetReq
+	(*PutReq)(nil),       // 1: cache.PutReq
+	(*BatchGetReq)(nil),  // 2: cache.BatchGetReq
+	(*BatchPutReq)(nil),  // 3: cache.BatchPutReq
+	(*DeleteReq)(nil),    // 4: cache.DeleteReq
+	(*GetResp)(nil),      // 5: cache.GetResp
+	(*PutResp)(nil),      // 6: cache.PutResp
+	(*BatchGetResp)(nil), // 7: cache.BatchGetResp
+	(*BatchPutResp)(nil), // 8: cache.BatchPutResp
+	(*DeleteResp)(nil),   // 9: cache.DeleteResp
 }
 var file_cache_cache_service_proto_depIdxs = []int32{
 	0, // 0: cache.CacheService.Get:input_type -> cache.GetReq
 	1, // 1: cache.CacheService.Put:input_type -> cache.PutReq
-	2, // 2: cache.CacheService.Get:output_type -> cache.GetResp
-	3, // 3: cache.CacheService.Put:output_type -> cache.PutResp
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	2, // 2: cache.CacheService.BatchGet:input_type -> cache.BatchGetReq
+	3, // 3: cache.CacheService.BatchPut:input_type -> cache.BatchPutReq
+	4, // 4: cache.CacheService.Delete:input_type -> cache.DeleteReq
+	5, // 5: cache.CacheService.Get:output_type -> cache.GetResp
+	6, // 6: cache.CacheService.Put:output_type -> cache.PutResp
+	7, // 7: cache.CacheService.BatchGet:output_type -> cache.BatchGetResp
+	8, // 8: cache.CacheService.BatchPut:output_type -> cache.BatchPutResp
+	9, // 9: cache.CacheService.Delete:output_type -> cache.DeleteResp
+	5, // [5:10] is the sub-list for method output_type
+	0, // [0:5] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -107,6 +129,9 @@ const _ = grpc.SupportPackageIsVersion6
 type CacheServiceClient interface {
 	Get(ctx context.Context, in *GetReq, opts ...grpc.CallOption) (*GetResp, error)
 	Put(ctx context.Context, in *PutReq, opts ...grpc.CallOption) (*PutResp, error)
+	BatchGet(ctx context.Context, in *BatchGetReq, opts ...grpc.CallOption) (*BatchGetResp, error)
+	BatchPut(ctx context.Context, in *BatchPutReq, opts ...grpc.CallOption) (*BatchPutResp, error)
+	Delete(ctx context.Context, in *DeleteReq, opts ...grpc.CallOption) (*DeleteResp, error)
 }
 
 type cacheServiceClient struct {
@@ -135,10 +160,40 @@ func (c *cacheServiceClient) Put(ctx context.Context, in *PutReq, opts ...grpc.C
 	return out, nil
 }
 
+func (c *cacheServiceClient) BatchGet(ctx context.Context, in *BatchGetReq, opts ...grpc.CallOption) (*BatchGetResp, error) {
+	out := new(BatchGetResp)
+	err := c.cc.Invoke(ctx, "/cache.CacheService/BatchGet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) BatchPut(ctx context.Context, in *BatchPutReq, opts ...grpc.CallOption) (*BatchPutResp, error) {
+	out := new(BatchPutResp)
+	err := c.cc.Invoke(ctx, "/cache.CacheService/BatchPut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheServiceClient) Delete(ctx context.Context, in *DeleteReq, opts ...grpc.CallOption) (*DeleteResp, error) {
+	out := new(DeleteResp)
+	err := c.cc.Invoke(ctx, "/cache.CacheService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CacheServiceServer is the server API for CacheService service.
 type CacheServiceServer interface {
 	Get(context.Context, *GetReq) (*GetResp, error)
 	Put(context.Context, *PutReq) (*PutResp, error)
+	BatchGet(context.Context, *BatchGetReq) (*BatchGetResp, error)
+	BatchPut(context.Context, *BatchPutReq) (*BatchPutResp, error)
+	Delete(context.Context, *DeleteReq) (*DeleteResp, error)
 }
 
 // UnimplementedCacheServiceServer can be embedded to have forward compatible implementations.
@@ -151,6 +206,15 @@ func (*UnimplementedCacheServiceServer) Get(context.Context, *GetReq) (*GetResp,
 func (*UnimplementedCacheServiceServer) Put(context.Context, *PutReq) (*PutResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
 }
+func (*UnimplementedCacheServiceServer) BatchGet(context.Context, *BatchGetReq) (*BatchGetResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (*UnimplementedCacheServiceServer) BatchPut(context.Context, *BatchPutReq) (*BatchPutResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchPut not implemented")
+}
+func (*UnimplementedCacheServiceServer) Delete(context.Context, *DeleteReq) (*DeleteResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
 
 func RegisterCacheServiceServer(s *grpc.Server, srv CacheServiceServer) {
 	s.RegisterService(&_CacheService_serviceDesc, srv)
@@ -192,6 +256,60 @@ func _CacheService_Put_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CacheService_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cache.CacheService/BatchGet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).BatchGet(ctx, req.(*BatchGetReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_BatchPut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPutReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).BatchPut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cache.CacheService/BatchPut",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).BatchPut(ctx, req.(*BatchPutReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cache.CacheService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServiceServer).Delete(ctx, req.(*DeleteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CacheService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cache.CacheService",
 	HandlerType: (*CacheServiceServer)(nil),
@@ -204,6 +322,18 @@ var _CacheService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Put",
 			Handler:    _CacheService_Put_Handler,
 		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _CacheService_BatchGet_Handler,
+		},
+		{
+			MethodName: "BatchPut",
+			Handler:    _CacheService_BatchPut_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _CacheService_Delete_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cache/cache_service.proto",
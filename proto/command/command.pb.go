@@ -80,6 +80,107 @@ func (CmdDescriptor_PathType) EnumDescriptor() ([]byte, []int) {
 	return file_command_command_proto_rawDescGZIP(), []int{4, 0}
 }
 
+type RolloutConfig_HashKey int32
+
+const (
+	// user of the request.
+	RolloutConfig_USER RolloutConfig_HashKey = 0
+	// compiler_proxy_id of the request, without request sequence.
+	RolloutConfig_COMPILER_PROXY_ID RolloutConfig_HashKey = 1
+	// end user group of the request (i.e. acl group).
+	RolloutConfig_GROUP RolloutConfig_HashKey = 2
+)
+
+// Enum value maps for RolloutConfig_HashKey.
+var (
+	RolloutConfig_HashKey_name = map[int32]string{
+		0: "USER",
+		1: "COMPILER_PROXY_ID",
+		2: "GROUP",
+	}
+	RolloutConfig_HashKey_value = map[string]int32{
+		"USER":              0,
+		"COMPILER_PROXY_ID": 1,
+		"GROUP":             2,
+	}
+)
+
+func (x RolloutConfig_HashKey) Enum() *RolloutConfig_HashKey {
+	p := new(RolloutConfig_HashKey)
+	*p = x
+	return p
+}
+
+func (x RolloutConfig_HashKey) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RolloutConfig_HashKey) Descriptor() protoreflect.EnumDescriptor {
+	return file_command_command_proto_enumTypes[1].Descriptor()
+}
+
+func (RolloutConfig_HashKey) Type() protoreflect.EnumType {
+	return &file_command_command_proto_enumTypes[1]
+}
+
+func (x RolloutConfig_HashKey) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RolloutConfig_HashKey.Descriptor instead.
+func (RolloutConfig_HashKey) EnumDescriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type ACLRule_Action int32
+
+const (
+	ACLRule_UNSPECIFIED_ACTION ACLRule_Action = 0
+	ACLRule_ALLOW              ACLRule_Action = 1
+	ACLRule_DENY               ACLRule_Action = 2
+)
+
+// Enum value maps for ACLRule_Action.
+var (
+	ACLRule_Action_name = map[int32]string{
+		0: "UNSPECIFIED_ACTION",
+		1: "ALLOW",
+		2: "DENY",
+	}
+	ACLRule_Action_value = map[string]int32{
+		"UNSPECIFIED_ACTION": 0,
+		"ALLOW":              1,
+		"DENY":               2,
+	}
+)
+
+func (x ACLRule_Action) Enum() *ACLRule_Action {
+	p := new(ACLRule_Action)
+	*p = x
+	return p
+}
+
+func (x ACLRule_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ACLRule_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_command_command_proto_enumTypes[2].Descriptor()
+}
+
+func (ACLRule_Action) Type() protoreflect.EnumType {
+	return &file_command_command_proto_enumTypes[2]
+}
+
+func (x ACLRule_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ACLRule_Action.Descriptor instead.
+func (ACLRule_Action) EnumDescriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{22, 0}
+}
+
 // Selector is a command selector.
 // it is used to select a compiler or a subprogram/plugin to run on
 // cmd_server by matching it with CommandSpec or SubprogramSpec in a request
@@ -564,8 +665,24 @@ type Config struct {
 	RemoteexecPlatform *RemoteexecPlatform `protobuf:"bytes,5,opt,name=remoteexec_platform,json=remoteexecPlatform,proto3" json:"remoteexec_platform,omitempty"`
 	// If this config is configured for arbitrary toolchain support,
 	// set dimensions of the config. Otherwise, this should be nil.
+	// All requester's dimensions must be matched with some dimensions.
+	// Each dimension matches the same requester's dimension, unless
+	// dimension_expressions is set.
 	Dimensions []string `protobuf:"bytes,6,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
-	Acl        *ACL     `protobuf:"bytes,7,opt,name=acl,proto3" json:"acl,omitempty"`
+	// If true, each dimension is an expression matched with requester's
+	// dimensions.
+	//   "os:linux"     matches "os:linux".
+	//   "cpu:x86*"     matches "cpu:<value>" with glob pattern.
+	//   "glibc>=2.27"  matches "glibc:<version>" with version >= 2.27.
+	//                  operators: >=, <=, >, <, !=.
+	//   "!os:windows"  rejects the config if requester has "os:windows".
+	DimensionExpressions bool         `protobuf:"varint,11,opt,name=dimension_expressions,json=dimensionExpressions,proto3" json:"dimension_expressions,omitempty"`
+	Acl                  *ACL         `protobuf:"bytes,7,opt,name=acl,proto3" json:"acl,omitempty"`
+	InputLimits          *InputLimits `protobuf:"bytes,8,opt,name=input_limits,json=inputLimits,proto3" json:"input_limits,omitempty"`
+	// If this config is in staged rollout, set rollout state of the config.
+	Rollout *ConfigRollout `protobuf:"bytes,9,opt,name=rollout,proto3" json:"rollout,omitempty"`
+	// fallback policy of the runtime of this config.
+	Fallback *FallbackPolicy `protobuf:"bytes,10,opt,name=fallback,proto3" json:"fallback,omitempty"`
 }
 
 func (x *Config) Reset() {
@@ -635,6 +752,13 @@ func (x *Config) GetDimensions() []string {
 	return nil
 }
 
+func (x *Config) GetDimensionExpressions() bool {
+	if x != nil {
+		return x.DimensionExpressions
+	}
+	return false
+}
+
 func (x *Config) GetAcl() *ACL {
 	if x != nil {
 		return x.Acl
@@ -642,6 +766,103 @@ func (x *Config) GetAcl() *ACL {
 	return nil
 }
 
+func (x *Config) GetInputLimits() *InputLimits {
+	if x != nil {
+		return x.InputLimits
+	}
+	return nil
+}
+
+func (x *Config) GetRollout() *ConfigRollout {
+	if x != nil {
+		return x.Rollout
+	}
+	return nil
+}
+
+func (x *Config) GetFallback() *FallbackPolicy {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
+// ConfigRollout is rollout state of a config.
+type ConfigRollout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// name of runtime of the config.
+	Runtime string `protobuf:"bytes,1,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	// seq of the config's version.
+	Seq string `protobuf:"bytes,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	// true if the config is in candidate version.
+	// false if it is in stable version.
+	Candidate bool           `protobuf:"varint,3,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	Config    *RolloutConfig `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *ConfigRollout) Reset() {
+	*x = ConfigRollout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigRollout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigRollout) ProtoMessage() {}
+
+func (x *ConfigRollout) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigRollout.ProtoReflect.Descriptor instead.
+func (*ConfigRollout) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ConfigRollout) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *ConfigRollout) GetSeq() string {
+	if x != nil {
+		return x.Seq
+	}
+	return ""
+}
+
+func (x *ConfigRollout) GetCandidate() bool {
+	if x != nil {
+		return x.Candidate
+	}
+	return false
+}
+
+func (x *ConfigRollout) GetConfig() *RolloutConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
 // ACL is access control list for requester.
 type ACL struct {
 	state         protoimpl.MessageState
@@ -658,12 +879,17 @@ type ACL struct {
 	// If no disallowed_groups specified, only allowed_groups is allowed to use.
 	// If both are not specified, any groups are alllowed.
 	DisallowedGroups []string `protobuf:"bytes,2,rep,name=disallowed_groups,json=disallowedGroups,proto3" json:"disallowed_groups,omitempty"`
+	// Rules evaluated in order, after allowed_groups and disallowed_groups.
+	// The first matched rule decides whether the request is allowed.
+	// If no rule matched, the request is allowed. Add a DENY rule without
+	// conditions at the end to allow only requests matched with ALLOW rules.
+	Rules []*ACLRule `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
 }
 
 func (x *ACL) Reset() {
 	*x = ACL{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[7]
+		mi := &file_command_command_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -676,7 +902,7 @@ func (x *ACL) String() string {
 func (*ACL) ProtoMessage() {}
 
 func (x *ACL) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[7]
+	mi := &file_command_command_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -689,7 +915,7 @@ func (x *ACL) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ACL.ProtoReflect.Descriptor instead.
 func (*ACL) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{7}
+	return file_command_command_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ACL) GetAllowedGroups() []string {
@@ -706,6 +932,92 @@ func (x *ACL) GetDisallowedGroups() []string {
 	return nil
 }
 
+func (x *ACL) GetRules() []*ACLRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+// InputLimits is limits of inputs in a request.
+// It overrides server's default limits (e.g. --max-inputs) field by field.
+// 0 (unset) uses server's default limit, and -1 (or any negative value)
+// means unlimited even if server has default limit.
+type InputLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// maximum number of inputs.
+	MaxInputs int64 `protobuf:"varint,1,opt,name=max_inputs,json=maxInputs,proto3" json:"max_inputs,omitempty"`
+	// maximum total bytes of inputs.
+	MaxTotalBytes int64 `protobuf:"varint,2,opt,name=max_total_bytes,json=maxTotalBytes,proto3" json:"max_total_bytes,omitempty"`
+	// maximum depth of input tree.
+	MaxDepth int32 `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// maximum bytes of a single input file.
+	MaxFileBytes int64 `protobuf:"varint,4,opt,name=max_file_bytes,json=maxFileBytes,proto3" json:"max_file_bytes,omitempty"`
+}
+
+func (x *InputLimits) Reset() {
+	*x = InputLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InputLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputLimits) ProtoMessage() {}
+
+func (x *InputLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputLimits.ProtoReflect.Descriptor instead.
+func (*InputLimits) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *InputLimits) GetMaxInputs() int64 {
+	if x != nil {
+		return x.MaxInputs
+	}
+	return 0
+}
+
+func (x *InputLimits) GetMaxTotalBytes() int64 {
+	if x != nil {
+		return x.MaxTotalBytes
+	}
+	return 0
+}
+
+func (x *InputLimits) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *InputLimits) GetMaxFileBytes() int64 {
+	if x != nil {
+		return x.MaxFileBytes
+	}
+	return 0
+}
+
 // Platform is a set of requirements, such as haredware, operting system
 // for RBE backend.
 // matched with build.bazel.remote.execution.v2.Platform.
@@ -720,7 +1032,7 @@ type Platform struct {
 func (x *Platform) Reset() {
 	*x = Platform{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[8]
+		mi := &file_command_command_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -733,7 +1045,7 @@ func (x *Platform) String() string {
 func (*Platform) ProtoMessage() {}
 
 func (x *Platform) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[8]
+	mi := &file_command_command_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -746,7 +1058,7 @@ func (x *Platform) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Platform.ProtoReflect.Descriptor instead.
 func (*Platform) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{8}
+	return file_command_command_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Platform) GetProperties() []*Platform_Property {
@@ -757,7 +1069,7 @@ func (x *Platform) GetProperties() []*Platform_Property {
 }
 
 // RuntimeConfig is config for runtime.
-// NEXT ID TO USE: 10
+// NEXT ID TO USE: 13
 type RuntimeConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -796,12 +1108,20 @@ type RuntimeConfig struct {
 	// match any selector.
 	DisallowedCommands []*Selector `protobuf:"bytes,5,rep,name=disallowed_commands,json=disallowedCommands,proto3" json:"disallowed_commands,omitempty"`
 	Acl                *ACL        `protobuf:"bytes,9,opt,name=acl,proto3" json:"acl,omitempty"`
+	// limits of inputs for commands in the runtime.
+	// it overrides server's default limits if specified.
+	InputLimits *InputLimits `protobuf:"bytes,10,opt,name=input_limits,json=inputLimits,proto3" json:"input_limits,omitempty"`
+	// staged rollout of new version (seq) of the runtime.
+	Rollout *RolloutConfig `protobuf:"bytes,11,opt,name=rollout,proto3" json:"rollout,omitempty"`
+	// policy to use a command in the runtime for non-hermetic requests
+	// when requested command is not found.
+	Fallback *FallbackPolicy `protobuf:"bytes,12,opt,name=fallback,proto3" json:"fallback,omitempty"`
 }
 
 func (x *RuntimeConfig) Reset() {
 	*x = RuntimeConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[9]
+		mi := &file_command_command_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -814,7 +1134,7 @@ func (x *RuntimeConfig) String() string {
 func (*RuntimeConfig) ProtoMessage() {}
 
 func (x *RuntimeConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[9]
+	mi := &file_command_command_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -827,7 +1147,7 @@ func (x *RuntimeConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RuntimeConfig.ProtoReflect.Descriptor instead.
 func (*RuntimeConfig) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{9}
+	return file_command_command_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *RuntimeConfig) GetName() string {
@@ -886,35 +1206,68 @@ func (x *RuntimeConfig) GetAcl() *ACL {
 	return nil
 }
 
-// PlatformRuntimeConfig is a config to use the runtime.
-// NEXT ID TO USE: 3
-type PlatformRuntimeConfig struct {
+func (x *RuntimeConfig) GetInputLimits() *InputLimits {
+	if x != nil {
+		return x.InputLimits
+	}
+	return nil
+}
+
+func (x *RuntimeConfig) GetRollout() *RolloutConfig {
+	if x != nil {
+		return x.Rollout
+	}
+	return nil
+}
+
+func (x *RuntimeConfig) GetFallback() *FallbackPolicy {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
+// RolloutConfig is a config of staged rollout of new toolchain config
+// version.
+// When new seq is loaded, it becomes candidate version, and some
+// requests are routed to the candidate. The candidate is promoted to
+// stable or rolled back by comparing error rates of both versions.
+type RolloutConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Dimensions []string `protobuf:"bytes,1,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
-	// Set true if nsjail is available in the platform image.
-	HasNsjail bool `protobuf:"varint,2,opt,name=has_nsjail,json=hasNsjail,proto3" json:"has_nsjail,omitempty"`
-}
-
-func (x *PlatformRuntimeConfig) Reset() {
-	*x = PlatformRuntimeConfig{}
+	// percentage [0, 100] of requests routed to candidate version.
+	// 0 disables staged rollout, i.e. new version is used for all requests
+	// immediately.
+	CandidatePercent int32 `protobuf:"varint,1,opt,name=candidate_percent,json=candidatePercent,proto3" json:"candidate_percent,omitempty"`
+	// hash key to choose requests routed to candidate version.
+	HashKey RolloutConfig_HashKey `protobuf:"varint,2,opt,name=hash_key,json=hashKey,proto3,enum=command.RolloutConfig_HashKey" json:"hash_key,omitempty"`
+	// minimum number of requests of candidate version
+	// to promote or roll back.
+	MinRequests int64 `protobuf:"varint,3,opt,name=min_requests,json=minRequests,proto3" json:"min_requests,omitempty"`
+	// candidate version is rolled back if its error rate exceeds
+	// error rate of stable version by more than this value [0, 1].
+	MaxErrorRateIncrease float64 `protobuf:"fixed64,4,opt,name=max_error_rate_increase,json=maxErrorRateIncrease,proto3" json:"max_error_rate_increase,omitempty"`
+}
+
+func (x *RolloutConfig) Reset() {
+	*x = RolloutConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[10]
+		mi := &file_command_command_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PlatformRuntimeConfig) String() string {
+func (x *RolloutConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlatformRuntimeConfig) ProtoMessage() {}
+func (*RolloutConfig) ProtoMessage() {}
 
-func (x *PlatformRuntimeConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[10]
+func (x *RolloutConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -925,53 +1278,747 @@ func (x *PlatformRuntimeConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlatformRuntimeConfig.ProtoReflect.Descriptor instead.
-func (*PlatformRuntimeConfig) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use RolloutConfig.ProtoReflect.Descriptor instead.
+func (*RolloutConfig) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *PlatformRuntimeConfig) GetDimensions() []string {
+func (x *RolloutConfig) GetCandidatePercent() int32 {
 	if x != nil {
-		return x.Dimensions
+		return x.CandidatePercent
 	}
-	return nil
+	return 0
 }
 
-func (x *PlatformRuntimeConfig) GetHasNsjail() bool {
+func (x *RolloutConfig) GetHashKey() RolloutConfig_HashKey {
 	if x != nil {
-		return x.HasNsjail
+		return x.HashKey
 	}
-	return false
+	return RolloutConfig_USER
 }
 
-// ConfigMap is a config map; data source of Config.
-// admin creates/updates the file in <bucket>/<config>.config
-// and ConfigMapBucket will read the info.
-type ConfigMap struct {
+func (x *RolloutConfig) GetMinRequests() int64 {
+	if x != nil {
+		return x.MinRequests
+	}
+	return 0
+}
+
+func (x *RolloutConfig) GetMaxErrorRateIncrease() float64 {
+	if x != nil {
+		return x.MaxErrorRateIncrease
+	}
+	return 0
+}
+
+// PlatformRuntimeConfig is a config to use the runtime.
+// NEXT ID TO USE: 4
+type PlatformRuntimeConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Runtimes []*RuntimeConfig `protobuf:"bytes,1,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
-}
+	// dimensions. see Config.dimensions.
+	Dimensions []string `protobuf:"bytes,1,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
+	// Set true if dimensions are expressions.
+	// see Config.dimension_expressions.
+	DimensionExpressions bool `protobuf:"varint,3,opt,name=dimension_expressions,json=dimensionExpressions,proto3" json:"dimension_expressions,omitempty"`
+	// Set true if nsjail is available in the platform image.
+	HasNsjail bool `protobuf:"varint,2,opt,name=has_nsjail,json=hasNsjail,proto3" json:"has_nsjail,omitempty"`
+}
+
+func (x *PlatformRuntimeConfig) Reset() {
+	*x = PlatformRuntimeConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PlatformRuntimeConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlatformRuntimeConfig) ProtoMessage() {}
+
+func (x *PlatformRuntimeConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlatformRuntimeConfig.ProtoReflect.Descriptor instead.
+func (*PlatformRuntimeConfig) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PlatformRuntimeConfig) GetDimensions() []string {
+	if x != nil {
+		return x.Dimensions
+	}
+	return nil
+}
+
+func (x *PlatformRuntimeConfig) GetDimensionExpressions() bool {
+	if x != nil {
+		return x.DimensionExpressions
+	}
+	return false
+}
+
+func (x *PlatformRuntimeConfig) GetHasNsjail() bool {
+	if x != nil {
+		return x.HasNsjail
+	}
+	return false
+}
+
+// ConfigMap is a config map; data source of Config.
+// admin creates/updates the file in <bucket>/<config>.config
+// and ConfigMapBucket will read the info.
+type ConfigMap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Runtimes []*RuntimeConfig `protobuf:"bytes,1,rep,name=runtimes,proto3" json:"runtimes,omitempty"`
+}
 
 func (x *ConfigMap) Reset() {
 	*x = ConfigMap{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[11]
+		mi := &file_command_command_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigMap) ProtoMessage() {}
+
+func (x *ConfigMap) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigMap.ProtoReflect.Descriptor instead.
+func (*ConfigMap) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ConfigMap) GetRuntimes() []*RuntimeConfig {
+	if x != nil {
+		return x.Runtimes
+	}
+	return nil
+}
+
+// TODO: remove ConfigResp.
+type ConfigResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VersionId string    `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Configs   []*Config `protobuf:"bytes,1,rep,name=configs,proto3" json:"configs,omitempty"`
+}
+
+func (x *ConfigResp) Reset() {
+	*x = ConfigResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigResp) ProtoMessage() {}
+
+func (x *ConfigResp) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigResp.ProtoReflect.Descriptor instead.
+func (*ConfigResp) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConfigResp) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *ConfigResp) GetConfigs() []*Config {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+// InventoryFilter is a filter of configs in inventory.
+// Empty field matches any.
+type InventoryFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// selector name. e.g. "clang".
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// selector version. e.g. "13.0.0".
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// selector target. it will be normalized. e.g. "x86_64-linux".
+	Target string `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	// selector binary_hash.
+	BinaryHash string `protobuf:"bytes,4,opt,name=binary_hash,json=binaryHash,proto3" json:"binary_hash,omitempty"`
+	// config should have all dimensions.
+	Dimensions []string `protobuf:"bytes,5,rep,name=dimensions,proto3" json:"dimensions,omitempty"`
+}
+
+func (x *InventoryFilter) Reset() {
+	*x = InventoryFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InventoryFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryFilter) ProtoMessage() {}
+
+func (x *InventoryFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryFilter.ProtoReflect.Descriptor instead.
+func (*InventoryFilter) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *InventoryFilter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InventoryFilter) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *InventoryFilter) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *InventoryFilter) GetBinaryHash() string {
+	if x != nil {
+		return x.BinaryHash
+	}
+	return ""
+}
+
+func (x *InventoryFilter) GetDimensions() []string {
+	if x != nil {
+		return x.Dimensions
+	}
+	return nil
+}
+
+// InventoryResp is configs in inventory.
+type InventoryResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VersionId string `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	// configs of cmd_server, sorted by target addr and selector.
+	// configs of candidate versions in staged rollout have
+	// rollout.candidate=true.
+	Configs []*Config `protobuf:"bytes,2,rep,name=configs,proto3" json:"configs,omitempty"`
+	// configs for arbitrary toolchain support.
+	// selector in filter is not applied to these configs.
+	PlatformConfigs []*Config `protobuf:"bytes,3,rep,name=platform_configs,json=platformConfigs,proto3" json:"platform_configs,omitempty"`
+	// usages of toolchains in configs.
+	Usages []*ToolchainUsage `protobuf:"bytes,4,rep,name=usages,proto3" json:"usages,omitempty"`
+	// toolchains requested but not found in inventory.
+	Misses []*ToolchainMiss `protobuf:"bytes,5,rep,name=misses,proto3" json:"misses,omitempty"`
+}
+
+func (x *InventoryResp) Reset() {
+	*x = InventoryResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InventoryResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryResp) ProtoMessage() {}
+
+func (x *InventoryResp) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryResp.ProtoReflect.Descriptor instead.
+func (*InventoryResp) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *InventoryResp) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *InventoryResp) GetConfigs() []*Config {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+func (x *InventoryResp) GetPlatformConfigs() []*Config {
+	if x != nil {
+		return x.PlatformConfigs
+	}
+	return nil
+}
+
+func (x *InventoryResp) GetUsages() []*ToolchainUsage {
+	if x != nil {
+		return x.Usages
+	}
+	return nil
+}
+
+func (x *InventoryResp) GetMisses() []*ToolchainMiss {
+	if x != nil {
+		return x.Misses
+	}
+	return nil
+}
+
+// ToolchainUsage is usage of a toolchain on a cmd_server address.
+type ToolchainUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// normalized selector.
+	Selector *Selector `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	Addr     string    `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	// last time the toolchain was picked. unset if not picked.
+	LastPicked *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=last_picked,json=lastPicked,proto3" json:"last_picked,omitempty"`
+	// number of picks in the last hour, day and week.
+	Picks_1H int64 `protobuf:"varint,4,opt,name=picks_1h,json=picks1h,proto3" json:"picks_1h,omitempty"`
+	Picks_1D int64 `protobuf:"varint,5,opt,name=picks_1d,json=picks1d,proto3" json:"picks_1d,omitempty"`
+	Picks_7D int64 `protobuf:"varint,6,opt,name=picks_7d,json=picks7d,proto3" json:"picks_7d,omitempty"`
+}
+
+func (x *ToolchainUsage) Reset() {
+	*x = ToolchainUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolchainUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolchainUsage) ProtoMessage() {}
+
+func (x *ToolchainUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolchainUsage.ProtoReflect.Descriptor instead.
+func (*ToolchainUsage) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ToolchainUsage) GetSelector() *Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *ToolchainUsage) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *ToolchainUsage) GetLastPicked() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastPicked
+	}
+	return nil
+}
+
+func (x *ToolchainUsage) GetPicks_1H() int64 {
+	if x != nil {
+		return x.Picks_1H
+	}
+	return 0
+}
+
+func (x *ToolchainUsage) GetPicks_1D() int64 {
+	if x != nil {
+		return x.Picks_1D
+	}
+	return 0
+}
+
+func (x *ToolchainUsage) GetPicks_7D() int64 {
+	if x != nil {
+		return x.Picks_7D
+	}
+	return 0
+}
+
+// ToolchainMiss is a toolchain requested but not found.
+type ToolchainMiss struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// normalized selector.
+	// for subprogram, only name and binary_hash are set.
+	Selector   *Selector              `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	LastMissed *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_missed,json=lastMissed,proto3" json:"last_missed,omitempty"`
+	// number of misses in the last hour, day and week.
+	Misses_1H int64 `protobuf:"varint,3,opt,name=misses_1h,json=misses1h,proto3" json:"misses_1h,omitempty"`
+	Misses_1D int64 `protobuf:"varint,4,opt,name=misses_1d,json=misses1d,proto3" json:"misses_1d,omitempty"`
+	Misses_7D int64 `protobuf:"varint,5,opt,name=misses_7d,json=misses7d,proto3" json:"misses_7d,omitempty"`
+}
+
+func (x *ToolchainMiss) Reset() {
+	*x = ToolchainMiss{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolchainMiss) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolchainMiss) ProtoMessage() {}
+
+func (x *ToolchainMiss) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolchainMiss.ProtoReflect.Descriptor instead.
+func (*ToolchainMiss) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ToolchainMiss) GetSelector() *Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *ToolchainMiss) GetLastMissed() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastMissed
+	}
+	return nil
+}
+
+func (x *ToolchainMiss) GetMisses_1H() int64 {
+	if x != nil {
+		return x.Misses_1H
+	}
+	return 0
+}
+
+func (x *ToolchainMiss) GetMisses_1D() int64 {
+	if x != nil {
+		return x.Misses_1D
+	}
+	return 0
+}
+
+func (x *ToolchainMiss) GetMisses_7D() int64 {
+	if x != nil {
+		return x.Misses_7D
+	}
+	return 0
+}
+
+// ToolchainReport is a report of toolchain usage.
+type ToolchainReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VersionId string `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	// usage is tracked since this time (i.e. exec_server start).
+	TrackingSince *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=tracking_since,json=trackingSince,proto3" json:"tracking_since,omitempty"`
+	// toolchains in inventory not picked in the period.
+	Unused []*ToolchainUsage `protobuf:"bytes,3,rep,name=unused,proto3" json:"unused,omitempty"`
+	// toolchains requested but not found in the last week,
+	// sorted by number of misses.
+	Missing []*ToolchainMiss `protobuf:"bytes,4,rep,name=missing,proto3" json:"missing,omitempty"`
+}
+
+func (x *ToolchainReport) Reset() {
+	*x = ToolchainReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ToolchainReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolchainReport) ProtoMessage() {}
+
+func (x *ToolchainReport) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolchainReport.ProtoReflect.Descriptor instead.
+func (*ToolchainReport) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ToolchainReport) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *ToolchainReport) GetTrackingSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TrackingSince
+	}
+	return nil
+}
+
+func (x *ToolchainReport) GetUnused() []*ToolchainUsage {
+	if x != nil {
+		return x.Unused
+	}
+	return nil
+}
+
+func (x *ToolchainReport) GetMissing() []*ToolchainMiss {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+// FallbackPolicy is a policy to use a command that doesn't exactly match
+// with requested command. It is never applied to requests in hermetic mode.
+// Substituted command is reported in ExecResult.command_substitution.
+type FallbackPolicy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// if true, a command with the same name, version and target but
+	// different binary_hash can be used.
+	AllowBinaryHashMismatch bool `protobuf:"varint,1,opt,name=allow_binary_hash_mismatch,json=allowBinaryHashMismatch,proto3" json:"allow_binary_hash_mismatch,omitempty"`
+	// commands with the compatible versions can be used, if the same name
+	// and target. allow_binary_hash_mismatch takes precedence.
+	CompatibleVersions []*FallbackPolicy_CompatibleVersion `protobuf:"bytes,2,rep,name=compatible_versions,json=compatibleVersions,proto3" json:"compatible_versions,omitempty"`
+}
+
+func (x *FallbackPolicy) Reset() {
+	*x = FallbackPolicy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FallbackPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FallbackPolicy) ProtoMessage() {}
+
+func (x *FallbackPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FallbackPolicy.ProtoReflect.Descriptor instead.
+func (*FallbackPolicy) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FallbackPolicy) GetAllowBinaryHashMismatch() bool {
+	if x != nil {
+		return x.AllowBinaryHashMismatch
+	}
+	return false
+}
+
+func (x *FallbackPolicy) GetCompatibleVersions() []*FallbackPolicy_CompatibleVersion {
+	if x != nil {
+		return x.CompatibleVersions
+	}
+	return nil
+}
+
+// ACLRule is a rule to allow or deny requests.
+// All specified conditions must match for the rule to match.
+// Empty condition matches any requests.
+// NEXT ID TO USE: 11
+type ACLRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Action ACLRule_Action `protobuf:"varint,1,opt,name=action,proto3,enum=command.ACLRule_Action" json:"action,omitempty"`
+	// enduser groups.
+	Groups []string `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`
+	// enduser emails. e.g. "someone@example.com".
+	Emails []string `protobuf:"bytes,3,rep,name=emails,proto3" json:"emails,omitempty"`
+	// domains of enduser email. e.g. "example.com".
+	EmailDomains []string `protobuf:"bytes,4,rep,name=email_domains,json=emailDomains,proto3" json:"email_domains,omitempty"`
+	// prefixes of RequesterInfo.build_id.
+	BuildIdPrefixes []string `protobuf:"bytes,5,rep,name=build_id_prefixes,json=buildIdPrefixes,proto3" json:"build_id_prefixes,omitempty"`
+	// path type of RequesterInfo.path_style.
+	PathTypes []CmdDescriptor_PathType `protobuf:"varint,6,rep,packed,name=path_types,json=pathTypes,proto3,enum=command.CmdDescriptor_PathType" json:"path_types,omitempty"`
+	// minimum goma client version, i.e. build time in
+	// RequesterInfo.goma_revision ("<hash>@<unix time>").
+	MinGomaRevisionTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=min_goma_revision_time,json=minGomaRevisionTime,proto3" json:"min_goma_revision_time,omitempty"`
+	// goma client revision hash prefixes in RequesterInfo.goma_revision.
+	GomaRevisionPrefixes []string `protobuf:"bytes,8,rep,name=goma_revision_prefixes,json=gomaRevisionPrefixes,proto3" json:"goma_revision_prefixes,omitempty"`
+	// time window the rule is effective. e.g. DENY rule with not_after
+	// for toolchains under embargo.
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+}
+
+func (x *ACLRule) Reset() {
+	*x = ACLRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ConfigMap) String() string {
+func (x *ACLRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConfigMap) ProtoMessage() {}
+func (*ACLRule) ProtoMessage() {}
 
-func (x *ConfigMap) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[11]
+func (x *ACLRule) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -982,70 +2029,77 @@ func (x *ConfigMap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConfigMap.ProtoReflect.Descriptor instead.
-func (*ConfigMap) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ACLRule.ProtoReflect.Descriptor instead.
+func (*ACLRule) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ConfigMap) GetRuntimes() []*RuntimeConfig {
+func (x *ACLRule) GetAction() ACLRule_Action {
 	if x != nil {
-		return x.Runtimes
+		return x.Action
 	}
-	return nil
+	return ACLRule_UNSPECIFIED_ACTION
 }
 
-// TODO: remove ConfigResp.
-type ConfigResp struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *ACLRule) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
 
-	VersionId string    `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
-	Configs   []*Config `protobuf:"bytes,1,rep,name=configs,proto3" json:"configs,omitempty"`
+func (x *ACLRule) GetEmails() []string {
+	if x != nil {
+		return x.Emails
+	}
+	return nil
 }
 
-func (x *ConfigResp) Reset() {
-	*x = ConfigResp{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[12]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *ACLRule) GetEmailDomains() []string {
+	if x != nil {
+		return x.EmailDomains
 	}
+	return nil
 }
 
-func (x *ConfigResp) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ACLRule) GetBuildIdPrefixes() []string {
+	if x != nil {
+		return x.BuildIdPrefixes
+	}
+	return nil
 }
 
-func (*ConfigResp) ProtoMessage() {}
+func (x *ACLRule) GetPathTypes() []CmdDescriptor_PathType {
+	if x != nil {
+		return x.PathTypes
+	}
+	return nil
+}
 
-func (x *ConfigResp) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[12]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *ACLRule) GetMinGomaRevisionTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MinGomaRevisionTime
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use ConfigResp.ProtoReflect.Descriptor instead.
-func (*ConfigResp) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{12}
+func (x *ACLRule) GetGomaRevisionPrefixes() []string {
+	if x != nil {
+		return x.GomaRevisionPrefixes
+	}
+	return nil
 }
 
-func (x *ConfigResp) GetVersionId() string {
+func (x *ACLRule) GetNotBefore() *timestamppb.Timestamp {
 	if x != nil {
-		return x.VersionId
+		return x.NotBefore
 	}
-	return ""
+	return nil
 }
 
-func (x *ConfigResp) GetConfigs() []*Config {
+func (x *ACLRule) GetNotAfter() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Configs
+		return x.NotAfter
 	}
 	return nil
 }
@@ -1080,7 +2134,7 @@ type CmdDescriptor_Setup struct {
 func (x *CmdDescriptor_Setup) Reset() {
 	*x = CmdDescriptor_Setup{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[13]
+		mi := &file_command_command_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1093,7 +2147,7 @@ func (x *CmdDescriptor_Setup) String() string {
 func (*CmdDescriptor_Setup) ProtoMessage() {}
 
 func (x *CmdDescriptor_Setup) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[13]
+	mi := &file_command_command_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1152,7 +2206,7 @@ type CmdDescriptor_Cross struct {
 func (x *CmdDescriptor_Cross) Reset() {
 	*x = CmdDescriptor_Cross{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[14]
+		mi := &file_command_command_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1165,7 +2219,7 @@ func (x *CmdDescriptor_Cross) String() string {
 func (*CmdDescriptor_Cross) ProtoMessage() {}
 
 func (x *CmdDescriptor_Cross) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[14]
+	mi := &file_command_command_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1204,7 +2258,7 @@ type CmdDescriptor_EmulationOpts struct {
 func (x *CmdDescriptor_EmulationOpts) Reset() {
 	*x = CmdDescriptor_EmulationOpts{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[15]
+		mi := &file_command_command_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1217,7 +2271,7 @@ func (x *CmdDescriptor_EmulationOpts) String() string {
 func (*CmdDescriptor_EmulationOpts) ProtoMessage() {}
 
 func (x *CmdDescriptor_EmulationOpts) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[15]
+	mi := &file_command_command_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1254,7 +2308,7 @@ type RemoteexecPlatform_Property struct {
 func (x *RemoteexecPlatform_Property) Reset() {
 	*x = RemoteexecPlatform_Property{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[16]
+		mi := &file_command_command_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1267,7 +2321,7 @@ func (x *RemoteexecPlatform_Property) String() string {
 func (*RemoteexecPlatform_Property) ProtoMessage() {}
 
 func (x *RemoteexecPlatform_Property) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[16]
+	mi := &file_command_command_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1309,7 +2363,7 @@ type Platform_Property struct {
 func (x *Platform_Property) Reset() {
 	*x = Platform_Property{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_command_command_proto_msgTypes[17]
+		mi := &file_command_command_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1322,7 +2376,7 @@ func (x *Platform_Property) String() string {
 func (*Platform_Property) ProtoMessage() {}
 
 func (x *Platform_Property) ProtoReflect() protoreflect.Message {
-	mi := &file_command_command_proto_msgTypes[17]
+	mi := &file_command_command_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1335,7 +2389,7 @@ func (x *Platform_Property) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Platform_Property.ProtoReflect.Descriptor instead.
 func (*Platform_Property) Descriptor() ([]byte, []int) {
-	return file_command_command_proto_rawDescGZIP(), []int{8, 0}
+	return file_command_command_proto_rawDescGZIP(), []int{10, 0}
 }
 
 func (x *Platform_Property) GetName() string {
@@ -1352,6 +2406,75 @@ func (x *Platform_Property) GetValue() string {
 	return ""
 }
 
+// CompatibleVersion designates a version of command that can be used
+// for requested version.
+type FallbackPolicy_CompatibleVersion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// command name. e.g. "clang". empty matches any name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// requested version.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// version of command in the runtime, that can be used
+	// for requested version.
+	CompatibleVersion string `protobuf:"bytes,3,opt,name=compatible_version,json=compatibleVersion,proto3" json:"compatible_version,omitempty"`
+}
+
+func (x *FallbackPolicy_CompatibleVersion) Reset() {
+	*x = FallbackPolicy_CompatibleVersion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_command_command_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FallbackPolicy_CompatibleVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FallbackPolicy_CompatibleVersion) ProtoMessage() {}
+
+func (x *FallbackPolicy_CompatibleVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_command_command_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FallbackPolicy_CompatibleVersion.ProtoReflect.Descriptor instead.
+func (*FallbackPolicy_CompatibleVersion) Descriptor() ([]byte, []int) {
+	return file_command_command_proto_rawDescGZIP(), []int{21, 0}
+}
+
+func (x *FallbackPolicy_CompatibleVersion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FallbackPolicy_CompatibleVersion) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *FallbackPolicy_CompatibleVersion) GetCompatibleVersion() string {
+	if x != nil {
+		return x.CompatibleVersion
+	}
+	return ""
+}
+
 var File_command_command_proto protoreflect.FileDescriptor
 
 var file_command_command_proto_rawDesc = []byte{
@@ -1453,8 +2576,8 @@ var file_command_command_proto_rawDesc = []byte{
 	0x61, 0x73, 0x4e, 0x73, 0x6a, 0x61, 0x69, 0x6c, 0x1a, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70,
 	0x65, 0x72, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xbe,
-	0x02, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x27, 0x0a, 0x06, 0x74, 0x61, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x93,
+	0x04, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x27, 0x0a, 0x06, 0x74, 0x61, 0x72,
 	0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
 	0x61, 0x6e, 0x64, 0x2e, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67,
 	0x65, 0x74, 0x12, 0x31, 0x0a, 0x0a, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x69, 0x6e, 0x66, 0x6f,
@@ -1471,68 +2594,252 @@ var file_command_command_proto_rawDesc = []byte{
 	0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x65, 0x78, 0x65, 0x63, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f,
 	0x72, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
 	0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0c, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61,
-	0x63, 0x6c, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x22,
-	0x59, 0x0a, 0x03, 0x41, 0x43, 0x4c, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65,
-	0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d,
-	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x2b, 0x0a,
-	0x11, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75,
-	0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c,
-	0x6f, 0x77, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x7c, 0x0a, 0x08, 0x50, 0x6c,
-	0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x3a, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72,
-	0x74, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x63, 0x6f, 0x6d,
-	0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x50, 0x72,
-	0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69,
-	0x65, 0x73, 0x1a, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x12, 0x12,
+	0x6e, 0x73, 0x12, 0x33, 0x0a, 0x15, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f,
+	0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x14, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x41,
+	0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x37, 0x0a, 0x0c, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x73, 0x52, 0x0b, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73,
+	0x12, 0x30, 0x0a, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x46,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x08, 0x66,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x52, 0x05, 0x69,
+	0x6d, 0x61, 0x67, 0x65, 0x22, 0x89, 0x01, 0x0a, 0x0d, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x73,
+	0x65, 0x71, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x12, 0x2e, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x22, 0x81, 0x01, 0x0a, 0x03, 0x41, 0x43, 0x4c, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12,
+	0x2b, 0x0a, 0x11, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x64, 0x69, 0x73, 0x61,
+	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x05,
+	0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x72,
+	0x75, 0x6c, 0x65, 0x73, 0x22, 0x97, 0x01, 0x0a, 0x0b, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6d, 0x61,
+	0x78, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d,
+	0x61, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x6d, 0x61, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f,
+	0x66, 0x69, 0x6c, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0c, 0x6d, 0x61, 0x78, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x7c,
+	0x0a, 0x08, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x3a, 0x0a, 0x0a, 0x70, 0x72,
+	0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
+	0x6d, 0x2e, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x79, 0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70,
+	0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x1a, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72,
+	0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xce, 0x04, 0x0a,
+	0x0d, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12,
 	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xae, 0x03, 0x0a, 0x0d, 0x52, 0x75, 0x6e,
-	0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21,
-	0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x41, 0x64, 0x64,
-	0x72, 0x12, 0x56, 0x0a, 0x17, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x72, 0x75,
-	0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x50, 0x6c, 0x61,
-	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x52, 0x15, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x75, 0x6e, 0x74,
-	0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2d, 0x0a, 0x08, 0x70, 0x6c, 0x61,
-	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x08,
-	0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x2b, 0x0a, 0x11, 0x61, 0x6c, 0x6c, 0x6f,
-	0x77, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x73, 0x18, 0x03, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50, 0x72, 0x65, 0x62,
-	0x75, 0x69, 0x6c, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f,
-	0x77, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x73, 0x18, 0x04, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x13, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x50,
-	0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x73, 0x12, 0x42, 0x0a, 0x13, 0x64, 0x69, 0x73, 0x61,
-	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18,
-	0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e,
-	0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x12, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c,
-	0x6f, 0x77, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x03,
-	0x61, 0x63, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
-	0x61, 0x6e, 0x64, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x4a, 0x04, 0x08, 0x07,
-	0x10, 0x08, 0x52, 0x15, 0x72, 0x62, 0x65, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
-	0x5f, 0x62, 0x61, 0x73, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x56, 0x0a, 0x15, 0x50, 0x6c, 0x61,
+	0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x41, 0x64, 0x64, 0x72, 0x12, 0x56, 0x0a, 0x17, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72,
+	0x6d, 0x5f, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x15, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d,
+	0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2d, 0x0a,
+	0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x50, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x2b, 0x0a, 0x11,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64,
+	0x50, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x64, 0x69, 0x73,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x50, 0x72, 0x65, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x73, 0x12, 0x42, 0x0a, 0x13,
+	0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x12, 0x64, 0x69,
+	0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73,
+	0x12, 0x1e, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x03, 0x61, 0x63, 0x6c,
+	0x12, 0x37, 0x0a, 0x0c, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x2e, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x0b, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x07, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x75, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x66,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b,
+	0x4a, 0x04, 0x08, 0x07, 0x10, 0x08, 0x52, 0x15, 0x72, 0x62, 0x65, 0x5f, 0x69, 0x6e, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x5f, 0x62, 0x61, 0x73, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x88, 0x02,
+	0x0a, 0x0d, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x2b, 0x0a, 0x11, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x70, 0x65, 0x72,
+	0x63, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x63, 0x61, 0x6e, 0x64,
+	0x69, 0x64, 0x61, 0x74, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x08,
+	0x68, 0x61, 0x73, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1e,
+	0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x52, 0x07,
+	0x68, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d,
+	0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x35, 0x0a, 0x17, 0x6d, 0x61,
+	0x78, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x6e, 0x63,
+	0x72, 0x65, 0x61, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x14, 0x6d, 0x61, 0x78,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x63, 0x72, 0x65, 0x61, 0x73,
+	0x65, 0x22, 0x35, 0x0a, 0x07, 0x48, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x12, 0x08, 0x0a, 0x04,
+	0x55, 0x53, 0x45, 0x52, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x43, 0x4f, 0x4d, 0x50, 0x49, 0x4c,
+	0x45, 0x52, 0x5f, 0x50, 0x52, 0x4f, 0x58, 0x59, 0x5f, 0x49, 0x44, 0x10, 0x01, 0x12, 0x09, 0x0a,
+	0x05, 0x47, 0x52, 0x4f, 0x55, 0x50, 0x10, 0x02, 0x22, 0x8b, 0x01, 0x0a, 0x15, 0x50, 0x6c, 0x61,
 	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66,
 	0x69, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
 	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x6e, 0x73, 0x6a, 0x61, 0x69, 0x6c,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61, 0x73, 0x4e, 0x73, 0x6a, 0x61, 0x69,
-	0x6c, 0x22, 0x3f, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x12, 0x32,
-	0x0a, 0x08, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x52, 0x75, 0x6e, 0x74, 0x69,
-	0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x22, 0x56, 0x0a, 0x0a, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x73, 0x70,
-	0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
-	0x29, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x6e, 0x73, 0x12, 0x33, 0x0a, 0x15, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x5f,
+	0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x14, 0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x61, 0x73, 0x5f, 0x6e,
+	0x73, 0x6a, 0x61, 0x69, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61, 0x73,
+	0x4e, 0x73, 0x6a, 0x61, 0x69, 0x6c, 0x22, 0x3f, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x4d, 0x61, 0x70, 0x12, 0x32, 0x0a, 0x08, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e,
+	0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x72,
+	0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x22, 0x56, 0x0a, 0x0a, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x52, 0x65, 0x73, 0x70, 0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x22,
+	0x98, 0x01, 0x0a, 0x0f, 0x49, 0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x69, 0x6e,
+	0x61, 0x72, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x48, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69,
+	0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a,
+	0x64, 0x69, 0x6d, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xf6, 0x01, 0x0a, 0x0d, 0x49,
+	0x6e, 0x76, 0x65, 0x6e, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x12, 0x1d, 0x0a, 0x0a,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x07, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x07, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x12, 0x3a, 0x0a, 0x10, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f,
+	0x72, 0x6d, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
 	0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x73, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x6f,
-	0x2e, 0x63, 0x68, 0x72, 0x6f, 0x6d, 0x69, 0x75, 0x6d, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f,
-	0x6d, 0x61, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f,
-	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x67, 0x52, 0x0f, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x73, 0x12, 0x2f, 0x0a, 0x06, 0x75, 0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54, 0x6f, 0x6f,
+	0x6c, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x06, 0x75, 0x73, 0x61,
+	0x67, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54, 0x6f,
+	0x6f, 0x6c, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x06, 0x6d, 0x69, 0x73,
+	0x73, 0x65, 0x73, 0x22, 0xe1, 0x01, 0x0a, 0x0e, 0x54, 0x6f, 0x6f, 0x6c, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x08, 0x73, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x08, 0x73, 0x65, 0x6c,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x3b, 0x0a, 0x0b, 0x6c, 0x61, 0x73,
+	0x74, 0x5f, 0x70, 0x69, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x6c, 0x61, 0x73, 0x74,
+	0x50, 0x69, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x5f,
+	0x31, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x31,
+	0x68, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x5f, 0x31, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x07, 0x70, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x64, 0x12, 0x19, 0x0a, 0x08,
+	0x70, 0x69, 0x63, 0x6b, 0x73, 0x5f, 0x37, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x70, 0x69, 0x63, 0x6b, 0x73, 0x37, 0x64, 0x22, 0xd2, 0x01, 0x0a, 0x0d, 0x54, 0x6f, 0x6f, 0x6c,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x4d, 0x69, 0x73, 0x73, 0x12, 0x2d, 0x0a, 0x08, 0x73, 0x65, 0x6c,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x08,
+	0x73, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x3b, 0x0a, 0x0b, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x4d,
+	0x69, 0x73, 0x73, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x5f,
+	0x31, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73,
+	0x31, 0x68, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x5f, 0x31, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x31, 0x64, 0x12,
+	0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x5f, 0x37, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x37, 0x64, 0x22, 0xd6, 0x01, 0x0a,
+	0x0f, 0x54, 0x6f, 0x6f, 0x6c, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x41, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x69, 0x6e, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x53, 0x69, 0x6e,
+	0x63, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x75, 0x6e, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54, 0x6f, 0x6f,
+	0x6c, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x06, 0x75, 0x6e, 0x75,
+	0x73, 0x65, 0x64, 0x12, 0x30, 0x0a, 0x07, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x54,
+	0x6f, 0x6f, 0x6c, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x4d, 0x69, 0x73, 0x73, 0x52, 0x07, 0x6d, 0x69,
+	0x73, 0x73, 0x69, 0x6e, 0x67, 0x22, 0x9b, 0x02, 0x0a, 0x0e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61,
+	0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x3b, 0x0a, 0x1a, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x5f, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6d, 0x69,
+	0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x17, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x48, 0x61, 0x73, 0x68, 0x4d, 0x69, 0x73,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x5a, 0x0a, 0x13, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69,
+	0x62, 0x6c, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x29, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x46, 0x61, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x12, 0x63,
+	0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x1a, 0x70, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x0a, 0x12, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62,
+	0x6c, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x11, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x22, 0xad, 0x04, 0x0a, 0x07, 0x41, 0x43, 0x4c, 0x52, 0x75, 0x6c, 0x65, 0x12,
+	0x2f, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x17, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x41, 0x43, 0x4c, 0x52, 0x75, 0x6c,
+	0x65, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x6d, 0x61, 0x69,
+	0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x44, 0x6f,
+	0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x69,
+	0x64, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0f, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x49, 0x64, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65,
+	0x73, 0x12, 0x3e, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e,
+	0x43, 0x6d, 0x64, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x50, 0x61,
+	0x74, 0x68, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x70, 0x61, 0x74, 0x68, 0x54, 0x79, 0x70, 0x65,
+	0x73, 0x12, 0x4f, 0x0a, 0x16, 0x6d, 0x69, 0x6e, 0x5f, 0x67, 0x6f, 0x6d, 0x61, 0x5f, 0x72, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x13, 0x6d,
+	0x69, 0x6e, 0x47, 0x6f, 0x6d, 0x61, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x34, 0x0a, 0x16, 0x67, 0x6f, 0x6d, 0x61, 0x5f, 0x72, 0x65, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x14, 0x67, 0x6f, 0x6d, 0x61, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x6e, 0x6f, 0x74, 0x5f,
+	0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x42, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0x35, 0x0a, 0x06,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x12, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x00, 0x12, 0x09,
+	0x0a, 0x05, 0x41, 0x4c, 0x4c, 0x4f, 0x57, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x45, 0x4e,
+	0x59, 0x10, 0x02, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x6f, 0x2e, 0x63, 0x68, 0x72, 0x6f, 0x6d, 0x69,
+	0x75, 0x6d, 0x2e, 0x6f, 0x72, 0x67, 0x2f, 0x67, 0x6f, 0x6d, 0x61, 0x2f, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1547,59 +2854,98 @@ func file_command_command_proto_rawDescGZIP() []byte {
 	return file_command_command_proto_rawDescData
 }
 
-var file_command_command_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_command_command_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_command_command_proto_goTypes = []interface{}{
-	(CmdDescriptor_PathType)(0),         // 0: command.CmdDescriptor.PathType
-	(*Selector)(nil),                    // 1: command.Selector
-	(*FileSpec)(nil),                    // 2: command.FileSpec
-	(*Target)(nil),                      // 3: command.Target
-	(*BuildInfo)(nil),                   // 4: command.BuildInfo
-	(*CmdDescriptor)(nil),               // 5: command.CmdDescriptor
-	(*RemoteexecPlatform)(nil),          // 6: command.RemoteexecPlatform
-	(*Config)(nil),                      // 7: command.Config
-	(*ACL)(nil),                         // 8: command.ACL
-	(*Platform)(nil),                    // 9: command.Platform
-	(*RuntimeConfig)(nil),               // 10: command.RuntimeConfig
-	(*PlatformRuntimeConfig)(nil),       // 11: command.PlatformRuntimeConfig
-	(*ConfigMap)(nil),                   // 12: command.ConfigMap
-	(*ConfigResp)(nil),                  // 13: command.ConfigResp
-	(*CmdDescriptor_Setup)(nil),         // 14: command.CmdDescriptor.Setup
-	(*CmdDescriptor_Cross)(nil),         // 15: command.CmdDescriptor.Cross
-	(*CmdDescriptor_EmulationOpts)(nil), // 16: command.CmdDescriptor.EmulationOpts
-	(*RemoteexecPlatform_Property)(nil), // 17: command.RemoteexecPlatform.Property
-	(*Platform_Property)(nil),           // 18: command.Platform.Property
-	(*api.FileBlob)(nil),                // 19: devtools_goma.FileBlob
-	(*timestamppb.Timestamp)(nil),       // 20: google.protobuf.Timestamp
+	(CmdDescriptor_PathType)(0),              // 0: command.CmdDescriptor.PathType
+	(RolloutConfig_HashKey)(0),               // 1: command.RolloutConfig.HashKey
+	(ACLRule_Action)(0),                      // 2: command.ACLRule.Action
+	(*Selector)(nil),                         // 3: command.Selector
+	(*FileSpec)(nil),                         // 4: command.FileSpec
+	(*Target)(nil),                           // 5: command.Target
+	(*BuildInfo)(nil),                        // 6: command.BuildInfo
+	(*CmdDescriptor)(nil),                    // 7: command.CmdDescriptor
+	(*RemoteexecPlatform)(nil),               // 8: command.RemoteexecPlatform
+	(*Config)(nil),                           // 9: command.Config
+	(*ConfigRollout)(nil),                    // 10: command.ConfigRollout
+	(*ACL)(nil),                              // 11: command.ACL
+	(*InputLimits)(nil),                      // 12: command.InputLimits
+	(*Platform)(nil),                         // 13: command.Platform
+	(*RuntimeConfig)(nil),                    // 14: command.RuntimeConfig
+	(*RolloutConfig)(nil),                    // 15: command.RolloutConfig
+	(*PlatformRuntimeConfig)(nil),            // 16: command.PlatformRuntimeConfig
+	(*ConfigMap)(nil),                        // 17: command.ConfigMap
+	(*ConfigResp)(nil),                       // 18: command.ConfigResp
+	(*InventoryFilter)(nil),                  // 19: command.InventoryFilter
+	(*InventoryResp)(nil),                    // 20: command.InventoryResp
+	(*ToolchainUsage)(nil),                   // 21: command.ToolchainUsage
+	(*ToolchainMiss)(nil),                    // 22: command.ToolchainMiss
+	(*ToolchainReport)(nil),                  // 23: command.ToolchainReport
+	(*FallbackPolicy)(nil),                   // 24: command.FallbackPolicy
+	(*ACLRule)(nil),                          // 25: command.ACLRule
+	(*CmdDescriptor_Setup)(nil),              // 26: command.CmdDescriptor.Setup
+	(*CmdDescriptor_Cross)(nil),              // 27: command.CmdDescriptor.Cross
+	(*CmdDescriptor_EmulationOpts)(nil),      // 28: command.CmdDescriptor.EmulationOpts
+	(*RemoteexecPlatform_Property)(nil),      // 29: command.RemoteexecPlatform.Property
+	(*Platform_Property)(nil),                // 30: command.Platform.Property
+	(*FallbackPolicy_CompatibleVersion)(nil), // 31: command.FallbackPolicy.CompatibleVersion
+	(*api.FileBlob)(nil),                     // 32: devtools_goma.FileBlob
+	(*timestamppb.Timestamp)(nil),            // 33: google.protobuf.Timestamp
 }
 var file_command_command_proto_depIdxs = []int32{
-	19, // 0: command.FileSpec.blob:type_name -> devtools_goma.FileBlob
-	20, // 1: command.BuildInfo.timestamp:type_name -> google.protobuf.Timestamp
-	1,  // 2: command.CmdDescriptor.selector:type_name -> command.Selector
-	14, // 3: command.CmdDescriptor.setup:type_name -> command.CmdDescriptor.Setup
-	15, // 4: command.CmdDescriptor.cross:type_name -> command.CmdDescriptor.Cross
-	16, // 5: command.CmdDescriptor.emulation_opts:type_name -> command.CmdDescriptor.EmulationOpts
-	17, // 6: command.RemoteexecPlatform.properties:type_name -> command.RemoteexecPlatform.Property
-	3,  // 7: command.Config.target:type_name -> command.Target
-	4,  // 8: command.Config.build_info:type_name -> command.BuildInfo
-	5,  // 9: command.Config.cmd_descriptor:type_name -> command.CmdDescriptor
-	6,  // 10: command.Config.remoteexec_platform:type_name -> command.RemoteexecPlatform
-	8,  // 11: command.Config.acl:type_name -> command.ACL
-	18, // 12: command.Platform.properties:type_name -> command.Platform.Property
-	11, // 13: command.RuntimeConfig.platform_runtime_config:type_name -> command.PlatformRuntimeConfig
-	9,  // 14: command.RuntimeConfig.platform:type_name -> command.Platform
-	1,  // 15: command.RuntimeConfig.disallowed_commands:type_name -> command.Selector
-	8,  // 16: command.RuntimeConfig.acl:type_name -> command.ACL
-	10, // 17: command.ConfigMap.runtimes:type_name -> command.RuntimeConfig
-	7,  // 18: command.ConfigResp.configs:type_name -> command.Config
-	2,  // 19: command.CmdDescriptor.Setup.cmd_file:type_name -> command.FileSpec
-	2,  // 20: command.CmdDescriptor.Setup.files:type_name -> command.FileSpec
-	0,  // 21: command.CmdDescriptor.Setup.path_type:type_name -> command.CmdDescriptor.PathType
-	22, // [22:22] is the sub-list for method output_type
-	22, // [22:22] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	32, // 0: command.FileSpec.blob:type_name -> devtools_goma.FileBlob
+	33, // 1: command.BuildInfo.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 2: command.CmdDescriptor.selector:type_name -> command.Selector
+	26, // 3: command.CmdDescriptor.setup:type_name -> command.CmdDescriptor.Setup
+	27, // 4: command.CmdDescriptor.cross:type_name -> command.CmdDescriptor.Cross
+	28, // 5: command.CmdDescriptor.emulation_opts:type_name -> command.CmdDescriptor.EmulationOpts
+	29, // 6: command.RemoteexecPlatform.properties:type_name -> command.RemoteexecPlatform.Property
+	5,  // 7: command.Config.target:type_name -> command.Target
+	6,  // 8: command.Config.build_info:type_name -> command.BuildInfo
+	7,  // 9: command.Config.cmd_descriptor:type_name -> command.CmdDescriptor
+	8,  // 10: command.Config.remoteexec_platform:type_name -> command.RemoteexecPlatform
+	11, // 11: command.Config.acl:type_name -> command.ACL
+	12, // 12: command.Config.input_limits:type_name -> command.InputLimits
+	10, // 13: command.Config.rollout:type_name -> command.ConfigRollout
+	24, // 14: command.Config.fallback:type_name -> command.FallbackPolicy
+	15, // 15: command.ConfigRollout.config:type_name -> command.RolloutConfig
+	25, // 16: command.ACL.rules:type_name -> command.ACLRule
+	30, // 17: command.Platform.properties:type_name -> command.Platform.Property
+	16, // 18: command.RuntimeConfig.platform_runtime_config:type_name -> command.PlatformRuntimeConfig
+	13, // 19: command.RuntimeConfig.platform:type_name -> command.Platform
+	3,  // 20: command.RuntimeConfig.disallowed_commands:type_name -> command.Selector
+	11, // 21: command.RuntimeConfig.acl:type_name -> command.ACL
+	12, // 22: command.RuntimeConfig.input_limits:type_name -> command.InputLimits
+	15, // 23: command.RuntimeConfig.rollout:type_name -> command.RolloutConfig
+	24, // 24: command.RuntimeConfig.fallback:type_name -> command.FallbackPolicy
+	1,  // 25: command.RolloutConfig.hash_key:type_name -> command.RolloutConfig.HashKey
+	14, // 26: command.ConfigMap.runtimes:type_name -> command.RuntimeConfig
+	9,  // 27: command.ConfigResp.configs:type_name -> command.Config
+	9,  // 28: command.InventoryResp.configs:type_name -> command.Config
+	9,  // 29: command.InventoryResp.platform_configs:type_name -> command.Config
+	21, // 30: command.InventoryResp.usages:type_name -> command.ToolchainUsage
+	22, // 31: command.InventoryResp.misses:type_name -> command.ToolchainMiss
+	3,  // 32: command.ToolchainUsage.selector:type_name -> command.Selector
+	33, // 33: command.ToolchainUsage.last_picked:type_name -> google.protobuf.Timestamp
+	3,  // 34: command.ToolchainMiss.selector:type_name -> command.Selector
+	33, // 35: command.ToolchainMiss.last_missed:type_name -> google.protobuf.Timestamp
+	33, // 36: command.ToolchainReport.tracking_since:type_name -> google.protobuf.Timestamp
+	21, // 37: command.ToolchainReport.unused:type_name -> command.ToolchainUsage
+	22, // 38: command.ToolchainReport.missing:type_name -> command.ToolchainMiss
+	31, // 39: command.FallbackPolicy.compatible_versions:type_name -> command.FallbackPolicy.CompatibleVersion
+	2,  // 40: command.ACLRule.action:type_name -> command.ACLRule.Action
+	0,  // 41: command.ACLRule.path_types:type_name -> command.CmdDescriptor.PathType
+	33, // 42: command.ACLRule.min_goma_revision_time:type_name -> google.protobuf.Timestamp
+	33, // 43: command.ACLRule.not_before:type_name -> google.protobuf.Timestamp
+	33, // 44: command.ACLRule.not_after:type_name -> google.protobuf.Timestamp
+	4,  // 45: command.CmdDescriptor.Setup.cmd_file:type_name -> command.FileSpec
+	4,  // 46: command.CmdDescriptor.Setup.files:type_name -> command.FileSpec
+	0,  // 47: command.CmdDescriptor.Setup.path_type:type_name -> command.CmdDescriptor.PathType
+	48, // [48:48] is the sub-list for method output_type
+	48, // [48:48] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
 }
 
 func init() { file_command_command_proto_init() }
@@ -1693,7 +3039,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ACL); i {
+			switch v := v.(*ConfigRollout); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1705,7 +3051,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Platform); i {
+			switch v := v.(*ACL); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1717,7 +3063,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RuntimeConfig); i {
+			switch v := v.(*InputLimits); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1729,7 +3075,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PlatformRuntimeConfig); i {
+			switch v := v.(*Platform); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1741,7 +3087,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ConfigMap); i {
+			switch v := v.(*RuntimeConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1753,7 +3099,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ConfigResp); i {
+			switch v := v.(*RolloutConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1765,7 +3111,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CmdDescriptor_Setup); i {
+			switch v := v.(*PlatformRuntimeConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1777,7 +3123,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CmdDescriptor_Cross); i {
+			switch v := v.(*ConfigMap); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1789,7 +3135,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CmdDescriptor_EmulationOpts); i {
+			switch v := v.(*ConfigResp); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1801,7 +3147,7 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RemoteexecPlatform_Property); i {
+			switch v := v.(*InventoryFilter); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1813,6 +3159,126 @@ func file_command_command_proto_init() {
 			}
 		}
 		file_command_command_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InventoryResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ToolchainUsage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ToolchainMiss); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ToolchainReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FallbackPolicy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ACLRule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CmdDescriptor_Setup); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CmdDescriptor_Cross); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CmdDescriptor_EmulationOpts); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoteexecPlatform_Property); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_command_command_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Platform_Property); i {
 			case 0:
 				return &v.state
@@ -1824,14 +3290,26 @@ func file_command_command_proto_init() {
 				return nil
 			}
 		}
+		file_command_command_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FallbackPolicy_CompatibleVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_command_command_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   18,
+			NumEnums:      3,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
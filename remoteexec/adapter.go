@@ -27,12 +27,14 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"go.chromium.org/goma/server/auth/enduser"
+	"go.chromium.org/goma/server/command/pathconv"
 	"go.chromium.org/goma/server/exec"
 	"go.chromium.org/goma/server/log"
 	gomapb "go.chromium.org/goma/server/proto/api"
 	fpb "go.chromium.org/goma/server/proto/file"
 	"go.chromium.org/goma/server/remoteexec/cas"
 	"go.chromium.org/goma/server/remoteexec/digest"
+	"go.chromium.org/goma/server/remoteexec/merkletree"
 	"go.chromium.org/goma/server/server"
 )
 
@@ -52,6 +54,7 @@ type SpanTimeout struct {
 	UploadBlobs  time.Duration
 	Execute      time.Duration
 	Response     time.Duration
+	Reproducer   time.Duration
 }
 
 // DefaultSpanTimeout is default timeout.
@@ -64,6 +67,7 @@ var DefaultSpanTimeout = SpanTimeout{
 	UploadBlobs:  60 * time.Second,
 	Execute:      0,
 	Response:     30 * time.Second,
+	Reproducer:   60 * time.Second,
 }
 
 // Adapter is an adapter from goma API to remoteexec API.
@@ -93,9 +97,32 @@ type Adapter struct {
 	// key: goma file hash.
 	DigestCache DigestCache
 
+	// MerkleTreeCache caches directory subtrees of command files
+	// in input tree. nil disables the cache.
+	MerkleTreeCache *merkletree.Cache
+
+	// PathConverterOptions is options of path converter selected by
+	// requester's path style. Client paths are converted to server
+	// paths to check input root. If WindowsMountRoot is empty,
+	// defaultWindowsMountRoot is used.
+	PathConverterOptions pathconv.Options
+
+	// InputLimits is default limits of inputs in a request.
+	// It is overridden by input limits in command config.
+	InputLimits InputLimits
+
 	// CmdStorage is a storage for command files.
 	CmdStorage CmdStorage
 
+	// CrashReproducers is a storage for reproducer bundles of
+	// compiler crashes in remote. nil disables capture.
+	CrashReproducers ReproducerStore
+
+	// ReproducerSema specifies concurrency to capture reproducer
+	// bundles in background. A reproducer is dropped if no slot is
+	// available. nil uses concurrency 1.
+	ReproducerSema chan struct{}
+
 	// Tool details put in request metadata.
 	ToolDetails *rpb.ToolDetails
 
@@ -386,6 +413,16 @@ func (f *Adapter) Exec(ctx context.Context, req *gomapb.ExecReq) (resp *gomapb.E
 		logger.Infof("fail fast in inventory lookup: %s", dur)
 		return resp, nil
 	}
+	defer func() {
+		if len(resp.GetMissingInput()) > 0 {
+			// client will retry with missing inputs.
+			return
+		}
+		// bad requests are client's fault, so count only
+		// server side failures.
+		failed := err != nil || r.crash != "" || (resp.GetError() != gomapb.ExecResp_BAD_REQUEST && len(resp.GetErrorMessage()) > 0)
+		f.Inventory.RecordResult(ctx, r.cmdConfig, failed)
+	}()
 
 	dur = espan.Do(ctx, "input tree", f.SpanTimeout.InputTree, func(ctx context.Context) {
 		resp = r.newInputTree(ctx)
@@ -438,6 +475,9 @@ func (f *Adapter) Exec(ctx context.Context, req *gomapb.ExecReq) (resp *gomapb.E
 	espan.Do(ctx, "response", f.SpanTimeout.Response, func(ctx context.Context) {
 		resp, err = r.newResp(ctx, eresp, cached)
 	})
+	if r.crash != "" {
+		r.captureReproducer(ctx)
+	}
 	if err != nil {
 		logger.Errorf("exec call: resp err=%v", err)
 	}
@@ -33,13 +33,15 @@ func fileSpecToEntry(ctx context.Context, fs *pb.FileSpec, cmdStorage CmdStorage
 		if fs.Symlink != "" {
 			// Symlink
 			return merkletree.Entry{
-				Name:   fs.Path,
-				Target: fs.Symlink,
+				Name:      fs.Path,
+				Target:    fs.Symlink,
+				Immutable: true,
 			}, nil
 		}
 		// dir
 		return merkletree.Entry{
-			Name: fs.Path,
+			Name:      fs.Path,
+			Immutable: true,
 		}, nil
 	}
 	d := &rpb.Digest{
@@ -54,6 +56,7 @@ func fileSpecToEntry(ctx context.Context, fs *pb.FileSpec, cmdStorage CmdStorage
 		Name:         fs.Path,
 		Data:         digest.New(src, d),
 		IsExecutable: fs.IsExecutable,
+		Immutable:    true,
 	}, nil
 }
 
@@ -48,6 +48,7 @@ func TestFileSpecToEntry(t *testing.T) {
 						Hash:      "7bf4c008d0321a9956279edd58fd2078569e9595fbfe5775c228836b36d71796",
 						SizeBytes: 1234,
 					}),
+				Immutable: true,
 			}, false,
 		}, {
 			&pb.FileSpec{
@@ -68,6 +69,7 @@ func TestFileSpecToEntry(t *testing.T) {
 						Hash:      "42151bf3845e7b44d0339964cdc19ce55427e7eee9e4bf0d306fe313ed8b5db8",
 						SizeBytes: 4567,
 					}),
+				Immutable: true,
 			}, false,
 		}, {
 			// Test IsExecutable=false.
@@ -89,6 +91,7 @@ func TestFileSpecToEntry(t *testing.T) {
 						Hash:      "7fc88a31bbededbe1f276c23a66797b21cf8d7f837e6580e70b2755a817a08c7",
 						SizeBytes: 1111,
 					}),
+				Immutable: true,
 			}, false,
 		}, {
 			// Invalid entry.
@@ -104,7 +107,8 @@ func TestFileSpecToEntry(t *testing.T) {
 				Path: "../../native_client/toolchain/linux_x86/pnacl_newlib/bin",
 			},
 			merkletree.Entry{
-				Name: "../../native_client/toolchain/linux_x86/pnacl_newlib/bin",
+				Name:      "../../native_client/toolchain/linux_x86/pnacl_newlib/bin",
+				Immutable: true,
 			}, false,
 		}, {
 			// Symlinks.
@@ -113,8 +117,9 @@ func TestFileSpecToEntry(t *testing.T) {
 				Symlink: "clang",
 			},
 			merkletree.Entry{
-				Name:   "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/clang++",
-				Target: "clang",
+				Name:      "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/clang++",
+				Target:    "clang",
+				Immutable: true,
 			}, false,
 		}, {
 			&pb.FileSpec{
@@ -122,8 +127,9 @@ func TestFileSpecToEntry(t *testing.T) {
 				Symlink: "libc++.so.1",
 			},
 			merkletree.Entry{
-				Name:   "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/../lib/libc++.so",
-				Target: "libc++.so.1",
+				Name:      "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/../lib/libc++.so",
+				Target:    "libc++.so.1",
+				Immutable: true,
 			}, false,
 		}, {
 			&pb.FileSpec{
@@ -131,8 +137,9 @@ func TestFileSpecToEntry(t *testing.T) {
 				Symlink: "libc++.so.1.0",
 			},
 			merkletree.Entry{
-				Name:   "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/clang++",
-				Target: "libc++.so.1.0",
+				Name:      "../../native_client/toolchain/linux_x86/pnacl_newlib/bin/clang++",
+				Target:    "libc++.so.1.0",
+				Immutable: true,
 			}, false,
 		},
 	}
@@ -55,7 +55,8 @@ var (
 
 // Cache caches file's digest data.
 type Cache struct {
-	c cachepb.CacheServiceClient
+	c    cachepb.CacheServiceClient
+	disk *DiskTier
 
 	mu  sync.Mutex
 	lru lru.Cache
@@ -71,6 +72,12 @@ func NewCache(c cachepb.CacheServiceClient, maxEntries int) *Cache {
 	return cache
 }
 
+// SetDiskTier sets disk tier of the cache.
+// It must be called before the cache is used.
+func (c *Cache) SetDiskTier(d *DiskTier) {
+	c.disk = d
+}
+
 var errNoCacheClient = errors.New("no cache client")
 
 func (c *Cache) cacheGet(ctx context.Context, key string) (*rpb.Digest, error) {
@@ -139,6 +146,20 @@ func (c *Cache) Get(ctx context.Context, key string, src Source) (Data, error) {
 	}
 	start := time.Now()
 	logger := log.FromContext(ctx)
+	if c != nil {
+		if dk, ok := c.disk.Get(key); ok {
+			logger.Infof("digest cache disk get %s => %v: %s", keystr, dk, time.Since(start))
+			d := New(src, dk)
+			c.mu.Lock()
+			c.lru.Add(lru.Key(key), d)
+			c.mu.Unlock()
+			stats.RecordWithTags(ctx, []tag.Mutator{
+				tag.Upsert(opKey, "disk-get"),
+				tag.Upsert(fileExtKey, fileExt),
+			}, cacheStats.M(1))
+			return d, nil
+		}
+	}
 	// singleflight?
 	dk, err := c.cacheGet(ctx, key)
 	if err == nil {
@@ -148,6 +169,7 @@ func (c *Cache) Get(ctx context.Context, key string, src Source) (Data, error) {
 			c.mu.Lock()
 			c.lru.Add(lru.Key(key), d)
 			c.mu.Unlock()
+			c.diskSet(ctx, key, dk)
 			stats.RecordWithTags(ctx, []tag.Mutator{
 				tag.Upsert(opKey, "cache-get"),
 				tag.Upsert(fileExtKey, fileExt),
@@ -174,6 +196,7 @@ func (c *Cache) Get(ctx context.Context, key string, src Source) (Data, error) {
 		c.lru.Add(lru.Key(key), d)
 		c.mu.Unlock()
 		logger.Infof("digest cache set %s => %v: %s", keystr, d, time.Since(start))
+		c.diskSet(ctx, key, d.Digest())
 		err = c.cacheSet(ctx, key, d.Digest())
 		op := "cache-set"
 		if err != nil {
@@ -188,6 +211,17 @@ func (c *Cache) Get(ctx context.Context, key string, src Source) (Data, error) {
 	return d, nil
 }
 
+func (c *Cache) diskSet(ctx context.Context, key string, d *rpb.Digest) {
+	if c.disk == nil {
+		return
+	}
+	err := c.disk.Set(key, d)
+	if err != nil {
+		logger := log.FromContext(ctx)
+		logger.Warnf("digest cache disk set fail %s => %v: %v", key, d, err)
+	}
+}
+
 func (c *Cache) onEvicted(k lru.Key, value interface{}) {
 	ctx := context.Background()
 	logger := log.FromContext(ctx)
@@ -0,0 +1,304 @@
+/* Copyright 2021 Google Inc. All Rights Reserved. */
+
+package digest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+)
+
+// diskHeader is the first line of disk tier file.
+const diskHeader = "# goma digest cache v1\n"
+
+type diskEntry struct {
+	hash      string
+	sizeBytes int64
+	// seq is used to keep recent entries in compaction.
+	seq uint64
+}
+
+// DiskTier is a local disk tier of digest cache.
+//
+// It is an append-only log of "<key> <hash> <size>" lines,
+// loaded in memory on open. The log is compacted when the file size
+// exceeds max bytes, or when more than half of the file is superseded
+// lines, so that memory usage is bounded by max bytes.
+// It makes digest cache warm after restart without redis.
+type DiskTier struct {
+	fname    string
+	maxBytes int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	// live is bytes of lines for entries in m.
+	live int64
+	seq  uint64
+	m    map[string]diskEntry
+}
+
+// OpenDiskTier opens disk tier at fname.
+// If fname exists, it loads entries from the file.
+// maxBytes is the size limit of the file, and must be positive.
+func OpenDiskTier(fname string, maxBytes int64) (*DiskTier, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("disk tier %s: max bytes must be positive: %d", fname, maxBytes)
+	}
+	d := &DiskTier{
+		fname:    fname,
+		maxBytes: maxBytes,
+		m:        make(map[string]diskEntry),
+	}
+	err := d.load()
+	if err != nil {
+		return nil, err
+	}
+	if d.needsCompaction() {
+		err = d.compact()
+	} else {
+		err = d.openForAppend()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DiskTier) load() error {
+	f, err := os.Open(d.fname)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			// ignore partially written last line.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %v", d.fname, err)
+		}
+		d.size += int64(len(line))
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, e, ok := parseDiskLine(line)
+		if !ok {
+			continue
+		}
+		d.seq++
+		e.seq = d.seq
+		d.setLocked(key, e)
+	}
+}
+
+// setLocked sets e for key in memory index.
+func (d *DiskTier) setLocked(key string, e diskEntry) {
+	if old, ok := d.m[key]; ok {
+		d.live -= int64(len(diskLine(key, old)))
+	}
+	d.m[key] = e
+	d.live += int64(len(diskLine(key, e)))
+}
+
+// needsCompaction reports whether the file exceeds max bytes,
+// or more than half of the file is superseded lines.
+func (d *DiskTier) needsCompaction() bool {
+	if d.size > d.maxBytes {
+		return true
+	}
+	stale := d.size - int64(len(diskHeader)) - d.live
+	return stale > d.size/2
+}
+
+func parseDiskLine(line string) (string, diskEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", diskEntry{}, false
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return "", diskEntry{}, false
+	}
+	return fields[0], diskEntry{
+		hash:      fields[1],
+		sizeBytes: size,
+	}, true
+}
+
+func diskLine(key string, e diskEntry) string {
+	return fmt.Sprintf("%s %s %d\n", key, e.hash, e.sizeBytes)
+}
+
+func (d *DiskTier) openForAppend() error {
+	f, err := os.OpenFile(d.fname, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if d.size == 0 {
+		n, err := f.WriteString(diskHeader)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		d.size = int64(n)
+	}
+	d.f = f
+	return nil
+}
+
+// compact rewrites the file with recent entries, so that the file
+// is at most half of max bytes.
+func (d *DiskTier) compact() error {
+	if d.f != nil {
+		d.f.Close()
+		d.f = nil
+	}
+	keys := make([]string, 0, len(d.m))
+	for k := range d.m {
+		keys = append(keys, k)
+	}
+	// most recent first.
+	sort.Slice(keys, func(i, j int) bool {
+		return d.m[keys[i]].seq > d.m[keys[j]].seq
+	})
+	size := int64(len(diskHeader))
+	limit := d.maxBytes / 2
+	n := 0
+	for _, k := range keys {
+		sz := int64(len(diskLine(k, d.m[k])))
+		if size+sz > limit {
+			break
+		}
+		size += sz
+		n++
+	}
+	for _, k := range keys[n:] {
+		delete(d.m, k)
+	}
+	keys = keys[:n]
+	d.live = size - int64(len(diskHeader))
+
+	tmpname := d.fname + ".tmp"
+	f, err := os.Create(tmpname)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	w.WriteString(diskHeader)
+	for i := len(keys) - 1; i >= 0; i-- {
+		w.WriteString(diskLine(keys[i], d.m[keys[i]]))
+	}
+	err = w.Flush()
+	if err == nil {
+		err = f.Sync()
+	}
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpname)
+		return err
+	}
+	err = os.Rename(tmpname, d.fname)
+	if err != nil {
+		return err
+	}
+	d.size = size
+	return d.openForAppend()
+}
+
+// Get gets digest for key.
+func (d *DiskTier) Get(key string) (*rpb.Digest, bool) {
+	if d == nil {
+		return nil, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e, ok := d.m[key]
+	if !ok {
+		return nil, false
+	}
+	// keep recently used entries in compaction.
+	d.seq++
+	e.seq = d.seq
+	d.m[key] = e
+	return &rpb.Digest{
+		Hash:      e.hash,
+		SizeBytes: e.sizeBytes,
+	}, true
+}
+
+// Set sets digest for key.
+func (d *DiskTier) Set(key string, dg *rpb.Digest) error {
+	if d == nil {
+		return nil
+	}
+	if strings.ContainsAny(key, " \n") {
+		return fmt.Errorf("bad key for disk tier: %q", key)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.f == nil {
+		return fmt.Errorf("disk tier %s: closed", d.fname)
+	}
+	e, ok := d.m[key]
+	if ok && e.hash == dg.Hash && e.sizeBytes == dg.SizeBytes {
+		return nil
+	}
+	d.seq++
+	e = diskEntry{
+		hash:      dg.Hash,
+		sizeBytes: dg.SizeBytes,
+		seq:       d.seq,
+	}
+	n, err := d.f.WriteString(diskLine(key, e))
+	d.size += int64(n)
+	if err != nil {
+		return err
+	}
+	d.setLocked(key, e)
+	if d.needsCompaction() {
+		return d.compact()
+	}
+	return nil
+}
+
+// Len returns number of entries in disk tier.
+func (d *DiskTier) Len() int {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.m)
+}
+
+// Close closes disk tier.
+func (d *DiskTier) Close() error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.f == nil {
+		return nil
+	}
+	err := d.f.Close()
+	d.f = nil
+	return err
+}
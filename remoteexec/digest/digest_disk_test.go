@@ -0,0 +1,160 @@
+/* Copyright 2021 Google Inc. All Rights Reserved. */
+
+package digest
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestDiskTier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "digest.TestDiskTier.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "digest-cache")
+
+	const maxBytes = 1024
+	d, err := OpenDiskTier(fname, maxBytes)
+	if err != nil {
+		t.Fatalf("OpenDiskTier(%q, %d)=%v; want nil error", fname, maxBytes, err)
+	}
+	want := Bytes("first", []byte{12}).Digest()
+	err = d.Set("12", want)
+	if err != nil {
+		t.Errorf("Set(12, %v)=%v; want nil error", want, err)
+	}
+	err = d.Close()
+	if err != nil {
+		t.Errorf("Close()=%v; want nil error", err)
+	}
+
+	d, err = OpenDiskTier(fname, maxBytes)
+	if err != nil {
+		t.Fatalf("OpenDiskTier(%q, %d)=%v; want nil error", fname, maxBytes, err)
+	}
+	defer d.Close()
+	got, ok := d.Get("12")
+	if !ok || !proto.Equal(got, want) {
+		t.Errorf("Get(12)=%v, %t; want %v, true", got, ok, want)
+	}
+
+	// disk tier is used before computing digest from source.
+	dc := NewCache(nil, 1000)
+	dc.SetDiskTier(d)
+	data, err := dc.Get(context.Background(), "12", Bytes("second", []byte{34}))
+	if err != nil {
+		t.Fatalf("Get(ctx, 12, 'second')=%v; want nil error", err)
+	}
+	if !proto.Equal(data.Digest(), want) {
+		t.Errorf("Get(ctx, 12, 'second')=%v; want %v", data.Digest(), want)
+	}
+}
+
+func TestDiskTierCompaction(t *testing.T) {
+	dir, err := ioutil.TempDir("", "digest.TestDiskTierCompaction.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "digest-cache")
+
+	const maxBytes = 1024
+	d, err := OpenDiskTier(fname, maxBytes)
+	if err != nil {
+		t.Fatalf("OpenDiskTier(%q, %d)=%v; want nil error", fname, maxBytes, err)
+	}
+	defer d.Close()
+	var last *rpb.Digest
+	for i := 0; i < 100; i++ {
+		last = Bytes(fmt.Sprintf("data%d", i), []byte(fmt.Sprintf("content%d", i))).Digest()
+		err := d.Set(fmt.Sprintf("key%d", i), last)
+		if err != nil {
+			t.Fatalf("Set(key%d)=%v; want nil error", i, err)
+		}
+		// key1 is used recently.
+		d.Get("key1")
+	}
+	fi, err := os.Stat(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() > maxBytes {
+		t.Errorf("file size=%d; want <= %d", fi.Size(), maxBytes)
+	}
+	if _, ok := d.Get("key0"); ok {
+		t.Errorf("Get(key0)=_, true; want false (compacted)")
+	}
+	if _, ok := d.Get("key1"); !ok {
+		t.Errorf("Get(key1)=_, false; want true (recently used)")
+	}
+	got, ok := d.Get("key99")
+	if !ok || !proto.Equal(got, last) {
+		t.Errorf("Get(key99)=%v, %t; want %v, true", got, ok, last)
+	}
+	n := d.Len()
+	d.Close()
+
+	d, err = OpenDiskTier(fname, maxBytes)
+	if err != nil {
+		t.Fatalf("OpenDiskTier(%q, %d)=%v; want nil error", fname, maxBytes, err)
+	}
+	defer d.Close()
+	if got := d.Len(); got != n {
+		t.Errorf("Len()=%d after reopen; want %d", got, n)
+	}
+}
+
+func TestDiskTierCompactStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "digest.TestDiskTierCompactStale.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "digest-cache")
+
+	// same key updated many times.
+	content := diskHeader
+	var want diskEntry
+	for i := 0; i < 10; i++ {
+		want = diskEntry{
+			hash:      fmt.Sprintf("hash%d", i),
+			sizeBytes: int64(i),
+		}
+		content += diskLine("key", want)
+	}
+	err = ioutil.WriteFile(fname, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const maxBytes = 1024
+	d, err := OpenDiskTier(fname, maxBytes)
+	if err != nil {
+		t.Fatalf("OpenDiskTier(%q, %d)=%v; want nil error", fname, maxBytes, err)
+	}
+	defer d.Close()
+	got, ok := d.Get("key")
+	if !ok || got.Hash != want.hash || got.SizeBytes != want.sizeBytes {
+		t.Errorf("Get(key)=%v, %t; want %s/%d, true", got, ok, want.hash, want.sizeBytes)
+	}
+	buf, err := ioutil.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf), diskHeader+diskLine("key", want); got != want {
+		t.Errorf("file=%q; want %q (compacted)", got, want)
+	}
+
+	if _, err := OpenDiskTier(fname, 0); err == nil {
+		t.Errorf("OpenDiskTier(%q, 0)=nil; want error", fname)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"os"
 	"path"
 	"path/filepath"
 	"sort"
@@ -29,7 +30,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
-	"go.chromium.org/goma/server/command/descriptor"
+	"go.chromium.org/goma/server/command/pathconv"
 	"go.chromium.org/goma/server/exec"
 	"go.chromium.org/goma/server/log"
 	gomapb "go.chromium.org/goma/server/proto/api"
@@ -57,6 +58,8 @@ type request struct {
 	input       gomaInputInterface
 
 	filepath clientFilePath
+	// pathconv converts client path to server path.
+	pathconv pathconv.PathConverter
 
 	args         []string
 	envs         []string
@@ -69,6 +72,9 @@ type request struct {
 	allowChroot bool
 	needChroot  bool
 
+	// crash is crash cause if compiler crashed in remote.
+	crash string
+
 	err error
 }
 
@@ -85,6 +91,8 @@ type clientFilePath interface {
 	Clean(path string) string
 	SplitElem(path string) []string
 	PathSep() string
+	EqualElem(a, b string) bool
+	IsRoot(path string) bool
 }
 
 func doNotCache(req *gomapb.ExecReq) bool {
@@ -196,11 +204,11 @@ func (r *request) getInventoryData(ctx context.Context) *gomapb.ExecResp {
 		return r.gomaResp
 	}
 
-	r.filepath, err = descriptor.FilePathOf(cmdConfig.GetCmdDescriptor().GetSetup().GetPathType())
+	r.filepath, r.pathconv, err = clientPath(r.gomaReq.GetRequesterInfo().GetPathStyle(), cmdConfig.GetCmdDescriptor().GetSetup().GetPathType(), r.f.PathConverterOptions)
 	if err != nil {
-		logger.Errorf("bad path type in setup %s: %v", cmdConfig.GetCmdDescriptor().GetSelector(), err)
+		logger.Errorf("bad path style of requester or path type in setup %s: %v", cmdConfig.GetCmdDescriptor().GetSelector(), err)
 		r.gomaResp.Error = gomapb.ExecResp_BAD_REQUEST.Enum()
-		r.gomaResp.ErrorMessage = append(r.gomaResp.ErrorMessage, fmt.Sprintf("bad compiler config: %v", err))
+		r.gomaResp.ErrorMessage = append(r.gomaResp.ErrorMessage, fmt.Sprintf("bad path style or compiler config: %v", err))
 		return r.gomaResp
 	}
 
@@ -451,7 +459,7 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 		return r.gomaResp
 	}
 	execRootDir := r.gomaReq.GetRequesterInfo().GetExecRoot()
-	rootDir, needChroot, err := inputRootDir(r.filepath, inputPaths, r.allowChroot, execRootDir)
+	rootDir, needChroot, err := inputRootDir(r.filepath, r.pathconv, inputPaths, r.allowChroot, execRootDir)
 	if err != nil {
 		logger.Errorf("input root detection failed: %v", err)
 		logFileList(logger, "input paths", inputPaths)
@@ -459,7 +467,13 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 		r.gomaResp.ErrorMessage = append(r.gomaResp.ErrorMessage, fmt.Sprintf("input root detection failed: %v", err))
 		return r.gomaResp
 	}
+	limits := r.f.InputLimits.merge(r.cmdConfig.GetInputLimits())
+	err = limits.checkRequest(r.filepath, r.pathconv, r.gomaReq, rootDir)
+	if err != nil {
+		return r.inputLimitExceeded(ctx, err)
+	}
 	r.tree = merkletree.New(r.filepath, rootDir, r.digestStore)
+	r.tree.SetCache(r.f.MerkleTreeCache)
 	r.needChroot = needChroot
 
 	logger.Infof("new input tree cwd:%s root:%s execRoot:%s %s", r.gomaReq.GetCwd(), r.tree.RootDir(), execRootDir, r.cmdConfig.GetCmdDescriptor().GetSetup().GetPathType())
@@ -493,7 +507,7 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 
 	start := time.Now()
 	results := inputFiles(ctx, r.gomaReq.Input, r.input, func(filename string) (string, error) {
-		return rootRel(r.filepath, filename, cleanCWD, cleanRootDir)
+		return rootRel(r.filepath, r.pathconv, filename, cleanCWD, cleanRootDir)
 	}, executableInputs)
 	uploads := make([]*gomapb.ExecReq_Input, 0, len(r.gomaReq.Input))
 	for i, input := range r.gomaReq.Input {
@@ -510,6 +524,10 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 		return nil
 	}
 	logger.Infof("inputFiles=%d uploads=%d in %s", len(r.gomaReq.Input), len(uploads), time.Since(start))
+	err = limits.checkInputFiles(results)
+	if err != nil {
+		return r.inputLimitExceeded(ctx, err)
+	}
 
 	var files []merkletree.Entry
 	var missingInputs []string
@@ -573,7 +591,7 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 				return nil
 			}
 		}
-		fname, err := rootRel(r.filepath, e.Name, cleanCWD, cleanRootDir)
+		fname, err := rootRel(r.filepath, r.pathconv, e.Name, cleanCWD, cleanRootDir)
 		if err != nil {
 			if err == errOutOfRoot {
 				continue
@@ -590,7 +608,7 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 			return
 		}
 		for _, d := range dirs {
-			rel, err := rootRel(r.filepath, d, cleanCWD, cleanRootDir)
+			rel, err := rootRel(r.filepath, r.pathconv, d, cleanCWD, cleanRootDir)
 			if err != nil {
 				if err == errOutOfRoot {
 					logger.Warnf("%s %s: %v", name, d, err)
@@ -652,6 +670,16 @@ func (r *request) newInputTree(ctx context.Context) *gomapb.ExecResp {
 	return nil
 }
 
+// inputLimitExceeded sets BAD_REQUEST for input limit violation err.
+func (r *request) inputLimitExceeded(ctx context.Context, err error) *gomapb.ExecResp {
+	logger := log.FromContext(ctx)
+	logger.Errorf("input limit exceeded: %v", err)
+	r.gomaResp.Error = gomapb.ExecResp_BAD_REQUEST.Enum()
+	r.gomaResp.BadRequestReasonCode = gomapb.ExecResp_INPUT_LIMIT_EXCEEDED.Enum()
+	r.gomaResp.ErrorMessage = append(r.gomaResp.ErrorMessage, fmt.Sprintf("input limit exceeded: %v", err))
+	return r.gomaResp
+}
+
 type wrapperType int
 
 const (
@@ -707,7 +735,7 @@ func (r *request) newWrapperScript(ctx context.Context, cmdConfig *cmdpb.Config,
 	cwd := r.gomaReq.GetCwd()
 	cleanCWD := r.filepath.Clean(cwd)
 	cleanRootDir := r.filepath.Clean(r.tree.RootDir())
-	wd, err := rootRel(r.filepath, cwd, cleanCWD, cleanRootDir)
+	wd, err := rootRel(r.filepath, r.pathconv, cwd, cleanCWD, cleanRootDir)
 	if err != nil {
 		return badRequestError{err: fmt.Errorf("bad cwd=%s: %v", cwd, err)}
 	}
@@ -862,7 +890,7 @@ func (r *request) newWrapperScript(ctx context.Context, cmdConfig *cmdpb.Config,
 	// `wrapperPath` in `r.args` later.
 	wrapperPath := ""
 	for i, w := range files {
-		w.Name, err = rootRel(r.filepath, w.Name, cleanCWD, cleanRootDir)
+		w.Name, err = rootRel(r.filepath, r.pathconv, w.Name, cleanCWD, cleanRootDir)
 		if err != nil {
 			// rootRel should not fail with any user input at this point?
 			return err
@@ -1088,7 +1116,7 @@ func (r *request) newCommand(ctx context.Context) (*rpb.Command, error) {
 	cleanRootDir := r.filepath.Clean(r.tree.RootDir())
 	// set output files from command line flags.
 	for _, output := range r.outputs {
-		rel, err := rootRel(r.filepath, output, cleanCWD, cleanRootDir)
+		rel, err := rootRel(r.filepath, r.pathconv, output, cleanCWD, cleanRootDir)
 		if err != nil {
 			return nil, fmt.Errorf("output %s: %v", output, err)
 		}
@@ -1099,7 +1127,7 @@ func (r *request) newCommand(ctx context.Context) (*rpb.Command, error) {
 	logger.Debugf("setup for output dirs: %v", r.outputDirs)
 	// set output dirs from command line flags.
 	for _, output := range r.outputDirs {
-		rel, err := rootRel(r.filepath, output, cleanCWD, cleanRootDir)
+		rel, err := rootRel(r.filepath, r.pathconv, output, cleanCWD, cleanRootDir)
 		if err != nil {
 			return nil, fmt.Errorf("output dir %s: %v", output, err)
 		}
@@ -1413,6 +1441,13 @@ func (r *request) newResp(ctx context.Context, eresp *rpb.ExecuteResponse, cache
 		}
 		logger.Infof("stderr %s", shortLogMsg(r.gomaResp.Result.StderrBuffer))
 	}
+	if eresp.Result.ExitCode != 0 {
+		r.checkMissingFiles(ctx)
+		if cause, ok := detectCrash(eresp.Result.ExitCode, r.gomaResp.Result.StdoutBuffer, r.gomaResp.Result.StderrBuffer); ok {
+			logger.Errorf("compiler crash in remote: %s exit=%d", cause, eresp.Result.ExitCode)
+			r.crash = cause
+		}
+	}
 
 	for _, output := range eresp.Result.OutputFiles {
 		if r.err != nil {
@@ -1508,6 +1543,109 @@ func shortLogMsg(msg []byte) string {
 	return b.String()
 }
 
+// checkMissingFiles checks compiler's diagnostics for missing files
+// in stdout/stderr, and makes the response BAD_REQUEST, so that
+// compiler_proxy will fallback to local.
+func (r *request) checkMissingFiles(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	var files []missingFile
+	files = append(files, extractMissingFiles(r.gomaResp.Result.StdoutBuffer)...)
+	files = append(files, extractMissingFiles(r.gomaResp.Result.StderrBuffer)...)
+	if len(files) == 0 {
+		return
+	}
+	var fnames []string
+	for _, f := range files {
+		fnames = append(fnames, f.filename)
+		stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(compilerNameKey, f.diag),
+		}, missingRemoteInputCount.M(1))
+	}
+	logger.Warnf("missing files in remote: %q", fnames)
+	r.gomaResp.Error = gomapb.ExecResp_BAD_REQUEST.Enum()
+	r.gomaResp.BadRequestReasonCode = gomapb.ExecResp_MISSING_REMOTE_INPUT.Enum()
+	r.gomaResp.ErrorMessage = append(r.gomaResp.ErrorMessage, fmt.Sprintf("missing files in remote: %s", strings.Join(fnames, ", ")))
+}
+
+// defaultReproducerSema is used if Adapter.ReproducerSema is nil.
+var defaultReproducerSema = make(chan struct{}, 1)
+
+// captureReproducer captures reproducer bundle of the crashed action
+// in the adapter's reproducer store in background.
+func (r *request) captureReproducer(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	if r.f.CrashReproducers == nil {
+		recordReproducer(ctx, r.crash, "disabled")
+		return
+	}
+	sema := r.f.ReproducerSema
+	if sema == nil {
+		sema = defaultReproducerSema
+	}
+	select {
+	case sema <- struct{}{}:
+	default:
+		logger.Warnf("reproducer for %s dropped: too many captures in progress", r.crash)
+		recordReproducer(ctx, r.crash, "dropped")
+		return
+	}
+	rp := reproducer{
+		cause:        r.crash,
+		actionDigest: r.actionDigest,
+		store:        r.digestStore,
+		stdout:       r.gomaResp.GetResult().GetStdoutBuffer(),
+		stderr:       r.gomaResp.GetResult().GetStderrBuffer(),
+	}
+	// request ctx will be canceled when the response is sent.
+	ctx = detachedContext{ctx}
+	go func() {
+		defer func() {
+			<-sema
+		}()
+		if d := r.f.SpanTimeout.Reproducer; d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		recordReproducer(ctx, rp.cause, r.f.writeReproducer(ctx, rp))
+	}()
+}
+
+// writeReproducer writes reproducer bundle of rp in the adapter's
+// reproducer store, and returns the result.
+func (f *Adapter) writeReproducer(ctx context.Context, rp reproducer) string {
+	logger := log.FromContext(ctx)
+	name := reproducerName(rp.actionDigest)
+	w, err := f.CrashReproducers.Create(ctx, name)
+	if os.IsExist(err) {
+		logger.Infof("reproducer %s already exists", name)
+		return "exists"
+	}
+	if err != nil {
+		logger.Errorf("failed to create reproducer %s: %v", name, err)
+		return "error"
+	}
+	err = rp.writeTo(ctx, w)
+	if err != nil {
+		w.Abort()
+	} else {
+		err = w.Close()
+	}
+	if err != nil {
+		logger.Errorf("failed to write reproducer %s: %v", name, err)
+		return "error"
+	}
+	logger.Infof("reproducer %s captured for %s", name, rp.cause)
+	return "captured"
+}
+
+func recordReproducer(ctx context.Context, cause, result string) {
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(crashCauseKey, cause),
+		tag.Upsert(reproducerKey, result),
+	}, crashReproducerCount.M(1))
+}
+
 // logLLVMError records LLVM ERROR.
 // http://b/145177862
 func logLLVMError(logger log.Logger, id string, msg []byte) {
@@ -405,6 +405,32 @@ func (f *fakeRedis) Put(ctx context.Context, req *cachepb.PutReq, opts ...grpc.C
 	return &cachepb.PutResp{}, nil
 }
 
+func (f *fakeRedis) BatchGet(ctx context.Context, req *cachepb.BatchGetReq, opts ...grpc.CallOption) (*cachepb.BatchGetResp, error) {
+	resp := &cachepb.BatchGetResp{}
+	for _, key := range req.Keys {
+		r, err := f.Get(ctx, &cachepb.GetReq{Key: key})
+		if err != nil {
+			r = &cachepb.GetResp{}
+		}
+		resp.Resps = append(resp.Resps, r)
+	}
+	return resp, nil
+}
+
+func (f *fakeRedis) BatchPut(ctx context.Context, req *cachepb.BatchPutReq, opts ...grpc.CallOption) (*cachepb.BatchPutResp, error) {
+	for _, r := range req.Reqs {
+		f.Put(ctx, r)
+	}
+	return &cachepb.BatchPutResp{}, nil
+}
+
+func (f *fakeRedis) Delete(ctx context.Context, req *cachepb.DeleteReq, opts ...grpc.CallOption) (*cachepb.DeleteResp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.m, req.Key)
+	return &cachepb.DeleteResp{}, nil
+}
+
 // fakeCmdStorage represents fake cmdstorage bucket.
 type fakeCmdStorage struct {
 	m map[string]string // hash -> data
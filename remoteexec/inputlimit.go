@@ -0,0 +1,199 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.chromium.org/goma/server/command/pathconv"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+)
+
+// InputLimits specifies limits of inputs in a request.
+// 0 means no limit.
+type InputLimits struct {
+	// MaxInputs is maximum number of inputs.
+	MaxInputs int
+	// MaxTotalBytes is maximum total bytes of inputs.
+	MaxTotalBytes int64
+	// MaxDepth is maximum depth of input paths.
+	MaxDepth int
+	// MaxFileBytes is maximum bytes of a single input file.
+	MaxFileBytes int64
+}
+
+// merge returns limits overridden by cmd config's limits.
+// 0 in cmd config's limits keeps the limit, and negative value
+// removes the limit.
+func (l InputLimits) merge(cl *cmdpb.InputLimits) InputLimits {
+	if v := cl.GetMaxInputs(); v != 0 {
+		l.MaxInputs = int(unlimitedIfNegative(v))
+	}
+	if v := cl.GetMaxTotalBytes(); v != 0 {
+		l.MaxTotalBytes = unlimitedIfNegative(v)
+	}
+	if v := cl.GetMaxDepth(); v != 0 {
+		l.MaxDepth = int(unlimitedIfNegative(int64(v)))
+	}
+	if v := cl.GetMaxFileBytes(); v != 0 {
+		l.MaxFileBytes = unlimitedIfNegative(v)
+	}
+	return l
+}
+
+// unlimitedIfNegative returns 0 (no limit) if v is negative.
+func unlimitedIfNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// maxOffenders is the number of offending inputs reported in error message.
+const maxOffenders = 5
+
+type inputSize struct {
+	filename string
+	size     int64
+}
+
+type inputDepth struct {
+	filename string
+	depth    int
+}
+
+// inputLimitError is an error for input limit violations.
+type inputLimitError struct {
+	msgs []string
+}
+
+func (e inputLimitError) Error() string {
+	return strings.Join(e.msgs, "; ")
+}
+
+// largest returns the largest n inputs in sizes.
+func largest(sizes []inputSize, n int) []string {
+	sizes = append([]inputSize(nil), sizes...)
+	sort.SliceStable(sizes, func(i, j int) bool {
+		return sizes[i].size > sizes[j].size
+	})
+	if len(sizes) > n {
+		sizes = sizes[:n]
+	}
+	var s []string
+	for _, sz := range sizes {
+		s = append(s, fmt.Sprintf("%s (%d bytes)", sz.filename, sz.size))
+	}
+	return s
+}
+
+// checkSizes checks sizes of inputs against limits.
+func (l InputLimits) checkSizes(sizes []inputSize) []string {
+	var msgs []string
+	var total int64
+	var tooLarge []inputSize
+	for _, sz := range sizes {
+		total += sz.size
+		if l.MaxFileBytes > 0 && sz.size > l.MaxFileBytes {
+			tooLarge = append(tooLarge, sz)
+		}
+	}
+	if len(tooLarge) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d input files exceed max file bytes %d: largest %s", len(tooLarge), l.MaxFileBytes, strings.Join(largest(tooLarge, maxOffenders), ", ")))
+	}
+	if l.MaxTotalBytes > 0 && total > l.MaxTotalBytes {
+		msgs = append(msgs, fmt.Sprintf("total input bytes %d exceeds max total bytes %d: largest %s", total, l.MaxTotalBytes, strings.Join(largest(sizes, maxOffenders), ", ")))
+	}
+	return msgs
+}
+
+// checkRequest checks inputs in req against limits,
+// before input contents are looked up.
+// It checks number of inputs, depth of input paths from rootDir and
+// sizes of embedded contents.
+func (l InputLimits) checkRequest(filepath clientFilePath, pc pathconv.PathConverter, req *gomapb.ExecReq, rootDir string) error {
+	var msgs []string
+	inputs := req.GetInput()
+	if l.MaxInputs > 0 && len(inputs) > l.MaxInputs {
+		msgs = append(msgs, fmt.Sprintf("number of inputs %d exceeds max inputs %d", len(inputs), l.MaxInputs))
+	}
+	cwd := filepath.Clean(req.GetCwd())
+	rootDir = filepath.Clean(rootDir)
+	var sizes []inputSize
+	var deep []inputDepth
+	for _, input := range inputs {
+		if input.Content != nil {
+			sizes = append(sizes, inputSize{
+				filename: input.GetFilename(),
+				size:     input.GetContent().GetFileSize(),
+			})
+		}
+		if l.MaxDepth <= 0 {
+			continue
+		}
+		rel, err := rootRel(filepath, pc, input.GetFilename(), cwd, rootDir)
+		if err != nil {
+			// input out of root will be reported later.
+			continue
+		}
+		depth := len(filepath.SplitElem(filepath.Clean(rel)))
+		if depth <= l.MaxDepth {
+			continue
+		}
+		deep = append(deep, inputDepth{
+			filename: input.GetFilename(),
+			depth:    depth,
+		})
+	}
+	if len(deep) > 0 {
+		sort.SliceStable(deep, func(i, j int) bool {
+			return deep[i].depth > deep[j].depth
+		})
+		maxDepth := deep[0].depth
+		if len(deep) > maxOffenders {
+			deep = deep[:maxOffenders]
+		}
+		var deepest []string
+		for _, d := range deep {
+			deepest = append(deepest, d.filename)
+		}
+		msgs = append(msgs, fmt.Sprintf("input tree depth %d exceeds max depth %d: %s", maxDepth, l.MaxDepth, strings.Join(deepest, ", ")))
+	}
+	msgs = append(msgs, l.checkSizes(sizes)...)
+	if len(msgs) > 0 {
+		return inputLimitError{msgs: msgs}
+	}
+	return nil
+}
+
+// checkInputFiles checks sizes of input files against limits,
+// after input digests are computed.
+func (l InputLimits) checkInputFiles(results []inputFileResult) error {
+	if l.MaxTotalBytes <= 0 && l.MaxFileBytes <= 0 {
+		return nil
+	}
+	sizes := make([]inputSize, 0, len(results))
+	for _, r := range results {
+		if r.file.Data == nil {
+			continue
+		}
+		filename := r.file.Name
+		if d, ok := r.file.Data.(inputDigestData); ok {
+			filename = d.filename
+		}
+		sizes = append(sizes, inputSize{
+			filename: filename,
+			size:     r.file.Data.Digest().GetSizeBytes(),
+		})
+	}
+	msgs := l.checkSizes(sizes)
+	if len(msgs) > 0 {
+		return inputLimitError{msgs: msgs}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"go.chromium.org/goma/server/command/descriptor/posixpath"
+	"go.chromium.org/goma/server/command/pathconv"
+	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
+	"go.chromium.org/goma/server/remoteexec/digest"
+	"go.chromium.org/goma/server/remoteexec/merkletree"
+)
+
+func TestInputLimitsMerge(t *testing.T) {
+	l := InputLimits{
+		MaxInputs:     100,
+		MaxTotalBytes: 1000,
+	}
+	got := l.merge(&cmdpb.InputLimits{
+		MaxTotalBytes: 2000,
+		MaxDepth:      10,
+	})
+	want := InputLimits{
+		MaxInputs:     100,
+		MaxTotalBytes: 2000,
+		MaxDepth:      10,
+	}
+	if got != want {
+		t.Errorf("merge=%#v; want=%#v", got, want)
+	}
+	if got := l.merge(nil); got != l {
+		t.Errorf("merge(nil)=%#v; want=%#v", got, l)
+	}
+
+	// negative value removes server's default limit.
+	got = l.merge(&cmdpb.InputLimits{
+		MaxInputs: -1,
+		MaxDepth:  -1,
+	})
+	want = InputLimits{
+		MaxTotalBytes: 1000,
+	}
+	if got != want {
+		t.Errorf("merge(unlimited)=%#v; want=%#v", got, want)
+	}
+}
+
+func TestInputLimitsCheckRequest(t *testing.T) {
+	req := &gomapb.ExecReq{
+		Cwd: proto.String("/b/c/b/linux/src/out/Release"),
+		Input: []*gomapb.ExecReq_Input{
+			{
+				Filename: proto.String("../../base/logging.h"),
+				HashKey:  proto.String("hash-logging.h"),
+			},
+			{
+				Filename: proto.String("../../third_party/a/b/c/d/e/f.h"),
+				HashKey:  proto.String("hash-f.h"),
+				Content: &gomapb.FileBlob{
+					BlobType: gomapb.FileBlob_FILE.Enum(),
+					FileSize: proto.Int64(300),
+				},
+			},
+			{
+				Filename: proto.String("gen/foo.h"),
+				HashKey:  proto.String("hash-foo.h"),
+				Content: &gomapb.FileBlob{
+					BlobType: gomapb.FileBlob_FILE.Enum(),
+					FileSize: proto.Int64(200),
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		limits  InputLimits
+		wantErr []string
+	}{
+		{
+			desc: "no limits",
+		},
+		{
+			desc: "within limits",
+			limits: InputLimits{
+				MaxInputs:     3,
+				MaxTotalBytes: 500,
+				MaxDepth:      7,
+				MaxFileBytes:  300,
+			},
+		},
+		{
+			desc: "too many inputs",
+			limits: InputLimits{
+				MaxInputs: 2,
+			},
+			wantErr: []string{"number of inputs 3 exceeds max inputs 2"},
+		},
+		{
+			desc: "too deep",
+			limits: InputLimits{
+				MaxDepth: 6,
+			},
+			wantErr: []string{"input tree depth 7 exceeds max depth 6: ../../third_party/a/b/c/d/e/f.h"},
+		},
+		{
+			desc: "too large",
+			limits: InputLimits{
+				MaxTotalBytes: 400,
+				MaxFileBytes:  250,
+			},
+			wantErr: []string{
+				"1 input files exceed max file bytes 250: largest ../../third_party/a/b/c/d/e/f.h (300 bytes)",
+				"total input bytes 500 exceeds max total bytes 400: largest ../../third_party/a/b/c/d/e/f.h (300 bytes), gen/foo.h (200 bytes)",
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.limits.checkRequest(posixpath.FilePath{}, pathconv.Default(), req, "/b/c/b/linux/src")
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("checkRequest=%v; want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("checkRequest=nil; want error")
+			}
+			for _, want := range tc.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("checkRequest=%v; want contains %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestInputLimitsCheckRequestDeepest(t *testing.T) {
+	req := &gomapb.ExecReq{
+		Cwd: proto.String("/b/c/b/linux/src/out/Release"),
+	}
+	for _, fname := range []string{
+		"../../a/b.h",
+		"../../a/b/c.h",
+		"../../a/b/c/d.h",
+		"../../a/b/c/d/e.h",
+		"../../a/b/c/d/e/f.h",
+		"../../a/b/c/d/e/f/g.h",
+		"../../a/b/c/d/e/f/g/h.h",
+	} {
+		req.Input = append(req.Input, &gomapb.ExecReq_Input{
+			Filename: proto.String(fname),
+		})
+	}
+	l := InputLimits{
+		MaxDepth: 1,
+	}
+	err := l.checkRequest(posixpath.FilePath{}, pathconv.Default(), req, "/b/c/b/linux/src")
+	want := "input tree depth 8 exceeds max depth 1: ../../a/b/c/d/e/f/g/h.h, ../../a/b/c/d/e/f/g.h, ../../a/b/c/d/e/f.h, ../../a/b/c/d/e.h, ../../a/b/c/d.h"
+	if err == nil || err.Error() != want {
+		t.Errorf("checkRequest=%v; want %q", err, want)
+	}
+}
+
+func TestInputLimitsCheckInputFiles(t *testing.T) {
+	results := []inputFileResult{
+		{
+			file: merkletree.Entry{
+				Name: "base/logging.h",
+				Data: inputDigestData{
+					filename: "../../base/logging.h",
+					Data:     digest.Bytes("logging.h", make([]byte, 100)),
+				},
+			},
+		},
+		{
+			file: merkletree.Entry{
+				Name: "base/logging.cc",
+				Data: inputDigestData{
+					filename: "../../base/logging.cc",
+					Data:     digest.Bytes("logging.cc", make([]byte, 1000)),
+				},
+			},
+		},
+		{
+			missingInput: "../../base/missing.h",
+		},
+	}
+	err := InputLimits{MaxTotalBytes: 2000}.checkInputFiles(results)
+	if err != nil {
+		t.Errorf("checkInputFiles(MaxTotalBytes: 2000)=%v; want nil", err)
+	}
+	err = InputLimits{MaxFileBytes: 500}.checkInputFiles(results)
+	if err == nil || !strings.Contains(err.Error(), "../../base/logging.cc (1000 bytes)") {
+		t.Errorf("checkInputFiles(MaxFileBytes: 500)=%v; want error for ../../base/logging.cc", err)
+	}
+}
@@ -11,23 +11,56 @@ import (
 
 	"go.chromium.org/goma/server/command/descriptor/posixpath"
 	"go.chromium.org/goma/server/command/descriptor/winpath"
+	"go.chromium.org/goma/server/command/pathconv"
 	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
 )
 
+// defaultWindowsMountRoot is a server directory where windows client's
+// drives are mapped if not configured. Windows paths are converted to
+// server paths only to check input root, so it doesn't need to exist.
+const defaultWindowsMountRoot = "/mnt/win"
+
+// clientPath returns client filepath and path converter for requester's
+// path style. If path style is unknown, it uses path type of the command
+// setup.
+func clientPath(style gomapb.RequesterInfo_PathStyle, pt cmdpb.CmdDescriptor_PathType, opts pathconv.Options) (clientFilePath, pathconv.PathConverter, error) {
+	if style == gomapb.RequesterInfo_UNKNOWN_STYLE {
+		switch pt {
+		case cmdpb.CmdDescriptor_POSIX:
+			style = gomapb.RequesterInfo_POSIX_STYLE
+		case cmdpb.CmdDescriptor_WINDOWS:
+			style = gomapb.RequesterInfo_WINDOWS_STYLE
+		default:
+			return nil, nil, fmt.Errorf("bad path type: %s", pt)
+		}
+	}
+	var filepath clientFilePath
+	switch style {
+	case gomapb.RequesterInfo_POSIX_STYLE:
+		filepath = posixpath.FilePath{}
+	case gomapb.RequesterInfo_WINDOWS_STYLE:
+		filepath = winpath.FilePath{}
+		if opts.WindowsMountRoot == "" {
+			opts.WindowsMountRoot = defaultWindowsMountRoot
+		}
+	default:
+		return nil, nil, fmt.Errorf("bad path style: %s", style)
+	}
+	pc, err := pathconv.ForPathStyle(style, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filepath, pc, nil
+}
+
 func samePathElement(filepath clientFilePath, a, b []string) []string {
 	for i, p := range a {
 		if i >= len(b) {
 			return a[:i]
 		}
-		switch filepath.(type) {
-		case winpath.FilePath:
-			if strings.ToLower(p) != strings.ToLower(b[i]) {
-				return a[:i]
-			}
-		default:
-			if p != b[i] {
-				return a[:i]
-			}
+		if !filepath.EqualElem(p, b[i]) {
+			return a[:i]
 		}
 	}
 	return a
@@ -159,24 +192,9 @@ func validCommonDir(filepath clientFilePath, dir string) bool {
 	if dir == "" {
 		return false
 	}
-	switch filepath.(type) {
-	case posixpath.FilePath:
-		if dir == "/" {
-			return false
-		}
-	case winpath.FilePath:
-		// The drive should be considered as root on Win.
-		// e.g. c:\ will return false.
-		if len(dir) == 3 && dir[1:] == `:\` {
-			return false
-		}
-
-	default:
-		// unknown filepath?
-		return true
-	}
-
-	return true
+	// The drive should be considered as root on Win.
+	// e.g. c:\ will return false.
+	return !filepath.IsRoot(dir)
 }
 
 // needChroot returns true if chroot is needed to run the task with
@@ -206,53 +224,50 @@ func getPathsWithNoCommonDir(filepath clientFilePath, paths []string) []string {
 	return nil
 }
 
-func checkInputRootDir(filepath clientFilePath, dir string) error {
-	switch filepath.(type) {
-	case posixpath.FilePath:
-		// if dir covers these paths, command (e.g. clang) won't
-		// work because required *.so etc would not be accessible.
-		for _, p := range []string{
-			"/lib/x86_64-linux-gnu/",
-			"/usr/lib/x86_64-linux-gnu/",
-			"/lib64/",
-		} {
-			if strings.HasPrefix(p, dir+"/") {
-				return fmt.Errorf("bad input root: %s", dir)
-			}
+// checkInputRootDir checks client path dir is valid for input root.
+// It is checked in server path converted by pc, so it works for any
+// client path style.
+func checkInputRootDir(pc pathconv.PathConverter, dir string) error {
+	sdir, err := pc.ToServerPath(dir)
+	if err != nil {
+		return fmt.Errorf("bad input root %s: %v", dir, err)
+	}
+	// if dir covers these paths, command (e.g. clang) won't
+	// work because required *.so etc would not be accessible.
+	for _, p := range []string{
+		"/lib/x86_64-linux-gnu/",
+		"/usr/lib/x86_64-linux-gnu/",
+		"/lib64/",
+	} {
+		if strings.HasPrefix(p, sdir+"/") {
+			return fmt.Errorf("bad input root: %s", dir)
 		}
-		return nil
-
-	case winpath.FilePath:
-		// TODO: check for win path.
-		return nil
-
-	default:
-		// unknown filepath?
-		return nil
 	}
+	return nil
 }
 
 // inputRootDir returns common root of paths.
 // if execRootDir is not empty, use it as root of paths.
 // If second return value is true, chroot must be used.  It become true only
-// if `allowChroot` is true and common input root is "/".
-func inputRootDir(filepath clientFilePath, paths []string, allowChroot bool, execRootDir string) (string, bool, error) {
+// if `allowChroot` is true and common input root is the root of server,
+// i.e. the client has the same root as server.
+func inputRootDir(filepath clientFilePath, pc pathconv.PathConverter, paths []string, allowChroot bool, execRootDir string) (string, bool, error) {
 	if execRootDir != "" {
 		return execRootDir, execRootDir == "/" && allowChroot, nil
 	}
 	root := commonDir(filepath, paths)
 	if needChroot(filepath, root) && allowChroot {
-		switch filepath.(type) {
-		// TODO: support non-posix platform
-		case posixpath.FilePath:
-			return "/", true, nil
+		// windows client has no root path, since drives are
+		// mapped to directories under mount root in server.
+		if croot, err := pc.ToClientPath("/"); err == nil {
+			return croot, true, nil
 		}
 	}
 	if !validCommonDir(filepath, root) {
 		pair := getPathsWithNoCommonDir(filepath, paths)
 		return "", false, fmt.Errorf("no common paths in inputs: %v", pair)
 	}
-	err := checkInputRootDir(filepath, root)
+	err := checkInputRootDir(pc, root)
 	if err != nil {
 		return "", false, err
 	}
@@ -264,26 +279,34 @@ var errOutOfRoot = errors.New("out of root")
 // rootRel returns relative path from rootDir for fname,
 // which is relative path from cwd, or absolute path.
 // cwd and rootDir should be clean path.
-func rootRel(filepath clientFilePath, fname, cwd, rootDir string) (string, error) {
-	if filepath == nil {
+// fname is checked in server path converted by pc, and relative path
+// preserves fname's path elements, e.g. case of windows path.
+func rootRel(filepath clientFilePath, pc pathconv.PathConverter, fname, cwd, rootDir string) (string, error) {
+	if filepath == nil || pc == nil {
 		return "", errors.New("rootRel: client filepath unknown")
 	}
 	if !filepath.IsAbs(fname) {
 		fname = filepath.Join(cwd, fname)
 	}
+	sfname, err := pc.ToServerPath(fname)
+	if err != nil {
+		return "", err
+	}
+	sroot, err := pc.ToServerPath(rootDir)
+	if err != nil {
+		return "", err
+	}
+	if !hasPrefixDir(sfname, sroot) {
+		return "", errOutOfRoot
+	}
 	// filepath.Rel cleans paths, so we can't use it here.
 	// suppose rootdir and cwd are clean path, and
 	// cwd is under rootdir.
 	rootElems := filepath.SplitElem(rootDir)
 	fileElems := filepath.SplitElem(fname)
-
 	if len(fileElems) < len(rootElems) {
 		return "", errOutOfRoot
 	}
-	commonElems := samePathElement(filepath, rootElems, fileElems)
-	if len(commonElems) != len(rootElems) {
-		return "", errOutOfRoot
-	}
 	fileElems = fileElems[len(rootElems):]
 	relname := filepath.Join(fileElems...)
 	fileElems = filepath.SplitElem(filepath.Clean(relname))
@@ -294,8 +317,7 @@ func rootRel(filepath clientFilePath, fname, cwd, rootDir string) (string, error
 }
 
 // hasPrefixDir returns true if p has prefix as a directory name.
-// Note: it is case sensitive.
-// TODO: support non-posix platforms.
+// Note: it is case sensitive. Use it for posix path, e.g. server path.
 func hasPrefixDir(p, prefix string) bool {
 	prefix = strings.TrimRight(prefix, "/")
 	if !strings.HasPrefix(p, prefix) {
@@ -12,7 +12,9 @@ import (
 
 	"go.chromium.org/goma/server/command/descriptor/posixpath"
 	"go.chromium.org/goma/server/command/descriptor/winpath"
+	"go.chromium.org/goma/server/command/pathconv"
 	gomapb "go.chromium.org/goma/server/proto/api"
+	cmdpb "go.chromium.org/goma/server/proto/command"
 )
 
 func TestGetPathsWithNoCommonDirPosix(t *testing.T) {
@@ -397,7 +399,7 @@ func TestInputRootDir(t *testing.T) {
 		if err != nil {
 			t.Errorf("inputPaths(req, %q)=%v, %v; want nil error", tc.argv0, paths, err)
 		}
-		got, needChroot, err := inputRootDir(posixpath.FilePath{}, paths, tc.allowChroot, tc.execRoot)
+		got, needChroot, err := inputRootDir(posixpath.FilePath{}, pathconv.Default(), paths, tc.allowChroot, tc.execRoot)
 		if tc.wantRootErr {
 			if err == nil {
 				t.Errorf("inputRootDir(files)=%v, %t, nil; want err", got, needChroot)
@@ -410,6 +412,225 @@ func TestInputRootDir(t *testing.T) {
 	}
 }
 
+func TestInputRootDirWin(t *testing.T) {
+	pc, err := pathconv.Windows("/mnt/win")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		desc        string
+		paths       []string
+		allowChroot bool
+		execRoot    string
+		want        string
+		wantChroot  bool
+		wantErr     bool
+	}{
+		{
+			desc: "basic",
+			paths: []string{
+				`C:\src\chromium\src\out\Release`,
+				`C:\src\chromium\src\base\logging.h`,
+				`c:\src\chromium\src\third_party\llvm-build\Release+Asserts\bin\clang-cl.exe`,
+			},
+			want: `C:\src\chromium\src`,
+		},
+		{
+			desc: "mixed separator",
+			paths: []string{
+				`C:\src\chromium\src\out\Release`,
+				`C:/src/chromium/src/base/logging.h`,
+			},
+			want: `C:\src\chromium\src`,
+		},
+		{
+			desc: "drive root",
+			paths: []string{
+				`C:\src\chromium\src\out\Release`,
+				`C:\Program Files\include\stdio.h`,
+			},
+			allowChroot: true,
+			wantErr:     true,
+		},
+		{
+			// no chroot for windows, since drives are
+			// directories under mount root.
+			desc: "other drive",
+			paths: []string{
+				`C:\src\chromium\src\out\Release`,
+				`D:\sdk\include\stdio.h`,
+			},
+			allowChroot: true,
+			wantErr:     true,
+		},
+		{
+			desc: "exec root",
+			paths: []string{
+				`C:\src\chromium\src\out\Release`,
+				`D:\sdk\include\stdio.h`,
+			},
+			execRoot: `C:\src`,
+			want:     `C:\src`,
+		},
+	} {
+		got, needChroot, err := inputRootDir(winpath.FilePath{}, pc, tc.paths, tc.allowChroot, tc.execRoot)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: inputRootDir(%q)=%q, %t, nil; want error", tc.desc, tc.paths, got, needChroot)
+			}
+			continue
+		}
+		if err != nil || got != tc.want || needChroot != tc.wantChroot {
+			t.Errorf("%s: inputRootDir(%q)=%q, %t, %v; want %q, %t, nil", tc.desc, tc.paths, got, needChroot, err, tc.want, tc.wantChroot)
+		}
+	}
+}
+
+func TestCheckInputRootDir(t *testing.T) {
+	win, err := pathconv.Windows("/lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, err := pathconv.PrefixMap(pathconv.PrefixMapping{
+		Client: "/home/foo/src",
+		Server: "/lib64/src",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		pc      pathconv.PathConverter
+		dir     string
+		wantErr bool
+	}{
+		{
+			desc: "posix",
+			pc:   pathconv.Default(),
+			dir:  "/home/foo/src",
+		},
+		{
+			desc:    "posix covers lib",
+			pc:      pathconv.Default(),
+			dir:     "/usr",
+			wantErr: true,
+		},
+		{
+			desc: "prefix map",
+			pc:   prefix,
+			dir:  "/home/foo/src",
+		},
+		{
+			desc:    "prefix map unmapped server path",
+			pc:      prefix,
+			dir:     "/lib64/src",
+			wantErr: true,
+		},
+		{
+			desc: "windows",
+			pc:   win,
+			dir:  `C:\src`,
+		},
+		{
+			desc:    "windows relative path",
+			pc:      win,
+			dir:     `src\chromium`,
+			wantErr: true,
+		},
+	} {
+		err := checkInputRootDir(tc.pc, tc.dir)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: checkInputRootDir(%q)=%v; want error %t", tc.desc, tc.dir, err, tc.wantErr)
+		}
+	}
+}
+
+func TestClientPath(t *testing.T) {
+	for _, tc := range []struct {
+		style    gomapb.RequesterInfo_PathStyle
+		pathType cmdpb.CmdDescriptor_PathType
+		opts     pathconv.Options
+		want     clientFilePath
+		// client path converted to server path.
+		client, server string
+		wantErr        bool
+	}{
+		{
+			style:    gomapb.RequesterInfo_POSIX_STYLE,
+			pathType: cmdpb.CmdDescriptor_POSIX,
+			want:     posixpath.FilePath{},
+			client:   "/home/foo/src",
+			server:   "/home/foo/src",
+		},
+		{
+			style:    gomapb.RequesterInfo_POSIX_STYLE,
+			pathType: cmdpb.CmdDescriptor_POSIX,
+			opts: pathconv.Options{
+				PrefixMappings: []pathconv.PrefixMapping{
+					{Client: "/home/foo", Server: "/b"},
+				},
+			},
+			want:   posixpath.FilePath{},
+			client: "/home/foo/src",
+			server: "/b/src",
+		},
+		{
+			// windows client for posix toolchain.
+			style:    gomapb.RequesterInfo_WINDOWS_STYLE,
+			pathType: cmdpb.CmdDescriptor_POSIX,
+			opts: pathconv.Options{
+				WindowsMountRoot: "/win",
+			},
+			want:   winpath.FilePath{},
+			client: `C:\Src`,
+			server: "/win/c/src",
+		},
+		{
+			style:    gomapb.RequesterInfo_WINDOWS_STYLE,
+			pathType: cmdpb.CmdDescriptor_WINDOWS,
+			want:     winpath.FilePath{},
+			client:   `C:\Src`,
+			server:   defaultWindowsMountRoot + "/c/src",
+		},
+		{
+			// path type of setup is used for unknown style.
+			pathType: cmdpb.CmdDescriptor_WINDOWS,
+			want:     winpath.FilePath{},
+			client:   `C:\Src`,
+			server:   defaultWindowsMountRoot + "/c/src",
+		},
+		{
+			pathType: cmdpb.CmdDescriptor_POSIX,
+			want:     posixpath.FilePath{},
+			client:   "/home/foo/src",
+			server:   "/home/foo/src",
+		},
+		{
+			wantErr: true,
+		},
+		{
+			style:   gomapb.RequesterInfo_PathStyle(100),
+			wantErr: true,
+		},
+	} {
+		filepath, pc, err := clientPath(tc.style, tc.pathType, tc.opts)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("clientPath(%v, %v, %v)=%T, %T, nil; want error", tc.style, tc.pathType, tc.opts, filepath, pc)
+			}
+			continue
+		}
+		if err != nil || filepath != tc.want {
+			t.Errorf("clientPath(%v, %v, %v)=%T, %T, %v; want %T, nil", tc.style, tc.pathType, tc.opts, filepath, pc, err, tc.want)
+			continue
+		}
+		server, err := pc.ToServerPath(tc.client)
+		if err != nil || server != tc.server {
+			t.Errorf("clientPath(%v, %v, %v): ToServerPath(%q)=%q, %v; want %q, nil", tc.style, tc.pathType, tc.opts, tc.client, server, err, tc.server)
+		}
+	}
+}
+
 func TestRootRelPosix(t *testing.T) {
 	for _, tc := range []struct {
 		fname, cwd, rootDir string
@@ -472,7 +693,7 @@ func TestRootRelPosix(t *testing.T) {
 		},
 	} {
 		var filepath posixpath.FilePath
-		got, err := rootRel(filepath, tc.fname, filepath.Clean(tc.cwd), filepath.Clean(tc.rootDir))
+		got, err := rootRel(filepath, pathconv.Default(), tc.fname, filepath.Clean(tc.cwd), filepath.Clean(tc.rootDir))
 		if tc.wantErr {
 			if err == nil {
 				t.Errorf("rootRel(posixpath.FilePath, %q, %q, %q)=%v, nil; want error", tc.fname, tc.cwd, tc.rootDir, got)
@@ -515,9 +736,43 @@ func TestRootRelWin(t *testing.T) {
 			rootDir: `c:\Users\foo\src\chromium\src`,
 			wantErr: true,
 		},
+		{ // "/" is also path separator.
+			fname:   `../../base/foo.cc`,
+			cwd:     `c:\Users\foo\src\chromium\src\out\Release`,
+			rootDir: `c:\Users\foo\src\chromium\src`,
+			want:    `out\Release\..\..\base\foo.cc`,
+		},
+		{
+			fname:   `C:/Users/Foo/src/chromium/src/base/foo.cc`,
+			cwd:     `c:\Users\foo\src\chromium\src\out\Release`,
+			rootDir: `c:\Users\foo\src\chromium\src`,
+			want:    `base\foo.cc`,
+		},
+		{ // other drive.
+			fname:   `d:\Users\foo\src\chromium\src\base\foo.cc`,
+			cwd:     `c:\Users\foo\src\chromium\src\out\Release`,
+			rootDir: `c:\Users\foo\src\chromium\src`,
+			wantErr: true,
+		},
+		{ // ignore case to check out of root.
+			fname:   `..\..\..\SRC\base\foo.cc`,
+			cwd:     `c:\Users\foo\src\chromium\src\out\Release`,
+			rootDir: `c:\Users\foo\src\chromium\src`,
+			wantErr: true,
+		},
+		{
+			fname:   `..\..\..\Src\base\foo.cc`,
+			cwd:     `c:\Users\foo\src\chromium\src\out\Release`,
+			rootDir: `c:\Users\foo\src\chromium`,
+			want:    `src\out\Release\..\..\..\Src\base\foo.cc`,
+		},
 	} {
 		var filepath winpath.FilePath
-		got, err := rootRel(filepath, tc.fname, filepath.Clean(tc.cwd), filepath.Clean(tc.rootDir))
+		pc, err := pathconv.Windows("/mnt/win")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := rootRel(filepath, pc, tc.fname, filepath.Clean(tc.cwd), filepath.Clean(tc.rootDir))
 		if tc.wantErr {
 			if err == nil {
 				t.Errorf("rootRel(winpath.FilePath, %q, %q, %q)=%v, nil; want error", tc.fname, tc.cwd, tc.rootDir, got)
@@ -532,7 +787,7 @@ func TestRootRelWin(t *testing.T) {
 
 func BenchmarkRootRel(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		rootRel(posixpath.FilePath{}, "../../base/foo.cc", "/home/foo/src/chromium/src/out/Release", "/home/foo/src/chromium/src")
+		rootRel(posixpath.FilePath{}, pathconv.Default(), "../../base/foo.cc", "/home/foo/src/chromium/src/out/Release", "/home/foo/src/chromium/src")
 	}
 }
 
@@ -0,0 +1,166 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package merkletree
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/golang/groupcache/lru"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"go.chromium.org/goma/server/remoteexec/digest"
+)
+
+var (
+	cacheStats = stats.Int64(
+		"go.chromium.org/goma/server/remoteexec/merkletree.cache",
+		"merkletree subtree cache operations",
+		stats.UnitDimensionless)
+
+	opKey = tag.MustNewKey("op")
+
+	// DefaultViews are the default views provided by this package.
+	// You need to register the view for data to actually be collected.
+	DefaultViews = []*view.View{
+		{
+			Name:        "go.chromium.org/goma/server/remoteexec/merkletree.cache-entries",
+			Description: `number of merkletree subtree cache entries`,
+			Measure:     cacheStats,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "go.chromium.org/goma/server/remoteexec/merkletree.cache-ops",
+			Description: `merkletree subtree cache operations`,
+			Measure:     cacheStats,
+			TagKeys: []tag.Key{
+				opKey,
+			},
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+// Cache caches digests of directory subtrees that consist only of
+// immutable entries (e.g. toolchain files from command config), so that
+// MerkleTree doesn't need to rebuild directory protos of such subtrees
+// for every request.
+//
+// Cache is safe for concurrent use by multiple MerkleTrees.
+type Cache struct {
+	mu  sync.Mutex
+	lru lru.Cache
+}
+
+// NewCache creates new subtree cache with maxEntries.
+// 0 means no limit.
+func NewCache(maxEntries int) *Cache {
+	c := &Cache{}
+	c.lru.MaxEntries = maxEntries
+	c.lru.OnEvicted = c.onEvicted
+	return c
+}
+
+// fingerprint identifies contents of a subtree.
+// It is sha256 of sorted entries in the directory, including
+// fingerprints of its subdirectories.
+type fingerprint [sha256.Size]byte
+
+func newFingerprint(entries [][]byte) fingerprint {
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i], entries[j]) < 0
+	})
+	h := sha256.New()
+	var buf [binary.MaxVarintLen64]byte
+	for _, e := range entries {
+		n := binary.PutUvarint(buf[:], uint64(len(e)))
+		h.Write(buf[:n])
+		h.Write(e)
+	}
+	var fp fingerprint
+	h.Sum(fp[:0])
+	return fp
+}
+
+// cacheKey identifies a subtree.
+// fingerprint doesn't include name of the directory itself, so
+// dirname is needed to distinguish the same subtrees in different
+// directories.
+type cacheKey struct {
+	dirname string
+	fp      fingerprint
+}
+
+// subtree holds fingerprint state of a directory.
+type subtree struct {
+	// mutable is true if any entry in the subtree is not immutable.
+	mutable bool
+	// entries are (name, digest, mode) of entries in the directory.
+	// subdirectories are added when fingerprint is computed.
+	entries [][]byte
+	fp      *fingerprint
+}
+
+// cacheEntry is a cached subtree.
+type cacheEntry struct {
+	// digest of the subtree's directory.
+	digest *rpb.Digest
+	// dirs are data of all directories in the subtree,
+	// including the subtree directory itself.
+	dirs []digest.Data
+}
+
+func (c *Cache) get(ctx context.Context, key cacheKey) (cacheEntry, bool) {
+	c.mu.Lock()
+	v, ok := c.lru.Get(key)
+	c.mu.Unlock()
+	if !ok {
+		stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(opKey, "miss"),
+		}, cacheStats.M(0))
+		return cacheEntry{}, false
+	}
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(opKey, "hit"),
+	}, cacheStats.M(0))
+	return v.(cacheEntry), true
+}
+
+func (c *Cache) add(ctx context.Context, key cacheKey, e cacheEntry) {
+	c.mu.Lock()
+	if _, ok := c.lru.Get(key); ok {
+		// other request has already added the same subtree.
+		c.mu.Unlock()
+		return
+	}
+	c.lru.Add(key, e)
+	c.mu.Unlock()
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(opKey, "add"),
+	}, cacheStats.M(1))
+}
+
+func (c *Cache) onEvicted(k lru.Key, value interface{}) {
+	stats.RecordWithTags(context.Background(), []tag.Mutator{
+		tag.Upsert(opKey, "evict"),
+	}, cacheStats.M(-1))
+}
+
+// Len returns number of cached subtrees.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
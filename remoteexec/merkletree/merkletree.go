@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
@@ -31,6 +32,10 @@ type MerkleTree struct {
 	// dirname to Directory
 	m     map[string]*rpb.Directory
 	store *digest.Store
+
+	cache *Cache
+	// dirname to subtree state. used only if cache is set.
+	subtrees map[string]*subtree
 }
 
 // FilePath provides filepath functionalities.
@@ -51,6 +56,15 @@ func New(filepath FilePath, rootDir string, store *digest.Store) *MerkleTree {
 	}
 }
 
+// SetCache sets subtree cache.
+// It must be called before any entry is set.
+func (m *MerkleTree) SetCache(c *Cache) {
+	m.cache = c
+	if c != nil {
+		m.subtrees = make(map[string]*subtree)
+	}
+}
+
 // RootDir returns root dir of merkle tree.
 func (m *MerkleTree) RootDir() string {
 	return m.rootDir
@@ -76,6 +90,13 @@ type Entry struct {
 
 	// If the file is a symlink, then this should be set to the target of the symlink.
 	Target string
+
+	// Immutable is true if the entry is known to have the same content
+	// for the same name, e.g. toolchain files.
+	// Subtrees that consist only of immutable entries may be
+	// reused from Cache.
+	// Directory entries are always treated as immutable.
+	Immutable bool
 }
 
 var (
@@ -145,15 +166,18 @@ func (m *MerkleTree) Set(entry Entry) error {
 						Name:   name,
 						Target: entry.Target,
 					})
+					m.account(entry, append(dirstack, cur), name)
 					return nil
 				}
 				m.setDir(cur, name)
+				m.account(entry, append(dirstack, cur), name)
 				return nil
 			}
 			if name == "." || name == ".." {
 				return ErrBadPath
 			}
 			m.store.Set(entry.Data)
+			m.account(entry, append(dirstack, cur), name)
 			cur.dir.Files = append(cur.dir.Files, &rpb.FileNode{
 				Name:         name,
 				Digest:       entry.Data.Digest(),
@@ -176,6 +200,79 @@ func (m *MerkleTree) Set(entry Entry) error {
 	}
 }
 
+// account updates subtree states of dirs for the entry named
+// name in the last dir of dirs.
+func (m *MerkleTree) account(entry Entry, dirs []dirstate, name string) {
+	if m.cache == nil {
+		return
+	}
+	var b []byte
+	b = append(b, name...)
+	switch {
+	case entry.Data != nil:
+		d := entry.Data.Digest()
+		b = append(b, 0, 'f')
+		b = append(b, d.Hash...)
+		b = append(b, 0)
+		b = strconv.AppendInt(b, d.SizeBytes, 10)
+		b = strconv.AppendBool(b, entry.IsExecutable)
+	case entry.Target != "":
+		b = append(b, 0, 's')
+		b = append(b, entry.Target...)
+	default:
+		b = append(b, 0, 'd')
+	}
+	st := m.subtree(dirs[len(dirs)-1].name)
+	st.entries = append(st.entries, b)
+	if entry.Immutable || (entry.Data == nil && entry.Target == "") {
+		return
+	}
+	for _, d := range dirs {
+		m.subtree(d.name).mutable = true
+	}
+}
+
+// fingerprint returns fingerprint of immutable subtree at dir,
+// which is located as dirname.
+func (m *MerkleTree) fingerprint(dir *rpb.Directory, dirname string) (fingerprint, error) {
+	st := m.subtree(dirname)
+	if st.fp != nil {
+		return *st.fp, nil
+	}
+	entries := append([][]byte(nil), st.entries...)
+	for _, subdir := range dir.Directories {
+		dirname := pathJoin(dirname, subdir.Name)
+		d, found := m.m[dirname]
+		if !found {
+			return fingerprint{}, fmt.Errorf("directory not found: %s", dirname)
+		}
+		fp, err := m.fingerprint(d, dirname)
+		if err != nil {
+			return fingerprint{}, err
+		}
+		var b []byte
+		b = append(b, subdir.Name...)
+		b = append(b, 0, 'D')
+		b = append(b, fp[:]...)
+		entries = append(entries, b)
+	}
+	fp := newFingerprint(entries)
+	st.fp = &fp
+	return fp, nil
+}
+
+func (m *MerkleTree) subtree(dirname string) *subtree {
+	if dirname == "" {
+		dirname = "."
+	}
+	st, ok := m.subtrees[dirname]
+	if !ok {
+		st = &subtree{}
+		m.subtrees[dirname] = st
+	}
+	return st
+}
+
 func pathJoin(dir, base string) string {
 	var b strings.Builder
 	if dir == "." || dir == "" {
@@ -205,11 +302,54 @@ func (m *MerkleTree) setDir(cur dirstate, name string) dirstate {
 
 // Build builds merkle tree and returns root's digest.
 func (m *MerkleTree) Build(ctx context.Context) (*rpb.Digest, error) {
-	return m.buildTree(ctx, m.root, "")
+	return m.buildTree(ctx, m.root, "", nil)
 }
 
-// buildtree builds tree at curdir, which is located as dirname.
-func (m *MerkleTree) buildTree(ctx context.Context, curdir *rpb.Directory, dirname string) (*rpb.Digest, error) {
+// buildTree builds tree at curdir, which is located as dirname.
+// If dirs is not nil, data of directories built in the subtree is appended
+// to dirs.
+func (m *MerkleTree) buildTree(ctx context.Context, curdir *rpb.Directory, dirname string, dirs *[]digest.Data) (*rpb.Digest, error) {
+	if m.cache == nil {
+		return m.buildDir(ctx, curdir, dirname, dirs)
+	}
+	st := m.subtree(dirname)
+	if st.mutable {
+		return m.buildDir(ctx, curdir, dirname, dirs)
+	}
+	fp, err := m.fingerprint(curdir, dirname)
+	if err != nil {
+		return nil, err
+	}
+	key := cacheKey{
+		dirname: dirname,
+		fp:      fp,
+	}
+	if e, ok := m.cache.get(ctx, key); ok {
+		for _, d := range e.dirs {
+			m.store.Set(d)
+		}
+		if dirs != nil {
+			*dirs = append(*dirs, e.dirs...)
+		}
+		return e.digest, nil
+	}
+	var built []digest.Data
+	d, err := m.buildDir(ctx, curdir, dirname, &built)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.add(ctx, key, cacheEntry{
+		digest: d,
+		dirs:   built,
+	})
+	if dirs != nil {
+		*dirs = append(*dirs, built...)
+	}
+	return d, nil
+}
+
+// buildDir builds directory curdir, which is located as dirname.
+func (m *MerkleTree) buildDir(ctx context.Context, curdir *rpb.Directory, dirname string, dirs *[]digest.Data) (*rpb.Digest, error) {
 	logger := log.FromContext(ctx)
 	// directory should not have duplicate name.
 	// http://b/124693412
@@ -253,14 +393,14 @@ func (m *MerkleTree) buildTree(ctx context.Context, curdir *rpb.Directory, dirna
 	})
 	curdir.Symlinks = symlinks
 
-	var dirs []*rpb.DirectoryNode
+	var subdirs []*rpb.DirectoryNode
 	for _, subdir := range curdir.Directories {
 		dirname := pathJoin(dirname, subdir.Name)
 		dir, found := m.m[dirname]
 		if !found {
 			return nil, fmt.Errorf("directory not found: %s", dirname)
 		}
-		digest, err := m.buildTree(ctx, dir, dirname)
+		digest, err := m.buildTree(ctx, dir, dirname, dirs)
 		if err != nil {
 			return nil, err
 		}
@@ -275,17 +415,20 @@ func (m *MerkleTree) buildTree(ctx context.Context, curdir *rpb.Directory, dirna
 			continue
 		}
 		names[subdir.Name] = subdir
-		dirs = append(dirs, subdir)
+		subdirs = append(subdirs, subdir)
 	}
-	sort.Slice(dirs, func(i, j int) bool {
-		return dirs[i].Name < dirs[j].Name
+	sort.Slice(subdirs, func(i, j int) bool {
+		return subdirs[i].Name < subdirs[j].Name
 	})
-	curdir.Directories = dirs
+	curdir.Directories = subdirs
 
 	data, err := digest.Proto(curdir)
 	if err != nil {
 		return nil, fmt.Errorf("directory digest %s: %v", dirname, err)
 	}
 	m.store.Set(data)
+	if dirs != nil {
+		*dirs = append(*dirs, data)
+	}
 	return data.Digest(), nil
 }
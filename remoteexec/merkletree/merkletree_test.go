@@ -410,6 +410,164 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+func TestBuildWithCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache(0)
+
+	build := func(t *testing.T, rootDir, src string) (*rpb.Digest, *digest.Store) {
+		t.Helper()
+		ds := digest.NewStore()
+		mt := New(posixpath.FilePath{}, rootDir, ds)
+		mt.SetCache(cache)
+		for _, ent := range []Entry{
+			{
+				Name:         "third_party/llvm-build/Release+Asserts/bin/clang",
+				Data:         digest.Bytes("clang binary", []byte("clang binary")),
+				IsExecutable: true,
+				Immutable:    true,
+			},
+			{
+				Name:      "third_party/llvm-build/Release+Asserts/bin/clang++",
+				Target:    "clang",
+				Immutable: true,
+			},
+			{
+				Name:      "third_party/llvm-build/Release+Asserts/lib/libLLVM.so",
+				Data:      digest.Bytes("libLLVM.so", []byte("libLLVM.so content")),
+				Immutable: true,
+			},
+			{
+				Name: "third_party/llvm-build/Release+Asserts/lib/clang/include",
+				// directory
+			},
+			{
+				Name: "base/foo.cc",
+				Data: digest.Bytes("foo.cc", []byte(src)),
+			},
+		} {
+			err := mt.Set(ent)
+			if err != nil {
+				t.Fatalf("mt.Set(%q)=%v; want=nil", ent.Name, err)
+			}
+		}
+		d, err := mt.Build(ctx)
+		if err != nil {
+			t.Fatalf("mt.Build()=_, %v; want=nil", err)
+		}
+		return d, ds
+	}
+
+	d1, _ := build(t, "/path/to/root", "foo.cc content")
+	// third_party, llvm-build, Release+Asserts, bin, lib, lib/clang
+	// and lib/clang/include.
+	if got, want := cache.Len(), 7; got != want {
+		t.Errorf("cache.Len()=%d; want=%d", got, want)
+	}
+
+	d2, ds := build(t, "/path/to/other/root", "foo.cc modified")
+	if proto.Equal(d1, d2) {
+		t.Errorf("root digest unchanged for modified input: %v", d1)
+	}
+	if got, want := cache.Len(), 7; got != want {
+		t.Errorf("cache.Len()=%d; want=%d", got, want)
+	}
+
+	dir, err := openDir(ctx, ds, d2)
+	if err != nil {
+		t.Fatalf("root %v not found: %v", d2, err)
+	}
+	tpDir := checkDir(ctx, t, ds, dir, "third_party", nil, []string{"llvm-build"}, nil)
+	llvmDir := checkDir(ctx, t, ds, tpDir, "llvm-build", nil, []string{"Release+Asserts"}, nil)
+	raDir := checkDir(ctx, t, ds, llvmDir, "Release+Asserts", nil, []string{"bin", "lib"}, nil)
+	checkDir(ctx, t, ds, raDir, "bin", []string{"clang"}, nil, []string{"clang++"})
+	libDir := checkDir(ctx, t, ds, raDir, "lib", []string{"libLLVM.so"}, []string{"clang"}, nil)
+	clangDir := checkDir(ctx, t, ds, libDir, "clang", nil, []string{"include"}, nil)
+	checkDir(ctx, t, ds, clangDir, "include", nil, nil, nil)
+
+	ds = digest.NewStore()
+	mt := New(posixpath.FilePath{}, "/path/to/root", ds)
+	for _, ent := range []Entry{
+		{
+			Name:         "third_party/llvm-build/Release+Asserts/bin/clang",
+			Data:         digest.Bytes("clang binary", []byte("clang binary")),
+			IsExecutable: true,
+		},
+		{
+			Name:   "third_party/llvm-build/Release+Asserts/bin/clang++",
+			Target: "clang",
+		},
+		{
+			Name: "third_party/llvm-build/Release+Asserts/lib/libLLVM.so",
+			Data: digest.Bytes("libLLVM.so", []byte("libLLVM.so content")),
+		},
+		{
+			Name: "third_party/llvm-build/Release+Asserts/lib/clang/include",
+		},
+		{
+			Name: "base/foo.cc",
+			Data: digest.Bytes("foo.cc", []byte("foo.cc modified")),
+		},
+	} {
+		err := mt.Set(ent)
+		if err != nil {
+			t.Fatalf("mt.Set(%q)=%v; want=nil", ent.Name, err)
+		}
+	}
+	d3, err := mt.Build(ctx)
+	if err != nil {
+		t.Fatalf("mt.Build()=_, %v; want=nil", err)
+	}
+	if !proto.Equal(d2, d3) {
+		t.Errorf("mt.Build()=%v; want=%v (same as built with cache)", d3, d2)
+	}
+}
+
+func TestBuildWithCacheSwappedContents(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache(0)
+
+	build := func(t *testing.T, a, b string) *rpb.Digest {
+		t.Helper()
+		mt := New(posixpath.FilePath{}, "/path/to/root", digest.NewStore())
+		mt.SetCache(cache)
+		for _, ent := range []Entry{
+			{
+				Name:      "toolchain/bin/a",
+				Data:      digest.Bytes(a, []byte(a)),
+				Immutable: true,
+			},
+			{
+				Name:      "toolchain/bin/b",
+				Data:      digest.Bytes(b, []byte(b)),
+				Immutable: true,
+			},
+		} {
+			err := mt.Set(ent)
+			if err != nil {
+				t.Fatalf("mt.Set(%q)=%v; want=nil", ent.Name, err)
+			}
+		}
+		d, err := mt.Build(ctx)
+		if err != nil {
+			t.Fatalf("mt.Build()=_, %v; want=nil", err)
+		}
+		return d
+	}
+
+	d1 := build(t, "content1", "content2")
+	d2 := build(t, "content2", "content1")
+	if proto.Equal(d1, d2) {
+		t.Errorf("root digest unchanged for swapped contents: %v", d1)
+	}
+	// root, toolchain and toolchain/bin for each tree.
+	if got, want := cache.Len(), 6; got != want {
+		t.Errorf("cache.Len()=%d; want=%d", got, want)
+	}
+	if d := build(t, "content1", "content2"); !proto.Equal(d, d1) {
+		t.Errorf("mt.Build()=%v; want=%v", d, d1)
+	}
+}
+
 func TestBuildDuplicateError(t *testing.T) {
 	for _, tc := range []struct {
 		desc string
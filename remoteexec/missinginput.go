@@ -0,0 +1,67 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"regexp"
+)
+
+// missingFile is a file reported as missing by a compiler.
+type missingFile struct {
+	// filename is a filename in compiler's diagnostic.
+	// it may be a relative path to include directory.
+	filename string
+
+	// diag is diagnostic style. "gcc", "clang" or "cl".
+	diag string
+}
+
+var missingFileDiags = []struct {
+	diag string
+	re   *regexp.Regexp
+}{
+	{
+		// foo.cc:1:10: fatal error: foo.h: No such file or directory
+		diag: "gcc",
+		re:   regexp.MustCompile(`fatal error: ([^:\r\n]+): No such file or directory`),
+	},
+	{
+		// foo.cc:1:10: fatal error: 'foo.h' file not found
+		// also used by clang-cl.
+		diag: "clang",
+		re:   regexp.MustCompile(`fatal error: '([^'\r\n]+)' file not found`),
+	},
+	{
+		// clang: error: no such file or directory: 'foo.cc'
+		diag: "clang",
+		re:   regexp.MustCompile(`error: no such file or directory: '([^'\r\n]+)'`),
+	},
+	{
+		// foo.cc(1): fatal error C1083: Cannot open include file: 'foo.h': No such file or directory
+		diag: "cl",
+		re:   regexp.MustCompile(`fatal error C1083: Cannot open [a-z ]*file: '([^'\r\n]+)': No such file or directory`),
+	},
+}
+
+// extractMissingFiles extracts files reported as missing by compilers
+// in msg (i.e. stdout or stderr).
+func extractMissingFiles(msg []byte) []missingFile {
+	var files []missingFile
+	seen := make(map[string]bool)
+	for _, d := range missingFileDiags {
+		for _, m := range d.re.FindAllSubmatch(msg, -1) {
+			fname := string(m[1])
+			if seen[fname] {
+				continue
+			}
+			seen[fname] = true
+			files = append(files, missingFile{
+				filename: fname,
+				diag:     d.diag,
+			})
+		}
+	}
+	return files
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractMissingFiles(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		msg  string
+		want []missingFile
+	}{
+		{
+			desc: "no error",
+			msg:  "warning: unused variable 'x'\n",
+		},
+		{
+			desc: "gcc",
+			msg: `../../base/foo.cc:5:10: fatal error: base/bar.h: No such file or directory
+    5 | #include "base/bar.h"
+      |          ^~~~~~~~~~~~
+compilation terminated.
+`,
+			want: []missingFile{
+				{filename: "base/bar.h", diag: "gcc"},
+			},
+		},
+		{
+			desc: "clang",
+			msg: `../../base/foo.cc:5:10: fatal error: 'base/bar.h' file not found
+#include "base/bar.h"
+         ^~~~~~~~~~~~
+1 error generated.
+`,
+			want: []missingFile{
+				{filename: "base/bar.h", diag: "clang"},
+			},
+		},
+		{
+			desc: "clang-cl",
+			msg: "../../base/foo.cc(5,10): fatal error: 'base/bar.h' file not found\r\n" +
+				"#include \"base/bar.h\"\r\n" +
+				"1 error generated.\r\n",
+			want: []missingFile{
+				{filename: "base/bar.h", diag: "clang"},
+			},
+		},
+		{
+			desc: "clang driver",
+			msg:  "clang: error: no such file or directory: '../../base/foo.cc'\n",
+			want: []missingFile{
+				{filename: "../../base/foo.cc", diag: "clang"},
+			},
+		},
+		{
+			desc: "cl.exe",
+			msg: "foo.cc\r\n" +
+				`..\..\base\foo.cc(5): fatal error C1083: Cannot open include file: 'base/bar.h': No such file or directory` + "\r\n",
+			want: []missingFile{
+				{filename: "base/bar.h", diag: "cl"},
+			},
+		},
+		{
+			desc: "cl.exe source",
+			msg:  `c1xx: fatal error C1083: Cannot open source file: '..\..\base\foo.cc': No such file or directory` + "\r\n",
+			want: []missingFile{
+				{filename: `..\..\base\foo.cc`, diag: "cl"},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := extractMissingFiles([]byte(tc.msg))
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(missingFile{})); diff != "" {
+				t.Errorf("extractMissingFiles(%q) diff -want +got:\n%s", tc.msg, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,560 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/golang/protobuf/proto"
+
+	"go.chromium.org/goma/server/remoteexec/digest"
+)
+
+// crash causes.
+const (
+	crashLLVM    = "llvm-crash"
+	crashLLVMErr = "llvm-error"
+	crashSeccomp = "seccomp"
+	crashSignal  = "signal"
+	crashWinExc  = "exception"
+)
+
+// llvmCrashBanners are messages printed by LLVM's crash handler.
+var llvmCrashBanners = [][]byte{
+	[]byte("PLEASE submit a bug report"),
+	[]byte("Stack dump:"),
+	[]byte("failed due to signal"),
+}
+
+// detectCrash detects compiler crash from exit code and stdout/stderr.
+// It returns crash cause if the compiler crashed.
+func detectCrash(exitCode int32, stdout, stderr []byte) (string, bool) {
+	if exitCode == 0 {
+		return "", false
+	}
+	for _, msg := range [][]byte{stdout, stderr} {
+		for _, banner := range llvmCrashBanners {
+			if bytes.Contains(msg, banner) {
+				return crashLLVM, true
+			}
+		}
+	}
+	for _, msg := range [][]byte{stdout, stderr} {
+		if _, ok := extractLLVMError(msg); ok {
+			return crashLLVMErr, true
+		}
+	}
+	switch {
+	case exitCode == 159:
+		// 128 + SIGSYS: seccomp violation.
+		return crashSeccomp, true
+	case exitCode > 128 && exitCode <= 128+64:
+		// killed by signal.
+		return crashSignal, true
+	case exitCode < 0:
+		// windows exception code (e.g. 0xC0000005 access violation)
+		// appears as negative exit code.
+		return crashWinExc, true
+	}
+	return "", false
+}
+
+// ReproducerStore is a storage of crash reproducer bundles.
+type ReproducerStore interface {
+	// Create creates a new bundle named name.
+	// It returns an error that satisfies os.IsExist if the bundle
+	// already exists.
+	// The bundle will be available once the writer is closed
+	// successfully.
+	Create(ctx context.Context, name string) (ReproducerWriter, error)
+}
+
+// ReproducerWriter is a writer of a reproducer bundle.
+type ReproducerWriter interface {
+	io.WriteCloser
+
+	// Abort discards the bundle being written.
+	Abort()
+}
+
+// ReproducerDir is a ReproducerStore in local directory.
+type ReproducerDir string
+
+// Create creates a new bundle in the directory.
+func (d ReproducerDir) Create(ctx context.Context, name string) (ReproducerWriter, error) {
+	fname := filepath.Join(string(d), name)
+	_, err := os.Stat(fname)
+	if err == nil {
+		return nil, &os.PathError{Op: "create", Path: fname, Err: os.ErrExist}
+	}
+	err = os.MkdirAll(string(d), 0755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile(string(d), name+".tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &reproducerFile{File: f, fname: fname}, nil
+}
+
+// reproducerFile is a temporary file that is renamed to fname on close.
+type reproducerFile struct {
+	*os.File
+	fname string
+}
+
+func (f *reproducerFile) Close() error {
+	err := f.File.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	err = os.Rename(f.Name(), f.fname)
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (f *reproducerFile) Abort() {
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// detachedContext is a context that has values of parent context,
+// but is not canceled when parent is canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// reproducerName returns bundle name for the action.
+func reproducerName(actionDigest *rpb.Digest) string {
+	return fmt.Sprintf("%s-%d.tar.gz", actionDigest.GetHash(), actionDigest.GetSizeBytes())
+}
+
+// names in reproducer bundle.
+const (
+	reproducerCauseName   = "cause"
+	reproducerActionName  = "action.pb"
+	reproducerCommandName = "command.pb"
+	reproducerStdoutName  = "stdout"
+	reproducerStderrName  = "stderr"
+	reproducerBlobsDir    = "blobs"
+)
+
+func blobName(d *rpb.Digest) string {
+	return path.Join(reproducerBlobsDir, fmt.Sprintf("%s-%d", d.GetHash(), d.GetSizeBytes()))
+}
+
+// reproducer is a crash reproducer to be written in a bundle.
+type reproducer struct {
+	cause          string
+	actionDigest   *rpb.Digest
+	store          *digest.Store
+	stdout, stderr []byte
+}
+
+// writeTo writes reproducer bundle in tar.gz format to w.
+// The bundle contains cause, action, command, stdout, stderr and
+// blobs in the input tree of the action.
+func (rp reproducer) writeTo(ctx context.Context, w io.Writer) error {
+	action := &rpb.Action{}
+	err := readProto(ctx, rp.store, rp.actionDigest, action)
+	if err != nil {
+		return fmt.Errorf("action %v: %v", rp.actionDigest, err)
+	}
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	writeBytes := func(name string, b []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: int64(len(b)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(b)
+		return err
+	}
+	writeData := func(name string, d *rpb.Digest) error {
+		src, ok := rp.store.GetSource(d)
+		if !ok {
+			return fmt.Errorf("%s: not found for %v", name, d)
+		}
+		err := tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0644,
+			Size: d.GetSizeBytes(),
+		})
+		if err != nil {
+			return err
+		}
+		r, err := src.Open(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: open %s: %v", name, src, err)
+		}
+		defer r.Close()
+		n, err := io.Copy(tw, r)
+		if err != nil {
+			return fmt.Errorf("%s: copy %s: %v", name, src, err)
+		}
+		if n != d.GetSizeBytes() {
+			return fmt.Errorf("%s: size mismatch %s: %d != %d", name, src, n, d.GetSizeBytes())
+		}
+		return nil
+	}
+
+	err = writeBytes(reproducerCauseName, []byte(rp.cause))
+	if err != nil {
+		return err
+	}
+	err = writeData(reproducerActionName, rp.actionDigest)
+	if err != nil {
+		return err
+	}
+	err = writeData(reproducerCommandName, action.GetCommandDigest())
+	if err != nil {
+		return err
+	}
+	err = writeBytes(reproducerStdoutName, rp.stdout)
+	if err != nil {
+		return err
+	}
+	err = writeBytes(reproducerStderrName, rp.stderr)
+	if err != nil {
+		return err
+	}
+	digests, err := inputTreeDigests(ctx, rp.store, action.GetInputRootDigest())
+	if err != nil {
+		return fmt.Errorf("input tree %v: %v", action.GetInputRootDigest(), err)
+	}
+	sort.Slice(digests, func(i, j int) bool {
+		return blobName(digests[i]) < blobName(digests[j])
+	})
+	for _, d := range digests {
+		err = writeData(blobName(d), d)
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// inputTreeDigests returns digests of directories and files in the
+// input tree rooted at root.
+func inputTreeDigests(ctx context.Context, ds *digest.Store, root *rpb.Digest) ([]*rpb.Digest, error) {
+	seen := make(map[string]bool)
+	var digests []*rpb.Digest
+	add := func(d *rpb.Digest) bool {
+		name := blobName(d)
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+		digests = append(digests, d)
+		return true
+	}
+	dirs := []*rpb.Digest{root}
+	for len(dirs) > 0 {
+		d := dirs[0]
+		dirs = dirs[1:]
+		if !add(d) {
+			continue
+		}
+		dir := &rpb.Directory{}
+		err := readProto(ctx, ds, d, dir)
+		if err != nil {
+			return nil, fmt.Errorf("directory %v: %v", d, err)
+		}
+		for _, f := range dir.Files {
+			add(f.Digest)
+		}
+		for _, sd := range dir.Directories {
+			dirs = append(dirs, sd.Digest)
+		}
+	}
+	return digests, nil
+}
+
+func readProto(ctx context.Context, ds *digest.Store, d *rpb.Digest, m proto.Message) error {
+	src, ok := ds.GetSource(d)
+	if !ok {
+		return fmt.Errorf("not found for %v", d)
+	}
+	r, err := src.Open(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, m)
+}
+
+// maxReproducerMetaBytes is maximum size of non-blob entry in bundle,
+// which is read in memory.
+const maxReproducerMetaBytes = 64 << 20
+
+// Reproducer is a crash reproducer read from a bundle.
+type Reproducer struct {
+	// Cause is the crash cause detected by the adapter.
+	Cause   string
+	Action  *rpb.Action
+	Command *rpb.Command
+	Stdout  []byte
+	Stderr  []byte
+
+	// blobDir is a directory that has contents of the input tree,
+	// named by blob name without blobs dir.
+	blobDir string
+}
+
+// ReadReproducer reads a reproducer bundle from r.
+// Blobs in the bundle are extracted in blobDir, rather than
+// read in memory.
+func ReadReproducer(r io.Reader, blobDir string) (*Reproducer, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	err = os.MkdirAll(blobDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	rp := &Reproducer{
+		blobDir: blobDir,
+	}
+	var action, command []byte
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Dir(hdr.Name) == reproducerBlobsDir {
+			err = rp.extractBlob(hdr, tr)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if hdr.Size > maxReproducerMetaBytes {
+			return nil, fmt.Errorf("%s: too large %d > %d", hdr.Name, hdr.Size, maxReproducerMetaBytes)
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %v", hdr.Name, err)
+		}
+		switch hdr.Name {
+		case reproducerCauseName:
+			rp.Cause = string(b)
+		case reproducerActionName:
+			action = b
+		case reproducerCommandName:
+			command = b
+		case reproducerStdoutName:
+			rp.Stdout = b
+		case reproducerStderrName:
+			rp.Stderr = b
+		}
+	}
+	if action == nil || command == nil {
+		return nil, fmt.Errorf("no %s or %s in bundle", reproducerActionName, reproducerCommandName)
+	}
+	rp.Action = &rpb.Action{}
+	err = proto.Unmarshal(action, rp.Action)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", reproducerActionName, err)
+	}
+	rp.Command = &rpb.Command{}
+	err = proto.Unmarshal(command, rp.Command)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", reproducerCommandName, err)
+	}
+	return rp, nil
+}
+
+// extractBlob extracts blob entry hdr from r in blob dir.
+func (rp *Reproducer) extractBlob(hdr *tar.Header, r io.Reader) error {
+	name := path.Base(hdr.Name)
+	if !validEntryName(name) {
+		return fmt.Errorf("bad blob name %q", hdr.Name)
+	}
+	f, err := os.Create(filepath.Join(rp.blobDir, name))
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, r)
+	cerr := f.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("extract %s: %v", hdr.Name, err)
+	}
+	if n != hdr.Size {
+		return fmt.Errorf("extract %s: size mismatch %d != %d", hdr.Name, n, hdr.Size)
+	}
+	return nil
+}
+
+// blobFile returns filename of the blob for d in blob dir.
+func (rp *Reproducer) blobFile(d *rpb.Digest) (string, error) {
+	fname := filepath.Join(rp.blobDir, path.Base(blobName(d)))
+	fi, err := os.Stat(fname)
+	if err != nil {
+		return "", fmt.Errorf("blob %v not found in bundle: %v", d, err)
+	}
+	if fi.Size() != d.GetSizeBytes() {
+		return "", fmt.Errorf("blob %v size mismatch: %d", d, fi.Size())
+	}
+	return fname, nil
+}
+
+// Materialize materializes the input tree of the action in dir.
+// The command should run in the working directory of the command
+// relative to dir.
+func (rp *Reproducer) Materialize(dir string) error {
+	return rp.materializeDir(dir, ".", rp.Action.GetInputRootDigest())
+}
+
+// materializeDir materializes directory d in dir, which is located as
+// rel from root of the input tree.
+func (rp *Reproducer) materializeDir(dir, rel string, d *rpb.Digest) error {
+	fname, err := rp.blobFile(d)
+	if err != nil {
+		return fmt.Errorf("%s: %v", dir, err)
+	}
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("%s: %v", dir, err)
+	}
+	pd := &rpb.Directory{}
+	err = proto.Unmarshal(b, pd)
+	if err != nil {
+		return fmt.Errorf("%s: %v", dir, err)
+	}
+	names := make(map[string]bool)
+	checkName := func(kind, name string) error {
+		if !validEntryName(name) {
+			return fmt.Errorf("%s: bad %s name %q", dir, kind, name)
+		}
+		if names[name] {
+			return fmt.Errorf("%s: duplicate name %q", dir, name)
+		}
+		names[name] = true
+		return nil
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, f := range pd.Files {
+		err := checkName("file", f.Name)
+		if err != nil {
+			return err
+		}
+		src, err := rp.blobFile(f.Digest)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filepath.Join(dir, f.Name), err)
+		}
+		mode := os.FileMode(0644)
+		if f.IsExecutable {
+			mode = 0755
+		}
+		err = copyFile(filepath.Join(dir, f.Name), src, mode)
+		if err != nil {
+			return err
+		}
+	}
+	for _, s := range pd.Symlinks {
+		err := checkName("symlink", s.Name)
+		if err != nil {
+			return err
+		}
+		if !validSymlinkTarget(rel, s.Target) {
+			return fmt.Errorf("%s: bad symlink target %s -> %q", dir, s.Name, s.Target)
+		}
+		err = os.Symlink(filepath.FromSlash(s.Target), filepath.Join(dir, s.Name))
+		if err != nil {
+			return err
+		}
+	}
+	for _, sd := range pd.Directories {
+		err := checkName("directory", sd.Name)
+		if err != nil {
+			return err
+		}
+		err = rp.materializeDir(filepath.Join(dir, sd.Name), path.Join(rel, sd.Name), sd.Digest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(dst, src string, mode os.FileMode) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	cerr := w.Close()
+	if err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// validSymlinkTarget reports whether target of symlink in dir rel
+// is a relative path that doesn't escape from the input tree.
+func validSymlinkTarget(rel, target string) bool {
+	if target == "" || path.IsAbs(target) || strings.ContainsAny(target, `\:`) {
+		return false
+	}
+	p := path.Join(rel, target)
+	return p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// validEntryName reports whether name is a valid name in a directory,
+// so that materialized tree doesn't escape from the dir.
+func validEntryName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,366 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package remoteexec
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rpb "github.com/bazelbuild/remote-apis/build/bazel/remote/execution/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	gomapb "go.chromium.org/goma/server/proto/api"
+	"go.chromium.org/goma/server/remoteexec/digest"
+)
+
+func TestDetectCrash(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		exitCode int32
+		stdout   string
+		stderr   string
+		want     string
+	}{
+		{
+			desc: "success",
+		},
+		{
+			desc:     "compile error",
+			exitCode: 1,
+			stderr:   "foo.cc:1:1: error: unknown type name 'foo'\n",
+		},
+		{
+			desc:     "clang crash",
+			exitCode: 1,
+			stderr: `PLEASE submit a bug report to https://crbug.com and include the crash backtrace, preprocessed source, and associated run script.
+Stack dump:
+0.	Program arguments: clang++ -c foo.cc
+clang++: error: clang frontend command failed due to signal (use -v to see invocation)
+`,
+			want: crashLLVM,
+		},
+		{
+			desc:     "llvm error",
+			exitCode: 1,
+			stderr:   "LLVM ERROR: out of memory\n",
+			want:     crashLLVMErr,
+		},
+		{
+			desc:     "seccomp",
+			exitCode: 159,
+			want:     crashSeccomp,
+		},
+		{
+			desc:     "segv",
+			exitCode: 139,
+			want:     crashSignal,
+		},
+		{
+			desc:     "windows access violation",
+			exitCode: -1073741819,
+			want:     crashWinExc,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := detectCrash(tc.exitCode, []byte(tc.stdout), []byte(tc.stderr))
+			if got != tc.want || ok != (tc.want != "") {
+				t.Errorf("detectCrash(%d, %q, %q)=%q, %t; want %q, %t", tc.exitCode, tc.stdout, tc.stderr, got, ok, tc.want, tc.want != "")
+			}
+		})
+	}
+}
+
+func TestReproducerRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := digest.NewStore()
+	setBytes := func(name string, b []byte) *rpb.Digest {
+		t.Helper()
+		d := digest.Bytes(name, b)
+		store.Set(d)
+		return d.Digest()
+	}
+	setProto := func(m proto.Message) *rpb.Digest {
+		t.Helper()
+		d, err := digest.Proto(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Set(d)
+		return d.Digest()
+	}
+
+	binDir := setProto(&rpb.Directory{
+		Files: []*rpb.FileNode{
+			{
+				Name:         "clang",
+				Digest:       setBytes("clang", []byte("clang binary")),
+				IsExecutable: true,
+			},
+		},
+		Symlinks: []*rpb.SymlinkNode{
+			{
+				Name:   "clang++",
+				Target: "clang",
+			},
+		},
+	})
+	root := setProto(&rpb.Directory{
+		Files: []*rpb.FileNode{
+			{
+				Name:   "foo.cc",
+				Digest: setBytes("foo.cc", []byte("int main() {}\n")),
+			},
+		},
+		Directories: []*rpb.DirectoryNode{
+			{
+				Name:   "bin",
+				Digest: binDir,
+			},
+		},
+	})
+	command := &rpb.Command{
+		Arguments:   []string{"bin/clang++", "-c", "foo.cc"},
+		OutputFiles: []string{"foo.o"},
+	}
+	action := &rpb.Action{
+		CommandDigest:   setProto(command),
+		InputRootDigest: root,
+	}
+	actionDigest := setProto(action)
+
+	dir, err := ioutil.TempDir("", "reproducer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rs := ReproducerDir(filepath.Join(dir, "bundles"))
+	name := reproducerName(actionDigest)
+	w, err := rs.Create(ctx, name)
+	if err != nil {
+		t.Fatalf("Create(ctx, %q)=_, %v; want nil error", name, err)
+	}
+	rp := reproducer{
+		cause:        crashLLVM,
+		actionDigest: actionDigest,
+		store:        store,
+		stderr:       []byte("Stack dump:\n"),
+	}
+	err = rp.writeTo(ctx, w)
+	if err != nil {
+		t.Fatalf("writeTo()=%v; want nil error", err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("Close()=%v; want nil error", err)
+	}
+	_, err = rs.Create(ctx, name)
+	if !os.IsExist(err) {
+		t.Errorf("Create(ctx, %q) again=_, %v; want exist error", name, err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "bundles", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := ReadReproducer(f, filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("ReadReproducer()=_, %v; want nil error", err)
+	}
+	if got.Cause != crashLLVM {
+		t.Errorf("Cause=%q; want %q", got.Cause, crashLLVM)
+	}
+	if !proto.Equal(got.Action, action) {
+		t.Errorf("Action=%v; want %v", got.Action, action)
+	}
+	if !proto.Equal(got.Command, command) {
+		t.Errorf("Command=%v; want %v", got.Command, command)
+	}
+	if diff := cmp.Diff("Stack dump:\n", string(got.Stderr)); diff != "" {
+		t.Errorf("Stderr diff -want +got:\n%s", diff)
+	}
+
+	inputDir := filepath.Join(dir, "input")
+	err = got.Materialize(inputDir)
+	if err != nil {
+		t.Fatalf("Materialize(%q)=%v; want nil error", inputDir, err)
+	}
+	for fname, want := range map[string]string{
+		"foo.cc":      "int main() {}\n",
+		"bin/clang":   "clang binary",
+		"bin/clang++": "clang binary",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(inputDir, fname))
+		if err != nil {
+			t.Errorf("ReadFile(%q)=_, %v; want nil error", fname, err)
+			continue
+		}
+		if string(b) != want {
+			t.Errorf("%s=%q; want %q", fname, b, want)
+		}
+	}
+	fi, err := os.Stat(filepath.Join(inputDir, "bin/clang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&0100 == 0 {
+		t.Errorf("bin/clang mode=%v; want executable", fi.Mode())
+	}
+}
+
+func TestCaptureReproducer(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "reproducer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := digest.NewStore()
+	setProto := func(m proto.Message) *rpb.Digest {
+		t.Helper()
+		d, err := digest.Proto(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Set(d)
+		return d.Digest()
+	}
+	actionDigest := setProto(&rpb.Action{
+		CommandDigest:   setProto(&rpb.Command{Arguments: []string{"clang"}}),
+		InputRootDigest: setProto(&rpb.Directory{}),
+	})
+	sema := make(chan struct{}, 1)
+	r := &request{
+		f: &Adapter{
+			CrashReproducers: ReproducerDir(dir),
+			ReproducerSema:   sema,
+		},
+		gomaResp:     &gomapb.ExecResp{},
+		digestStore:  store,
+		actionDigest: actionDigest,
+		crash:        crashSignal,
+	}
+	fname := filepath.Join(dir, reproducerName(actionDigest))
+
+	// no capture slot available.
+	sema <- struct{}{}
+	r.captureReproducer(ctx)
+	<-sema
+	if _, err := os.Stat(fname); !os.IsNotExist(err) {
+		t.Errorf("reproducer exists while no slot available: %v", err)
+	}
+
+	r.captureReproducer(ctx)
+	// wait for background capture.
+	sema <- struct{}{}
+	<-sema
+	if _, err := os.Stat(fname); err != nil {
+		t.Errorf("reproducer not captured: %v", err)
+	}
+}
+
+func TestReproducerMaterializeBadTree(t *testing.T) {
+	file := &rpb.FileNode{
+		Name:   "foo.cc",
+		Digest: digest.Bytes("foo.cc", []byte("int main() {}\n")).Digest(),
+	}
+	for _, tc := range []struct {
+		desc string
+		dir  *rpb.Directory
+	}{
+		{
+			desc: "absolute symlink",
+			dir: &rpb.Directory{
+				Symlinks: []*rpb.SymlinkNode{
+					{Name: "passwd", Target: "/etc/passwd"},
+				},
+			},
+		},
+		{
+			desc: "escaping symlink",
+			dir: &rpb.Directory{
+				Symlinks: []*rpb.SymlinkNode{
+					{Name: "parent", Target: "../.."},
+				},
+			},
+		},
+		{
+			desc: "windows symlink",
+			dir: &rpb.Directory{
+				Symlinks: []*rpb.SymlinkNode{
+					{Name: "system", Target: `C:\Windows`},
+				},
+			},
+		},
+		{
+			desc: "duplicate name",
+			dir: &rpb.Directory{
+				Files: []*rpb.FileNode{file},
+				Symlinks: []*rpb.SymlinkNode{
+					{Name: "foo.cc", Target: "bar.cc"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "reproducer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			blobDir := filepath.Join(dir, "blobs")
+			err = os.Mkdir(blobDir, 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeBlob := func(d digest.Data) *rpb.Digest {
+				t.Helper()
+				r, err := d.Open(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer r.Close()
+				b, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = ioutil.WriteFile(filepath.Join(blobDir, filepath.Base(blobName(d.Digest()))), b, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return d.Digest()
+			}
+			writeBlob(digest.Bytes("foo.cc", []byte("int main() {}\n")))
+			sub, err := digest.Proto(tc.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root, err := digest.Proto(&rpb.Directory{
+				Directories: []*rpb.DirectoryNode{
+					{Name: "sub", Digest: writeBlob(sub)},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rp := &Reproducer{
+				Action: &rpb.Action{
+					InputRootDigest: writeBlob(root),
+				},
+				blobDir: blobDir,
+			}
+			err = rp.Materialize(filepath.Join(dir, "input"))
+			if err == nil {
+				t.Errorf("Materialize()=nil; want error")
+			}
+		})
+	}
+}
@@ -31,6 +31,19 @@ var (
 		stats.UnitDimensionless)
 	compilerNameKey = tag.MustNewKey("compiler")
 
+	missingRemoteInputCount = stats.Int64(
+		"go.chromium.org/goma/server/remoteexec.missing-remote-inputs",
+		"Number of missing files reported by compilers in remote",
+		stats.UnitDimensionless)
+
+	crashReproducerCount = stats.Int64(
+		"go.chromium.org/goma/server/remoteexec.crash-reproducers",
+		"Number of compiler crashes in remote and its reproducer capture status",
+		stats.UnitDimensionless)
+
+	crashCauseKey = tag.MustNewKey("cause")
+	reproducerKey = tag.MustNewKey("reproducer")
+
 	inputBufferAllocSize = stats.Int64(
 		"go.chromium.org/goma/server/remoteexec.input-buffer-alloc",
 		"Size to allocate buffer for input files",
@@ -128,6 +141,23 @@ var (
 			},
 			Aggregation: view.Count(),
 		},
+		{
+			Description: "Number of missing files reported by compilers in remote",
+			Measure:     missingRemoteInputCount,
+			TagKeys: []tag.Key{
+				compilerNameKey,
+			},
+			Aggregation: view.Count(),
+		},
+		{
+			Description: "Number of compiler crashes in remote and its reproducer capture status",
+			Measure:     crashReproducerCount,
+			TagKeys: []tag.Key{
+				crashCauseKey,
+				reproducerKey,
+			},
+			Aggregation: view.Count(),
+		},
 		{
 			Description: "Size to allocate buffer for input files",
 			TagKeys: []tag.Key{
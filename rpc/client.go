@@ -16,12 +16,17 @@ import (
 	"go.opencensus.io/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"go.chromium.org/goma/server/log"
 )
 
 const (
 	lookupInterval = 3 * time.Second
+
+	// unhealthyDuration is duration to treat backend as unhealthy
+	// after it returns Unavailable error.
+	unhealthyDuration = 10 * time.Second
 )
 
 // backend represents each backend per IP.
@@ -35,6 +40,8 @@ type backend struct {
 	nerr   int64
 	load   int
 	err    error
+
+	unhealthyUntil time.Time
 }
 
 func (b *backend) init(ctx context.Context, target string, newc func(*grpc.ClientConn) interface{}, dialOpts []grpc.DialOption) error {
@@ -79,6 +86,17 @@ func (b *backend) done(ctx context.Context, err error) {
 		b.nerr++
 		logger.Warnf("backend error %s: %v", b.addr, err)
 	}
+	if status.Code(err) == codes.Unavailable {
+		b.unhealthyUntil = time.Now().Add(unhealthyDuration)
+	}
+}
+
+// healthy reports whether backend is healthy, i.e. it didn't return
+// Unavailable error recently.
+func (b *backend) healthy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err == nil && time.Now().After(b.unhealthyUntil)
 }
 
 func (b *backend) close() {
@@ -99,6 +117,8 @@ type Client struct {
 	newc     func(cc *grpc.ClientConn) interface{}
 	dialOpts []grpc.DialOption
 
+	lookupHost func(host string) ([]string, error)
+
 	closech chan chan bool
 
 	mu        sync.RWMutex
@@ -106,6 +126,7 @@ type Client struct {
 	addrs     []string
 	backends  map[string]*backend
 	shards    *consistenthash.Map
+	ring      *ring
 }
 
 var (
@@ -132,15 +153,25 @@ func DialOptions(opts ...grpc.DialOption) []Option {
 	return dopts
 }
 
+// LookupHost returns an Option to resolve target's host by f,
+// instead of net.LookupHost.
+// It would be used for static backend addresses.
+func LookupHost(f func(host string) ([]string, error)) Option {
+	return func(c *Client) {
+		c.lookupHost = f
+	}
+}
+
 // NewClient creates new load-balancing client.
 // newc should return grpc client interface for given *grpc.ClientConn.
 // target is <hostname>:<port>.
 // TODO: support grpc new naming?
 func NewClient(ctx context.Context, target string, newc func(cc *grpc.ClientConn) interface{}, opts ...Option) *Client {
 	client := &Client{
-		target:  target,
-		newc:    newc,
-		closech: make(chan chan bool),
+		target:     target,
+		newc:       newc,
+		closech:    make(chan chan bool),
+		lookupHost: net.LookupHost,
 	}
 	for _, opt := range opts {
 		opt(client)
@@ -185,7 +216,7 @@ func (c *Client) update(ctx context.Context) error {
 	if err != nil {
 		logger.Errorf("lookup %s ... %v", c.target, err)
 	}
-	addrs, err := net.LookupHost(host)
+	addrs, err := c.lookupHost(host)
 	if err != nil {
 		logger.Errorf("lookup %s ... %v", host, err)
 		return err
@@ -236,6 +267,7 @@ func (c *Client) update(ctx context.Context) error {
 	c.backends = backends
 	c.shards = consistenthash.New(len(addrs), nil)
 	c.shards.Add(addrs...)
+	c.ring = newRing(addrs)
 	return nil
 }
 
@@ -277,10 +309,92 @@ func (c *Client) Shard(ctx context.Context, key interface{}) (*backend, error) {
 	return b, nil
 }
 
+// ShardKeys groups keys by backend that Shard picks for the key.
+// It returns indexes of keys for each backend.
+// It is used to send batch request to each shard.
+func (c *Client) ShardKeys(ctx context.Context, keys []string) ([][]int, error) {
+	logger := log.FromContext(ctx)
+	err := c.update(ctx)
+	if err != nil {
+		logger.Errorf("lookup failed: %v", err)
+		return nil, grpc.Errorf(codes.Aborted, "rpc: %s lookup failed: %v", c.target, err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var groups [][]int
+	m := make(map[string]int)
+	for i, key := range keys {
+		addr := c.shards.Get(key)
+		if addr == "" {
+			logger.Errorf("no backend")
+			return nil, grpc.Errorf(codes.Aborted, "rpc: %s no backends available", c.target)
+		}
+		j, ok := m[addr]
+		if !ok {
+			j = len(groups)
+			m[addr] = j
+			groups = append(groups, nil)
+		}
+		groups[j] = append(groups[j], i)
+	}
+	return groups, nil
+}
+
+// Replicas returns at most n distinct backend addresses for the key,
+// in order of the hash ring, but healthy backends come first.
+// Each address can be used as key for PickAddr.
+// Adding or removing a backend only changes replicas of the keys
+// that the backend holds.
+func (c *Client) Replicas(ctx context.Context, key string, n int) ([]string, error) {
+	logger := log.FromContext(ctx)
+	err := c.update(ctx)
+	if err != nil {
+		logger.Errorf("lookup failed: %v", err)
+		return nil, grpc.Errorf(codes.Aborted, "rpc: %s lookup failed: %v", c.target, err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	addrs := c.ring.get(key, n)
+	if len(addrs) == 0 {
+		logger.Errorf("no backend")
+		return nil, grpc.Errorf(codes.Aborted, "rpc: %s no backends available", c.target)
+	}
+	var healthy, unhealthy []string
+	for _, addr := range addrs {
+		if c.backends[addr].healthy() {
+			healthy = append(healthy, addr)
+			continue
+		}
+		unhealthy = append(unhealthy, addr)
+	}
+	return append(healthy, unhealthy...), nil
+}
+
+// PickAddr picks backend for key, which is backend address returned by
+// Replicas.
+func (c *Client) PickAddr(ctx context.Context, key interface{}) (*backend, error) {
+	logger := log.FromContext(ctx)
+	addr, _ := key.(string)
+	c.mu.RLock()
+	b := c.backends[addr]
+	c.mu.RUnlock()
+	if b == nil {
+		logger.Errorf("no backend %s", addr)
+		return nil, grpc.Errorf(codes.Aborted, "rpc: %s no backend %s", c.target, addr)
+	}
+	err := b.init(ctx, c.target, c.newc, c.dialOpts)
+	if err != nil {
+		return nil, err
+	}
+	b.use()
+	return b, nil
+}
+
 // Call calls new rpc call.
 // picker and key will be used to pick backend.
 // picker will be Client's Pick, or Shard.
 // Pick will use key for backend addr, or empty for least loaded.
+// PickAddr will use key for backend address returned by Replicas.
 // Shard will use key for sharding.
 // Rand will use key for *RandomState.
 // f is called with grpc client inferface for selected backend.
@@ -0,0 +1,77 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package rpc
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// ringVirtualNodes is number of virtual nodes per address on the hash ring.
+// It is fixed regardless of number of addresses, so adding or removing
+// an address only moves keys from/to the address.
+const ringVirtualNodes = 128
+
+// ring is a consistent hash ring that picks multiple distinct addresses
+// for a key.
+type ring struct {
+	hashes []uint32
+	addrs  map[uint32]string
+	naddrs int
+}
+
+func newRing(addrs []string) *ring {
+	r := &ring{
+		addrs: make(map[uint32]string),
+	}
+	seen := make(map[string]bool)
+	for _, addr := range addrs {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		r.naddrs++
+		for i := 0; i < ringVirtualNodes; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + addr))
+			if _, ok := r.addrs[h]; ok {
+				// hash collision. keep first one.
+				continue
+			}
+			r.addrs[h] = addr
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+	return r
+}
+
+// get returns at most n distinct addresses for key, in order of
+// walking the ring clockwise from the key's hash.
+func (r *ring) get(key string, n int) []string {
+	if r == nil || len(r.hashes) == 0 || n <= 0 {
+		return nil
+	}
+	if n > r.naddrs {
+		n = r.naddrs
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	addrs := make([]string, 0, n)
+	seen := make(map[string]bool)
+	for j := 0; j < len(r.hashes) && len(addrs) < n; j++ {
+		addr := r.addrs[r.hashes[(i+j)%len(r.hashes)]]
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
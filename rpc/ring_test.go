@@ -0,0 +1,84 @@
+// Copyright 2021 The Goma Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package rpc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRingGet(t *testing.T) {
+	r := newRing([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.2"})
+	for _, tc := range []struct {
+		n    int
+		want int
+	}{
+		{n: 0, want: 0},
+		{n: 1, want: 1},
+		{n: 2, want: 2},
+		{n: 3, want: 3},
+		{n: 4, want: 3},
+	} {
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("key%d", i)
+			addrs := r.get(key, tc.n)
+			if len(addrs) != tc.want {
+				t.Fatalf("get(%q, %d)=%q; want %d addrs", key, tc.n, addrs, tc.want)
+			}
+			seen := make(map[string]bool)
+			for _, addr := range addrs {
+				if seen[addr] {
+					t.Errorf("get(%q, %d)=%q; duplicate %s", key, tc.n, addrs, addr)
+				}
+				seen[addr] = true
+			}
+			if tc.n > 0 {
+				if got, want := addrs[0], r.get(key, 1)[0]; got != want {
+					t.Errorf("get(%q, %d)[0]=%q; want %q", key, tc.n, got, want)
+				}
+			}
+		}
+	}
+
+	var empty *ring
+	if got := empty.get("key", 1); len(got) != 0 {
+		t.Errorf("nil ring get=%q; want empty", got)
+	}
+}
+
+func TestRingMinimalMove(t *testing.T) {
+	addrs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+	const newAddr = "10.0.0.5"
+	const n = 2
+	const nkeys = 10000
+
+	r := newRing(addrs)
+	nr := newRing(append(append([]string{}, addrs...), newAddr))
+	moved := 0
+	for i := 0; i < nkeys; i++ {
+		key := fmt.Sprintf("key%d", i)
+		before := r.get(key, n)
+		after := nr.get(key, n)
+		// removing new address from after should give prefix of before.
+		var rest []string
+		for _, addr := range after {
+			if addr != newAddr {
+				rest = append(rest, addr)
+			}
+		}
+		for j := range rest {
+			if rest[j] != before[j] {
+				t.Fatalf("key %q: replicas %q -> %q; moved between old addresses", key, before, after)
+			}
+		}
+		if len(rest) != len(after) {
+			moved++
+		}
+	}
+	// new address should hold about n/5 of keys.
+	if got, want := float64(moved)/nkeys, float64(n)/5; got < want*0.7 || got > want*1.3 {
+		t.Errorf("moved %d/%d keys=%.3f; want about %.3f", moved, nkeys, got, want)
+	}
+}
@@ -8,6 +8,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -112,6 +113,28 @@ func NewHTTPS(hs *http.Server, certFile, keyFile string) Server {
 	return httpsServer{Server: hs, certFile: certFile, keyFile: keyFile}
 }
 
+type closer struct {
+	io.Closer
+	done chan struct{}
+}
+
+func (c closer) ListenAndServe() error {
+	<-c.done
+	return nil
+}
+
+func (c closer) Shutdown(ctx context.Context) error {
+	defer close(c.done)
+	return c.Closer.Close()
+}
+
+// NewCloser creates server that closes c on shutdown.
+// Run exits without running deferred functions, so resources that
+// need to be flushed on exit should be closed by this server.
+func NewCloser(c io.Closer) Server {
+	return closer{Closer: c, done: make(chan struct{})}
+}
+
 // Run runs servers.
 // This is typically invoked as the last statement in the server's main function.
 func Run(ctx context.Context, servers ...Server) {